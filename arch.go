@@ -0,0 +1,218 @@
+package rpm
+
+import "strings"
+
+type canon struct {
+	name string
+	num  uint16
+}
+
+// arch_canon from rpmrc, the first entry for a number is used
+// when formatting.
+var archCanon = []canon{
+	{"x86_64", 1},
+	{"i386", 1},
+	{"i486", 1},
+	{"i586", 1},
+	{"i686", 1},
+	{"athlon", 1},
+	{"geode", 1},
+	{"pentium3", 1},
+	{"pentium4", 1},
+	{"amd64", 1},
+	{"ia32e", 1},
+	{"em64t", 1},
+	{"alpha", 2},
+	{"alphaev5", 2},
+	{"alphaev56", 2},
+	{"alphapca56", 2},
+	{"alphaev6", 2},
+	{"alphaev67", 2},
+	{"sparc64", 2},
+	{"sparc", 3},
+	{"sparcv8", 3},
+	{"sparcv9", 3},
+	{"sparcv9v", 3},
+	{"mips", 4},
+	{"mipsel", 4},
+	{"ppc", 5},
+	{"ppc8260", 5},
+	{"ppc8560", 5},
+	{"ppc32dy4", 5},
+	{"ppciseries", 5},
+	{"ppcpseries", 5},
+	{"m68k", 6},
+	{"sgi", 7},
+	{"rs6000", 8},
+	{"ia64", 9},
+	{"mips64", 11},
+	{"mips64el", 11},
+	{"armv3l", 12},
+	{"armv4b", 12},
+	{"armv4l", 12},
+	{"armv5tl", 12},
+	{"armv5tel", 12},
+	{"armv5tejl", 12},
+	{"armv6l", 12},
+	{"armv6hl", 12},
+	{"armv7l", 12},
+	{"armv7hl", 12},
+	{"armv7hnl", 12},
+	{"armv8l", 12},
+	{"armv8hl", 12},
+	{"m68kmint", 13},
+	{"s390", 14},
+	{"i370", 14},
+	{"s390x", 15},
+	{"ppc64", 16},
+	{"ppc64le", 16},
+	{"ppc64p7", 16},
+	{"ppc64pseries", 16},
+	{"ppc64iseries", 16},
+	{"sh", 17},
+	{"sh3", 17},
+	{"sh4", 17},
+	{"sh4a", 17},
+	{"xtensa", 18},
+	{"aarch64", 19},
+	{"mipsr6", 20},
+	{"mipsr6el", 20},
+	{"mips64r6", 21},
+	{"mips64r6el", 21},
+	{"riscv64", 22},
+	{"loongarch64", 23},
+}
+
+// os_canon from rpmrc
+var osCanon = []canon{
+	{"linux", 1},
+	{"irix", 2},
+	{"solaris", 3},
+	{"sunos", 4},
+	{"amigaos", 5},
+	{"aix", 5},
+	{"hpux10", 6},
+	{"osf1", 7},
+	{"freebsd", 8},
+	{"sco_sv3.2v5.0.2", 9},
+	{"irix64", 10},
+	{"nextstep", 11},
+	{"bsdi", 12},
+	{"machten", 13},
+	{"cygwin32", 14},
+	{"mp_ras", 16},
+	{"freemint", 17},
+	{"os/390", 18},
+	{"vm/esa", 19},
+	{"darwin", 21},
+	{"macosx", 21},
+	{"netbsd", 22},
+	{"openbsd", 23},
+}
+
+func canonNum(c []canon, name string) (uint16, bool) {
+	name = strings.ToLower(name)
+	for _, v := range c {
+		if v.name == name {
+			return v.num, true
+		}
+	}
+	return 0, false
+}
+
+func canonName(c []canon, num uint16) (string, bool) {
+	for _, v := range c {
+		if v.num == num {
+			return v.name, true
+		}
+	}
+	return "", false
+}
+
+func ArchNum(arch string) (uint16, bool) { return canonNum(archCanon, arch) }
+func ArchName(num uint16) (string, bool) { return canonName(archCanon, num) }
+func OSNum(os string) (uint16, bool)     { return canonNum(osCanon, os) }
+func OSName(num uint16) (string, bool)   { return canonName(osCanon, num) }
+
+// arch_compat from rpmrc, noarch is handled separately.
+var archCompat = map[string][]string{
+	"x86_64":       {"amd64", "em64t", "athlon"},
+	"amd64":        {"x86_64", "em64t", "athlon"},
+	"ia32e":        {"x86_64", "em64t", "athlon"},
+	"em64t":        {"x86_64", "amd64", "athlon"},
+	"athlon":       {"i686"},
+	"pentium4":     {"pentium3"},
+	"pentium3":     {"i686"},
+	"geode":        {"i686"},
+	"i686":         {"i586"},
+	"i586":         {"i486"},
+	"i486":         {"i386"},
+	"alphaev67":    {"alphaev6"},
+	"alphaev6":     {"alphapca56"},
+	"alphapca56":   {"alphaev56"},
+	"alphaev56":    {"alphaev5"},
+	"alphaev5":     {"alpha"},
+	"sparc64":      {"sparcv9"},
+	"sparcv9v":     {"sparcv9"},
+	"sparcv9":      {"sparcv8"},
+	"sparcv8":      {"sparc"},
+	"mips64":       {"mips"},
+	"mips64el":     {"mipsel"},
+	"ppc64p7":      {"ppc64"},
+	"ppc64pseries": {"ppc64"},
+	"ppc64iseries": {"ppc64"},
+	"ppc64":        {"ppc"},
+	"ppciseries":   {"ppc"},
+	"ppcpseries":   {"ppc"},
+	"ppc":          {"rs6000"},
+	"s390x":        {"s390"},
+	"armv8hl":      {"armv7hnl"},
+	"armv7hnl":     {"armv7hl"},
+	"armv7hl":      {"armv6hl"},
+	"armv8l":       {"armv7l"},
+	"armv7l":       {"armv6l"},
+	"armv6l":       {"armv5tejl"},
+	"armv5tejl":    {"armv5tel"},
+	"armv5tel":     {"armv5tl"},
+	"armv5tl":      {"armv4tl"},
+	"armv4tl":      {"armv4l"},
+	"armv4l":       {"armv3l"},
+	"sh4a":         {"sh4"},
+	"sh4":          {"sh3"},
+}
+
+// ArchCompatible reports whether packages built for pkg can be
+// installed on arch.
+func ArchCompatible(arch, pkg string) bool {
+	seen := make(map[string]bool)
+	next := []string{arch}
+	for len(next) > 0 {
+		a := next[0]
+		next = next[1:]
+		if a == pkg {
+			return true
+		}
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		next = append(next, archCompat[a]...)
+	}
+	return false
+}
+
+func MatchesArch(hdr *Header, arch string, noarch bool) bool {
+	a, ok := hdr.StringData(RPMTAG_ARCH)
+	if !ok {
+		return false
+	}
+	if a == "noarch" {
+		return noarch
+	}
+	return ArchCompatible(arch, a)
+}
+
+func MatchesOS(hdr *Header, os string) bool {
+	o, ok := hdr.StringData(RPMTAG_OS)
+	return ok && strings.EqualFold(o, os)
+}
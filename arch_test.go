@@ -0,0 +1,63 @@
+package rpm
+
+import "testing"
+
+func TestArchCompatible(t *testing.T) {
+	for _, v := range []struct {
+		arch, pkg string
+		ok        bool
+	}{
+		{"x86_64", "x86_64", true},
+		{"x86_64", "i686", true},
+		{"x86_64", "i386", true},
+		{"i686", "x86_64", false},
+		{"i386", "i686", false},
+		{"aarch64", "aarch64", true},
+		{"aarch64", "x86_64", false},
+		{"armv7hl", "armv6hl", true},
+		{"ppc64", "ppc", true},
+		{"s390x", "s390", true},
+		{"x86_64", "noarch", false},
+	} {
+		if a := ArchCompatible(v.arch, v.pkg); a != v.ok {
+			t.Errorf("%s/%s: want %v, have %v", v.arch, v.pkg, v.ok, a)
+		}
+	}
+}
+
+func TestMatchesArch(t *testing.T) {
+	hdr := new(Header)
+	if MatchesArch(hdr, "x86_64", true) {
+		t.Fatalf("matched without arch tag")
+	}
+
+	hdr.AddString(RPMTAG_ARCH, "noarch")
+	hdr.AddString(RPMTAG_OS, "linux")
+	if !MatchesArch(hdr, "x86_64", true) {
+		t.Fatalf("noarch not allowed")
+	}
+	if MatchesArch(hdr, "x86_64", false) {
+		t.Fatalf("noarch allowed")
+	}
+	if !MatchesOS(hdr, "Linux") {
+		t.Fatalf("os mismatch")
+	}
+
+	hdr = new(Header)
+	hdr.AddString(RPMTAG_ARCH, "i686")
+	if !MatchesArch(hdr, "x86_64", false) || MatchesArch(hdr, "aarch64", true) {
+		t.Fatalf("i686 compat")
+	}
+}
+
+func TestArchNum(t *testing.T) {
+	if n, ok := ArchNum("AArch64"); !ok || n != 19 {
+		t.Fatalf("aarch64: %d", n)
+	}
+	if s, ok := OSName(1); !ok || s != "linux" {
+		t.Fatalf("os: %s", s)
+	}
+	if _, ok := ArchName(0xfff); ok {
+		t.Fatalf("unexpected arch")
+	}
+}
@@ -0,0 +1,34 @@
+package rpm
+
+// CapabilitySet describes the file metadata that survives packaging
+// with FileIndex and the stripped cpio payload.
+type CapabilitySet struct {
+	Owners      bool // user and group names
+	Modes       bool // permission bits
+	SpecialBits bool // setuid, setgid and sticky
+	MTimes      bool
+	Symlinks    bool
+	Digests     bool
+	Verity      bool // fs-verity signatures, see sign.SignVerity
+	FileCaps    bool // security.capability, RPMTAG_FILECAPS
+	SELinux     bool // security.selinux, RPMTAG_FILECONTEXTS
+	Xattrs      bool // any other extended attribute
+	Hardlinks   bool // RPMTAG_FILEINODES and RPMTAG_FILEDEVICES
+	Devices     bool // device nodes, fifos and sockets
+	Sparse      bool // holes are stored as zeros otherwise
+}
+
+// Capabilities returns the metadata preserved by this package, tools
+// can check it before relying on it.
+func Capabilities() CapabilitySet {
+	return CapabilitySet{
+		Owners:   true,
+		Modes:    true,
+		MTimes:   true,
+		Symlinks: true,
+		Digests:  true,
+		Verity:   true,
+		FileCaps: true,
+		SELinux:  true,
+	}
+}
@@ -0,0 +1,57 @@
+package rpm
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	c := Capabilities()
+
+	_, err := Mode(os.ModeDevice | 0600)
+	if a, b := err == nil, c.Devices; a != b {
+		t.Fatalf("devices: mode %v, capability %v", a, b)
+	}
+	m, err := Mode(os.ModeSetuid | 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := m&04000 != 0, c.SpecialBits; a != b {
+		t.Fatalf("special bits: mode %v, capability %v", a, b)
+	}
+	if m, err := Mode(os.ModeSymlink | 0777); (err == nil) != c.Symlinks ||
+		m>>12 != typeSymlink {
+		t.Fatalf("symlinks: %o, %v", m, err)
+	}
+
+	fi := NewFileIndex()
+	fi.Add(&File{
+		Name:    "/usr/bin/foo",
+		User:    "foo",
+		Group:   "bar",
+		Mode:    typeRegular<<12 | 0755,
+		MTime:   1,
+		Digest:  "00",
+		Caps:    "cap_net_raw=ep",
+		Context: "system_u:object_r:bin_t:s0",
+	})
+	hdr := new(Header)
+	fi.Append(hdr)
+	for _, v := range []struct {
+		tag TagType
+		c   bool
+	}{
+		{RPMTAG_FILEUSERNAME, c.Owners},
+		{RPMTAG_FILEMODES, c.Modes},
+		{RPMTAG_FILEMTIMES, c.MTimes},
+		{RPMTAG_FILEDIGESTS, c.Digests},
+		{RPMTAG_FILECAPS, c.FileCaps},
+		{RPMTAG_FILECONTEXTS, c.SELinux},
+		{RPMTAG_FILEINODES, c.Hardlinks},
+		{RPMTAG_FILEDEVICES, c.Hardlinks},
+	} {
+		if a := hdr.Find(v.tag) != nil; a != v.c {
+			t.Fatalf("%s: tag %v, capability %v", v.tag, a, v.c)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rpm
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var capNames = [...]string{
+	"cap_chown",
+	"cap_dac_override",
+	"cap_dac_read_search",
+	"cap_fowner",
+	"cap_fsetid",
+	"cap_kill",
+	"cap_setgid",
+	"cap_setuid",
+	"cap_setpcap",
+	"cap_linux_immutable",
+	"cap_net_bind_service",
+	"cap_net_broadcast",
+	"cap_net_admin",
+	"cap_net_raw",
+	"cap_ipc_lock",
+	"cap_ipc_owner",
+	"cap_sys_module",
+	"cap_sys_rawio",
+	"cap_sys_chroot",
+	"cap_sys_ptrace",
+	"cap_sys_pacct",
+	"cap_sys_admin",
+	"cap_sys_boot",
+	"cap_sys_nice",
+	"cap_sys_resource",
+	"cap_sys_time",
+	"cap_sys_tty_config",
+	"cap_mknod",
+	"cap_lease",
+	"cap_audit_write",
+	"cap_audit_control",
+	"cap_setfcap",
+	"cap_mac_override",
+	"cap_mac_admin",
+	"cap_syslog",
+	"cap_wake_alarm",
+	"cap_block_suspend",
+	"cap_audit_read",
+	"cap_perfmon",
+	"cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+const (
+	vfsCapRevisionMask = 0xff000000
+	vfsCapRevision2    = 0x02000000
+	vfsCapRevision3    = 0x03000000
+	vfsCapEffective    = 0x1
+)
+
+var errCapability = errors.New("rpm: invalid security.capability")
+
+func capName(i int) string {
+	if i < len(capNames) {
+		return capNames[i]
+	}
+	return strconv.Itoa(i)
+}
+
+// CapabilityText converts a security.capability xattr to the text form
+// stored in RPMTAG_FILECAPS, e.g. "cap_net_raw=ep".
+func CapabilityText(xattr []byte) (string, error) {
+	if len(xattr) < 4 {
+		return "", errCapability
+	}
+	magic := binary.LittleEndian.Uint32(xattr)
+	switch magic & vfsCapRevisionMask {
+	case vfsCapRevision2:
+		if len(xattr) != 20 {
+			return "", errCapability
+		}
+	case vfsCapRevision3:
+		// v3 adds the namespace root uid
+		if len(xattr) != 24 {
+			return "", errCapability
+		}
+	default:
+		return "", errCapability
+	}
+
+	var perm, inh uint64
+	for i := 0; i < 2; i++ {
+		perm |= uint64(binary.LittleEndian.Uint32(xattr[4+i*8:])) << (32 * i)
+		inh |= uint64(binary.LittleEndian.Uint32(xattr[8+i*8:])) << (32 * i)
+	}
+
+	// group capabilities with the same flags like cap_to_text
+	var (
+		order  []string
+		groups = make(map[string][]string)
+	)
+	for i := 0; i < 64; i++ {
+		var f string
+		if magic&vfsCapEffective != 0 && perm&(1<<uint(i)) != 0 {
+			f += "e"
+		}
+		if inh&(1<<uint(i)) != 0 {
+			f += "i"
+		}
+		if perm&(1<<uint(i)) != 0 {
+			f += "p"
+		}
+		if f == "" {
+			continue
+		}
+		if _, ok := groups[f]; !ok {
+			order = append(order, f)
+		}
+		groups[f] = append(groups[f], capName(i))
+	}
+
+	r := make([]string, len(order))
+	for i, v := range order {
+		r[i] = strings.Join(groups[v], ",") + "=" + v
+	}
+	return strings.Join(r, " "), nil
+}
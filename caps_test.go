@@ -0,0 +1,50 @@
+package rpm
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func capXattr(rev, magic uint32, perm, inh uint64) []byte {
+	n := 20
+	if rev == vfsCapRevision3 {
+		n = 24
+	}
+	b := make([]byte, n)
+	binary.LittleEndian.PutUint32(b, rev|magic)
+	binary.LittleEndian.PutUint32(b[4:], uint32(perm))
+	binary.LittleEndian.PutUint32(b[8:], uint32(inh))
+	binary.LittleEndian.PutUint32(b[12:], uint32(perm>>32))
+	binary.LittleEndian.PutUint32(b[16:], uint32(inh>>32))
+	return b
+}
+
+func TestCapabilityText(t *testing.T) {
+	for _, v := range []struct {
+		xattr []byte
+		want  string
+	}{
+		{capXattr(vfsCapRevision2, vfsCapEffective, 1<<13, 0), "cap_net_raw=ep"},
+		{capXattr(vfsCapRevision2, 0, 1<<12|1<<13, 0), "cap_net_admin,cap_net_raw=p"},
+		{capXattr(vfsCapRevision3, vfsCapEffective, 1<<0, 1<<1), "cap_chown=ep cap_dac_override=i"},
+		{capXattr(vfsCapRevision2, 0, 1<<39, 1<<39), "cap_bpf=ip"},
+	} {
+		have, err := CapabilityText(v.xattr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != v.want {
+			t.Errorf("%q != %q", have, v.want)
+		}
+	}
+
+	for _, v := range [][]byte{
+		nil,
+		capXattr(vfsCapRevision2, 0, 1, 0)[:16],
+		capXattr(0x01000000, 0, 1, 0),
+	} {
+		if _, err := CapabilityText(v); err == nil {
+			t.Errorf("%x: no error", v)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package rpm
+
+import "time"
+
+// TrimChangelog keeps the first, newest, n changelog entries.
+func (hdr *Header) TrimChangelog(n int) {
+	if n < 0 {
+		return
+	}
+	hdr.trimChangelog(func(i int, _ uint32) bool { return i < n })
+}
+
+// TrimChangelogSince removes changelog entries older than t as
+// %_changelog_trimtime does in rpmbuild.
+func (hdr *Header) TrimChangelogSince(t time.Time) {
+	hdr.trimChangelog(func(_ int, v uint32) bool { return int64(v) >= t.Unix() })
+}
+
+func (hdr *Header) trimChangelog(keep func(int, uint32) bool) {
+	tt := hdr.Find(RPMTAG_CHANGELOGTIME)
+	if tt == nil {
+		return
+	}
+	times, ok := tt.Int32()
+	if !ok {
+		return
+	}
+
+	var idx []int
+	for i, v := range times {
+		if keep(i, v) {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == len(times) {
+		return
+	}
+
+	r := hdr.Tags[:0]
+	for _, v := range hdr.Tags {
+		var n int
+		switch v.Tag {
+		case RPMTAG_CHANGELOGTIME:
+			t := make(tagUint32, 0, len(idx))
+			for _, i := range idx {
+				t = append(t, times[i])
+			}
+			v.data, n = t, len(t)
+		case RPMTAG_CHANGELOGNAME, RPMTAG_CHANGELOGTEXT:
+			s, ok := v.StringArray()
+			if !ok {
+				// not ours to fix, leave it as it is
+				r = append(r, v)
+				continue
+			}
+			t := make([]string, 0, len(idx))
+			for _, i := range idx {
+				if i < len(s) {
+					t = append(t, s[i])
+				}
+			}
+			v.data, n = &tagString{data: t}, len(t)
+		default:
+			r = append(r, v)
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		v.Count = uint32(n)
+		r = append(r, v)
+	}
+	hdr.Tags = r
+	hdr.Relayout()
+}
@@ -0,0 +1,90 @@
+package rpm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func changelogHdr() *Header {
+	hdr := new(Header)
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddInt32(RPMTAG_CHANGELOGTIME, 300, 200, 100)
+	hdr.AddStringArray(RPMTAG_CHANGELOGNAME, "c", "b", "a")
+	hdr.AddStringArray(RPMTAG_CHANGELOGTEXT, "- three", "- two", "- one")
+	hdr.AddString(RPMTAG_LICENSE, "MIT")
+	return hdr
+}
+
+func TestTrimChangelog(t *testing.T) {
+	for _, v := range []struct {
+		trim func(*Header)
+		want []string
+	}{
+		{func(h *Header) { h.TrimChangelog(2) }, []string{"c", "b"}},
+		{func(h *Header) { h.TrimChangelog(5) }, []string{"c", "b", "a"}},
+		{func(h *Header) { h.TrimChangelog(0) }, nil},
+		{func(h *Header) { h.TrimChangelogSince(time.Unix(200, 0)) }, []string{"c", "b"}},
+	} {
+		hdr := changelogHdr()
+		v.trim(hdr)
+
+		var names []string
+		if t := hdr.Find(RPMTAG_CHANGELOGNAME); t != nil {
+			names, _ = t.StringArray()
+		}
+		if len(names) != len(v.want) {
+			t.Fatalf("names: %v != %v", names, v.want)
+		}
+		for i := range names {
+			if names[i] != v.want[i] {
+				t.Fatalf("names: %v != %v", names, v.want)
+			}
+		}
+		if v.want == nil && (hdr.Find(RPMTAG_CHANGELOGTIME) != nil ||
+			hdr.Find(RPMTAG_CHANGELOGTEXT) != nil) {
+			t.Fatalf("changelog tags not removed")
+		}
+
+		var b bytes.Buffer
+		if _, err := hdr.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		have, err := NewReader(&b).Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdrEq(t, hdr, have)
+	}
+}
+
+func TestTrimChangelogMismatch(t *testing.T) {
+	hdr := new(Header)
+	hdr.AddInt32(RPMTAG_CHANGELOGTIME, 300, 200, 100)
+	hdr.AddStringArray(RPMTAG_CHANGELOGNAME, "c")
+	hdr.AddInt32(RPMTAG_CHANGELOGTEXT, 1, 2, 3)
+	hdr.TrimChangelog(2)
+
+	for _, v := range []struct {
+		tag   TagType
+		count uint32
+	}{
+		{RPMTAG_CHANGELOGTIME, 2},
+		{RPMTAG_CHANGELOGNAME, 1},
+		{RPMTAG_CHANGELOGTEXT, 3}, // wrong type, left as is
+	} {
+		tag := hdr.Find(v.tag)
+		if tag == nil {
+			t.Fatalf("%v removed", v.tag)
+		}
+		n := 0
+		if s, ok := tag.StringArray(); ok {
+			n = len(s)
+		} else if a, ok := tag.Int32(); ok {
+			n = len(a)
+		}
+		if tag.Count != v.count || n != int(v.count) {
+			t.Errorf("%v: count %d, data %d, want %d", v.tag, tag.Count, n, v.count)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+// readHeader reads the JSON of a header, or of the headers rpmdump
+// -json prints, and returns the main header. The signature header is
+// rebuilt.
+func readHeader(name string) (*rpm.Header, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(list) == 0 || !bytes.HasPrefix(bytes.TrimSpace(list[0]), []byte("[")) {
+		list = []json.RawMessage{b}
+	}
+
+	var hdr *rpm.Header
+	for _, v := range list {
+		h := new(rpm.Header)
+		if err := json.Unmarshal(v, h); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if h.Kind() != rpm.KindSignature {
+			hdr = h
+		}
+	}
+	if hdr == nil || hdr.Len() == 0 {
+		return nil, fmt.Errorf("%s: no main header", name)
+	}
+	return hdr, nil
+}
+
+// archiveSize returns the uncompressed size of the payload of hdr in
+// name.
+func archiveSize(hdr *rpm.Header, name string) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r, err := rpm.DecompressPayload(hdr, f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	defer r.Close()
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return n, nil
+}
+
+// build writes the package of hdr and the payload in name to w, with a
+// new signature header signed by key unless it's nil.
+func build(w io.Writer, hdr *rpm.Header, name string, key *openpgp.Entity, reserve int) error {
+	size, err := archiveSize(hdr, name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	spool := new(bytes.Buffer)
+	s := sign.NewSigner(spool)
+	s.ReserveSpace(reserve)
+	s.SetArchiveSize(size)
+	if _, err := s.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := io.Copy(s, f); err != nil {
+		return err
+	}
+	var keys []*openpgp.Entity
+	if key != nil {
+		keys = append(keys, key)
+	}
+	sig, err := s.Signature(keys...)
+	if err != nil {
+		return err
+	}
+
+	n := rpm.HeaderNEVRA(hdr)
+	lt := rpm.LeadBinary
+	if rpm.IsSource(hdr) {
+		lt = rpm.LeadSource
+	}
+	lead := rpm.NewLead(strings.Join([]string{n.Name, n.Version, n.Release}, "-"), lt)
+	lead.SetArch(n.Arch)
+
+	buf := bufio.NewWriterSize(w, 1<<20)
+	if _, err := rpm.WriteHeaders(buf, lead, sig, spool); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("json2rpm: ")
+
+	from := flag.String("from-json", "", "JSON of the main header, or of both headers as rpmdump -json prints them")
+	payload := flag.String("payload", "", "payload file, compressed as the header says")
+	out := flag.String("o", "", "output file, default stdout")
+	keyFile := flag.String("sign", "", "sign the header with the first secret key in file")
+	reserve := flag.Int("reserve", 0, "reserve bytes in the signature header for signing in place later, rpmbuild uses 4096")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: json2rpm -from-json header.json -payload file [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *from == "" || *payload == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	hdr, err := readHeader(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var key *openpgp.Entity
+	if *keyFile != "" {
+		f, err := os.Open(*keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key, err = sign.ReadSigningKey(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keyFile, err)
+		}
+	}
+
+	if *out == "" {
+		err = build(os.Stdout, hdr, *payload, key, *reserve)
+	} else {
+		var f *os.File
+		if f, err = os.Create(*out); err == nil {
+			err = errors.Join(build(f, hdr, *payload, key, *reserve), f.Close())
+			if err != nil {
+				os.Remove(*out)
+			}
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
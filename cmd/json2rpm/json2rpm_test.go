@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+// writeFile writes b to name in dir and returns the path.
+func writeFile(t *testing.T, dir, name string, b []byte) string {
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	cb := new(bytes.Buffer)
+	cw := scpio.NewWriter(cb)
+	cw.WriteHeader(0)
+	cw.Write([]byte("hello\n"))
+	cw.Close()
+	zb := new(bytes.Buffer)
+	zw := gzip.NewWriter(zb)
+	zw.Write(cb.Bytes())
+	zw.Close()
+	payload := writeFile(t, dir, "payload", zb.Bytes())
+	sum := sha256.Sum256(zb.Bytes())
+
+	hdr := new(rpm.Header)
+	hdr.AddString(rpm.RPMTAG_NAME, "foo")
+	hdr.AddString(rpm.RPMTAG_VERSION, "1.0")
+	hdr.AddString(rpm.RPMTAG_RELEASE, "1")
+	hdr.AddString(rpm.RPMTAG_ARCH, "noarch")
+	hdr.AddString(rpm.RPMTAG_OS, "linux")
+	hdr.AddStringI18N(rpm.RPMTAG_SUMMARY, "foo")
+	hdr.AddStringArray(rpm.RPMTAG_BASENAMES, "hello")
+	hdr.AddStringArray(rpm.RPMTAG_DIRNAMES, "/")
+	hdr.AddInt32(rpm.RPMTAG_DIRINDEXES, 0)
+	hdr.AddInt32(rpm.RPMTAG_FILESIZES, 6)
+	hdr.AddString(rpm.RPMTAG_PAYLOADFORMAT, "cpio")
+	hdr.AddString(rpm.RPMTAG_PAYLOADCOMPRESSOR, "gzip")
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum[:]))
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	b, err := json.Marshal(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := openpgp.NewEntity("a", "", "a@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := new(bytes.Buffer)
+	e.SerializePrivate(sec, nil)
+	key, err := sign.ReadSigningKey(sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the JSON of a header, then both headers as rpmdump -json prints
+	// them
+	from := writeFile(t, dir, "hdr.json", b)
+	want := b
+	for i := 0; i < 2; i++ {
+		h, err := readHeader(from)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		pkg := new(bytes.Buffer)
+		if err := build(pkg, h, payload, key, 4096); err != nil {
+			t.Fatalf("%d: build: %v", i, err)
+		}
+		rep, err := sign.VerifyPackage(bytes.NewReader(pkg.Bytes()),
+			&sign.VerifyOptions{Keyring: openpgp.EntityList{e}})
+		if err != nil || !rep.OK() || rep.Signer != e {
+			t.Fatalf("%d: verify: %v, %+v", i, err, rep)
+		}
+
+		p, err := rpm.ReadPackage(bytes.NewReader(pkg.Bytes()))
+		if err != nil {
+			t.Fatalf("%d: read: %v", i, err)
+		}
+		if have, _ := json.Marshal(p.Header); !bytes.Equal(have, want) {
+			t.Errorf("%d: header changed:\n%s\n%s", i, have, want)
+		}
+		if b, err = json.Marshal([]*rpm.Header{p.Signature, p.Header}); err != nil {
+			t.Fatal(err)
+		}
+		from = writeFile(t, dir, "pkg.json", b)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/solver"
+)
+
+func load(name string) (*solver.Package, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return solver.NewPackage(p.Header), nil
+}
+
+func loadDir(dir string) (*solver.Set, error) {
+	m, err := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	if err != nil {
+		return nil, err
+	}
+	s := solver.New()
+	for _, v := range m {
+		p, err := load(v)
+		if err != nil {
+			return nil, err
+		}
+		s.Add(p)
+	}
+	return s, nil
+}
+
+type jsonUnresolved struct {
+	Package  string
+	Requires string
+}
+
+type jsonClosure struct {
+	Package    string
+	Closure    []string
+	Unresolved []jsonUnresolved `json:",omitempty"`
+}
+
+func writeJSON(w io.Writer, p *solver.Package, c []*solver.Package, u []solver.Unresolved) error {
+	r := jsonClosure{Package: p.String()}
+	for _, v := range c {
+		r.Closure = append(r.Closure, v.String())
+	}
+	for _, v := range u {
+		r.Unresolved = append(r.Unresolved, jsonUnresolved{
+			v.Package.String(), v.Dep.String(),
+		})
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "\t")
+	return e.Encode(r)
+}
+
+func writeText(w io.Writer, c []*solver.Package, u []solver.Unresolved) error {
+	for _, v := range c {
+		if _, err := fmt.Fprintln(w, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range u {
+		if _, err := fmt.Fprintf(w, "unresolved: %s requires %s\n",
+			v.Package, v.Dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGraph(w io.Writer, format string, g *solver.Graph) error {
+	switch format {
+	case "dot":
+		return g.WriteDot(w)
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "\t")
+		return e.Encode(g)
+	}
+	return fmt.Errorf("unknown graph format: %s", format)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmdeps: ")
+
+	dir := flag.String("dir", ".", "Directory of packages")
+	format := flag.String("format", "text", "Output format: text, json or dot")
+
+	all := flag.Bool("all", false, "Print the graph of all packages in dir")
+	from := flag.String("upgrade-from", "", "Report broken upgrade paths from packages in this directory to dir")
+
+	flag.Parse()
+
+	if *from != "" {
+		old, err := loadDir(*from)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s, err := loadDir(*dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p := solver.UpgradeProblems(old, s)
+		for _, v := range p {
+			fmt.Println(v)
+		}
+		if len(p) > 0 {
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+
+	if *all {
+		s, err := loadDir(*dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := writeGraph(os.Stdout, *format, s.Graph()); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	if flag.NArg() != 1 {
+		log.Fatal("usage: rpmdeps [-dir dir] [-format fmt] [-all | package]")
+	}
+
+	s, err := loadDir(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var target *solver.Package
+	if name := flag.Arg(0); strings.HasSuffix(name, ".rpm") {
+		if target, err = load(name); err != nil {
+			log.Fatal(err)
+		}
+		found := false
+		for _, v := range s.Find(target.Name) {
+			if v.String() == target.String() {
+				target, found = v, true
+			}
+		}
+		if !found {
+			s.Add(target)
+		}
+	} else if p := s.Find(name); len(p) > 0 {
+		target = p[0]
+	} else {
+		log.Fatalf("%s: package not found", name)
+	}
+
+	c, u := s.Closure(target)
+	switch *format {
+	case "text":
+		err = writeText(os.Stdout, c, u)
+	case "json":
+		err = writeJSON(os.Stdout, target, c, u)
+	case "dot":
+		err = s.Graph(c...).WriteDot(os.Stdout)
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(u) > 0 {
+		os.Exit(2)
+	}
+}
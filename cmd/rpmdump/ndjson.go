@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pschou/go-rpm"
+)
+
+type flatTag struct {
+	File   string
+	Header string
+	Tag    *rpm.Tag
+}
+
+func ndjsonPackage(jw *json.Encoder, name string, r io.Reader, flat bool) error {
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(r, 1<<20))
+	if err != nil {
+		return err
+	}
+	if !flat {
+		return jw.Encode(struct {
+			File string
+			*rpm.Package
+		}{name, p})
+	}
+	for _, h := range []struct {
+		name string
+		hdr  *rpm.Header
+	}{
+		{"signature", p.Signature},
+		{"header", p.Header},
+	} {
+		for _, v := range h.hdr.Tags {
+			if err := jw.Encode(&flatTag{name, h.name, v}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ndjson writes one object per package, or per tag if flat is set.
+// Errors are logged and the remaining files are still processed.
+func ndjson(w io.Writer, flat bool, files []string) bool {
+	jw := json.NewEncoder(w)
+	if len(files) == 0 {
+		if err := ndjsonPackage(jw, "-", os.Stdin, flat); err != nil {
+			log.Print(err)
+			return false
+		}
+		return true
+	}
+
+	ok := true
+	for _, v := range files {
+		f, err := os.Open(v)
+		if err != nil {
+			log.Print(err)
+			ok = false
+			continue
+		}
+		if err := ndjsonPackage(jw, v, f, flat); err != nil {
+			log.Printf("%s: %v", v, err)
+			ok = false
+		}
+		f.Close()
+	}
+	return ok
+}
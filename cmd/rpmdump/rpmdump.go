@@ -7,36 +7,42 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"sort"
 
-	"github.com/tlahdekorpi/rpm"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
 )
 
 func dump(w io.Writer, fl bool, h ...*rpm.Header) error {
 	for i, v := range h {
-		var tt rpm.TagType
 		rtag, err := v.Region()
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("hdr(%d), len:%#x, count:%d\n", i, v.Length, v.Count)
+		fmt.Fprintf(w, "hdr(%d), len:%#x, count:%d\n", i, v.Length, v.Count)
 		if rtag != nil {
 			if err = rtag.Dump(w); err != nil {
-				log.Fatal(err)
+				return err
 			}
-			tt = rtag.Tag
 		}
 
+		// dump all tags, the first error is returned
 		for _, j := range v.Tags {
-			switch tt {
-			case rpm.RPMTAG_HEADERSIGNATURES:
-				err = j.DumpSignature(w)
+			var terr error
+			switch v.Kind() {
+			case rpm.KindSignature:
+				terr = j.DumpSignature(w)
 			default:
-				err = j.Dump(w)
+				terr = j.Dump(w)
 			}
 			fmt.Fprintln(w)
+			if err == nil {
+				err = terr
+			}
 		}
 		if err != nil {
 			return err
@@ -57,6 +63,93 @@ func dump(w io.Writer, fl bool, h ...*rpm.Header) error {
 	return nil
 }
 
+func dumpSizes(w io.Writer, hdr *rpm.Header) error {
+	s := hdr.SizeBreakdown()
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].Total() > s[j].Total()
+	})
+
+	total := 16
+	for _, v := range s {
+		total += v.Total()
+	}
+	compact, err := hdr.WriteCompact(ioutil.Discard)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "hdr, len:%d, compact:%d, count:%d\n",
+		total, compact, len(s)); err != nil {
+		return err
+	}
+	for _, v := range s {
+		if _, err := fmt.Fprintf(w, "%8d %5.1f%% %s, index:%d, data:%d, pad:%d\n",
+			v.Total(), float64(v.Total())*100/float64(total),
+			v.Tag, v.Index, v.Data, v.Padding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPolicy(name string) (*rpm.Policy, error) {
+	if name == "default" {
+		p := rpm.DefaultPolicy
+		return &p, nil
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	p := new(rpm.Policy)
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// Exit codes, output goes to stdout and errors to stderr.
+const (
+	exitOK     = 0
+	exitParse  = 1 // unreadable package or other error
+	exitVerify = 2 // failed verification or policy check
+)
+
+func fatal(err error) {
+	var de *rpm.DumpError
+	if errors.As(err, &de) {
+		log.Printf("%v\n%s", err, de.Hexdump())
+		os.Exit(exitParse)
+	}
+	log.Fatal(err)
+}
+
+// verify prints the failed checks and warnings of the package.
+func verify(w io.Writer, r io.Reader) (bool, error) {
+	rep, err := sign.VerifyPackage(r, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range []struct {
+		name string
+		c    sign.PackageCheck
+	}{
+		{"lead", rep.Lead},
+		{"md5", rep.MD5},
+		{"sha1", rep.SHA1},
+		{"sha256", rep.SHA256},
+		{"size", rep.Size},
+		{"payload-digest", rep.PayloadDigest},
+	} {
+		if v.c.Err != nil {
+			fmt.Fprintf(w, "%s: FAIL: %v\n", v.name, v.c.Err)
+		}
+	}
+	for _, v := range rep.Warnings {
+		fmt.Fprintln(w, v)
+	}
+	return rep.OK(), nil
+}
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("rpmdump: ")
@@ -64,8 +157,47 @@ func main() {
 	jd := flag.Bool("json", false, "JSON format")
 	fl := flag.Bool("files", false, "Filelist from tags")
 	nhdr := flag.Int("nhdr", 2, "Number of headers")
+	layout := flag.Bool("layout", false, "Print package layout")
+	hexdump := flag.Int("hexdump", 0, "Bytes of context to dump on errors, dump or -json only")
+	nd := flag.Bool("ndjson", false, "NDJSON format, one object per package")
+	flat := flag.Bool("flat", false, "NDJSON format, one object per tag")
+	lint := flag.Bool("lint", false, "Print warnings for the payload header")
+	sizes := flag.Bool("sizes", false, "Print bytes used per tag, largest first")
+	policy := flag.String("policy", "", "Check the package against a JSON policy file, \"default\" for the default policy")
+	verifyPkg := flag.Bool("verify", false, "Verify lead, digests and payload digest, print failures and warnings")
+	quiet := flag.Bool("quiet", false, "Print nothing on stdout, only set the exit code")
+	hdrFile := flag.Bool("hdr", false, "Input is a header file without lead and payload, gzip compressed or not, dump or -json only")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rpmdump [flags] [file]\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "exit status: %d ok, %d parse error, %d verification or policy failure\n",
+			exitOK, exitParse, exitVerify)
+	}
+
+	// flag exits with 2 on usage errors, that's exitVerify here
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(exitOK)
+	} else if err != nil {
+		os.Exit(exitParse)
+	}
+
+	if *hexdump > 0 && (*layout || *verifyPkg || *lint || *policy != "" || *sizes || *nd || *flat || *hdrFile) {
+		log.Print("-hexdump is for dump or -json only")
+		os.Exit(exitParse)
+	}
+
+	var out io.Writer = os.Stdout
+	if *quiet {
+		out = io.Discard
+	}
 
-	flag.Parse()
+	if *nd || *flat {
+		if !ndjson(out, *flat, flag.Args()) {
+			os.Exit(exitParse)
+		}
+		os.Exit(exitOK)
+	}
 
 	f := os.Stdin
 	if flag.NArg() > 0 {
@@ -77,13 +209,69 @@ func main() {
 	}
 
 	buf := bufio.NewReaderSize(f, 1<<20)
-	r := rpm.NewReader(buf)
+	if *layout {
+		p, err := rpm.ReadPackage(buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := p.Layout().Dump(out); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(exitOK)
+	}
 
-	if _, err := r.Lead(); err != nil {
-		log.Fatal(err)
+	if *verifyPkg {
+		ok, err := verify(out, buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !ok {
+			os.Exit(exitVerify)
+		}
+		os.Exit(exitOK)
 	}
-	if *nhdr < 1 {
-		os.Exit(0)
+
+	if *lint {
+		p, err := rpm.ReadPackage(buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, v := range rpm.Lint(p.Header) {
+			fmt.Fprintln(out, v)
+		}
+		os.Exit(exitOK)
+	}
+
+	if *policy != "" {
+		pol, err := readPolicy(*policy)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p, err := rpm.ReadPackage(buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+		v := pol.Check(p.Signature, p.Header)
+		for _, v := range v {
+			fmt.Fprintln(out, v)
+		}
+		if len(v) > 0 {
+			os.Exit(exitVerify)
+		}
+		os.Exit(exitOK)
+	}
+
+	if *sizes {
+		p, err := rpm.ReadPackage(buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, v := range []*rpm.Header{p.Signature, p.Header} {
+			if err := dumpSizes(out, v); err != nil {
+				log.Fatal(err)
+			}
+		}
+		os.Exit(exitOK)
 	}
 
 	var (
@@ -91,30 +279,46 @@ func main() {
 		h   []*rpm.Header
 		err error
 	)
-	for i := 0; i < *nhdr; i++ {
-		hdr, err = r.Next()
-		if err != nil {
-			break
+	if *hdrFile {
+		if hdr, err = rpm.ReadHeader(buf); err != nil {
+			fatal(err)
 		}
 		h = append(h, hdr)
-	}
-	if len(h) == 0 {
-		log.Fatalf("no headers: %v", err)
+	} else {
+		r := rpm.NewReader(buf)
+		r.SetHexdump(*hexdump)
+
+		if _, err := r.Lead(); err != nil {
+			fatal(err)
+		}
+		if *nhdr < 1 {
+			os.Exit(exitOK)
+		}
+		for i := 0; i < *nhdr; i++ {
+			hdr, err = r.Next()
+			if err != nil {
+				break
+			}
+			h = append(h, hdr)
+		}
+		if len(h) == 0 {
+			fatal(fmt.Errorf("no headers: %w", err))
+		}
 	}
 
 	if *jd {
-		jw := json.NewEncoder(os.Stdout)
+		jw := json.NewEncoder(out)
 		if err := jw.Encode(h); err != nil {
 			log.Fatal(err)
 		}
-		os.Exit(0)
+		os.Exit(exitOK)
 	}
 
-	if err := dump(os.Stdout, *fl, h...); err != nil {
+	if err := dump(out, *fl, h...); err != nil {
 		log.Fatal(err)
 	}
 
 	if err != nil && !errors.Is(err, io.EOF) {
-		log.Fatalf("error: %v", err)
+		fatal(fmt.Errorf("error: %w", err))
 	}
 }
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+	"github.com/pschou/go-rpm/scpio"
+	"github.com/ulikunitz/xz"
+)
+
+// Exit codes, output goes to stdout and errors to stderr.
+const (
+	exitOK     = 0
+	exitParse  = 1 // a package couldn't be read, or other error
+	exitVerify = 2 // the packages don't form a complete set
+)
+
+var errSet = errors.New("not a complete split package")
+
+type pkg struct {
+	name    string // file name
+	sig     *rpm.Header
+	hdr     *rpm.Header
+	payload []byte // compressed
+}
+
+// readPackage reads the package name and checks its digests.
+func readPackage(name string) (*pkg, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var b bytes.Buffer
+	p, err := sign.VerifyDigests(io.TeeReader(bufio.NewReaderSize(f, 1<<20), &b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &pkg{name, p.Signature, p.Header, b.Bytes()[p.Layout().Payload.Off:]}, nil
+}
+
+// partNumber returns N of a name-partN package of main.
+func partNumber(main, name string) (int, bool) {
+	s, ok := strings.CutPrefix(name, main+"-part")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n > 0
+}
+
+// requires reports if hdr requires exactly name = evr.
+func requires(hdr *rpm.Header, name string, evr rpm.EVR) bool {
+	for _, v := range hdr.Requires() {
+		if v.Name == name && v.Flags&(rpm.RPMSENSE_LESS|rpm.RPMSENSE_GREATER|rpm.RPMSENSE_EQUAL) == rpm.RPMSENSE_EQUAL &&
+			rpm.ParseEVR(v.Version).Compare(evr) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// order returns the package and its parts in order, as tar2rpm -split
+// writes them: the package requires name-part1, each part the next,
+// all of the same version and arch, each file in one part.
+func order(list []*pkg) (*pkg, []*pkg, error) {
+	var main *pkg
+	for _, v := range list {
+		n := rpm.HeaderNEVRA(v.hdr)
+		if requires(v.hdr, n.Name+"-part1", n.EVR) {
+			if main != nil {
+				return nil, nil, fmt.Errorf("%w: %s and %s both require parts", errSet, main.name, v.name)
+			}
+			main = v
+		}
+	}
+	if main == nil {
+		return nil, nil, fmt.Errorf("%w: no package requires a part1", errSet)
+	}
+	mn := rpm.HeaderNEVRA(main.hdr)
+	if idx, err := rpm.FileIndexHeader(main.hdr); err == nil && len(idx.Files()) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s has files", errSet, main.name)
+	}
+
+	parts := make([]*pkg, len(list)-1)
+	for _, v := range list {
+		if v == main {
+			continue
+		}
+		n := rpm.HeaderNEVRA(v.hdr)
+		i, ok := partNumber(mn.Name, n.Name)
+		switch {
+		case !ok || i > len(parts):
+			return nil, nil, fmt.Errorf("%w: %s is not a part of %s", errSet, v.name, mn)
+		case parts[i-1] != nil:
+			return nil, nil, fmt.Errorf("%w: part %d twice", errSet, i)
+		case n.EVR != mn.EVR || n.Arch != mn.Arch:
+			return nil, nil, fmt.Errorf("%w: %s is not of %s", errSet, v.name, mn)
+		}
+		parts[i-1] = v
+	}
+
+	seen := make(map[string]string)
+	for i, v := range parts {
+		next := requires(v.hdr, mn.Name+"-part"+strconv.Itoa(i+2), mn.EVR)
+		switch {
+		case i+1 < len(parts) && !next:
+			return nil, nil, fmt.Errorf("%w: %s doesn't require the next part", errSet, v.name)
+		case i+1 == len(parts) && next:
+			return nil, nil, fmt.Errorf("%w: %s requires a missing part", errSet, v.name)
+		}
+		idx, err := rpm.FileIndexHeader(v.hdr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", v.name, err)
+		}
+		for _, f := range idx.Filenames() {
+			if o, ok := seen[f]; ok {
+				return nil, nil, fmt.Errorf("%w: %s in %s and %s", errSet, f, o, v.name)
+			}
+			seen[f] = v.name
+		}
+	}
+	return main, parts, nil
+}
+
+// compressors write the payload compressions of tar2rpm, the package
+// keeps that of the main package. The level is of its
+// RPMTAG_PAYLOADFLAGS, 0 if it has none.
+var compressors = map[string]func(w io.Writer, level int) (io.WriteCloser, error){
+	"": func(w io.Writer, level int) (io.WriteCloser, error) {
+		return nopCloser{w}, nil
+	},
+	"gzip": func(w io.Writer, level int) (io.WriteCloser, error) {
+		if level == 0 {
+			level = gzip.BestCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+	"xz": func(w io.Writer, level int) (io.WriteCloser, error) {
+		return xz.NewWriter(w)
+	},
+	"zstd": func(w io.Writer, level int) (io.WriteCloser, error) {
+		if level == 0 {
+			level = 19
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	},
+}
+
+// payloadLevel returns the level of the RPMTAG_PAYLOADFLAGS of hdr, the
+// leading digits like the 19 of 19L27.
+func payloadLevel(hdr *rpm.Header) int {
+	s, _ := hdr.StringData(rpm.RPMTAG_PAYLOADFLAGS)
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// archive writes the cpio archives of parts as one, with the files in
+// the order of idx.
+func archive(w io.Writer, parts []*pkg, idx *rpm.FileIndex) error {
+	cw := scpio.NewWriter(w)
+	var base uint32
+	for _, p := range parts {
+		pi, err := rpm.FileIndexHeader(p.hdr)
+		if err != nil {
+			return err
+		}
+		files := pi.Files()
+		for _, f := range files {
+			idx.Add(&f)
+		}
+
+		pr, err := rpm.DecompressPayload(p.hdr, bytes.NewReader(p.payload))
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+		r := scpio.NewReader(pr)
+		sz := 0
+		for {
+			e, err := r.NextEntry(sz)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.name, err)
+			}
+			if e.Name != "" || int(e.Index) >= len(files) {
+				return fmt.Errorf("%s: not a stripped payload of its files", p.name)
+			}
+			sz = 0
+			if f := files[e.Index]; f.Mode&0170000 == 0100000 {
+				sz = int(f.Size)
+			}
+			if err := cw.WriteHeader(base + e.Index); err != nil {
+				return err
+			}
+			if _, err := io.Copy(cw, r.Data(int64(sz))); err != nil {
+				return err
+			}
+		}
+		pr.Close()
+		base += uint32(len(files))
+	}
+	return cw.Close()
+}
+
+// join writes the package main with the files of parts to w, and its
+// requirements on them removed.
+func join(w io.Writer, main *pkg, parts []*pkg) error {
+	hdr := main.hdr
+	c, _ := hdr.StringData(rpm.RPMTAG_PAYLOADCOMPRESSOR)
+	newWriter, ok := compressors[c]
+	if !ok {
+		return fmt.Errorf("%s: unsupported payload compressor %q", main.name, c)
+	}
+	algo := uint32(rpm.PGPHASHALGO_SHA256)
+	if t := hdr.Find(rpm.RPMTAG_PAYLOADDIGESTALGO); t != nil {
+		if a, ok := t.Int32(); ok && len(a) > 0 {
+			algo = a[0]
+		}
+	}
+
+	idx := rpm.NewFileIndex()
+	if pi, err := rpm.FileIndexHeader(parts[0].hdr); err == nil {
+		idx.SetDigestAlgo(pi.DigestAlgo())
+	}
+	var data bytes.Buffer
+	if err := archive(&data, parts, idx); err != nil {
+		return err
+	}
+	alt, err := rpm.NewDigest(algo)
+	if err != nil {
+		return err
+	}
+	sum, err := rpm.NewDigest(algo)
+	if err != nil {
+		return err
+	}
+	alt.Write(data.Bytes())
+	var payload bytes.Buffer
+	zw, err := newWriter(io.MultiWriter(&payload, sum), payloadLevel(hdr))
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(data.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	name := rpm.HeaderNEVRA(hdr).Name
+	var (
+		flags         []uint32
+		names, evrs   []string
+		requiresParts = func(d rpm.Dependency) bool {
+			_, ok := partNumber(name, d.Name)
+			return ok
+		}
+	)
+	for _, v := range hdr.Requires() {
+		if !requiresParts(v) {
+			flags = append(flags, v.Flags)
+			names = append(names, v.Name)
+			evrs = append(evrs, v.Version)
+		}
+	}
+	for _, t := range []rpm.TagType{
+		rpm.RPMTAG_REQUIREFLAGS, rpm.RPMTAG_REQUIRENAME, rpm.RPMTAG_REQUIREVERSION,
+		rpm.RPMTAG_SIZE, rpm.RPMTAG_LONGSIZE,
+		rpm.RPMTAG_PAYLOADDIGEST, rpm.RPMTAG_PAYLOADDIGESTALT,
+	} {
+		hdr.Delete(t)
+	}
+	if len(names) > 0 {
+		hdr.AddInt32(rpm.RPMTAG_REQUIREFLAGS, flags...)
+		hdr.AddStringArray(rpm.RPMTAG_REQUIRENAME, names...)
+		hdr.AddStringArray(rpm.RPMTAG_REQUIREVERSION, evrs...)
+	}
+	idx.Append(hdr)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, sum.Sum())
+	if c != "" {
+		hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGESTALT, alt.Sum())
+	}
+	sort.Sort(hdr)
+
+	spool := new(bytes.Buffer)
+	s := sign.NewSigner(spool)
+	s.SetArchiveSize(int64(data.Len()))
+	if _, err := s.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := s.Write(payload.Bytes()); err != nil {
+		return err
+	}
+	sig, err := s.Signature()
+	if err != nil {
+		return err
+	}
+
+	n := rpm.HeaderNEVRA(hdr)
+	lead := rpm.NewLead(strings.Join([]string{n.Name, n.Version, n.Release}, "-"), rpm.LeadBinary)
+	lead.SetArch(n.Arch)
+	buf := bufio.NewWriterSize(w, 1<<20)
+	if _, err := rpm.WriteHeaders(buf, lead, sig, spool); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmjoin: ")
+
+	out := flag.String("o", "", "write the package with the files of its parts to file")
+	quiet := flag.Bool("quiet", false, "print nothing on stdout, only set the exit code")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rpmjoin [flags] package.rpm part.rpm...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "verifies the packages of tar2rpm -split, and joins them with -o\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "exit status: %d ok, %d read error, %d incomplete or inconsistent set\n",
+			exitOK, exitParse, exitVerify)
+	}
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(exitOK)
+	} else if err != nil {
+		os.Exit(exitParse)
+	}
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(exitParse)
+	}
+	var stdout io.Writer = os.Stdout
+	if *quiet {
+		stdout = io.Discard
+	}
+
+	var list []*pkg
+	for _, v := range flag.Args() {
+		p, err := readPackage(v)
+		if err != nil {
+			log.Print(err)
+			os.Exit(exitParse)
+		}
+		list = append(list, p)
+	}
+	main, parts, err := order(list)
+	if err != nil {
+		log.Print(err)
+		os.Exit(exitVerify)
+	}
+	files := 0
+	for _, v := range parts {
+		if idx, err := rpm.FileIndexHeader(v.hdr); err == nil {
+			files += len(idx.Files())
+		}
+	}
+	fmt.Fprintf(stdout, "%s: OK, %d parts, %d files\n", rpm.HeaderNEVRA(main.hdr), len(parts), files)
+	if *out == "" {
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err == nil {
+		if err = errors.Join(join(f, main, parts), f.Close()); err != nil {
+			os.Remove(*out)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
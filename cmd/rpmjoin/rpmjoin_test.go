@@ -0,0 +1,159 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/pschou/go-rpm"
+)
+
+// tar2rpm builds tar2rpm and returns a function running it in dir.
+func tar2rpm(t *testing.T) func(dir string, args ...string) {
+	if testing.Short() {
+		t.Skip("builds tar2rpm")
+	}
+	bin := filepath.Join(t.TempDir(), "tar2rpm")
+	out, err := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-o", bin, "../tar2rpm").CombinedOutput()
+	if err != nil {
+		t.Fatalf("build tar2rpm: %v\n%s", err, out)
+	}
+	return func(dir string, args ...string) {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("tar2rpm %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+// testTar writes a tar file of incompressible files to dir.
+func testTar(t *testing.T, dir string) string {
+	b := new(bytes.Buffer)
+	tw := tar.NewWriter(b)
+	rnd := rand.New(rand.NewSource(1))
+	tw.WriteHeader(&tar.Header{Name: "usr/share/foo/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for i := 0; i < 12; i++ {
+		data := make([]byte, 5000+rnd.Intn(20000))
+		rnd.Read(data)
+		tw.WriteHeader(&tar.Header{Name: "usr/share/foo/" + strconv.Itoa(i), Mode: 0o644, Size: int64(len(data))})
+		tw.Write(data)
+	}
+	tw.WriteHeader(&tar.Header{Name: "usr/share/foo/link", Typeflag: tar.TypeSymlink, Linkname: "0", Mode: 0o777})
+	tw.Close()
+	name := filepath.Join(dir, "in.tar")
+	if err := os.WriteFile(name, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// readDir reads the packages in dir.
+func readDir(t *testing.T, dir string) []*pkg {
+	names, _ := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	var r []*pkg
+	for _, v := range names {
+		p, err := readPackage(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = append(r, p)
+	}
+	return r
+}
+
+// archiveOf returns the uncompressed payload of p.
+func archiveOf(t *testing.T, p *pkg) []byte {
+	r, err := rpm.DecompressPayload(p.hdr, bytes.NewReader(p.payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSplitJoin(t *testing.T) {
+	run := tar2rpm(t)
+	dir := t.TempDir()
+	in := testTar(t, dir)
+	whole, split := filepath.Join(dir, "whole"), filepath.Join(dir, "split")
+	for _, v := range []string{whole, split} {
+		os.Mkdir(v, 0o755)
+	}
+	run(dir, "-i", in, "-o", whole+"/")
+	run(dir, "-i", in, "-o", split+"/", "-split", "64K")
+
+	ref := readDir(t, whole)[0]
+	list := readDir(t, split)
+	main, parts, err := order(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 3 {
+		t.Fatalf("%d parts", len(parts))
+	}
+
+	// incomplete and inconsistent sets, join changes the header of main
+	without := func(i int) []*pkg {
+		return slices.Delete(slices.Clone(list), i, i+1)
+	}
+	for name, v := range map[string][]*pkg{
+		"no main":    {parts[0], parts[1]},
+		"first part": without(slices.Index(list, parts[0])),
+		"last part":  without(slices.Index(list, parts[len(parts)-1])),
+		"twice":      append(without(slices.Index(list, parts[0])), parts[1]),
+		"whole":      append(slices.Clone(list), ref),
+	} {
+		if _, _, err := order(v); !errors.Is(err, errSet) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := join(buf, main, parts); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "joined.rpm")
+	os.WriteFile(name, buf.Bytes(), 0o644)
+	joined, err := readPackage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(archiveOf(t, joined), archiveOf(t, ref)) {
+		t.Error("joined payload differs")
+	}
+	ji, err := rpm.FileIndexHeader(joined.hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ri, _ := rpm.FileIndexHeader(ref.hdr)
+	if !slices.Equal(ji.Files(), ri.Files()) {
+		t.Errorf("files %v, want %v", ji.Filenames(), ri.Filenames())
+	}
+	if len(joined.hdr.Requires()) != len(ref.hdr.Requires()) {
+		t.Errorf("requires %v", joined.hdr.Requires())
+	}
+	a, _ := joined.hdr.Find(rpm.RPMTAG_PAYLOADDIGESTALT).StringArray()
+	b, _ := ref.hdr.Find(rpm.RPMTAG_PAYLOADDIGESTALT).StringArray()
+	if !slices.Equal(a, b) {
+		t.Errorf("payload digest alt %v, want %v", a, b)
+	}
+	s, _ := joined.hdr.Find(rpm.RPMTAG_SIZE).Int32()
+	if rs, _ := ref.hdr.Find(rpm.RPMTAG_SIZE).Int32(); !slices.Equal(s, rs) {
+		t.Errorf("size %v, want %v", s, rs)
+	}
+}
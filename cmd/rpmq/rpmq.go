@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+const (
+	qfDefault = "%{NAME}-%{VERSION}-%{RELEASE}.%{ARCH}\n"
+
+	qfInfo = `Name        : %{NAME}
+Epoch       : %|EPOCH?{%{EPOCH}}:{(none)}|
+Version     : %{VERSION}
+Release     : %{RELEASE}
+Architecture: %{ARCH}
+Group       : %{GROUP}
+Size        : %{SIZE}
+License     : %{LICENSE}
+Source RPM  : %{SOURCERPM}
+Build Date  : %{BUILDTIME:date}
+Build Host  : %{BUILDHOST}
+Packager    : %{PACKAGER}
+Vendor      : %{VENDOR}
+URL         : %{URL}
+Summary     : %{SUMMARY}
+Description :
+%{DESCRIPTION}
+`
+
+	qfList = "[%{FILENAMES}\n]"
+
+	qfRequires = "[%{REQUIRENAME} %{REQUIREFLAGS:depflags} %{REQUIREVERSION}\n]"
+
+	qfProvides = "[%{PROVIDENAME} %{PROVIDEFLAGS:depflags} %{PROVIDEVERSION}\n]"
+
+	qfChangelog = "[* %{CHANGELOGTIME:day} %{CHANGELOGNAME}\n%{CHANGELOGTEXT}\n\n]"
+
+	qfScripts = `%|PRETRANS?{pretrans scriptlet (using %{PRETRANSPROG}):
+%{PRETRANS}
+}:{}|%|PREIN?{preinstall scriptlet (using %{PREINPROG}):
+%{PREIN}
+}:{}|%|POSTIN?{postinstall scriptlet (using %{POSTINPROG}):
+%{POSTIN}
+}:{}|%|PREUN?{preuninstall scriptlet (using %{PREUNPROG}):
+%{PREUN}
+}:{}|%|POSTUN?{postuninstall scriptlet (using %{POSTUNPROG}):
+%{POSTUN}
+}:{}|%|POSTTRANS?{posttrans scriptlet (using %{POSTTRANSPROG}):
+%{POSTTRANS}
+}:{}|%|VERIFYSCRIPT?{verify scriptlet (using %{VERIFYSCRIPTPROG}):
+%{VERIFYSCRIPT}
+}:{}|`
+)
+
+func query(name string, qf []string, w *bufio.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	for _, v := range qf {
+		if err := p.Header.Format(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify prints the files that differ like rpm -V, a package with
+// differences is an error.
+func verify(name, root string, w *bufio.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	r, err := rpm.VerifyFiles(p.Header, root)
+	for _, v := range r {
+		fmt.Fprintln(w, v)
+	}
+	if err == nil && len(r) > 0 {
+		err = fmt.Errorf("%d files differ", len(r))
+	}
+	return err
+}
+
+// checksig checks the digests and header signatures of the package name
+// like rpm -K, any failed check is an error.
+func checksig(name string, keyring openpgp.KeyRing, w *bufio.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rep, err := sign.VerifyPackage(bufio.NewReaderSize(f, 1<<20), &sign.VerifyOptions{Keyring: keyring})
+	if err != nil {
+		return err
+	}
+	if err := rep.Err(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s: digests signatures OK, key ID %s\n", name, rep.Signer.PrimaryKey.KeyIdString())
+	return err
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmq: ")
+
+	info := flag.Bool("i", false, "Display package information")
+	list := flag.Bool("l", false, "List files in package")
+	req := flag.Bool("R", false, "List capabilities the package requires")
+	prov := flag.Bool("provides", false, "List capabilities the package provides")
+	clog := flag.Bool("changelog", false, "Display change information")
+	scripts := flag.Bool("scripts", false, "List package scriptlets")
+	qf := flag.String("qf", "", "Query format")
+	keys := flag.String("K", "", "Check digests and header signatures against keyring file")
+	root := flag.String("V", "", "Verify the package files installed below root")
+
+	flag.Parse()
+
+	var format []string
+	for _, v := range []struct {
+		set bool
+		qf  string
+	}{
+		{*info, qfInfo},
+		{*list, qfList},
+		{*req, qfRequires},
+		{*prov, qfProvides},
+		{*clog, qfChangelog},
+		{*scripts, qfScripts},
+		{*qf != "", *qf},
+	} {
+		if v.set {
+			format = append(format, v.qf)
+		}
+	}
+	if len(format) == 0 {
+		format = append(format, qfDefault)
+	}
+
+	if flag.NArg() == 0 {
+		log.Fatal("no package files given")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	var keyring openpgp.EntityList
+	if *keys != "" {
+		f, err := os.Open(*keys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keyring, err = sign.ReadKeyRing(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keys, err)
+		}
+	}
+
+	var failed bool
+	for _, v := range flag.Args() {
+		var err error
+		switch {
+		case keyring != nil:
+			err = checksig(v, keyring, w)
+		case *root != "":
+			err = verify(v, *root, w)
+		default:
+			err = query(v, format, w)
+		}
+		if err != nil {
+			w.Flush()
+			log.Printf("%s: %v", v, err)
+			failed = true
+		}
+	}
+	if failed {
+		w.Flush()
+		os.Exit(1)
+	}
+}
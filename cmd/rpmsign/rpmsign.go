@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+var errHeader = errors.New("header doesn't round trip, not rewritten")
+
+// keepTags are the signature tags addsign doesn't compute, they're
+// carried over.
+var keepTags = map[rpm.SigTagType]bool{
+	rpm.RPMSIGTAG_PAYLOADSIZE:         true,
+	rpm.RPMSIGTAG_RESERVEDSPACE:       true,
+	rpm.RPMSIGTAG_LONGARCHIVESIZE:     true,
+	rpm.RPMSIGTAG_FILESIGNATURES:      true,
+	rpm.RPMSIGTAG_FILESIGNATURELENGTH: true,
+	rpm.RPMSIGTAG_VERITYSIGNATURES:    true,
+	rpm.RPMSIGTAG_VERITYSIGNATUREALGO: true,
+}
+
+// readerTo writes the rest of a reader, for WriteHeaders.
+type readerTo struct{ r io.Reader }
+
+func (r readerTo) WriteTo(w io.Writer) (int64, error) { return io.Copy(w, r.r) }
+
+// rewrite replaces the signature header of the package name with the
+// one sig returns, it's called with the payload. The main header and
+// payload are copied byte for byte to out, name if out is empty.
+func rewrite(name, out string, sig func(p *rpm.Package, payload io.Reader) (*rpm.Header, error)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	l := p.Layout()
+
+	// the digests are computed over the parsed header, it has to
+	// be the one in the file
+	raw := make([]byte, l.Header.Len)
+	if _, err := f.ReadAt(raw, l.Header.Off); err != nil {
+		return err
+	}
+	hb := new(bytes.Buffer)
+	if _, err := p.Header.WriteTo(hb); err != nil {
+		return err
+	}
+	if !bytes.Equal(raw, hb.Bytes()) {
+		return errHeader
+	}
+
+	payload := io.NewSectionReader(f, l.Payload.Off, fi.Size()-l.Payload.Off)
+	s, err := sig(p, bufio.NewReaderSize(payload, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		// the reserved space kept the size, only the signature
+		// header is replaced
+		b := new(bytes.Buffer)
+		if _, err := rpm.WriteHeaders(b, s); err != nil {
+			return err
+		}
+		b.Write(make([]byte, (8-b.Len()%8)%8))
+		if int64(b.Len()) == l.Header.Off-l.Signature.Off {
+			return writeAt(name, b.Bytes(), l.Signature.Off)
+		}
+		out = name
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".rpmsign-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	rest := io.NewSectionReader(f, l.Header.Off, fi.Size()-l.Header.Off)
+	w := bufio.NewWriterSize(tmp, 1<<20)
+	_, err = rpm.WriteHeaders(w, p.Lead, s, readerTo{rest})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Chmod(fi.Mode().Perm())
+	}
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), out)
+}
+
+func writeAt(name string, b []byte, off int64) error {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(b, off)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// addSign recomputes the signature header and signs it with key, like
+// rpmsign --addsign. Existing header signatures are replaced, unless
+// keep, then the new one follows them.
+func addSign(key *openpgp.Entity, keep bool) func(*rpm.Package, io.Reader) (*rpm.Header, error) {
+	return func(p *rpm.Package, payload io.Reader) (*rpm.Header, error) {
+		s := sign.NewSigner(nil)
+		if _, err := s.WriteHeader(p.Header); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(s, payload); err != nil {
+			return nil, err
+		}
+		var (
+			sig *rpm.Header
+			err error
+		)
+		if keep {
+			sig, err = s.Signature()
+			if err == nil {
+				err = sign.CopySignatures(sig, p.Signature)
+			}
+			if err == nil {
+				err = sign.SignHeader(sig, p.Header, key)
+			}
+		} else {
+			sig, err = s.Signature(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range p.Signature.Tags {
+			if keepTags[t.Tag] {
+				if err := sig.Add(t); err != nil {
+					return nil, err
+				}
+			}
+		}
+		keepSize(p, sig)
+		return sig, nil
+	}
+}
+
+// keepSize resizes the reserved space of sig, if it has any, to keep
+// the size of the signature header of p. Without room for the new
+// signatures it's dropped.
+func keepSize(p *rpm.Package, sig *rpm.Header) {
+	if sig.Find(rpm.RPMSIGTAG_RESERVEDSPACE) == nil {
+		return
+	}
+	l := p.Layout()
+	if err := sign.FitReservedSpace(sig, l.Header.Off-l.Signature.Off); err != nil {
+		sig.Delete(rpm.RPMSIGTAG_RESERVEDSPACE)
+	}
+}
+
+// delSign removes the header signatures, like rpmsign --delsign.
+func delSign(p *rpm.Package, _ io.Reader) (*rpm.Header, error) {
+	if sign.DeleteSignatures(p.Signature) {
+		keepSize(p, p.Signature)
+	}
+	return p.Signature, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: rpmsign addsign -k key [-append] [-o file] file...\n"+
+		"       rpmsign delsign [-o file] file...\n")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmsign: ")
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	out := fs.String("o", "", "output file, only with one package, default in place")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: rpmsign %s [flags] file...\n", fs.Name())
+		fs.PrintDefaults()
+	}
+
+	var sig func(*rpm.Package, io.Reader) (*rpm.Header, error)
+	switch os.Args[1] {
+	case "addsign":
+		keyFile := fs.String("k", "", "sign with the first secret key in file")
+		keep := fs.Bool("append", false, "keep the existing signatures, add one")
+		fs.Parse(os.Args[2:])
+		if *keyFile == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		f, err := os.Open(*keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key, err := sign.ReadSigningKey(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keyFile, err)
+		}
+		sig = addSign(key, *keep)
+	case "delsign":
+		fs.Parse(os.Args[2:])
+		sig = delSign
+	default:
+		usage()
+	}
+	if fs.NArg() == 0 || *out != "" && fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, v := range fs.Args() {
+		if err := rewrite(v, *out, sig); err != nil {
+			log.Printf("%s: %v", v, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
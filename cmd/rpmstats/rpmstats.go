@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+)
+
+func load(name string) (*rpm.Package, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := rpm.ReadPackage(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// walk calls fn with the .rpm files under root, root itself if it's a
+// file.
+func walk(root string, fn func(string)) error {
+	return filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root && !d.IsDir() || d.Type().IsRegular() && strings.HasSuffix(name, ".rpm") {
+			fn(name)
+		}
+		return nil
+	})
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmstats: ")
+
+	jd := flag.Bool("json", false, "JSON format, default CSV")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rpmstats [-json] dir|file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// unreadable packages are reported and skipped
+	ok := true
+	s := rpm.NewStats()
+	for _, v := range flag.Args() {
+		err := walk(v, func(name string) {
+			p, err := load(name)
+			if err != nil {
+				log.Print(err)
+				ok = false
+				return
+			}
+			s.Add(p)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var err error
+	if *jd {
+		jw := json.NewEncoder(os.Stdout)
+		jw.SetIndent("", "  ")
+		err = jw.Encode(s)
+	} else {
+		err = s.WriteCSV(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+// Exit codes, output goes to stdout and errors to stderr.
+const (
+	exitOK     = 0
+	exitParse  = 1 // a package couldn't be read, or other error
+	exitVerify = 2 // a package failed verification
+)
+
+func status(c sign.PackageCheck) string {
+	if c.Err != nil {
+		return "BAD, " + c.Err.Error()
+	}
+	return "OK"
+}
+
+func keyIDs(ids []uint64) string {
+	if len(ids) == 0 {
+		return "unknown"
+	}
+	var b strings.Builder
+	for i, v := range ids {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%016x", v)
+	}
+	return b.String()
+}
+
+// verify prints the checks of the package name like rpm -Kv and
+// reports if it verifies and is protected at the required level at least.
+func verify(w io.Writer, name string, keyring openpgp.KeyRing, required sign.Protection) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	rep, err := sign.VerifyPackage(bufio.NewReaderSize(f, 1<<20), &sign.VerifyOptions{Keyring: keyring})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	fmt.Fprintf(w, "%s:\n", name)
+	for _, v := range []struct {
+		name string
+		c    sign.PackageCheck
+	}{
+		{"Lead", rep.Lead},
+		{"Header SHA256 digest", rep.SHA256},
+		{"Header SHA1 digest", rep.SHA1},
+		{"MD5 digest", rep.MD5},
+		{"Size", rep.Size},
+		{"Payload digest", rep.PayloadDigest},
+		{"fs-verity signatures", rep.Verity},
+	} {
+		if v.c.Present {
+			fmt.Fprintf(w, "    %s: %s\n", v.name, status(v.c))
+		}
+	}
+
+	ok := rep.OK()
+	switch {
+	case !rep.Signature.Present:
+		fmt.Fprintf(w, "    Header signature: NOT FOUND\n")
+		ok = ok && keyring == nil
+	case keyring == nil:
+		fmt.Fprintf(w, "    Header signature, key ID %s: NOT CHECKED\n", keyIDs(rep.KeyIDs))
+	case len(rep.Signers) > 0:
+		for _, e := range rep.Signers {
+			var uid string
+			for id := range e.Identities {
+				uid = id
+				break
+			}
+			fmt.Fprintf(w, "    Header signature, key ID %016x: OK, %s\n", e.PrimaryKey.KeyId, uid)
+		}
+	default:
+		fmt.Fprintf(w, "    Header signature, key ID %s: %s\n", keyIDs(rep.KeyIDs), status(rep.Signature))
+	}
+	if rep.Protection < required {
+		fmt.Fprintf(w, "    Protection: BAD, %s, want %s\n", rep.Protection, required)
+		ok = false
+	} else {
+		fmt.Fprintf(w, "    Protection: %s\n", rep.Protection)
+	}
+	return ok, nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmverify: ")
+
+	keys := flag.String("k", "", "public keyring to verify header signatures with, unsigned packages fail")
+	quiet := flag.Bool("quiet", false, "print nothing on stdout, only set the exit code")
+	var required sign.Protection
+	flag.TextVar(&required, "min-protection", sign.ProtectionNone,
+		"fail packages protected less: none, weak for MD5 or SHA1 only, header without a payload digest, digests or signed with -k")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rpmverify [flags] file.rpm...\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "exit status: %d ok, %d read error, %d verification failure\n",
+			exitOK, exitParse, exitVerify)
+	}
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(exitOK)
+	} else if err != nil {
+		os.Exit(exitParse)
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(exitParse)
+	}
+	var out io.Writer = os.Stdout
+	if *quiet {
+		out = io.Discard
+	}
+
+	var keyring openpgp.KeyRing
+	if *keys != "" {
+		f, err := os.Open(*keys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		el, err := sign.ReadKeyRing(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keys, err)
+		}
+		keyring = el
+	}
+
+	code := exitOK
+	for _, v := range flag.Args() {
+		ok, err := verify(out, v, keyring, required)
+		switch {
+		case err != nil:
+			log.Print(err)
+			code = exitParse
+		case !ok:
+			fmt.Fprintf(out, "%s: NOT OK\n", v)
+			if code == exitOK {
+				code = exitVerify
+			}
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+type result struct {
+	name   string
+	signer string
+	err    error
+	read   bool // err is a read or parse error
+}
+
+// Exit codes, output goes to stdout and errors to stderr.
+const (
+	exitOK     = 0
+	exitParse  = 1 // a package couldn't be read, or other error
+	exitVerify = 2 // a package failed verification
+)
+
+// verify checks the package name with sign.VerifyPackage, the header
+// signature unless keyring is nil.
+func verify(name string, keyring openpgp.KeyRing) result {
+	r := result{name: name}
+	f, err := os.Open(name)
+	if err != nil {
+		r.err, r.read = err, true
+		return r
+	}
+	defer f.Close()
+
+	rep, err := sign.VerifyPackage(bufio.NewReaderSize(f, 1<<20), &sign.VerifyOptions{Keyring: keyring})
+	if err != nil {
+		r.err, r.read = err, true
+		return r
+	}
+	if rep.Signer != nil {
+		for id := range rep.Signer.Identities {
+			r.signer = id
+			break
+		}
+	}
+	r.err = rep.Err()
+	return r
+}
+
+// walk calls fn with the .rpm files under root, root itself if it's a
+// file.
+func walk(root string, fn func(string)) error {
+	return filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root && !d.IsDir() || d.Type().IsRegular() && strings.HasSuffix(name, ".rpm") {
+			fn(name)
+		}
+		return nil
+	})
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rpmverifyall: ")
+
+	keys := flag.String("k", "", "public keyring to verify header signatures with")
+	nosig := flag.Bool("nosignature", false, "only verify digests")
+	jobs := flag.Int("j", runtime.NumCPU(), "packages verified concurrently")
+	verbose := flag.Bool("v", false, "also print packages that verify")
+	quiet := flag.Bool("quiet", false, "print nothing on stdout, only set the exit code")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rpmverifyall -k keyring|-nosignature [flags] dir|file...\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "exit status: %d ok, %d read error, %d verification failure\n",
+			exitOK, exitParse, exitVerify)
+	}
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(exitOK)
+	} else if err != nil {
+		os.Exit(exitParse)
+	}
+	if flag.NArg() == 0 || (*keys == "") == !*nosig || *jobs < 1 {
+		flag.Usage()
+		os.Exit(exitParse)
+	}
+	var out io.Writer = os.Stdout
+	if *quiet {
+		out = io.Discard
+	}
+
+	var keyring openpgp.KeyRing
+	if *keys != "" {
+		f, err := os.Open(*keys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		el, err := sign.ReadKeyRing(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keys, err)
+		}
+		keyring = el
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []result
+		names   = make(chan string)
+	)
+	for i := 0; i < *jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range names {
+				r := verify(v, keyring)
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, v := range flag.Args() {
+		if err := walk(v, func(name string) { names <- name }); err != nil {
+			mu.Lock()
+			results = append(results, result{name: v, err: err, read: true})
+			mu.Unlock()
+		}
+	}
+	close(names)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].name < results[j].name
+	})
+	failed, code := 0, exitOK
+	for _, v := range results {
+		switch {
+		case v.err != nil:
+			failed++
+			fmt.Fprintf(out, "%s: FAIL: %v\n", v.name, v.err)
+			if v.read {
+				code = exitParse
+			} else if code == exitOK {
+				code = exitVerify
+			}
+		case *verbose && v.signer != "":
+			fmt.Fprintf(out, "%s: OK, %s\n", v.name, v.signer)
+		case *verbose:
+			fmt.Fprintf(out, "%s: OK\n", v.name)
+		}
+	}
+	fmt.Fprintf(out, "%d packages, %d ok, %d failed\n", len(results), len(results)-failed, failed)
+	os.Exit(code)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// An apk is gzip compressed tar segments, the signature, the control
+// files and the data. Read as one stream the control files are the
+// dot files before the data.
+
+// apkArch maps Alpine architectures to rpm ones.
+var apkArch = map[string]string{
+	"x86":   "i686",
+	"armhf": "armv6hl",
+	"armv7": "armv7hl",
+}
+
+// apkScripts maps install scripts to scriptlets, upgrade scripts have
+// no counterpart.
+var apkScripts = map[string]func(c *Config) *script{
+	".pre-install":    func(c *Config) *script { return &c.PreInstall },
+	".post-install":   func(c *Config) *script { return &c.PostInstall },
+	".pre-deinstall":  func(c *Config) *script { return &c.PreUninstall },
+	".post-deinstall": func(c *Config) *script { return &c.PostUninstall },
+}
+
+// apkControlFile reports if name is a control file of an apk.
+func apkControlFile(name string) bool {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	return strings.HasPrefix(name, ".") && !strings.Contains(name, "/")
+}
+
+// apkVersion splits an Alpine version, 1.2.3-r4, into version and
+// release.
+func apkVersion(v string) (version, release string) {
+	if i := strings.LastIndex(v, "-r"); i != -1 {
+		return v[:i], v[i+2:]
+	}
+	return v, ""
+}
+
+// apkDep converts a dependency, the apk specific so:, cmd: and pc:
+// names are dropped.
+func apkDep(v string) (dep string, conflict bool) {
+	v, conflict = strings.CutPrefix(v, "!")
+	i := strings.IndexAny(v, "<>=~")
+	if i == -1 {
+		i = len(v)
+	}
+	name, rel := v[:i], v[i:]
+	op := strings.TrimRight(rel, "0123456789abcdefghijklmnopqrstuvwxyz._-")
+	ver := rel[len(op):]
+	if k, _, ok := strings.Cut(name, ":"); ok && (k == "so" || k == "cmd" || k == "pc") {
+		return "", conflict
+	}
+
+	// a fuzzy match is at least the version
+	if op == "~" || op == "~=" {
+		op = ">="
+	}
+	if ver != "" {
+		if version, release := apkVersion(ver); release != "" {
+			ver = version + "-" + release
+		}
+	}
+	return name + op + ver, conflict
+}
+
+// apk maps the .PKGINFO fields of an apk to c.
+func (c *Config) apk(r io.Reader) error {
+	dropped := 0
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		l := s.Text()
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		k, v, ok := strings.Cut(l, " = ")
+		if !ok {
+			return fmt.Errorf("invalid line: %q", l)
+		}
+		switch k {
+		case "pkgname":
+			c.Name = v
+		case "pkgver":
+			version, release := apkVersion(v)
+			c.Version = version
+			if release != "" {
+				c.Release = release
+			}
+		case "pkgdesc":
+			c.Summary, c.Description = v, v
+		case "url":
+			c.URL = v
+		case "license":
+			c.License = v
+		case "maintainer":
+			c.Packager = v
+		case "arch":
+			if a, ok := apkArch[v]; ok {
+				v = a
+			}
+			c.Arch = v
+		case "depend":
+			d, conflict := apkDep(v)
+			switch {
+			case d == "":
+				dropped++
+			case conflict:
+				c.Conflicts = append(c.Conflicts, d)
+			default:
+				c.Requires = append(c.Requires, d)
+			}
+		case "provides":
+			if d, _ := apkDep(v); d != "" {
+				c.Provides = append(c.Provides, d)
+			}
+		}
+	}
+	if dropped > 0 {
+		log.Printf("warning: %d so:, cmd: or pc: dependencies dropped", dropped)
+	}
+	return s.Err()
+}
+
+// apkControl reads the control files of the apk name into c.
+func apkControl(name string, c *Config) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = func() error {
+		dr, err := decompress(f)
+		if err != nil {
+			return err
+		}
+		var info bool
+		tr := tar.NewReader(dr)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if !apkControlFile(hdr.Name) {
+				break
+			}
+
+			n := path.Base(hdr.Name)
+			if n == ".PKGINFO" {
+				if err := c.apk(tr); err != nil {
+					return fmt.Errorf("%s: %w", n, err)
+				}
+				info = true
+				continue
+			}
+			if fn, ok := apkScripts[n]; ok {
+				b, err := io.ReadAll(tr)
+				if err != nil {
+					return err
+				}
+				prog, _ := interpreter(string(b))
+				*fn(c) = script{data: string(b), prog: prog}
+				continue
+			}
+			if strings.HasSuffix(n, "-upgrade") {
+				log.Printf("warning: %s is not converted", n)
+			}
+		}
+		if !info {
+			return errors.New("no .PKGINFO")
+		}
+		return nil
+	}()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// addApk adds the data files of the apk name.
+func addApk(x *indexer, name string) error {
+	x.keep = func(name string) bool {
+		return name != "/" && !apkControlFile(name)
+	}
+	return addFile(x, name)
+}
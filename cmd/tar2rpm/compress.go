@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pschou/go-rpm"
+	"github.com/ulikunitz/xz"
+)
+
+// compressor writes a compressed payload at a level, the
+// RPMTAG_PAYLOADFLAGS like rpmbuild's w19.zstdio.
+type compressor struct {
+	name   string
+	level  int
+	window int // zstd window bytes, 0 for the default of the level
+	new    func(w io.Writer, c *compressor) (io.WriteCloser, error)
+}
+
+// compressors are the payload compressions of -compress with their
+// default, lowest and highest levels, none leaves the payload and the
+// header as they are.
+var compressors = map[string]*struct {
+	level, min, max int
+	new             func(w io.Writer, c *compressor) (io.WriteCloser, error)
+}{
+	"none": nil,
+	"gzip": {9, 1, 9, func(w io.Writer, c *compressor) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, c.level)
+	}},
+	// the xz package has no presets, its defaults are close to -6
+	"xz": {6, 6, 6, func(w io.Writer, c *compressor) (io.WriteCloser, error) {
+		return xz.NewWriter(w)
+	}},
+	// Fedora 31 and later use level 19, the zstd package has four
+	// speeds for the 22 levels and writes frames any zstd reads
+	"zstd": {19, 1, 22, func(w io.Writer, c *compressor) (io.WriteCloser, error) {
+		opts := []zstd.EOption{
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(c.level)),
+			zstd.WithEncoderConcurrency(1),
+		}
+		if c.window > 0 {
+			opts = append(opts, zstd.WithWindowSize(c.window))
+		}
+		return zstd.NewWriter(w, opts...)
+	}},
+}
+
+// newCompressor returns the compressor name, nil for none. Level 0 is
+// the default level of name, and only zstd has a window.
+func newCompressor(name string, level int, window int64) (*compressor, error) {
+	v, ok := compressors[name]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("-compress: unknown compression %q", name)
+	case v == nil && (level != 0 || window != 0):
+		return nil, fmt.Errorf("-compress: %s has no level or window", name)
+	case v == nil:
+		return nil, nil
+	case level == 0:
+		level = v.level
+	case v.min == v.max && level != v.level:
+		return nil, fmt.Errorf("-level: %s has only level %d", name, v.level)
+	case level < v.min || level > v.max:
+		return nil, fmt.Errorf("-level: %s levels are %d to %d", name, v.min, v.max)
+	}
+	c := &compressor{name: name, level: level, new: v.new}
+	if window != 0 {
+		if name != "zstd" {
+			return nil, fmt.Errorf("-window: %s has no window size", name)
+		}
+		if window < zstd.MinWindowSize || window > zstd.MaxWindowSize || bits.OnesCount64(uint64(window)) != 1 {
+			return nil, fmt.Errorf("-window: %d is not a power of two from %d to %d", window, zstd.MinWindowSize, zstd.MaxWindowSize)
+		}
+		c.window = int(window)
+	}
+	return c, nil
+}
+
+// flags returns the RPMTAG_PAYLOADFLAGS of c, the level and for a
+// window its log2 like rpm's L option.
+func (c *compressor) flags() string {
+	r := strconv.Itoa(c.level)
+	if c.window > 0 {
+		r += "L" + strconv.Itoa(bits.TrailingZeros(uint(c.window)))
+	}
+	return r
+}
+
+// compress compresses the payload with c. Like rpmbuild, the payload
+// digest is then of the compressed payload and the alternative digest
+// of the uncompressed one.
+func (p *payload) compress(c *compressor) error {
+	p.size = int64(len(p.data))
+	if c == nil {
+		return nil
+	}
+
+	b := bytes.NewBuffer(make([]byte, 0, len(p.data)/2))
+	sum, err := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	if err != nil {
+		return err
+	}
+	w, err := c.new(io.MultiWriter(b, sum), c)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(p.data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	p.data, p.alt, p.digest = b.Bytes(), p.digest, sum.Sum()
+	p.compressor, p.flags = c.name, c.flags()
+	return nil
+}
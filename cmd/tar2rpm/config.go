@@ -74,6 +74,35 @@ func (s *script) load(value string, sc *bufio.Scanner) error {
 	return (*str)(&s.data).load(value, sc)
 }
 
+// interpreter returns the program and arguments of the #! line of a
+// script, /bin/sh without one.
+func interpreter(data string) (prog, args string) {
+	l, _, _ := strings.Cut(data, "\n")
+	if f := strings.Fields(strings.TrimPrefix(l, "#!")); strings.HasPrefix(l, "#!") && len(f) > 0 {
+		return f[0], strings.Join(f[1:], " ")
+	}
+	return "/bin/sh", ""
+}
+
+// appendScript appends data to a shell scriptlet, it fails for other
+// interpreters.
+func appendScript(s *script, data string) bool {
+	if s.data == "" {
+		s.data, s.prog = data, "/bin/sh"
+		return true
+	}
+	switch path.Base(s.prog) {
+	case "sh", "bash", "dash":
+	default:
+		return false
+	}
+	if !strings.HasSuffix(s.data, "\n") {
+		s.data += "\n"
+	}
+	s.data += data
+	return true
+}
+
 func kp(key string) (string, string) {
 	i := strings.IndexByte(key, '(')
 	if i == -1 {
@@ -129,6 +158,14 @@ func scan1(m map[string]loader, s *bufio.Scanner) error {
 	return ld.load(l[i:], s)
 }
 
+// configName returns the config key of a field.
+func configName(f reflect.StructField) string {
+	if n := f.Tag.Get("name"); n != "" {
+		return n
+	}
+	return strings.ToLower(f.Name)
+}
+
 func configMap(from interface{}) (map[string]loader, error) {
 	r := make(map[string]loader)
 	y := reflect.ValueOf(from).Elem()
@@ -140,11 +177,7 @@ func configMap(from interface{}) (map[string]loader, error) {
 		if !y.Field(i).CanSet() {
 			continue
 		}
-		f := t.Field(i)
-		n := f.Tag.Get("name")
-		if n == "" {
-			n = strings.ToLower(f.Name)
-		}
+		n := configName(t.Field(i))
 
 		switch v := y.Field(i).Addr().Interface().(type) {
 		case *string:
@@ -153,6 +186,10 @@ func configMap(from interface{}) (map[string]loader, error) {
 			r[n] = (*slice)(v)
 		case *script:
 			r[n] = v
+		case *sysusers:
+			r[n] = v
+		case *alternatives:
+			r[n] = v
 		default:
 			return nil, fmt.Errorf("unknown type: %T", v)
 		}
@@ -173,3 +210,74 @@ func loadconfig(r io.Reader, to interface{}) error {
 	}
 	return s.Err()
 }
+
+// dumpHeredoc writes a value as a heredoc, the terminator is any run
+// of ! that isn't a line of data.
+func dumpHeredoc(w io.Writer, key, data string) {
+	e := "!"
+	for l := "\n" + data + "\n"; strings.Contains(l, "\n"+e+"\n"); {
+		e += "!"
+	}
+	fmt.Fprintf(w, "%s <<%s\n%s\n%s\n", key, e, data, e)
+}
+
+// dumpconfig writes from in canonical form, one key per field in field
+// order, empty ones left out. loadconfig reads it back.
+func dumpconfig(w io.Writer, from interface{}) error {
+	m, err := configMap(from)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	t := reflect.ValueOf(from).Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		k := configName(t.Field(i))
+		switch v := m[k].(type) {
+		case *str:
+			switch s := string(*v); {
+			case s == "":
+			case strings.ContainsAny(s, "\n#") || s != strings.TrimSpace(s):
+				// a line would be cut at # or trimmed
+				dumpHeredoc(bw, k, s)
+			default:
+				fmt.Fprintf(bw, "%s %s\n", k, s)
+			}
+		case *slice:
+			if len(*v) == 0 {
+				break
+			}
+			fmt.Fprintf(bw, "%s {\n", k)
+			for _, v := range *v {
+				fmt.Fprintf(bw, "\t%s\n", v)
+			}
+			fmt.Fprintf(bw, "}\n")
+		case *script:
+			switch {
+			case v.data == "":
+				continue
+			case v.prog == "<lua>":
+				k += "(lua)"
+			case v.prog != "/bin/sh":
+				k += "(" + v.prog + ")"
+			}
+			dumpHeredoc(bw, k, v.data)
+		case *sysusers:
+			var l []string
+			for _, u := range *v {
+				l = append(l, u.line)
+			}
+			if len(l) > 0 {
+				dumpHeredoc(bw, k, strings.Join(l, "\n"))
+			}
+		case *alternatives:
+			var l []string
+			for _, a := range *v {
+				l = append(l, fmt.Sprintf("%s %s %s %d", a.name, a.link, a.path, a.priority))
+			}
+			if len(l) > 0 {
+				dumpHeredoc(bw, k, strings.Join(l, "\n"))
+			}
+		}
+	}
+	return bw.Flush()
+}
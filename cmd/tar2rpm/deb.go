@@ -0,0 +1,345 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+)
+
+const arMagic = "!<arch>\n"
+
+var errArHeader = errors.New("ar: invalid header")
+
+// arReader reads the members of an ar archive, a deb is one.
+type arReader struct {
+	r    io.Reader
+	data *io.LimitedReader
+	pad  int64
+}
+
+func newArReader(r io.Reader) (*arReader, error) {
+	var b [len(arMagic)]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil || string(b[:]) != arMagic {
+		return nil, errors.New("ar: invalid magic")
+	}
+	return &arReader{r: r}, nil
+}
+
+// Next returns the name and data of the next member, io.EOF after the
+// last one.
+func (a *arReader) Next() (string, io.Reader, error) {
+	if a.data != nil {
+		a.data.N += a.pad
+		if _, err := io.Copy(io.Discard, a.data); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var hdr [60]byte
+	if _, err := io.ReadFull(a.r, hdr[:]); err != nil {
+		return "", nil, err
+	}
+	if string(hdr[58:]) != "`\n" {
+		return "", nil, errArHeader
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(hdr[48:58])), 10, 64)
+	if err != nil || size < 0 {
+		return "", nil, errArHeader
+	}
+
+	// gnu ar terminates names with a slash
+	name := strings.TrimSuffix(strings.TrimRight(string(hdr[:16]), " "), "/")
+	a.data = &io.LimitedReader{R: a.r, N: size}
+	a.pad = size & 1
+	return name, a.data, nil
+}
+
+// debArch maps Debian architectures to rpm ones.
+var debArch = map[string]string{
+	"all":     "noarch",
+	"amd64":   "x86_64",
+	"i386":    "i686",
+	"arm64":   "aarch64",
+	"armhf":   "armv7hl",
+	"armel":   "armv5tel",
+	"ppc64el": "ppc64le",
+}
+
+// debScripts maps maintainer scripts to scriptlets and the action
+// they'd be called with on a fresh install or removal.
+var debScripts = []struct {
+	name   string
+	action string
+	script func(c *Config) *script
+}{
+	{"preinst", "install", func(c *Config) *script { return &c.PreInstall }},
+	{"postinst", "configure", func(c *Config) *script { return &c.PostInstall }},
+	{"prerm", "remove", func(c *Config) *script { return &c.PreUninstall }},
+	{"postrm", "remove", func(c *Config) *script { return &c.PostUninstall }},
+}
+
+// control parses the fields of a control file, continuation lines are
+// joined by newlines.
+func control(r io.Reader) (map[string]string, error) {
+	m := make(map[string]string)
+	var key string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		l := s.Text()
+		switch {
+		case l == "":
+		case l[0] == ' ' || l[0] == '\t':
+			if key == "" {
+				return nil, errors.New("control: continuation without field")
+			}
+			m[key] += "\n" + l[1:]
+		default:
+			i := strings.IndexByte(l, ':')
+			if i == -1 {
+				return nil, fmt.Errorf("control: invalid line: %q", l)
+			}
+			key = l[:i]
+			m[key] = strings.TrimSpace(l[i+1:])
+		}
+	}
+	return m, s.Err()
+}
+
+// debDeps converts a Depends style list, only the first of alternatives
+// is kept.
+func debDeps(v string) []string {
+	var r []string
+	for _, d := range strings.Split(v, ",") {
+		alt := strings.Split(d, "|")
+		d = strings.TrimSpace(alt[0])
+		if d == "" {
+			continue
+		}
+		if len(alt) > 1 {
+			log.Printf("warning: %s: alternatives dropped", strings.TrimSpace(v))
+		}
+
+		name, rel := d, ""
+		if i := strings.IndexByte(d, '('); i != -1 {
+			name = d[:i]
+			rel = strings.TrimSuffix(strings.TrimSpace(d[i+1:]), ")")
+		}
+		name = strings.TrimSpace(name)
+		if i := strings.IndexByte(name, ':'); i != -1 {
+			// architecture qualifier
+			name = name[:i]
+		}
+
+		op := strings.TrimRight(rel, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.+~-: ")
+		ver := strings.TrimSpace(rel[len(op):])
+		switch op = strings.TrimSpace(op); op {
+		case "<<":
+			op = "<"
+		case ">>":
+			op = ">"
+		}
+		if ver == "" {
+			op = ""
+		}
+		r = append(r, name+op+ver)
+	}
+	return r
+}
+
+// debVersion splits a Debian version into epoch, version and release.
+// rpm versions can't have dashes.
+func debVersion(v string) (epoch, version, release string) {
+	if i := strings.IndexByte(v, ':'); i != -1 {
+		epoch, v = v[:i], v[i+1:]
+	}
+	if i := strings.LastIndexByte(v, '-'); i != -1 {
+		v, release = v[:i], v[i+1:]
+	}
+	return epoch, strings.ReplaceAll(v, "-", "_"), release
+}
+
+// debScript converts a maintainer script, shell scripts get the
+// arguments of a fresh install or removal.
+func debScript(data, action string) script {
+	prog, args := interpreter(data)
+	switch path.Base(prog) {
+	case "sh", "bash", "dash":
+		pre := "set -- " + action + "\n"
+		if args != "" {
+			pre = "set " + args + "\n" + pre
+		}
+		data = pre + data
+	default:
+		log.Printf("warning: %s script, arguments differ from dpkg", prog)
+	}
+	return script{data: data, prog: prog}
+}
+
+// deb maps the control fields of a deb to c.
+func (c *Config) deb(m map[string]string) {
+	if v := m["Package"]; v != "" {
+		c.Name = v
+	}
+	if v := m["Version"]; v != "" {
+		epoch, version, release := debVersion(v)
+		c.Epoch, c.Version = epoch, version
+		if release != "" {
+			c.Release = release
+		}
+	}
+	if v := m["Architecture"]; v != "" {
+		if a, ok := debArch[v]; ok {
+			v = a
+		}
+		c.Arch = v
+	}
+	if v := m["Maintainer"]; v != "" {
+		c.Packager = v
+	}
+	if v := m["Homepage"]; v != "" {
+		c.URL = v
+	}
+	if v := m["Description"]; v != "" {
+		summary, body, _ := strings.Cut(v, "\n")
+		c.Summary = summary
+		var d []string
+		for _, l := range strings.Split(body, "\n") {
+			l = strings.TrimPrefix(l, " ")
+			if l == "." {
+				l = ""
+			}
+			d = append(d, l)
+		}
+		c.Description = strings.TrimSpace(strings.Join(d, "\n"))
+		if c.Description == "" {
+			c.Description = summary
+		}
+	}
+	for _, k := range []string{"Pre-Depends", "Depends"} {
+		c.Requires = append(c.Requires, debDeps(m[k])...)
+	}
+	for _, k := range []string{"Conflicts", "Breaks"} {
+		c.Conflicts = append(c.Conflicts, debDeps(m[k])...)
+	}
+	c.Provides = append(c.Provides, debDeps(m["Provides"])...)
+}
+
+// debControl reads the control archive of the deb name into c and
+// returns the RPMFILE_* flags of its conffiles.
+func debControl(name string, c *Config) (map[string]uint32, error) {
+	var flags map[string]uint32
+	err := readDeb(name, func(member string, r io.Reader) (bool, error) {
+		if !strings.HasPrefix(member, "control.tar") {
+			return false, nil
+		}
+		dr, err := decompress(r)
+		if err != nil {
+			return false, err
+		}
+		flags = make(map[string]uint32)
+		tr := tar.NewReader(dr)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				return false, err
+			}
+
+			switch n := path.Base(hdr.Name); n {
+			case "control":
+				m, err := control(bytes.NewReader(b))
+				if err != nil {
+					return false, err
+				}
+				c.deb(m)
+			case "conffiles":
+				for _, v := range strings.Split(string(b), "\n") {
+					// remove-on-upgrade and other flags aren't kept
+					if f := strings.Fields(v); len(f) == 1 {
+						flags[path.Clean(f[0])] = rpm.RPMFILE_CONFIG | rpm.RPMFILE_NOREPLACE
+					}
+				}
+			default:
+				for _, v := range debScripts {
+					if v.name == n {
+						*v.script(c) = debScript(string(b), v.action)
+					}
+				}
+			}
+		}
+	})
+	if err == nil && flags == nil {
+		err = errors.New("no control archive")
+	}
+	return flags, err
+}
+
+// addDeb adds the data archive of the deb name.
+func addDeb(x *indexer, name string) error {
+	var found bool
+	err := readDeb(name, func(member string, r io.Reader) (bool, error) {
+		if !strings.HasPrefix(member, "data.tar") {
+			return false, nil
+		}
+		found = true
+		// the root directory isn't owned by packages
+		x.keep = func(name string) bool { return name != "/" }
+		return true, addInput(x, r)
+	})
+	if err == nil && !found {
+		err = errors.New("no data archive")
+	}
+	return err
+}
+
+// readDeb calls fn with the members of the deb name until it's done.
+func readDeb(name string, fn func(string, io.Reader) (bool, error)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = func() error {
+		ar, err := newArReader(bufio.NewReader(f))
+		if err != nil {
+			return err
+		}
+		for {
+			member, r, err := ar.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			done, err := fn(member, r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", member, err)
+			}
+			if done {
+				return nil
+			}
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
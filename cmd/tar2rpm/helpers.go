@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+)
+
+// snippet is generated scriptlet code and the program it requires.
+type snippet struct {
+	post   string
+	preun  string
+	postun string
+	prog   string
+}
+
+// addSnippet appends s to the scriptlets of c, after any of the config.
+func (c *Config) addSnippet(what string, s snippet) {
+	req := sense{name: s.prog}
+	for _, v := range []struct {
+		s    *script
+		data string
+		flag uint32
+	}{
+		{&c.PostInstall, s.post, rpm.RPMSENSE_SCRIPT_POST},
+		{&c.PreUninstall, s.preun, rpm.RPMSENSE_SCRIPT_PREUN},
+		{&c.PostUninstall, s.postun, rpm.RPMSENSE_SCRIPT_POSTUN},
+	} {
+		if v.data == "" {
+			continue
+		}
+		if !appendScript(v.s, v.data) {
+			log.Printf("warning: %s scriptlet, %s not handled", v.s.prog, what)
+			continue
+		}
+		req.flags |= v.flag
+	}
+	if req.flags != 0 && req.name != "" {
+		c.scriptRequires = append(c.scriptRequires, req)
+	}
+}
+
+// helpers are the snippets selectable with the scriptlets key.
+var helpers = map[string]snippet{
+	"ldconfig": {
+		post:   "/sbin/ldconfig\n",
+		postun: "/sbin/ldconfig\n",
+		prog:   "/sbin/ldconfig",
+	},
+	"icon-cache": {
+		post:   "gtk-update-icon-cache -qf /usr/share/icons/hicolor >/dev/null 2>&1 || :\n",
+		postun: "gtk-update-icon-cache -qf /usr/share/icons/hicolor >/dev/null 2>&1 || :\n",
+	},
+	"desktop-database": {
+		post:   "update-desktop-database -q /usr/share/applications >/dev/null 2>&1 || :\n",
+		postun: "update-desktop-database -q /usr/share/applications >/dev/null 2>&1 || :\n",
+	},
+	"mime-database": {
+		post:   "update-mime-database /usr/share/mime >/dev/null 2>&1 || :\n",
+		postun: "update-mime-database /usr/share/mime >/dev/null 2>&1 || :\n",
+	},
+}
+
+// alternative is an update-alternatives registration.
+type alternative struct {
+	name     string
+	link     string
+	path     string
+	priority int
+}
+
+// alternatives are lines of name, link, path and priority, e.g.
+//
+//	alternatives <<!
+//	editor /usr/bin/editor /usr/bin/vim 50
+//	!
+type alternatives []alternative
+
+func (a *alternatives) load(value string, sc *bufio.Scanner) error {
+	var data string
+	if err := (*str)(&data).load(value, sc); err != nil {
+		return err
+	}
+	for _, l := range strings.Split(data, "\n") {
+		f := strings.Fields(l)
+		if len(f) == 0 || f[0][0] == '#' {
+			continue
+		}
+		if len(f) != 4 {
+			return fmt.Errorf("config/alternatives: invalid line: %q", l)
+		}
+		p, err := strconv.Atoi(f[3])
+		if err != nil {
+			return fmt.Errorf("config/alternatives: %s: %w", f[0], err)
+		}
+		*a = append(*a, alternative{f[0], f[1], f[2], p})
+	}
+	return nil
+}
+
+// helpers adds the selected scriptlet helpers and alternatives to c.
+func (c *Config) helpers() error {
+	for _, v := range c.Scriptlets {
+		s, ok := helpers[v]
+		if !ok {
+			return fmt.Errorf("scriptlets: unknown helper: %q", v)
+		}
+		c.addSnippet(v, s)
+	}
+
+	var s snippet
+	for _, v := range c.Alternatives {
+		s.post += fmt.Sprintf("update-alternatives --install %s %s %s %d\n",
+			quote(v.link), quote(v.name), quote(v.path), v.priority)
+		s.preun += fmt.Sprintf("\tupdate-alternatives --remove %s %s\n",
+			quote(v.name), quote(v.path))
+	}
+	if s.post != "" {
+		s.preun = "if [ $1 -eq 0 ]; then\n" + s.preun + "fi\n"
+		s.prog = "/usr/sbin/update-alternatives"
+		c.addSnippet("alternatives", s)
+	}
+	return nil
+}
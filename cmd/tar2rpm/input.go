@@ -0,0 +1,155 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress detects gzip, xz and zstd input by magic, anything else
+// is returned as is.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(xzMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, xzMagic):
+		return xz.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return br, nil
+}
+
+func addInput(x *indexer, r io.Reader) error {
+	dr, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	return x.add(dr)
+}
+
+func addFile(x *indexer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := addInput(x, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func scanFile(name string) (*scan, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return prescan(f)
+}
+
+type layers struct {
+	scan      *scan
+	conflicts []string
+	winner    map[string]int
+}
+
+// layer reads the tar headers of all inputs to find the last input of
+// each path, overriding anything but a directory is a conflict.
+func layer(names []string) (*layers, error) {
+	type entry struct {
+		input int
+		hdr   *tar.Header
+	}
+	var (
+		order []string
+		seen  = make(map[string]entry)
+		l     = &layers{scan: new(scan)}
+	)
+
+	for i, name := range names {
+		err := func() error {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			dr, err := decompress(f)
+			if err != nil {
+				return err
+			}
+			tr := tar.NewReader(dr)
+			for {
+				hdr, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				p := path.Join("/", hdr.Name)
+				e, ok := seen[p]
+				if !ok {
+					order = append(order, p)
+				} else if e.hdr.Typeflag != tar.TypeDir || hdr.Typeflag != tar.TypeDir {
+					l.conflicts = append(l.conflicts, fmt.Sprintf(
+						"%s: %s overrides %s", p, name, names[e.input],
+					))
+				}
+				seen[p] = entry{i, hdr}
+			}
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	l.winner = make(map[string]int, len(seen))
+	for _, v := range order {
+		e := seen[v]
+		l.winner[v] = e.input
+		l.scan.files++
+		switch e.hdr.Typeflag {
+		case tar.TypeReg:
+			l.scan.size += e.hdr.Size
+		case tar.TypeLink:
+			l.scan.hardlinks++
+		}
+	}
+	return l, nil
+}
+
+// keep returns the filter for an input, each path is kept once.
+func (l *layers) keep(input int) func(string) bool {
+	return func(name string) bool {
+		i, ok := l.winner[name]
+		if !ok || i != input {
+			return false
+		}
+		delete(l.winner, name)
+		return true
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pschou/go-rpm"
+)
+
+type output struct {
+	dir  string
+	tmpl *template.Template // nil for rpm.FileName
+	seen map[string]bool
+	max  int64 // of a package, 0 for any size
+}
+
+// newOutput parses -o, a directory, a template with the rpm.NEVRA
+// fields of the header or a plain file name.
+func newOutput(o string) (*output, error) {
+	if o == "" {
+		return nil, nil
+	}
+	if fi, err := os.Stat(o); err == nil && fi.IsDir() || strings.HasSuffix(o, "/") {
+		return &output{dir: o}, nil
+	}
+	t, err := template.New("o").Parse(o)
+	if err != nil {
+		return nil, fmt.Errorf("-o: %w", err)
+	}
+	return &output{tmpl: t}, nil
+}
+
+func (o *output) name(hdr *rpm.Header) (string, error) {
+	if o.tmpl == nil {
+		return filepath.Join(o.dir, rpm.FileName(hdr)), nil
+	}
+	var b bytes.Buffer
+	if err := o.tmpl.Execute(&b, rpm.HeaderNEVRA(hdr)); err != nil {
+		return "", err
+	}
+	return filepath.Join(o.dir, filepath.FromSlash(b.String())), nil
+}
+
+func (o *output) write(c *Config, hdr *rpm.Header, p *payload) error {
+	name, err := o.name(hdr)
+	if err != nil {
+		return err
+	}
+	if o.seen[name] {
+		return fmt.Errorf("%s: written more than once, use a template with {{.Arch}}", name)
+	}
+	if o.seen == nil {
+		o.seen = make(map[string]bool)
+	}
+	o.seen[name] = true
+	if dir := filepath.Dir(name); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := c.write(f, hdr, p); err != nil {
+		f.Close()
+		return err
+	}
+	if fi, err := f.Stat(); err == nil && o.max > 0 && fi.Size() > o.max {
+		f.Close()
+		return fmt.Errorf("%s: %d bytes, more than -split %d", name, fi.Size(), o.max)
+	}
+	return f.Close()
+}
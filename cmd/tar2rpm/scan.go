@@ -0,0 +1,84 @@
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+type scan struct {
+	files     int
+	size      int64
+	hardlinks int
+}
+
+// prescan reads the tar headers of r and seeks back to the start, a nil
+// scan is returned if r isn't seekable.
+func prescan(r io.Reader) (*scan, error) {
+	rs, ok := r.(io.Seeker)
+	if !ok {
+		return nil, nil
+	}
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// pipes and terminals
+		return nil, nil
+	}
+
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(scan)
+	tr := tar.NewReader(dr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.files++
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			s.size += hdr.Size
+		case tar.TypeLink:
+			s.hardlinks++
+		}
+	}
+
+	_, err = rs.Seek(start, io.SeekStart)
+	return s, err
+}
+
+type progress struct {
+	scan  *scan
+	files int
+	size  int64
+	last  int
+}
+
+func (p *progress) add(size int64) {
+	if p == nil {
+		return
+	}
+	p.files++
+	p.size += size
+
+	pct := 100
+	if p.scan.size > 0 {
+		pct = int(p.size * 100 / p.scan.size)
+	}
+	if pct == p.last && p.files != p.scan.files {
+		return
+	}
+	p.last = pct
+	fmt.Fprintf(os.Stderr, "\r%3d%% %d/%d files", pct, p.files, p.scan.files)
+	if p.files == p.scan.files {
+		fmt.Fprintln(os.Stderr)
+	}
+}
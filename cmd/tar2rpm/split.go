@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+// Estimated header bytes of a part and of each of its files, a part is
+// filled up to the -split size less these.
+const (
+	partOverhead = 16 << 10
+	fileOverhead = 256
+)
+
+// parseSize parses a byte count with an optional K, M or G suffix.
+func parseSize(s string) (int64, error) {
+	num, shift := s, 0
+	if i := len(s) - 1; i > 0 {
+		if n := strings.IndexByte("KMG", s[i]); n >= 0 {
+			num, shift = s[:i], 10*(n+1)
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64>>shift {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return n << shift, nil
+}
+
+// partName is the name of part i, from 0, of the package name.
+func partName(name string, i int) string {
+	return fmt.Sprintf("%s-part%d", name, i+1)
+}
+
+// split moves the files of p, in order, to parts of about limit bytes at
+// most, p is left without files.
+func (p *payload) split(limit int64) ([]*payload, error) {
+	budget := limit - partOverhead
+	if budget <= 0 {
+		return nil, fmt.Errorf("-split: %d is less than the header overhead", limit)
+	}
+
+	var (
+		parts []*payload
+		part  *payload
+		buf   *bytes.Buffer
+		sum   *rpm.Digest
+		cw    *scpio.Writer
+		used  int64
+		n     uint32 // files in part
+		algo  = p.idx.DigestAlgo()
+	)
+	done := func() error {
+		if part == nil {
+			return nil
+		}
+		if err := cw.Close(); err != nil {
+			return err
+		}
+		part.data, part.digest = buf.Bytes(), sum.Sum()
+		parts = append(parts, part)
+		part = nil
+		return nil
+	}
+
+	files := p.idx.Files()
+	r := scpio.NewReader(bytes.NewReader(p.data))
+	var sz int
+	for {
+		e, err := r.NextEntry(sz)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		i := e.Index
+		if int(i) >= len(files) {
+			return nil, fmt.Errorf("-split: payload entry %d not in the file index", i)
+		}
+		f := files[i]
+		sz = 0
+		if f.Mode&0170000 == 0100000 {
+			sz = int(f.Size)
+		}
+		cost := int64(sz) + fileOverhead
+		if cost > budget {
+			return nil, fmt.Errorf("-split: %s is larger than %d bytes", f.Name, limit)
+		}
+		if part != nil && used+cost > budget {
+			if err := done(); err != nil {
+				return nil, err
+			}
+		}
+		if part == nil {
+			part = &payload{idx: rpm.NewFileIndex()}
+			part.idx.SetDigestAlgo(algo)
+			if p.verity != nil {
+				part.verity = [][]byte{}
+			}
+			buf = new(bytes.Buffer)
+			if sum, err = rpm.NewDigest(rpm.PGPHASHALGO_SHA256); err != nil {
+				return nil, err
+			}
+			cw = scpio.NewWriter(io.MultiWriter(buf, sum))
+			used, n = 0, 0
+		}
+
+		if err := cw.WriteHeader(n); err != nil {
+			return nil, err
+		}
+		n++
+		if _, err := io.Copy(cw, r.Data(int64(sz))); err != nil {
+			return nil, err
+		}
+		part.idx.Add(&f)
+		if p.verity != nil {
+			part.verity = append(part.verity, p.verity[i])
+		}
+		used += cost
+	}
+	if err := done(); err != nil {
+		return nil, err
+	}
+
+	// the package itself keeps no files
+	empty := new(bytes.Buffer)
+	sum, err := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if err := scpio.NewWriter(io.MultiWriter(empty, sum)).Close(); err != nil {
+		return nil, err
+	}
+	p.idx = rpm.NewFileIndex()
+	p.idx.SetDigestAlgo(algo)
+	p.data, p.digest = empty.Bytes(), sum.Sum()
+	if p.verity != nil {
+		p.verity = [][]byte{}
+	}
+	return parts, nil
+}
+
+// part returns the config of part i of n, the metadata of c without
+// scriptlets and dependencies, requiring the next part.
+func (c *Config) part(i, n int) *Config {
+	summary := c.Summary
+	if summary == "" {
+		summary = c.Name
+	}
+	r := &Config{
+		Name:        partName(c.Name, i),
+		Epoch:       c.Epoch,
+		Version:     c.Version,
+		Release:     c.Release,
+		Arch:        c.Arch,
+		License:     c.License,
+		URL:         c.URL,
+		BugURL:      c.BugURL,
+		Packager:    c.Packager,
+		Vendor:      c.Vendor,
+		Summary:     fmt.Sprintf("%s, part %d of %d", summary, i+1, n),
+		Description: c.Description,
+	}
+	if i+1 < n {
+		r.Requires = []string{partName(c.Name, i+1) + "=" + c.evr()}
+	}
+	return r
+}
+
+// evr returns [epoch:]version-release of c.
+func (c *Config) evr() string {
+	r := c.Version + "-" + c.Release
+	if c.Epoch != "" {
+		r = c.Epoch + ":" + r
+	}
+	return r
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, v := range []struct {
+		s    string
+		want int64 // 0 for an error
+	}{
+		{"1", 1},
+		{"10K", 10 << 10},
+		{"3M", 3 << 20},
+		{"8G", 8 << 30},
+		{"9223372036854775807", 1<<63 - 1},
+		{"8589934591G", 8589934591 << 30},
+		{"8589934592G", 0},
+		{"9223372036854775807K", 0},
+		{"10KG", 0},
+		{"10k", 0},
+		{"K", 0},
+		{"", 0},
+		{"0", 0},
+		{"-1K", 0},
+		{"1.5M", 0},
+	} {
+		n, err := parseSize(v.s)
+		if n != v.want || (err == nil) != (v.want != 0) {
+			t.Errorf("%q: %d, %v", v.s, n, err)
+		}
+	}
+}
+
+// testPayload returns the payload of files with the given sizes, file i
+// is /d/i filled with byte 'a'+i, a size below 0 is a directory.
+func testPayload(t *testing.T, sizes ...int) *payload {
+	idx := rpm.NewFileIndex()
+	idx.SetDigestAlgo(rpm.PGPHASHALGO_SHA256)
+	b := new(bytes.Buffer)
+	sum, _ := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	cw := scpio.NewWriter(io.MultiWriter(b, sum))
+	for i, v := range sizes {
+		f := &rpm.File{Name: fmt.Sprintf("/d/%d", i), Mode: 0o40755}
+		if v >= 0 {
+			data := bytes.Repeat([]byte{byte('a' + i)}, v)
+			h := sha256.Sum256(data)
+			f.Mode, f.Size, f.Digest = 0o100644, uint64(v), hex.EncodeToString(h[:])
+		}
+		idx.Add(f)
+		if err := cw.WriteHeader(uint32(i)); err != nil {
+			t.Fatal(err)
+		}
+		cw.Write(bytes.Repeat([]byte{byte('a' + i)}, max(v, 0)))
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &payload{idx: idx, data: b.Bytes(), digest: sum.Sum()}
+}
+
+// contents returns the names and data of the files of p, in payload
+// order.
+func contents(t *testing.T, p *payload) (names []string, data []string) {
+	files := p.idx.Files()
+	r := scpio.NewReader(bytes.NewReader(p.data))
+	sz := 0
+	for {
+		e, err := r.NextEntry(sz)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := files[e.Index]
+		sz = 0
+		if f.Mode&0o170000 == 0o100000 {
+			sz = int(f.Size)
+		}
+		b, err := io.ReadAll(r.Data(int64(sz)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, data = append(names, f.Name), append(data, string(b))
+	}
+	return names, data
+}
+
+func TestSplit(t *testing.T) {
+	const k = 10 << 10
+	p := testPayload(t, k, -1, k, k, 100)
+	wantNames, wantData := contents(t, p)
+
+	// two files of 10K and their overhead fit the budget
+	parts, err := p.split(partOverhead + 2*k + 4*fileOverhead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, data []string
+	for i, v := range parts {
+		if sum := sha256.Sum256(v.data); hex.EncodeToString(sum[:]) != v.digest {
+			t.Errorf("part %d: digest", i)
+		}
+		n, d := contents(t, v)
+		if len(n) != len(v.idx.Files()) {
+			t.Errorf("part %d: %d of %d files in the payload", i, len(n), len(v.idx.Files()))
+		}
+		names, data = append(names, n...), append(data, d...)
+	}
+	if len(parts) != 2 || len(parts[0].idx.Files()) != 3 || len(parts[1].idx.Files()) != 2 {
+		t.Errorf("%d parts", len(parts))
+	}
+	if !slices.Equal(names, wantNames) || !slices.Equal(data, wantData) {
+		t.Errorf("files %v, want %v", names, wantNames)
+	}
+	if n, _ := contents(t, p); len(n) != 0 || len(p.idx.Files()) != 0 {
+		t.Errorf("package keeps %d files", len(n))
+	}
+	if sum := sha256.Sum256(p.data); hex.EncodeToString(sum[:]) != p.digest {
+		t.Error("package digest")
+	}
+
+	if _, err := testPayload(t, k).split(partOverhead + k); err == nil {
+		t.Error("file larger than a part split")
+	}
+	if _, err := testPayload(t, 1).split(partOverhead); err == nil {
+		t.Error("split below the overhead")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// the scriptlets of the %systemd_post, %systemd_preun and
+// %systemd_postun_with_restart macros, %s is the unit list
+const (
+	systemdPost = `if [ $1 -eq 1 ]; then
+	# Initial installation
+	systemctl --no-reload preset %s >/dev/null 2>&1 || :
+fi
+`
+	systemdPreun = `if [ $1 -eq 0 ]; then
+	# Package removal, not upgrade
+	systemctl --no-reload disable --now %s >/dev/null 2>&1 || :
+fi
+`
+	systemdPostun = `systemctl daemon-reload >/dev/null 2>&1 || :
+if [ $1 -ge 1 ]; then
+	# Package upgrade, not uninstall
+	systemctl try-restart %s >/dev/null 2>&1 || :
+fi
+`
+)
+
+var systemdDirs = []string{"/usr/lib/systemd/system/", "/lib/systemd/system/"}
+
+// systemdUnits returns the system units of names that can be enabled,
+// templates and targets are left alone.
+func systemdUnits(names []string) []string {
+	var r []string
+	for _, v := range names {
+		d, f := path.Split(v)
+		known := false
+		for _, s := range systemdDirs {
+			known = known || d == s
+		}
+		if !known || strings.Contains(f, "@.") {
+			continue
+		}
+		switch path.Ext(f) {
+		case ".service", ".socket", ".timer", ".path":
+			r = append(r, f)
+		}
+	}
+	return r
+}
+
+// systemd adds the scriptlets of units to c, after any of the config.
+func (c *Config) systemd(units []string) {
+	if len(units) == 0 {
+		return
+	}
+	list := strings.Join(units, " ")
+	c.addSnippet("systemd units", snippet{
+		post:   fmt.Sprintf(systemdPost, list),
+		preun:  fmt.Sprintf(systemdPreun, list),
+		postun: fmt.Sprintf(systemdPostun, list),
+		prog:   "systemd",
+	})
+}
@@ -4,93 +4,200 @@ import (
 	"archive/tar"
 	"bufio"
 	"bytes"
-	"crypto/sha256"
+	"crypto"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/exec"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 
-	"github.com/tlahdekorpi/rpm"
-	"github.com/tlahdekorpi/rpm/scpio"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+	"github.com/pschou/go-rpm/scpio"
 )
 
-func index(r io.Reader, w *scpio.Writer) (*rpm.FileIndex, error) {
-	var (
-		idx = rpm.NewFileIndex()
-		tr  = tar.NewReader(r)
-		i   uint32
-	)
+type indexer struct {
+	idx *rpm.FileIndex
+	w   *scpio.Writer
+	p   *progress
+	ino uint32
+
+	// keep reports if an entry is written, nil keeps everything
+	keep func(name string) bool
+
+	// xattrs without a tag, warned about once
+	dropped map[string]bool
+
+	// fs-verity digests per file, nil unless verity signing
+	verity [][]byte
+
+	// RPMFILE_* flags by path, e.g. conffiles of a deb
+	flags map[string]uint32
+}
+
+const paxXattr = "SCHILY.xattr."
+
+// xattrs maps the PAX xattr records of hdr to file capabilities and
+// selinux contexts.
+func (x *indexer) xattrs(file *rpm.File, hdr *tar.Header) error {
+	for k, v := range hdr.PAXRecords {
+		if !strings.HasPrefix(k, paxXattr) {
+			continue
+		}
+		switch k = k[len(paxXattr):]; k {
+		case "security.capability":
+			c, err := rpm.CapabilityText([]byte(v))
+			if err != nil {
+				return err
+			}
+			file.Caps = c
+		case "security.selinux":
+			file.Context = strings.TrimRight(v, "\x00")
+		default:
+			if x.dropped == nil {
+				x.dropped = make(map[string]bool)
+			}
+			if !x.dropped[k] {
+				x.dropped[k] = true
+				log.Printf("warning: xattr %s is not stored", k)
+			}
+		}
+	}
+	return nil
+}
+
+func (x *indexer) add(r io.Reader) error {
+	tr := tar.NewReader(r)
 	for {
 		hdr, err := tr.Next()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		name := path.Join("/", hdr.Name)
+		if x.keep != nil && !x.keep(name) {
+			continue
 		}
 
 		mode, err := rpm.Mode(hdr.FileInfo().Mode())
 		if err != nil {
-			return nil, err
+			return err
 		}
 		file := &rpm.File{
-			Name:   path.Join("/", hdr.Name),
+			Name:   name,
 			LinkTo: hdr.Linkname,
 			MTime:  uint32(hdr.ModTime.Unix()),
 			Size:   uint64(hdr.Size),
 			Mode:   mode,
+			Flags:  x.flags[name],
 		}
 
-		if err := w.WriteHeader(i); err != nil {
-			return nil, err
+		if err := x.xattrs(file, hdr); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
 		}
-		i++
 
 		if hdr.Typeflag != tar.TypeReg {
-			idx.Add(file)
+			if err := x.write(file, nil); err != nil {
+				return err
+			}
+			x.p.add(0)
 			continue
 		}
 
-		sum := sha256.New()
-		n, err := io.Copy(io.MultiWriter(w, sum), tr)
-		if err != nil {
-			return nil, err
+		if err := x.write(file, tr); err != nil {
+			return err
 		}
+		x.p.add(int64(file.Size))
+	}
+	return nil
+}
 
-		if n != hdr.Size {
-			return nil, fmt.Errorf(
-				"hdr size mismatch, want %d, have %d",
-				n, hdr.Size,
-			)
+// write adds file to the index and payload, r has the data of regular
+// files, nil for anything else.
+func (x *indexer) write(file *rpm.File, r io.Reader) error {
+	if err := x.w.WriteHeader(x.ino); err != nil {
+		return err
+	}
+	x.ino++
+
+	if r == nil {
+		x.idx.Add(file)
+		if x.verity != nil {
+			x.verity = append(x.verity, nil)
 		}
+		return nil
+	}
 
-		file.Digest = hex.EncodeToString(sum.Sum(nil))
-		idx.Add(file)
+	sum, err := rpm.NewHash(x.idx.DigestAlgo())
+	if err != nil {
+		return err
+	}
+	w := io.MultiWriter(x.w, sum)
+	var vh *sign.VerityHash
+	if x.verity != nil {
+		vh = sign.NewVerityHash()
+		w = io.MultiWriter(w, vh)
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return err
+	}
+
+	if uint64(n) != file.Size {
+		return fmt.Errorf(
+			"hdr size mismatch, want %d, have %d",
+			n, file.Size,
+		)
 	}
-	return idx, w.Close()
+
+	file.Digest = hex.EncodeToString(sum.Sum(nil))
+	x.idx.Add(file)
+	if vh != nil {
+		x.verity = append(x.verity, vh.Sum())
+	}
+	return nil
 }
 
 type Config struct {
-	Name        string
-	Version     string
-	Release     string
-	Arch        string
-	License     string
-	URL         string
-	BugURL      string `name:"bug-url"`
-	Packager    string
-	Vendor      string
-	Summary     string
-	Description string
-	Provides    []string
-	Requires    []string
-	PreInstall  script
-	PostInstall script
+	Name          string
+	Epoch         string
+	Version       string
+	Release       string
+	Arch          string
+	License       string
+	URL           string
+	BugURL        string `name:"bug-url"`
+	Packager      string
+	Vendor        string
+	Summary       string
+	Description   string
+	Provides      []string
+	Requires      []string
+	Conflicts     []string
+	PreInstall    script
+	PostInstall   script
+	PreUninstall  script
+	PostUninstall script
+	Users         sysusers
+	UsersMode     string `name:"users-mode"`
+	Scriptlets    []string
+	Alternatives  alternatives
+
+	// Requires(post) and the like of generated scriptlets
+	scriptRequires []sense
 }
 
 type sense struct {
@@ -122,9 +229,11 @@ func senseFlags(value string) sense {
 }
 
 func (c *Config) provides(hdr *rpm.Header) {
-	c.Provides = append(c.Provides,
-		c.Name+"="+c.Version+"-"+c.Release,
-	)
+	evr := c.Version + "-" + c.Release
+	if c.Epoch != "" {
+		evr = c.Epoch + ":" + evr
+	}
+	c.Provides = append(c.Provides, c.Name+"="+evr)
 	var (
 		flags   []uint32
 		names   []string
@@ -146,8 +255,76 @@ func (c *Config) provides(hdr *rpm.Header) {
 	hdr.AddStringArray(rpm.RPMTAG_PROVIDEVERSION, version...)
 }
 
+// interpreters returns the Requires(pre) and the like of the scriptlet
+// interpreters, as rpmbuild adds them. Lua is built into rpm.
+func (c *Config) interpreters() []sense {
+	var r []sense
+	for _, v := range []struct {
+		s    *script
+		flag uint32
+	}{
+		{&c.PreInstall, rpm.RPMSENSE_SCRIPT_PRE},
+		{&c.PostInstall, rpm.RPMSENSE_SCRIPT_POST},
+		{&c.PreUninstall, rpm.RPMSENSE_SCRIPT_PREUN},
+		{&c.PostUninstall, rpm.RPMSENSE_SCRIPT_POSTUN},
+	} {
+		if v.s.data == "" || v.s.prog == "" || v.s.prog[0] == '<' {
+			continue
+		}
+		r = append(r, sense{name: v.s.prog, flags: rpm.RPMSENSE_INTERP | v.flag})
+	}
+	return r
+}
+
+// scriptDeps returns the interpreters and the requires of generated
+// scriptlet code, one entry per program and version of the latter. A
+// program already required as the interpreter of a scriptlet isn't
+// required again for it.
+func (c *Config) scriptDeps() []sense {
+	r := c.interpreters()
+	n := len(r)
+	for _, s := range c.scriptRequires {
+		for _, v := range r[:n] {
+			if v.name == s.name && v.version == s.version {
+				s.flags &^= v.flags &^ rpm.RPMSENSE_INTERP
+			}
+		}
+		i := slices.IndexFunc(r[n:], func(v sense) bool {
+			return v.name == s.name && v.version == s.version
+		})
+		switch {
+		case s.flags == 0:
+		case i == -1:
+			r = append(r, s)
+		default:
+			r[n+i].flags |= s.flags
+		}
+	}
+	return r
+}
+
 func (c *Config) requires(hdr *rpm.Header) {
-	if len(c.Requires) == 0 {
+	deps(hdr, append(senses(c.Requires), c.scriptDeps()...),
+		rpm.RPMTAG_REQUIREFLAGS, rpm.RPMTAG_REQUIRENAME, rpm.RPMTAG_REQUIREVERSION,
+	)
+}
+
+func (c *Config) conflicts(hdr *rpm.Header) {
+	deps(hdr, senses(c.Conflicts),
+		rpm.RPMTAG_CONFLICTFLAGS, rpm.RPMTAG_CONFLICTNAME, rpm.RPMTAG_CONFLICTVERSION,
+	)
+}
+
+func senses(list []string) []sense {
+	r := make([]sense, len(list))
+	for i, v := range list {
+		r[i] = senseFlags(v)
+	}
+	return r
+}
+
+func deps(hdr *rpm.Header, list []sense, flagsTag, nameTag, versionTag rpm.TagType) {
+	if len(list) == 0 {
 		return
 	}
 	var (
@@ -155,20 +332,19 @@ func (c *Config) requires(hdr *rpm.Header) {
 		names   []string
 		version []string
 	)
-	rm := make(map[string]struct{})
-	for _, p := range c.Requires {
-		if _, ok := rm[p]; ok {
+	rm := make(map[sense]struct{})
+	for _, s := range list {
+		if _, ok := rm[s]; ok {
 			continue
 		}
-		s := senseFlags(p)
-		rm[s.name] = struct{}{}
+		rm[s] = struct{}{}
 		flags = append(flags, s.flags)
 		names = append(names, s.name)
 		version = append(version, s.version)
 	}
-	hdr.AddInt32(rpm.RPMTAG_REQUIREFLAGS, flags...)
-	hdr.AddStringArray(rpm.RPMTAG_REQUIRENAME, names...)
-	hdr.AddStringArray(rpm.RPMTAG_REQUIREVERSION, version...)
+	hdr.AddInt32(flagsTag, flags...)
+	hdr.AddStringArray(nameTag, names...)
+	hdr.AddStringArray(versionTag, version...)
 }
 
 func add(hdr *rpm.Header, t rpm.TagType, v string) {
@@ -180,6 +356,9 @@ func add(hdr *rpm.Header, t rpm.TagType, v string) {
 
 func (c *Config) append(hdr *rpm.Header) {
 	add(hdr, rpm.RPMTAG_NAME, c.Name)
+	if e, err := strconv.ParseUint(c.Epoch, 10, 32); err == nil {
+		hdr.AddInt32(rpm.RPMTAG_EPOCH, uint32(e))
+	}
 	add(hdr, rpm.RPMTAG_VERSION, c.Version)
 	add(hdr, rpm.RPMTAG_RELEASE, c.Release)
 	add(hdr, rpm.RPMTAG_ARCH, c.Arch)
@@ -199,12 +378,64 @@ func (c *Config) append(hdr *rpm.Header) {
 		hdr.AddString(rpm.RPMTAG_POSTIN, c.PostInstall.data)
 		hdr.AddString(rpm.RPMTAG_POSTINPROG, c.PostInstall.prog)
 	}
+	if c.PreUninstall.data != "" {
+		hdr.AddString(rpm.RPMTAG_PREUN, c.PreUninstall.data)
+		hdr.AddString(rpm.RPMTAG_PREUNPROG, c.PreUninstall.prog)
+	}
+	if c.PostUninstall.data != "" {
+		hdr.AddString(rpm.RPMTAG_POSTUN, c.PostUninstall.data)
+		hdr.AddString(rpm.RPMTAG_POSTUNPROG, c.PostUninstall.prog)
+	}
 
 	c.provides(hdr)
 	c.requires(hdr)
+	c.conflicts(hdr)
+}
+
+type inputs []string
+
+func (i *inputs) String() string { return strings.Join(*i, ",") }
+func (i *inputs) Set(v string) error {
+	*i = append(*i, v)
+	return nil
+}
+
+var (
+	flagConfig   = flag.String("c", "", "config file, - for stdin")
+	flagDump     = flag.Bool("dump-config", false, "print the effective config and exit")
+	flagInput    inputs
+	flagProgress = flag.Bool("progress", false, "print progress, needs a seekable input")
+	flagArch     arches
+	flagOutput   = flag.String("o", "", "output directory or file name template, default stdout")
+	flagSign     = flag.String("sign", "", "sign the header with the first secret key in file")
+	flagVerity   = flag.String("verity-sign", "", "sign fs-verity digests with command, digest on stdin, PKCS#7 signature on stdout")
+	flagDeb      = flag.String("deb", "", "convert a Debian package, before the config file")
+	flagApk      = flag.String("apk", "", "convert an Alpine package, before the config file")
+	flagSystemd  = flag.Bool("systemd", false, "preset, stop and restart packaged systemd units in scriptlets")
+	flagWrap     = flag.Bool("wrap", false, "wrap the description and join summary lines")
+	flagIMA      = flag.String("ima-sign", "", "sign file digests for IMA with the PEM RSA or ECDSA private key in file")
+	flagReserve  = flag.Int("reserve", 0, "reserve bytes in the signature header for signing in place later, rpmbuild uses 4096")
+	flagCompress = flag.String("compress", "gzip", "payload compression, gzip, xz, zstd or none")
+	flagLevel    = flag.Int("level", 0, "compression level, default 9 for gzip and 19 for zstd like Fedora")
+	flagWindow   = flag.String("window", "", "zstd window size, a power of two with a K or M suffix, default by level")
+	flagSplit    = flag.String("split", "", "move the files to name-partN packages of at most size bytes, with a K, M or G suffix")
+
+	signKey *openpgp.Entity
+	imaKey  crypto.Signer
+)
+
+type arches []string
+
+func (a *arches) String() string { return strings.Join(*a, ",") }
+func (a *arches) Set(v string) error {
+	*a = append(*a, strings.Split(v, ",")...)
+	return nil
 }
 
-var flagConfig = flag.String("c", "", "config file")
+func init() {
+	flag.Var(&flagInput, "i", "input tar file, later ones override earlier, default stdin")
+	flag.Var(&flagArch, "arch", "package arch, several write name-version-release.arch.rpm files")
+}
 
 func main() {
 	log.SetFlags(0)
@@ -218,7 +449,52 @@ func main() {
 		Arch:    "noarch",
 	}
 
-	if *flagConfig != "" {
+	var (
+		flags   map[string]uint32
+		convert = *flagDeb != "" || *flagApk != ""
+	)
+	if convert && len(flagInput) > 0 || *flagDeb != "" && *flagApk != "" {
+		log.Fatal("-deb, -apk and -i are exclusive")
+	}
+	var window int64
+	if *flagWindow != "" {
+		var err error
+		if window, err = parseSize(*flagWindow); err != nil {
+			log.Fatalf("-window: %v", err)
+		}
+	}
+	comp, err := newCompressor(*flagCompress, *flagLevel, window)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var splitMax int64
+	if *flagSplit != "" {
+		if splitMax, err = parseSize(*flagSplit); err != nil {
+			log.Fatalf("-split: %v", err)
+		}
+	}
+	switch {
+	case *flagDeb != "":
+		var err error
+		if flags, err = debControl(*flagDeb, config); err != nil {
+			log.Fatal(err)
+		}
+	case *flagApk != "":
+		if err := apkControl(*flagApk, config); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch *flagConfig {
+	case "":
+	case "-":
+		if !convert && len(flagInput) == 0 && !*flagDump {
+			log.Fatal("-c - needs -i, -deb or -apk, stdin is the config")
+		}
+		if err := loadconfig(os.Stdin, config); err != nil {
+			log.Fatal(err)
+		}
+	default:
 		f, err := os.Open(*flagConfig)
 		if err != nil {
 			log.Fatal(err)
@@ -228,19 +504,200 @@ func main() {
 		}
 		f.Close()
 	}
+	if *flagWrap {
+		config.Summary = rpm.CleanSummary(config.Summary)
+		config.Description = rpm.WrapText(config.Description, rpm.TextWidth)
+	}
+	if *flagDump {
+		if len(flagArch) == 1 {
+			config.Arch = flagArch[0]
+		} else if len(flagArch) > 1 {
+			fmt.Printf("# arch %s from -arch\n", strings.Join(flagArch, ","))
+		}
+		if err := dumpconfig(os.Stdout, config); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	hdr := rpm.NewPayloadHeader()
-	config.append(hdr)
+	if *flagSign != "" {
+		f, err := os.Open(*flagSign)
+		if err != nil {
+			log.Fatal(err)
+		}
+		signKey, err = sign.ReadSigningKey(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *flagSign, err)
+		}
+	}
+	if *flagIMA != "" {
+		var err error
+		if imaKey, err = readIMAKey(*flagIMA); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// TODO: write payload to disk
 	data := new(bytes.Buffer)
-	sum := sha256.New()
-	idx, err := index(os.Stdin, scpio.NewWriter(
-		io.MultiWriter(data, sum),
-	))
+	sum, err := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
 	if err != nil {
 		log.Fatal(err)
 	}
+	x := &indexer{
+		idx:   rpm.NewFileIndex(),
+		w:     scpio.NewWriter(io.MultiWriter(data, sum)),
+		flags: flags,
+	}
+	x.idx.SetDigestAlgo(rpm.PGPHASHALGO_SHA256)
+	if *flagVerity != "" {
+		x.verity = [][]byte{}
+	}
+
+	var (
+		sc *scan
+		l  *layers
+	)
+	switch len(flagInput) {
+	case 0:
+		if !convert {
+			sc, err = prescan(os.Stdin)
+		}
+	case 1:
+		sc, err = scanFile(flagInput[0])
+	default:
+		l, err = layer(flagInput)
+		if l != nil {
+			sc = l.scan
+			for _, v := range l.conflicts {
+				log.Printf("warning: %s", v)
+			}
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch {
+	case sc == nil && *flagProgress:
+		log.Print("input is not seekable, no progress")
+	case sc != nil && *flagProgress:
+		x.p = &progress{scan: sc, last: -1}
+	}
+	if sc != nil && sc.hardlinks > 0 {
+		log.Printf("warning: %d hardlinks", sc.hardlinks)
+	}
+
+	switch {
+	case *flagDeb != "":
+		err = addDeb(x, *flagDeb)
+	case *flagApk != "":
+		err = addApk(x, *flagApk)
+	case len(flagInput) == 0:
+		err = addInput(x, os.Stdin)
+	}
+	for i, v := range flagInput {
+		if l != nil {
+			x.keep = l.keep(i)
+		}
+		if err = addFile(x, v); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = config.users(x)
+	}
+	if err == nil {
+		err = x.w.Close()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *flagSystemd {
+		config.systemd(systemdUnits(x.idx.Filenames()))
+	}
+	if err := config.helpers(); err != nil {
+		log.Fatal(err)
+	}
+	var parts []*payload
+	payload := &payload{
+		idx:    x.idx,
+		data:   data.Bytes(),
+		digest: sum.Sum(),
+		verity: x.verity,
+	}
+	if splitMax > 0 {
+		if parts, err = payload.split(splitMax); err != nil {
+			log.Fatal(err)
+		}
+		if len(parts) > 0 {
+			config.Requires = append(config.Requires, partName(config.Name, 0)+"="+config.evr())
+		}
+	}
+	if err := payload.compress(comp); err != nil {
+		log.Fatal(err)
+	}
+	for _, v := range parts {
+		if err := v.compress(comp); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(flagArch) == 0 {
+		flagArch = append(flagArch, config.Arch)
+	}
+
+	out, err := newOutput(*flagOutput)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if out == nil && (len(flagArch) > 1 || len(parts) > 0) {
+		// one package per arch or part, the payload is the same
+		out = &output{dir: "."}
+	}
+	if out != nil {
+		out.max = splitMax
+	}
+
+	for _, v := range flagArch {
+		c := *config
+		c.Arch = v
+		c.Provides = append([]string(nil), config.Provides...)
+		hdr := c.header(payload)
+
+		if out == nil {
+			err = c.write(os.Stdout, hdr, payload)
+		} else {
+			err = out.write(&c, hdr, payload)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		for i, part := range parts {
+			pc := c.part(i, len(parts))
+			if err := out.write(pc, pc.header(part), part); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+type payload struct {
+	idx    *rpm.FileIndex
+	data   []byte
+	digest string
+	verity [][]byte
+
+	// set by compress, the uncompressed size and digest
+	size       int64
+	alt        string
+	compressor string
+	flags      string
+}
+
+func (c *Config) header(p *payload) *rpm.Header {
+	hdr := rpm.NewPayloadHeader()
+	c.append(hdr)
 
 	hdr.AddStringArray(rpm.RPMTAG_HEADERI18NTABLE, "C")
 	hdr.AddString(rpm.RPMTAG_ENCODING, "utf-8")
@@ -248,37 +705,102 @@ func main() {
 	hdr.AddString(rpm.RPMTAG_OS, "linux")
 	hdr.AddInt32(rpm.RPMTAG_BUILDTIME, 0) // rpm requires
 
+	if p.compressor != "" {
+		hdr.AddString(rpm.RPMTAG_PAYLOADCOMPRESSOR, p.compressor)
+		hdr.AddString(rpm.RPMTAG_PAYLOADFLAGS, p.flags)
+	}
 	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
-	hdr.AddInt32(rpm.RPMTAG_FILEDIGESTALGO, rpm.PGPHASHALGO_SHA256)
-	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum.Sum(nil)))
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, p.digest)
+	if p.alt != "" {
+		hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGESTALT, p.alt)
+	}
 
-	idx.Append(hdr)
+	p.idx.Append(hdr)
+	return hdr
+}
 
-	pb := new(bytes.Buffer)
-	hs := sha256.New()
-	if _, err := hdr.WriteTo(io.MultiWriter(pb, hs)); err != nil {
-		log.Fatal(err)
+func (c *Config) write(w io.Writer, hdr *rpm.Header, p *payload) error {
+	spool := bytes.NewBuffer(make([]byte, 0, len(p.data)+1<<16))
+	s := sign.NewSigner(spool)
+	s.ReserveSpace(*flagReserve)
+	s.SetArchiveSize(p.size)
+	if _, err := s.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := s.Write(p.data); err != nil {
+		return err
+	}
+	var keys []*openpgp.Entity
+	if signKey != nil {
+		keys = append(keys, signKey)
+	}
+	sig, err := s.Signature(keys...)
+	if err != nil {
+		return err
+	}
+	if p.verity != nil {
+		if err := sign.SignVerity(sig, p.verity, cmdSigner(*flagVerity)); err != nil {
+			return err
+		}
+	}
+	if imaKey != nil {
+		if err := sign.SignFiles(sig, hdr, imaKey); err != nil {
+			return err
+		}
 	}
 
-	sig := rpm.NewSignatureHeader()
-	sig.AddString(rpm.RPMSIGTAG_SHA256, hex.EncodeToString(hs.Sum(nil)))
+	lead := rpm.NewLead(strings.Join(
+		[]string{c.Name, c.Version, c.Release},
+		"-",
+	), rpm.LeadBinary)
+	lead.SetArch(c.Arch)
 
-	buf := bufio.NewWriterSize(os.Stdout, 1<<20)
-	if _, err := rpm.WriteHeaders(buf,
-		rpm.NewLead(strings.Join(
-			[]string{config.Name, config.Version, config.Release},
-			"-",
-		), rpm.LeadBinary),
-		sig,
-		pb,
-	); err != nil {
-		log.Fatal(err)
+	buf := bufio.NewWriterSize(w, 1<<20)
+	if _, err := rpm.WriteHeaders(buf, lead, sig, spool); err != nil {
+		return err
 	}
+	return buf.Flush()
+}
 
-	if _, err := io.Copy(buf, data); err != nil {
-		log.Fatal(err)
+// readIMAKey reads a PEM PKCS #8, PKCS #1 or SEC 1 private key.
+func readIMAKey(name string) (crypto.Signer, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
 	}
-	if err := buf.Flush(); err != nil {
-		log.Fatal(err)
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, fmt.Errorf("%s: no PEM data", name)
+	}
+	var k any
+	switch p.Type {
+	case "RSA PRIVATE KEY":
+		k, err = x509.ParsePKCS1PrivateKey(p.Bytes)
+	case "EC PRIVATE KEY":
+		k, err = x509.ParseECPrivateKey(p.Bytes)
+	default:
+		k, err = x509.ParsePKCS8PrivateKey(p.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	s, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a signing key", name)
+	}
+	return s, nil
+}
+
+// cmdSigner runs a shell command per file to sign its fs-verity digest.
+type cmdSigner string
+
+func (c cmdSigner) SignVerity(b []byte) ([]byte, error) {
+	cmd := exec.Command("/bin/sh", "-c", string(c))
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("verity-sign: %w", err)
 	}
+	return out, nil
 }
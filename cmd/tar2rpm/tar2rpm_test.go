@@ -0,0 +1,156 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+// TestHeaderFiles checks the file tags of a tar built by another OS do
+// not depend on the host: names keep their slashes, users default to
+// root whatever the tar owner, modes are those of the tar.
+func TestHeaderFiles(t *testing.T) {
+	b := new(bytes.Buffer)
+	tw := tar.NewWriter(b)
+	mtime := time.Unix(1700000000, 0)
+	for _, v := range []*tar.Header{
+		{Name: "./usr/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "./usr/bin/foo", Mode: 0o4755, Size: 3, Uname: "builder", Uid: 501, Gid: 20},
+		{Name: "usr/share/doc/foo/README", Mode: 0o644, Size: 3, Uname: "Administrator"},
+		{Name: "usr/share/doc/foo/a\\b", Mode: 0o600, Size: 3},
+		{Name: "/usr/bin/bar", Typeflag: tar.TypeSymlink, Linkname: "../share/foo", Mode: 0o777},
+		{Name: "var/lib/foo", Typeflag: tar.TypeDir, Mode: 0o700},
+	} {
+		v.ModTime = mtime
+		tw.WriteHeader(v)
+		if v.Size > 0 {
+			tw.Write([]byte("foo"))
+		}
+	}
+	tw.Close()
+
+	data := new(bytes.Buffer)
+	x := &indexer{idx: rpm.NewFileIndex(), w: scpio.NewWriter(data)}
+	x.idx.SetDigestAlgo(rpm.PGPHASHALGO_SHA256)
+	if err := x.add(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c := &Config{Name: "foo", Version: "1", Release: "1", Arch: "noarch"}
+	hdr := c.header(&payload{idx: x.idx, data: data.Bytes()})
+
+	for tag, want := range map[rpm.TagType][]string{
+		rpm.RPMTAG_BASENAMES:     {"usr", "foo", "README", "a\\b", "bar", "foo"},
+		rpm.RPMTAG_DIRNAMES:      {"/", "/usr/bin/", "/usr/share/doc/foo/", "/var/lib/"},
+		rpm.RPMTAG_FILEUSERNAME:  {"root", "root", "root", "root", "root", "root"},
+		rpm.RPMTAG_FILEGROUPNAME: {"root", "root", "root", "root", "root", "root"},
+		rpm.RPMTAG_FILELINKTOS:   {"", "", "", "", "../share/foo", ""},
+	} {
+		if s, _ := hdr.Find(tag).StringArray(); !slices.Equal(s, want) {
+			t.Errorf("%v: %q, want %q", tag, s, want)
+		}
+	}
+	if i, _ := hdr.Find(rpm.RPMTAG_DIRINDEXES).Int32(); !slices.Equal(i, []uint32{0, 1, 2, 2, 1, 3}) {
+		t.Errorf("dir indexes: %v", i)
+	}
+	// the setuid bit is dropped, see rpm.Capabilities
+	m, _ := hdr.Find(rpm.RPMTAG_FILEMODES).Int16()
+	if want := []uint16{0o40755, 0o100755, 0o100644, 0o100600, 0o120777, 0o40700}; !slices.Equal(m, want) {
+		t.Errorf("modes: %o, want %o", m, want)
+	}
+	// a backslash is part of a name, not a separator
+	names := []string{"/usr", "/usr/bin/foo", "/usr/share/doc/foo/README",
+		"/usr/share/doc/foo/a\\b", "/usr/bin/bar", "/var/lib/foo"}
+	if n := x.idx.Filenames(); !slices.Equal(n, names) {
+		t.Errorf("file names %q", n)
+	}
+
+	for o, want := range map[string]string{
+		"out/":                           filepath.Join("out", "foo-1-1.noarch.rpm"),
+		"out/{{.Arch}}/{{.Name}}.rpm":    filepath.Join("out", "noarch", "foo.rpm"),
+		"{{.Name}}-{{.Version}}.x86.rpm": "foo-1.x86.rpm",
+	} {
+		out, err := newOutput(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name, err := out.name(hdr); err != nil || name != want {
+			t.Errorf("-o %s: %s, %v", o, name, err)
+		}
+	}
+}
+
+func TestRequires(t *testing.T) {
+	const (
+		pre    = rpm.RPMSENSE_SCRIPT_PRE
+		post   = rpm.RPMSENSE_SCRIPT_POST
+		preun  = rpm.RPMSENSE_SCRIPT_PREUN
+		postun = rpm.RPMSENSE_SCRIPT_POSTUN
+		interp = rpm.RPMSENSE_INTERP
+	)
+	for _, v := range []struct {
+		name   string
+		config string
+		extra  []sense // scriptRequires
+		want   []sense
+	}{
+		{"sh", "preinstall <<!\necho pre\n!\n",
+			nil, []sense{{"/bin/sh", "", interp | pre}}},
+		{"python", "postinstall(/usr/bin/python3) <<!\n#!/usr/bin/python3\nprint('post')\n!\n",
+			nil, []sense{{"/usr/bin/python3", "", interp | post}}},
+		{"lua", "postuninstall(lua) <<!\nprint('postun')\n!\n",
+			nil, nil},
+		{"requires", "requires /bin/sh\npreuninstall <<!\necho preun\n!\n",
+			nil, []sense{{"/bin/sh", "", rpm.RPMSENSE_ANY}, {"/bin/sh", "", interp | preun}}},
+		{"helpers", "postinstall <<!\necho post\n!\n" +
+			"scriptlets {\n\tldconfig\n\tldconfig\n}\n" +
+			"users-mode useradd\nusers <<!\nu foo - - /var/lib/foo\n!\n",
+			nil, []sense{
+				{"/bin/sh", "", interp | pre},
+				{"/bin/sh", "", interp | post},
+				{"/bin/sh", "", interp | postun},
+				{"/usr/sbin/useradd", "", pre},
+				{"/usr/sbin/groupadd", "", pre},
+				{"/sbin/ldconfig", "", post | postun},
+			}},
+		{"interpreter", "postinstall <<!\necho post\n!\n",
+			[]sense{{"/bin/sh", "", post | postun}, {"/bin/sh", "", preun}, {"/bin/sh", "", post}},
+			[]sense{{"/bin/sh", "", interp | post}, {"/bin/sh", "", postun | preun}}},
+	} {
+		c := new(Config)
+		if err := loadconfig(strings.NewReader(v.config), c); err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		if err := c.users(nil); err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		if err := c.helpers(); err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		c.scriptRequires = append(c.scriptRequires, v.extra...)
+		hdr := new(rpm.Header)
+		c.requires(hdr)
+
+		var have []sense
+		if hdr.Find(rpm.RPMTAG_REQUIRENAME) != nil {
+			flags, _ := hdr.Find(rpm.RPMTAG_REQUIREFLAGS).Int32()
+			names, _ := hdr.Find(rpm.RPMTAG_REQUIRENAME).StringArray()
+			versions, _ := hdr.Find(rpm.RPMTAG_REQUIREVERSION).StringArray()
+			for i := range names {
+				have = append(have, sense{names[i], versions[i], flags[i]})
+			}
+		}
+		if !slices.Equal(have, v.want) {
+			t.Errorf("%s: requires %v, want %v", v.name, have, v.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+)
+
+// sysuser is a sysusers.d line, "-" fields are empty.
+type sysuser struct {
+	line  string
+	typ   string
+	name  string
+	id    string
+	gecos string
+	home  string
+	shell string
+}
+
+// sysusers are users and groups in sysusers.d syntax, e.g.
+//
+//	users <<!
+//	u foo - "foo daemon" /var/lib/foo
+//	g bar 900
+//	m foo bar
+//	!
+type sysusers []sysuser
+
+// fields splits a sysusers.d line, double quotes group fields.
+func fields(l string) []string {
+	var (
+		r     []string
+		b     strings.Builder
+		quote bool
+		field bool
+	)
+	for _, c := range l {
+		switch {
+		case c == '"':
+			quote, field = !quote, true
+		case !quote && (c == ' ' || c == '\t'):
+			if field {
+				r = append(r, b.String())
+				b.Reset()
+			}
+			field = false
+		default:
+			b.WriteRune(c)
+			field = true
+		}
+	}
+	if field {
+		r = append(r, b.String())
+	}
+	return r
+}
+
+func (s *sysusers) load(value string, sc *bufio.Scanner) error {
+	var data string
+	if err := (*str)(&data).load(value, sc); err != nil {
+		return err
+	}
+	for _, l := range strings.Split(data, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		f := fields(l)
+		if len(f) < 2 {
+			return fmt.Errorf("config/users: invalid line: %q", l)
+		}
+		u := sysuser{line: l, typ: f[0], name: f[1]}
+		for i, v := range []*string{&u.id, &u.gecos, &u.home, &u.shell} {
+			if i+2 < len(f) && f[i+2] != "-" {
+				*v = f[i+2]
+			}
+		}
+		switch u.typ {
+		case "u", "g", "r":
+		case "m":
+			if u.id == "" {
+				return fmt.Errorf("config/users: %s: missing group", u.name)
+			}
+		default:
+			return fmt.Errorf("config/users: unknown type: %q", u.typ)
+		}
+		*s = append(*s, u)
+	}
+	return nil
+}
+
+// quote quotes s for the shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// useradd returns the commands creating s, like systemd-sysusers would.
+func (s sysusers) useradd() string {
+	var b strings.Builder
+	for _, u := range s {
+		uid, gid, _ := strings.Cut(u.id, ":")
+		switch u.typ {
+		case "g":
+			fmt.Fprintf(&b, "getent group %s >/dev/null || groupadd -r", quote(u.name))
+			if u.id != "" {
+				fmt.Fprintf(&b, " -g %s", quote(u.id))
+			}
+			fmt.Fprintf(&b, " %s\n", quote(u.name))
+		case "u":
+			group := u.name
+			if gid == "" {
+				gid = uid
+			}
+			if strings.Trim(gid, "0123456789") != "" {
+				// an existing group by name
+				group = gid
+			} else {
+				fmt.Fprintf(&b, "getent group %s >/dev/null || groupadd -r", quote(u.name))
+				if gid != "" {
+					fmt.Fprintf(&b, " -g %s", quote(gid))
+				}
+				fmt.Fprintf(&b, " %s\n", quote(u.name))
+			}
+
+			home, shell := u.home, u.shell
+			if home == "" {
+				home = "/"
+			}
+			if shell == "" {
+				shell = "/sbin/nologin"
+			}
+			fmt.Fprintf(&b, "getent passwd %s >/dev/null || useradd -r -M -g %s -d %s -s %s",
+				quote(u.name), quote(group), quote(home), quote(shell))
+			if uid != "" {
+				fmt.Fprintf(&b, " -u %s", quote(uid))
+			}
+			if u.gecos != "" {
+				fmt.Fprintf(&b, " -c %s", quote(u.gecos))
+			}
+			fmt.Fprintf(&b, " %s\n", quote(u.name))
+		case "m":
+			fmt.Fprintf(&b, "id -nG %s | grep -qw %s || usermod -a -G %s %s\n",
+				quote(u.name), quote(u.id), quote(u.id), quote(u.name))
+		}
+	}
+	return b.String()
+}
+
+// users creates the users and groups of c, with a sysusers.d file and
+// the user() and group() provides of rpm 4.19 by default, or useradd in
+// the preinstall scriptlet.
+func (c *Config) users(x *indexer) error {
+	if len(c.Users) == 0 {
+		return nil
+	}
+
+	switch c.UsersMode {
+	case "useradd":
+		if !appendScript(&c.PreInstall, c.Users.useradd()) {
+			return fmt.Errorf("users: %s preinstall scriptlet", c.PreInstall.prog)
+		}
+		for _, v := range []string{"/usr/sbin/useradd", "/usr/sbin/groupadd"} {
+			c.scriptRequires = append(c.scriptRequires, sense{
+				name:  v,
+				flags: rpm.RPMSENSE_SCRIPT_PRE,
+			})
+		}
+		return nil
+	case "", "sysusers":
+	default:
+		return errors.New("users-mode: sysusers or useradd")
+	}
+
+	var b bytes.Buffer
+	for _, u := range c.Users {
+		b.WriteString(u.line + "\n")
+
+		// the version is the encoded line, rpm creates users from it
+		v := b64(u.line)
+		switch u.typ {
+		case "u":
+			c.Provides = append(c.Provides, "user("+u.name+")="+v)
+			if uid, gid, _ := strings.Cut(u.id, ":"); strings.Trim(gid, "0123456789") == "" {
+				// the implicit group of the user
+				if gid == "" {
+					gid = uid
+				}
+				if gid == "" {
+					gid = "-"
+				}
+				c.Provides = append(c.Provides, "group("+u.name+")="+b64("g "+u.name+" "+gid))
+			}
+		case "g":
+			c.Provides = append(c.Provides, "group("+u.name+")="+v)
+		case "m":
+			c.Provides = append(c.Provides, "groupmember("+u.name+"/"+u.id+")="+v)
+		}
+	}
+	return x.write(&rpm.File{
+		Name: "/usr/lib/sysusers.d/" + c.Name + ".conf",
+		Mode: 0100644,
+		Size: uint64(b.Len()),
+	}, &b)
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
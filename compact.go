@@ -0,0 +1,392 @@
+package rpm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The compact encoding is a cache format, not an rpm format. Integers
+// are varints and string arrays are front coded or dictionary encoded
+// when that is smaller, which pays off for BASENAMES, DIRNAMES,
+// FILEDIGESTS and the per-file user and group names.
+
+var compactMagic = [4]byte{'r', 'p', 'm', 'c'}
+
+const (
+	strRaw = iota
+	strFront
+	strDict
+)
+
+var errCompact = errors.New("rpm: invalid compact header")
+
+type compactWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (c *compactWriter) uvarint(v uint64) {
+	c.w.Write(c.buf[:binary.PutUvarint(c.buf[:], v)])
+}
+
+func (c *compactWriter) str(s string) {
+	c.uvarint(uint64(len(s)))
+	c.w.WriteString(s)
+}
+
+func uvarintLen(v uint64) int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(b[:], v)
+}
+
+func commonPrefix(a, b string) int {
+	var i int
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// stringsMode picks the smallest encoding for s.
+func stringsMode(s []string) int {
+	var raw, front, dict int
+	seen := make(map[string]int)
+	var prev string
+	for _, v := range s {
+		raw += uvarintLen(uint64(len(v))) + len(v)
+
+		p := commonPrefix(prev, v)
+		front += uvarintLen(uint64(p)) + uvarintLen(uint64(len(v)-p)) + len(v) - p
+		prev = v
+
+		i, ok := seen[v]
+		if !ok {
+			i = len(seen)
+			seen[v] = i
+			dict += uvarintLen(uint64(len(v))) + len(v)
+		}
+		dict += uvarintLen(uint64(i))
+	}
+	dict += uvarintLen(uint64(len(seen)))
+
+	switch {
+	case front < raw && front <= dict:
+		return strFront
+	case dict < raw:
+		return strDict
+	}
+	return strRaw
+}
+
+func (c *compactWriter) strings(s []string) {
+	mode := stringsMode(s)
+	c.uvarint(uint64(mode))
+	switch mode {
+	case strRaw:
+		for _, v := range s {
+			c.str(v)
+		}
+	case strFront:
+		var prev string
+		for _, v := range s {
+			p := commonPrefix(prev, v)
+			c.uvarint(uint64(p))
+			c.str(v[p:])
+			prev = v
+		}
+	case strDict:
+		idx := make(map[string]int)
+		var dict []string
+		for _, v := range s {
+			if _, ok := idx[v]; !ok {
+				idx[v] = len(dict)
+				dict = append(dict, v)
+			}
+		}
+		c.uvarint(uint64(len(dict)))
+		for _, v := range dict {
+			c.str(v)
+		}
+		for _, v := range s {
+			c.uvarint(uint64(idx[v]))
+		}
+	}
+}
+
+func (c *compactWriter) tag(t *Tag) error {
+	c.uvarint(uint64(t.Tag))
+	c.uvarint(uint64(t.Type))
+	c.uvarint(uint64(t.Count))
+
+	switch d := t.data.(type) {
+	case *tagString:
+		c.strings(d.data)
+	case tagUint16:
+		for _, v := range d {
+			c.uvarint(uint64(v))
+		}
+	case tagUint32:
+		for _, v := range d {
+			c.uvarint(uint64(v))
+		}
+	case tagUint64:
+		for _, v := range d {
+			c.uvarint(v)
+		}
+	case *tagBytes:
+		b := d.b.Bytes()
+		c.uvarint(uint64(len(b)))
+		c.w.Write(b)
+	default:
+		return tagError{t, errTagType}
+	}
+	return nil
+}
+
+// WriteCompact writes hdr in the compact cache encoding.
+func (hdr *Header) WriteCompact(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	c := &compactWriter{w: bufio.NewWriter(cw)}
+	c.w.Write(compactMagic[:])
+
+	var region uint64
+	if hdr.region != nil {
+		region = uint64(hdr.region.Tag)
+	}
+	c.uvarint(region)
+	c.uvarint(uint64(len(hdr.Tags)))
+	for _, v := range hdr.Tags {
+		if err := c.tag(v); err != nil {
+			return cw.n, err
+		}
+	}
+	err := c.w.Flush()
+	return cw.n, err
+}
+
+type compactReader struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+}
+
+func (c *compactReader) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(c.r)
+	return v, short(err)
+}
+
+// count reads a length or element count.
+func (c *compactReader) count() (int, error) {
+	v, err := c.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if v > 1<<31 {
+		return 0, errCompact
+	}
+	return int(v), nil
+}
+
+func (c *compactReader) bytes() ([]byte, error) {
+	n, err := c.count()
+	if err != nil {
+		return nil, err
+	}
+	// large lengths are only trusted as far as the input goes
+	if n > 1<<16 {
+		b := new(bytes.Buffer)
+		if _, err := io.CopyN(b, c.r, int64(n)); err != nil {
+			return nil, short(err)
+		}
+		return b.Bytes(), nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		return nil, short(err)
+	}
+	return b, nil
+}
+
+func (c *compactReader) str() (string, error) {
+	b, err := c.bytes()
+	return string(b), err
+}
+
+func (c *compactReader) strings(n int) ([]string, error) {
+	mode, err := c.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	var r []string
+	switch mode {
+	case strRaw:
+		for i := 0; i < n; i++ {
+			s, err := c.str()
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, s)
+		}
+	case strFront:
+		var prev string
+		for i := 0; i < n; i++ {
+			p, err := c.count()
+			if err != nil {
+				return nil, err
+			}
+			if p > len(prev) {
+				return nil, errCompact
+			}
+			s, err := c.str()
+			if err != nil {
+				return nil, err
+			}
+			prev = prev[:p] + s
+			r = append(r, prev)
+		}
+	case strDict:
+		nd, err := c.count()
+		if err != nil {
+			return nil, err
+		}
+		var dict []string
+		for i := 0; i < nd; i++ {
+			s, err := c.str()
+			if err != nil {
+				return nil, err
+			}
+			dict = append(dict, s)
+		}
+		for i := 0; i < n; i++ {
+			j, err := c.count()
+			if err != nil {
+				return nil, err
+			}
+			if j >= len(dict) {
+				return nil, errCompact
+			}
+			r = append(r, dict[j])
+		}
+	default:
+		return nil, errCompact
+	}
+	return r, nil
+}
+
+func (c *compactReader) tag() (*Tag, error) {
+	var v [3]uint64
+	for i := range v {
+		var err error
+		if v[i], err = c.uvarint(); err != nil {
+			return nil, err
+		}
+	}
+	if v[2] > 1<<31 {
+		return nil, errCompact
+	}
+	t := &Tag{tagHeader: tagHeader{
+		Tag:   TagType(v[0]),
+		Type:  uint32(v[1]),
+		Count: uint32(v[2]),
+	}}
+	n := int(t.Count)
+
+	switch t.Type {
+	case RPM_STRING_TYPE, RPM_I18NSTRING_TYPE, RPM_STRING_ARRAY_TYPE:
+		s, err := c.strings(n)
+		if err != nil {
+			return nil, err
+		}
+		t.data = &tagString{data: s}
+	case RPM_INT16_TYPE:
+		var d tagUint16
+		for i := 0; i < n; i++ {
+			v, err := c.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			d = append(d, uint16(v))
+		}
+		t.data = d
+	case RPM_INT32_TYPE:
+		var d tagUint32
+		for i := 0; i < n; i++ {
+			v, err := c.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			d = append(d, uint32(v))
+		}
+		t.data = d
+	case RPM_INT64_TYPE:
+		var d tagUint64
+		for i := 0; i < n; i++ {
+			v, err := c.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			d = append(d, v)
+		}
+		t.data = d
+	case RPM_BIN_TYPE, RPM_CHAR_TYPE, RPM_INT8_TYPE:
+		b, err := c.bytes()
+		if err != nil {
+			return nil, err
+		}
+		t.data = &tagBytes{b: bytes.NewBuffer(b)}
+	default:
+		return nil, tagError{t, errTagType}
+	}
+	return t, nil
+}
+
+// ReadCompact reads a header written by WriteCompact, tag offsets are
+// recomputed. r is buffered unless it is an io.ByteReader.
+func ReadCompact(r io.Reader) (*Header, error) {
+	c := new(compactReader)
+	if br, ok := r.(io.ByteReader); ok {
+		c.r = struct {
+			io.Reader
+			io.ByteReader
+		}{r, br}
+	} else {
+		c.r = bufio.NewReader(r)
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(c.r, magic[:]); err != nil {
+		return nil, short(err)
+	}
+	if magic != compactMagic {
+		return nil, errCompact
+	}
+
+	hdr := new(Header)
+	region, err := c.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if region != 0 {
+		hdr.SetRegion(TagType(region))
+	}
+
+	n, err := c.count()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		t, err := c.tag()
+		if err != nil {
+			return nil, err
+		}
+		if err := hdr.Add(t); err != nil {
+			return nil, err
+		}
+	}
+	return hdr, nil
+}
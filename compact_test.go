@@ -0,0 +1,123 @@
+package rpm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStringsMode(t *testing.T) {
+	for _, v := range []struct {
+		s    []string
+		mode int
+	}{
+		{[]string{"a", "b", "c"}, strRaw},
+		{[]string{"/usr/share/doc/a", "/usr/share/doc/b", "/usr/share/man/c"}, strFront},
+		{[]string{"root", "wheel", "root", "wheel", "root", "wheel"}, strDict},
+	} {
+		if m := stringsMode(v.s); m != v.mode {
+			t.Errorf("%v: %d != %d", v.s, m, v.mode)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	for _, hdr := range []*Header{makeHdr(), bigHdr(100)} {
+		if _, err := hdr.Region(); err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if _, err := hdr.WriteCompact(&b); err != nil {
+			t.Fatal(err)
+		}
+		have, err := ReadCompact(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdrEq(t, hdr, have)
+	}
+}
+
+func TestCompactTruncated(t *testing.T) {
+	var b bytes.Buffer
+	if _, err := bigHdr(10).WriteCompact(&b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < b.Len(); i++ {
+		if _, err := ReadCompact(bytes.NewReader(b.Bytes()[:i])); err == nil {
+			t.Fatalf("%d: no error", i)
+		}
+	}
+}
+
+func TestCompactRegionTag(t *testing.T) {
+	hdr := makeHdr()
+	hdr.add(&Tag{tagHeader: tagHeader{Tag: HEADER_IMMUTABLE, Type: RPM_BIN_TYPE, Count: 16},
+		data: &tagBytes{b: bytes.NewBuffer(make([]byte, 16))}})
+	var b bytes.Buffer
+	if _, err := hdr.WriteCompact(&b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCompact(&b); !errors.Is(err, errRegionTag) {
+		t.Fatalf("region tag: %v", err)
+	}
+}
+
+// bigHdr returns a header with n files spread over a few directories.
+func bigHdr(n int) *Header {
+	fi := NewFileIndex()
+	for i := 0; i < n; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprint(i)))
+		fi.Add(&File{
+			Name:   fmt.Sprintf("/usr/share/pkg/dir%d/file%d.txt", i%16, i),
+			User:   "root",
+			Group:  "root",
+			Size:   uint64(i),
+			Digest: hex.EncodeToString(sum[:]),
+		})
+	}
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_NAME, "big")
+	fi.Append(hdr)
+	return hdr
+}
+
+func benchmarkWrite(b *testing.B, compact bool) {
+	hdr := bigHdr(20000)
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		var err error
+		if compact {
+			_, err = hdr.WriteCompact(&buf)
+		} else {
+			_, err = hdr.WriteTo(&buf)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(buf.Len()), "size")
+}
+
+func BenchmarkWriteTo(b *testing.B)      { benchmarkWrite(b, false) }
+func BenchmarkWriteCompact(b *testing.B) { benchmarkWrite(b, true) }
+
+func BenchmarkReadCompact(b *testing.B) {
+	var buf bytes.Buffer
+	if _, err := bigHdr(20000).WriteCompact(&buf); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadCompact(bytes.NewReader(buf.Bytes())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package rpm
+
+import "strings"
+
+type Dependency struct {
+	Name    string
+	Flags   uint32
+	Version string
+}
+
+func (d Dependency) String() string {
+	if d.Version == "" {
+		return d.Name
+	}
+	return d.Name + " " + qfDepFlags(d.Flags) + " " + d.Version
+}
+
+const senseMask = RPMSENSE_LESS | RPMSENSE_GREATER | RPMSENSE_EQUAL
+
+// Satisfies reports whether the provide d satisfies the requirement req.
+func (d Dependency) Satisfies(req Dependency) bool {
+	if d.Name != req.Name {
+		return false
+	}
+	pf, rf := d.Flags&senseMask, req.Flags&senseMask
+	if pf == 0 || rf == 0 || d.Version == "" || req.Version == "" {
+		return true
+	}
+
+	switch c := ParseEVR(d.Version).Compare(ParseEVR(req.Version)); {
+	case c < 0:
+		return pf&RPMSENSE_GREATER != 0 || rf&RPMSENSE_LESS != 0
+	case c > 0:
+		return pf&RPMSENSE_LESS != 0 || rf&RPMSENSE_GREATER != 0
+	}
+	return pf&rf != 0
+}
+
+// RPMLib reports whether d is an rpmlib() feature dependency.
+func (d Dependency) RPMLib() bool {
+	return d.Flags&RPMSENSE_RPMLIB != 0 || strings.HasPrefix(d.Name, "rpmlib(")
+}
+
+func (hdr *Header) deps(name, flags, version TagType) []Dependency {
+	var (
+		n, v []string
+		f    []uint32
+	)
+	if t := hdr.Find(name); t != nil {
+		n, _ = t.StringArray()
+	}
+	if t := hdr.Find(version); t != nil {
+		v, _ = t.StringArray()
+	}
+	if t := hdr.Find(flags); t != nil {
+		f, _ = t.Int32()
+	}
+
+	r := make([]Dependency, len(n))
+	for i := range n {
+		r[i].Name = n[i]
+		if i < len(f) {
+			r[i].Flags = f[i]
+		}
+		if i < len(v) {
+			r[i].Version = v[i]
+		}
+	}
+	return r
+}
+
+func (hdr *Header) Requires() []Dependency {
+	return hdr.deps(RPMTAG_REQUIRENAME, RPMTAG_REQUIREFLAGS, RPMTAG_REQUIREVERSION)
+}
+
+func (hdr *Header) Provides() []Dependency {
+	return hdr.deps(RPMTAG_PROVIDENAME, RPMTAG_PROVIDEFLAGS, RPMTAG_PROVIDEVERSION)
+}
+
+func (hdr *Header) Conflicts() []Dependency {
+	return hdr.deps(RPMTAG_CONFLICTNAME, RPMTAG_CONFLICTFLAGS, RPMTAG_CONFLICTVERSION)
+}
+
+func (hdr *Header) Obsoletes() []Dependency {
+	return hdr.deps(RPMTAG_OBSOLETENAME, RPMTAG_OBSOLETEFLAGS, RPMTAG_OBSOLETEVERSION)
+}
+
+func (hdr *Header) Recommends() []Dependency {
+	return hdr.deps(RPMTAG_RECOMMENDNAME, RPMTAG_RECOMMENDFLAGS, RPMTAG_RECOMMENDVERSION)
+}
+
+func (hdr *Header) Suggests() []Dependency {
+	return hdr.deps(RPMTAG_SUGGESTNAME, RPMTAG_SUGGESTFLAGS, RPMTAG_SUGGESTVERSION)
+}
+
+func (hdr *Header) Supplements() []Dependency {
+	return hdr.deps(RPMTAG_SUPPLEMENTNAME, RPMTAG_SUPPLEMENTFLAGS, RPMTAG_SUPPLEMENTVERSION)
+}
+
+func (hdr *Header) Enhances() []Dependency {
+	return hdr.deps(RPMTAG_ENHANCENAME, RPMTAG_ENHANCEFLAGS, RPMTAG_ENHANCEVERSION)
+}
@@ -0,0 +1,159 @@
+package rpm
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+)
+
+var (
+	digestMagic = [4]byte{'r', 'p', 'm', 'd'}
+
+	errDigestState   = errors.New("rpm: invalid digest state")
+	errDigest        = errors.New("rpm: digest mismatch")
+	errNoDigest      = errors.New("rpm: no digest")
+	errPayloadDigest = errors.New("rpm: payload digest mismatch")
+)
+
+// Digest hashes a stream in chunks with a PGPHASHALGO_* algorithm. Its
+// state can be saved with MarshalBinary and restored with
+// UnmarshalBinary, the stream then continues at Size.
+type Digest struct {
+	algo uint32
+	h    hash.Hash
+	n    int64
+}
+
+// NewDigest returns a digest for algo, one of the PGPHASHALGO_*
+// constants.
+func NewDigest(algo uint32) (*Digest, error) {
+	h, err := NewHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	return &Digest{algo: algo, h: h}, nil
+}
+
+func (d *Digest) Write(b []byte) (int, error) {
+	n, err := d.h.Write(b)
+	d.n += int64(n)
+	return n, err
+}
+
+// Algo returns the PGPHASHALGO_* algorithm.
+func (d *Digest) Algo() uint32 { return d.algo }
+
+// Size returns the number of bytes written.
+func (d *Digest) Size() int64 { return d.n }
+
+// Sum returns the hex digest as stored in the header.
+func (d *Digest) Sum() string {
+	return hex.EncodeToString(d.h.Sum(nil))
+}
+
+// PayloadDigest returns RPMTAG_PAYLOADDIGESTALGO and the first
+// RPMTAG_PAYLOADDIGEST of hdr, the algorithm defaults to SHA256.
+func PayloadDigest(hdr *Header) (uint32, string) {
+	algo := uint32(PGPHASHALGO_SHA256)
+	if t := hdr.Find(RPMTAG_PAYLOADDIGESTALGO); t != nil {
+		if a, ok := t.Int32(); ok && len(a) > 0 {
+			algo = a[0]
+		}
+	}
+	var digest string
+	if t := hdr.Find(RPMTAG_PAYLOADDIGEST); t != nil {
+		if v, ok := t.StringArray(); ok && len(v) > 0 {
+			digest = v[0]
+		}
+	}
+	return algo, digest
+}
+
+// PayloadReader returns a reader of the payload r that checks it
+// against the payload digest of hdr as it's read, at the end it
+// returns an error instead of io.EOF if the digest doesn't match.
+func PayloadReader(hdr *Header, r io.Reader) (io.Reader, error) {
+	algo, digest := PayloadDigest(hdr)
+	if digest == "" {
+		return nil, errNoDigest
+	}
+	d, err := NewDigest(algo)
+	if err != nil {
+		return nil, err
+	}
+	return &digestReader{r: r, d: d, want: digest}, nil
+}
+
+type digestReader struct {
+	r    io.Reader
+	d    *Digest
+	want string
+	err  error
+}
+
+func (r *digestReader) Read(b []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(b)
+	r.d.Write(b[:n])
+	if err == io.EOF && r.d.Sum() != r.want {
+		err = errPayloadDigest
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (d *Digest) MarshalBinary() ([]byte, error) {
+	m, ok := d.h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errDigestAlgo
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b := append([]byte(nil), digestMagic[:]...)
+	b = binary.AppendUvarint(b, uint64(d.algo))
+	b = binary.AppendUvarint(b, uint64(d.n))
+	return append(b, state...), nil
+}
+
+func (d *Digest) UnmarshalBinary(b []byte) error {
+	if len(b) < len(digestMagic) || [4]byte(b) != digestMagic {
+		return errDigestState
+	}
+	b = b[len(digestMagic):]
+
+	var v [2]uint64
+	for i := range v {
+		n := 0
+		if v[i], n = binary.Uvarint(b); n <= 0 {
+			return errDigestState
+		}
+		b = b[n:]
+	}
+	if v[0] > 0xffffffff || v[1] > 1<<62 {
+		return errDigestState
+	}
+
+	r, err := NewDigest(uint32(v[0]))
+	if err != nil {
+		return err
+	}
+	u, ok := r.h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errDigestAlgo
+	}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return errDigestState
+	}
+	r.n = int64(v[1])
+	*d = *r
+	return nil
+}
@@ -0,0 +1,77 @@
+package rpm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDigestResume(t *testing.T) {
+	data := bytes.Repeat([]byte("payload"), 1000)
+	sum := sha256.Sum256(data)
+
+	d, err := NewDigest(PGPHASHALGO_SHA256)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	d.Write(data[:1234])
+	state, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	r := new(Digest)
+	if err := r.UnmarshalBinary(state); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if r.Size() != 1234 || r.Algo() != PGPHASHALGO_SHA256 {
+		t.Fatalf("resume: size %d, algo %d", r.Size(), r.Algo())
+	}
+	r.Write(data[r.Size():])
+	if a, b := r.Sum(), hex.EncodeToString(sum[:]); a != b {
+		t.Fatalf("sum: want %s, have %s", b, a)
+	}
+
+	for _, v := range [][]byte{nil, []byte("rpmd"), state[:len(state)-1], append([]byte("xxxx"), state[4:]...)} {
+		if err := new(Digest).UnmarshalBinary(v); err == nil {
+			t.Errorf("unmarshal %x: no error", v)
+		}
+	}
+	if _, err := NewDigest(PGPHASHALGO_TIGER192); err != errDigestAlgo {
+		t.Errorf("tiger: %v", err)
+	}
+}
+
+func TestPayloadReader(t *testing.T) {
+	data := bytes.Repeat([]byte("payload"), 1000)
+	sum := sha256.Sum256(data)
+	hdr := makeHdr()
+	if _, err := PayloadReader(hdr, bytes.NewReader(data)); err != errNoDigest {
+		t.Fatalf("no digest: %v", err)
+	}
+	hdr.AddStringArray(RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum[:]))
+	hdr.AddInt32(RPMTAG_PAYLOADDIGESTALGO, PGPHASHALGO_SHA256)
+
+	r, err := PayloadReader(hdr, iotest.HalfReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iotest.TestReader(r, data); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := append([]byte(nil), data...)
+	bad[len(bad)-1] ^= 1
+	r, _ = PayloadReader(hdr, bytes.NewReader(bad))
+	b, err := io.ReadAll(r)
+	if !errors.Is(err, errPayloadDigest) || len(b) != len(bad) {
+		t.Fatalf("mismatch: %d bytes, %v", len(b), err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != errPayloadDigest {
+		t.Fatalf("read after mismatch: %v", err)
+	}
+}
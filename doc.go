@@ -0,0 +1,13 @@
+// Package rpm reads and writes rpm packages and headers.
+//
+// # Compatibility
+//
+// The API of this package, reading, writing and inspecting headers and
+// packages with Reader, Header, Tag, Lead, Package, WriteHeaders,
+// FileIndex and the generated constants, keeps working across minor
+// releases.
+//
+// Packages whose API may still change are under experimental/: sign
+// signs and verifies packages and file signatures, repo reads and
+// writes repository metadata and solver resolves dependencies.
+package rpm
@@ -0,0 +1,180 @@
+package rpm_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+var exampleFiles = []struct {
+	name string
+	mode uint16
+	data string
+}{
+	{"/etc/hello.conf", 0100644, "greeting=hello\n"},
+	{"/usr/bin/hello", 0100755, "#!/bin/sh\necho hello\n"},
+}
+
+// buildPackage builds hello-1.0-1.noarch.rpm, see Example_buildPackage.
+func buildPackage() []byte {
+	// the payload is a stripped cpio archive, file data by index in
+	// the file list of the header
+	idx := rpm.NewFileIndex()
+	payload := new(bytes.Buffer)
+	digest, _ := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	zw := gzip.NewWriter(io.MultiWriter(payload, digest))
+	cw := scpio.NewWriter(zw)
+	for i, v := range exampleFiles {
+		idx.Add(&rpm.File{
+			Name:  v.name,
+			User:  "root",
+			Group: "root",
+			Mode:  v.mode,
+			MTime: 1700000000,
+			Size:  uint64(len(v.data)),
+		})
+		cw.WriteHeader(uint32(i))
+		io.WriteString(cw, v.data)
+	}
+	cw.Close()
+	zw.Close()
+
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, "hello")
+	hdr.AddString(rpm.RPMTAG_VERSION, "1.0")
+	hdr.AddString(rpm.RPMTAG_RELEASE, "1")
+	hdr.AddString(rpm.RPMTAG_ARCH, "noarch")
+	hdr.AddString(rpm.RPMTAG_OS, "linux")
+	hdr.AddStringI18N(rpm.RPMTAG_SUMMARY, "Says hello")
+	hdr.AddString(rpm.RPMTAG_LICENSE, "MIT")
+	hdr.AddInt32(rpm.RPMTAG_BUILDTIME, 1700000000)
+	hdr.AddString(rpm.RPMTAG_PAYLOADFORMAT, "cpio")
+	hdr.AddString(rpm.RPMTAG_PAYLOADCOMPRESSOR, "gzip")
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, digest.Sum())
+	idx.Append(hdr)
+
+	// the signature header has the digest of the header and the size
+	// of header and payload
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		log.Fatal(err)
+	}
+	sum := sha256.Sum256(hb.Bytes())
+	sig := rpm.NewSignatureHeader()
+	sig.AddString(rpm.RPMSIGTAG_SHA256, hex.EncodeToString(sum[:]))
+	sig.AddInt32(rpm.RPMSIGTAG_SIZE, uint32(hb.Len()+payload.Len()))
+
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("hello-1.0-1", rpm.LeadBinary), sig, hb); err != nil {
+		log.Fatal(err)
+	}
+	b.Write(payload.Bytes())
+	return b.Bytes()
+}
+
+// Example_buildPackage builds a package from a file list and a payload
+// and checks the payload digest. Signing and verifying packages are in
+// experimental/sign.
+func Example_buildPackage() {
+	r := bytes.NewReader(buildPackage())
+	p, err := rpm.ReadPackage(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pr, err := rpm.PayloadReader(p.Header, r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = io.Copy(io.Discard, pr)
+	fmt.Println(rpm.FileName(p.Header), err)
+	// Output: hello-1.0-1.noarch.rpm <nil>
+}
+
+// ExampleReader_Next reads the headers of a package one by one.
+func ExampleReader_Next() {
+	r := rpm.NewReader(bytes.NewReader(buildPackage()))
+	if _, err := r.Lead(); err != nil {
+		log.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		hdr, err := r.Next()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(hdr.Kind(), len(hdr.Tags), "tags")
+	}
+	// Output:
+	// signature 2 tags
+	// header 25 tags
+}
+
+// ExampleHeader_WriteTo writes a header on its own, as stored in the
+// rpm database, and reads it back.
+func ExampleHeader_WriteTo() {
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, "hello")
+	hdr.AddStringArray(rpm.RPMTAG_PROVIDENAME, "hello", "greeter")
+
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		log.Fatal(err)
+	}
+
+	have, err := rpm.NewReader(b).Next()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, v := range have.Provides() {
+		fmt.Println(v.Name)
+	}
+	// Output:
+	// hello
+	// greeter
+}
+
+// ExampleReadPackage extracts the files of a package, the payload
+// follows the headers.
+func ExampleReadPackage() {
+	r := bytes.NewReader(buildPackage())
+	p, err := rpm.ReadPackage(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fi, err := rpm.FileIndexHeader(p.Header)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files := fi.Files()
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cr := scpio.NewReader(zr)
+	var last int64
+	for {
+		e, err := cr.NextEntry(int(last))
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		f := files[e.Index]
+		fmt.Printf("%s %o: ", f.Name, f.Mode)
+		last = int64(f.Size)
+		io.Copy(os.Stdout, cr.Data(last))
+	}
+	// Output:
+	// /etc/hello.conf 100644: greeting=hello
+	// /usr/bin/hello 100755: #!/bin/sh
+	// echo hello
+}
@@ -0,0 +1,4 @@
+// Package experimental holds the packages whose API may still change
+// in a minor release, the changes are noted in the release. A package
+// moves out of experimental once its API is stable.
+package experimental
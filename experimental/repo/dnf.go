@@ -0,0 +1,193 @@
+package repo
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExpandVars replaces the dnf variables, $basearch or ${releasever},
+// in s. Unknown variables are kept.
+func ExpandVars(s string, vars map[string]string) string {
+	return os.Expand(s, func(k string) string {
+		if v, ok := vars[k]; ok {
+			return v
+		}
+		return "${" + k + "}"
+	})
+}
+
+// RepoFile is a repository in a yum/dnf .repo file. Exactly one of
+// BaseURL, Mirrorlist and Metalink is usually set, they may use dnf
+// variables.
+type RepoFile struct {
+	ID           string
+	Name         string
+	BaseURL      []string
+	Mirrorlist   string
+	Metalink     string
+	Enabled      bool
+	GPGCheck     bool
+	RepoGPGCheck bool
+	GPGKey       []string
+}
+
+func bit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// WriteTo writes the .repo file section of f.
+func (f *RepoFile) WriteTo(w io.Writer) (int64, error) {
+	if f.ID == "" || strings.ContainsAny(f.ID, "[]\n \t/") {
+		return 0, fmt.Errorf("repo: invalid repository id: %q", f.ID)
+	}
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "[%s]\n", f.ID)
+	if f.Name != "" {
+		fmt.Fprintf(b, "name=%s\n", f.Name)
+	}
+	if len(f.BaseURL) > 0 {
+		fmt.Fprintf(b, "baseurl=%s\n", strings.Join(f.BaseURL, "\n        "))
+	}
+	if f.Mirrorlist != "" {
+		fmt.Fprintf(b, "mirrorlist=%s\n", f.Mirrorlist)
+	}
+	if f.Metalink != "" {
+		fmt.Fprintf(b, "metalink=%s\n", f.Metalink)
+	}
+	fmt.Fprintf(b, "enabled=%s\ngpgcheck=%s\nrepo_gpgcheck=%s\n",
+		bit(f.Enabled), bit(f.GPGCheck), bit(f.RepoGPGCheck))
+	if len(f.GPGKey) > 0 {
+		fmt.Fprintf(b, "gpgkey=%s\n", strings.Join(f.GPGKey, "\n       "))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteMirrorlist writes a mirrorlist, the base URLs of the mirrors one
+// per line.
+func WriteMirrorlist(w io.Writer, mirrors []Mirror) error {
+	for _, v := range mirrors {
+		if _, err := fmt.Fprintln(w, v.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mirror is where a copy of the repository is served.
+type Mirror struct {
+	URL        string // base URL, repodata/ is below it
+	Location   string // ISO 3166 country code, optional
+	Preference int    // 1 to 100, higher first, 100 if 0
+}
+
+type metalinkHash struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type metalinkURL struct {
+	Protocol   string `xml:"protocol,attr"`
+	Type       string `xml:"type,attr"`
+	Location   string `xml:"location,attr,omitempty"`
+	Preference int    `xml:"preference,attr"`
+	URL        string `xml:",chardata"`
+}
+
+type metalink struct {
+	XMLName   xml.Name `xml:"metalink"`
+	Version   string   `xml:"version,attr"`
+	XMLNS     string   `xml:"xmlns,attr"`
+	XMLNSMM0  string   `xml:"xmlns:mm0,attr"`
+	Type      string   `xml:"type,attr"`
+	Pubdate   string   `xml:"pubdate,attr"`
+	Generator string   `xml:"generator,attr"`
+	File      struct {
+		Name      string         `xml:"name,attr"`
+		Timestamp int64          `xml:"mm0:timestamp"`
+		Size      int64          `xml:"size"`
+		Hashes    []metalinkHash `xml:"verification>hash"`
+		URLs      []metalinkURL  `xml:"resources>url"`
+	} `xml:"files>file"`
+}
+
+// WriteMetalink writes a metalink of repomd.xml in Dir for dnf, which
+// then checks the repomd.xml it downloads from mirrors against it.
+func (r *Repo) WriteMetalink(w io.Writer, mirrors []Mirror) error {
+	name := filepath.Join(r.Dir, "repodata", "repomd.xml")
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	m := &metalink{
+		Version:   "3.0",
+		XMLNS:     "http://www.metalinker.org/",
+		XMLNSMM0:  "http://fedorahosted.org/mirrormanager",
+		Type:      "dynamic",
+		Pubdate:   time.Now().UTC().Format(time.RFC1123),
+		Generator: "go-rpm",
+	}
+	m.File.Name = "repomd.xml"
+	m.File.Timestamp = fi.ModTime().Unix()
+	m.File.Size = int64(len(b))
+	for _, v := range []struct {
+		typ string
+		h   hash.Hash
+	}{
+		{"md5", md5.New()},
+		{"sha1", sha1.New()},
+		{"sha256", sha256.New()},
+		{"sha512", sha512.New()},
+	} {
+		v.h.Write(b)
+		m.File.Hashes = append(m.File.Hashes, metalinkHash{v.typ, hex.EncodeToString(v.h.Sum(nil))})
+	}
+	for _, v := range mirrors {
+		u, err := url.Parse(v.URL)
+		if err != nil {
+			return err
+		}
+		pref := v.Preference
+		if pref == 0 {
+			pref = 100
+		}
+		m.File.URLs = append(m.File.URLs, metalinkURL{
+			Protocol:   u.Scheme,
+			Type:       u.Scheme,
+			Location:   v.Location,
+			Preference: pref,
+			URL:        strings.TrimSuffix(v.URL, "/") + "/repodata/repomd.xml",
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", " ")
+	if err := e.Encode(m); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{"basearch": "x86_64", "releasever": "9"}
+	got := ExpandVars("https://m/$releasever/os/${basearch}/$contentdir", vars)
+	if want := "https://m/9/os/x86_64/${contentdir}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRepoFile(t *testing.T) {
+	f := &RepoFile{
+		ID:       "foo",
+		Name:     "Foo $releasever",
+		BaseURL:  []string{"https://a/$basearch/", "https://b/$basearch/"},
+		Enabled:  true,
+		GPGCheck: true,
+		GPGKey:   []string{"file:///etc/pki/rpm-gpg/foo"},
+	}
+	b := new(bytes.Buffer)
+	if _, err := f.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	want := `[foo]
+name=Foo $releasever
+baseurl=https://a/$basearch/
+        https://b/$basearch/
+enabled=1
+gpgcheck=1
+repo_gpgcheck=0
+gpgkey=file:///etc/pki/rpm-gpg/foo
+`
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+	if _, err := (&RepoFile{ID: "a b"}).WriteTo(b); err == nil {
+		t.Error("invalid id accepted")
+	}
+}
+
+func TestMetalink(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPackage(t, dir, "foo.rpm")
+	r := New(dir)
+	if err := r.Add("foo.rpm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrors := []Mirror{{URL: "https://a/repo/", Location: "US"}, {URL: "http://b/repo", Preference: 50}}
+	b := new(bytes.Buffer)
+	if err := WriteMirrorlist(b, mirrors); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://a/repo/\nhttp://b/repo\n"; b.String() != want {
+		t.Errorf("mirrorlist %q, want %q", b, want)
+	}
+
+	b.Reset()
+	if err := r.WriteMetalink(b, mirrors); err != nil {
+		t.Fatal(err)
+	}
+	repomd, err := os.ReadFile(filepath.Join(dir, "repodata", "repomd.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(repomd)
+	for _, v := range []string{
+		`<hash type="sha256">` + hex.EncodeToString(sum[:]) + `</hash>`,
+		`<url protocol="https" type="https" location="US" preference="100">https://a/repo/repodata/repomd.xml</url>`,
+		`<url protocol="http" type="http" preference="50">http://b/repo/repodata/repomd.xml</url>`,
+		`<mm0:timestamp>`,
+	} {
+		if !strings.Contains(b.String(), v) {
+			t.Errorf("metalink has no %s:\n%s", v, b)
+		}
+	}
+
+	if err := New(t.TempDir()).WriteMetalink(b, mirrors); err == nil {
+		t.Error("metalink without repomd.xml")
+	}
+}
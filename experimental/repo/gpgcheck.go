@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+var (
+	errChecksum       = errors.New("repo: checksum mismatch")
+	errNoPrimary      = errors.New("repo: no primary metadata")
+	errRepomdUnsigned = errors.New("repo: repomd.xml is not signed")
+)
+
+// Failure is a package that fails a check.
+type Failure struct {
+	Href string
+	Err  error
+}
+
+func (f Failure) Error() string { return f.Href + ": " + f.Err.Error() }
+
+// GPGCheck is the result of CheckGPG.
+type GPGCheck struct {
+	Packages int
+	Repomd   error // the repomd.xml.asc error, repo_gpgcheck=1
+	Failures []Failure
+}
+
+// OK reports if dnf with gpgcheck=1 and repo_gpgcheck=1 would accept
+// the repository and all of its packages.
+func (c *GPGCheck) OK() bool {
+	return c.Repomd == nil && len(c.Failures) == 0
+}
+
+// CheckGPG checks the repository in dir like dnf with gpgcheck=1 and
+// repo_gpgcheck=1 would: repomd.xml has to be signed in repomd.xml.asc,
+// primary.xml has to match its repomd.xml checksum and each package its
+// primary.xml checksum, header signature and digests. The error is for
+// metadata that can't be read, bad packages are in Failures.
+func CheckGPG(dir string, keyring openpgp.KeyRing) (*GPGCheck, error) {
+	name := filepath.Join(dir, "repodata", "repomd.xml")
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	md, err := ReadRepomd(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("repomd.xml: %w", err)
+	}
+	c := &GPGCheck{Repomd: checkRepomd(name, b, keyring)}
+
+	var primary *Data
+	for i, v := range md.Data {
+		if v.Type == "primary" {
+			primary = &md.Data[i]
+		}
+	}
+	if primary == nil {
+		return nil, errNoPrimary
+	}
+	pkgs, err := readPrimaryFile(dir, primary)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", primary.Location.Href, err)
+	}
+
+	c.Packages = len(pkgs)
+	for _, v := range pkgs {
+		if err := checkPackage(dir, v, keyring); err != nil {
+			c.Failures = append(c.Failures, Failure{v.Location.Href, err})
+		}
+	}
+	return c, nil
+}
+
+// checkRepomd verifies the armored, or binary, detached signature of
+// repomd.xml b.
+func checkRepomd(name string, b []byte, keyring openpgp.KeyRing) error {
+	sig, err := os.ReadFile(name + ".asc")
+	if errors.Is(err, os.ErrNotExist) {
+		return errRepomdUnsigned
+	}
+	if err != nil {
+		return err
+	}
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(b), bytes.NewReader(sig), nil)
+	if err != nil {
+		_, err2 := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(b), bytes.NewReader(sig), nil)
+		if err2 == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// readPrimaryFile reads the primary.xml of d after checking it.
+func readPrimaryFile(dir string, d *Data) ([]*Package, error) {
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(d.Location.Href)))
+	if err != nil {
+		return nil, err
+	}
+	h, err := newHash(d.Checksum.Type)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(b)
+	if hex.EncodeToString(h.Sum(nil)) != d.Checksum.Value {
+		return nil, errChecksum
+	}
+	return ReadPrimary(bytes.NewReader(b))
+}
+
+// checkPackage checks the file of p against its record and keyring.
+func checkPackage(dir string, p *Package, keyring openpgp.KeyRing) error {
+	h, err := newHash(p.Checksum.Type)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(p.Location.Href)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := bufio.NewReaderSize(io.TeeReader(f, h), 1<<20)
+	pkg, err := sign.VerifyDigests(buf)
+	if err != nil {
+		return err
+	}
+	if _, err := sign.VerifyHeader(pkg.Signature, pkg.Header, keyring); err != nil {
+		return fmt.Errorf("signature: %w", err)
+	}
+	if _, err := io.Copy(io.Discard, buf); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != p.Checksum.Value {
+		return errChecksum
+	}
+	return nil
+}
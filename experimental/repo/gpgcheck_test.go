@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func TestCheckGPG(t *testing.T) {
+	key, err := openpgp.NewEntity("repo", "", "repo@example.com",
+		&packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "", "other@example.com",
+		&packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTestPackage(t, dir, "a.rpm", key)
+	writeTestPackage(t, dir, "b.rpm", other)
+	writeTestPackage(t, dir, "c.rpm")
+	writeTestPackage(t, dir, "d.rpm", key)
+	r := New(dir)
+	for _, v := range []string{"a.rpm", "b.rpm", "c.rpm", "d.rpm"} {
+		if err := r.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Write(); err != nil {
+		t.Fatal(err)
+	}
+	// changed after the metadata was written
+	writeTestPackage(t, dir, "d.rpm", other)
+
+	keyring := openpgp.EntityList{key}
+	c, err := CheckGPG(dir, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.OK() || c.Packages != 4 || !errors.Is(c.Repomd, errRepomdUnsigned) {
+		t.Errorf("got %+v", c)
+	}
+	var hrefs []string
+	for _, v := range c.Failures {
+		hrefs = append(hrefs, v.Href)
+	}
+	if len(hrefs) != 3 || hrefs[0] != "b.rpm" || hrefs[1] != "c.rpm" || hrefs[2] != "d.rpm" {
+		t.Errorf("failures %v", c.Failures)
+	}
+
+	name := filepath.Join(dir, "repodata", "repomd.xml")
+	md, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asc, err := os.Create(name + ".asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = openpgp.ArmoredDetachSign(asc, key, md, nil)
+	md.Close()
+	asc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = CheckGPG(dir, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Repomd != nil || len(c.Failures) != 3 {
+		t.Errorf("got %+v", c)
+	}
+	if c, err := CheckGPG(dir, openpgp.EntityList{other}); err != nil || c.Repomd == nil {
+		t.Errorf("repomd.xml verified with the wrong key: %v", err)
+	}
+
+	if _, err := CheckGPG(t.TempDir(), keyring); err == nil {
+		t.Error("no error without metadata")
+	}
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LayoutLetter is the Packages/<first letter>/ layout of Fedora and
+// others.
+const LayoutLetter = "Packages/{{first .Name}}/{{.File}}"
+
+// LayoutFlat puts all packages in Packages/.
+const LayoutFlat = "Packages/{{.File}}"
+
+// PlaceMode is how Place puts a package file into the repository.
+type PlaceMode int
+
+const (
+	PlaceCopy PlaceMode = iota
+	PlaceLink           // hard link
+	PlaceMove
+)
+
+var layoutFuncs = template.FuncMap{
+	// first returns the lower case first letter of s
+	"first": func(s string) string {
+		r, _ := utf8.DecodeRuneInString(s)
+		return string(unicode.ToLower(r))
+	},
+	"lower": strings.ToLower,
+}
+
+// Href returns the href of p, a package file named file, in a layout.
+// The layout is a text/template of the Package with File and the
+// functions first and lower, e.g. LayoutLetter.
+func Href(layout string, p *Package, file string) (string, error) {
+	t, err := template.New("layout").Funcs(layoutFuncs).Parse(layout)
+	if err != nil {
+		return "", err
+	}
+	b := new(bytes.Buffer)
+	err = t.Execute(b, struct {
+		*Package
+		File string
+	}{p, file})
+	if err != nil {
+		return "", err
+	}
+	href := path.Clean(b.String())
+	if path.IsAbs(href) || href == ".." || strings.HasPrefix(href, "../") {
+		return "", fmt.Errorf("repo: href outside the repository: %s", href)
+	}
+	return href, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// Time.File is the mtime of the source
+	return os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+}
+
+// place puts src at dst, replacing a different file there.
+func place(src, dst string, mode PlaceMode) error {
+	sfi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dfi, err := os.Stat(dst); err == nil {
+		if os.SameFile(sfi, dfi) {
+			if mode == PlaceMove && filepath.Clean(src) != filepath.Clean(dst) {
+				// a hard link of dst
+				return os.Remove(src)
+			}
+			return nil
+		}
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	switch mode {
+	case PlaceLink:
+		return os.Link(src, dst)
+	case PlaceMove:
+		if os.Rename(src, dst) == nil {
+			return nil
+		}
+		// across file systems
+		if err := copyFile(src, dst); err != nil {
+			return err
+		}
+		return os.Remove(src)
+	}
+	return copyFile(src, dst)
+}
+
+// Place adds the package file name from outside the repository, it's
+// put at the href of r.Layout, LayoutLetter if empty.
+func (r *Repo) Place(name string, mode PlaceMode) (string, error) {
+	p, err := ReadPackage(name, "", r.checksum())
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	layout := r.Layout
+	if layout == "" {
+		layout = LayoutLetter
+	}
+	href, err := Href(layout, p, filepath.Base(name))
+	if err != nil {
+		return "", err
+	}
+	if err := place(name, filepath.Join(r.Dir, filepath.FromSlash(href)), mode); err != nil {
+		return "", err
+	}
+	p.Location.Href = href
+	r.packages = append(r.packages, p)
+	return href, nil
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHref(t *testing.T) {
+	p := &Package{Name: "Kernel", Arch: "x86_64", Version: Version{"0", "6.1", "1"}}
+	for _, v := range []struct {
+		layout, href string
+	}{
+		{LayoutLetter, "Packages/k/kernel.rpm"},
+		{LayoutFlat, "Packages/kernel.rpm"},
+		{"{{.Arch}}/{{lower .Name}}-{{.Version.Ver}}-{{.Version.Rel}}.{{.Arch}}.rpm", "x86_64/kernel-6.1-1.x86_64.rpm"},
+	} {
+		href, err := Href(v.layout, p, "kernel.rpm")
+		if err != nil || href != v.href {
+			t.Errorf("%s: %s, %v", v.layout, href, err)
+		}
+	}
+	if _, err := Href("../{{.File}}", p, "kernel.rpm"); err == nil {
+		t.Fatal("href outside the repository")
+	}
+}
+
+func TestPlace(t *testing.T) {
+	src, dir := t.TempDir(), t.TempDir()
+	writeTestPackage(t, src, "foo.rpm")
+	name := filepath.Join(src, "foo.rpm")
+
+	for _, mode := range []PlaceMode{PlaceCopy, PlaceLink, PlaceMove} {
+		r := New(dir)
+		r.BaseURL = "http://example.com/repo"
+		href, err := r.Place(name, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if href != "Packages/f/foo.rpm" {
+			t.Fatal(href)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "Packages", "f", "foo.rpm")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(name); (err == nil) != (mode != PlaceMove) {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		if err := r.Write(); err != nil {
+			t.Fatal(err)
+		}
+		if p := r.Packages()[0]; p.Location != (Location{r.BaseURL, href}) {
+			t.Fatalf("%+v", p.Location)
+		}
+	}
+}
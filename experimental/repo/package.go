@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/pschou/go-rpm"
+)
+
+// Changelog is an other.xml changelog entry.
+type Changelog struct {
+	Author string `xml:"author,attr"`
+	Date   int64  `xml:"date,attr"`
+	Text   string `xml:",chardata"`
+}
+
+// primaryFile matches the files listed in primary.xml, the rest are
+// only in filelists.xml.
+var primaryFile = regexp.MustCompile(`^(/etc/.*|.*bin/.*|/usr/lib/sendmail)$`)
+
+// ReadPackage returns the record of the package file name at href in
+// the repository, its checksum of type sum.
+func ReadPackage(name, href, sum string) (*Package, error) {
+	h, err := newHash(sum)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bufio.NewReaderSize(io.TeeReader(f, h), 1<<20)
+	p, err := rpm.ReadPackage(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(io.Discard, buf); err != nil {
+		return nil, err
+	}
+
+	r, err := NewPackage(p)
+	if err != nil {
+		return nil, err
+	}
+	r.Checksum = Checksum{Type: sum, Pkgid: "YES", Value: hex.EncodeToString(h.Sum(nil))}
+	r.Time.File = fi.ModTime().Unix()
+	r.Size.Package = fi.Size()
+	r.Location.Href = href
+	return r, nil
+}
+
+func intTag(hdr *rpm.Header, tags ...rpm.TagType) int64 {
+	for _, v := range tags {
+		t := hdr.Find(v)
+		if t == nil {
+			continue
+		}
+		if i, ok := t.Int64(); ok && len(i) > 0 {
+			return int64(i[0])
+		}
+		if i, ok := t.Int32(); ok && len(i) > 0 {
+			return int64(i[0])
+		}
+	}
+	return 0
+}
+
+func stringTag(hdr *rpm.Header, tag rpm.TagType) string {
+	s, _ := hdr.StringData(tag)
+	return s
+}
+
+// depEntries converts dependencies, rpmlib() ones and duplicates are left
+// out.
+func depEntries(deps []rpm.Dependency, pre bool) Entries {
+	var r Entries
+	seen := make(map[Entry]bool)
+	for _, v := range deps {
+		if v.RPMLib() {
+			continue
+		}
+		e := Entry{Name: v.Name}
+		switch v.Flags & (rpm.RPMSENSE_LESS | rpm.RPMSENSE_GREATER | rpm.RPMSENSE_EQUAL) {
+		case rpm.RPMSENSE_LESS:
+			e.Flags = "LT"
+		case rpm.RPMSENSE_GREATER:
+			e.Flags = "GT"
+		case rpm.RPMSENSE_EQUAL:
+			e.Flags = "EQ"
+		case rpm.RPMSENSE_LESS | rpm.RPMSENSE_EQUAL:
+			e.Flags = "LE"
+		case rpm.RPMSENSE_GREATER | rpm.RPMSENSE_EQUAL:
+			e.Flags = "GE"
+		}
+		if v.Version != "" {
+			evr := rpm.ParseEVR(v.Version)
+			e.Epoch = strconv.FormatUint(uint64(evr.Epoch), 10)
+			e.Ver, e.Rel = evr.Version, evr.Release
+		}
+		if pre && v.Flags&(rpm.RPMSENSE_PREREQ|rpm.RPMSENSE_SCRIPT_PRE|rpm.RPMSENSE_SCRIPT_POST) != 0 {
+			e.Pre = "1"
+		}
+		if !seen[e] {
+			seen[e] = true
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// NewPackage returns the record of p without the file related fields,
+// Checksum, Time.File, Size.Package and Location.
+func NewPackage(p *rpm.Package) (*Package, error) {
+	hdr := p.Header
+	fi, err := rpm.FileIndexHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	evr := rpm.HeaderEVR(hdr)
+	r := &Package{
+		Type:        "rpm",
+		Name:        stringTag(hdr, rpm.RPMTAG_NAME),
+		Arch:        stringTag(hdr, rpm.RPMTAG_ARCH),
+		Version:     Version{strconv.FormatUint(uint64(evr.Epoch), 10), evr.Version, evr.Release},
+		Summary:     stringTag(hdr, rpm.RPMTAG_SUMMARY),
+		Description: stringTag(hdr, rpm.RPMTAG_DESCRIPTION),
+		Packager:    stringTag(hdr, rpm.RPMTAG_PACKAGER),
+		URL:         stringTag(hdr, rpm.RPMTAG_URL),
+		Time:        Time{Build: intTag(hdr, rpm.RPMTAG_BUILDTIME)},
+		Size: Size{
+			Installed: intTag(hdr, rpm.RPMTAG_LONGSIZE, rpm.RPMTAG_SIZE),
+		},
+		Format: Format{
+			License:     stringTag(hdr, rpm.RPMTAG_LICENSE),
+			Vendor:      stringTag(hdr, rpm.RPMTAG_VENDOR),
+			Group:       stringTag(hdr, rpm.RPMTAG_GROUP),
+			Buildhost:   stringTag(hdr, rpm.RPMTAG_BUILDHOST),
+			Sourcerpm:   stringTag(hdr, rpm.RPMTAG_SOURCERPM),
+			HeaderRange: HeaderRange{p.Layout().Header.Off, p.Layout().Header.End()},
+			Provides:    depEntries(hdr.Provides(), false),
+			Requires:    depEntries(hdr.Requires(), true),
+			Conflicts:   depEntries(hdr.Conflicts(), false),
+			Obsoletes:   depEntries(hdr.Obsoletes(), false),
+			Recommends:  depEntries(hdr.Recommends(), false),
+			Suggests:    depEntries(hdr.Suggests(), false),
+			Supplements: depEntries(hdr.Supplements(), false),
+			Enhances:    depEntries(hdr.Enhances(), false),
+		},
+	}
+	r.Size.Archive, _ = rpm.ArchiveSize(p.Signature, hdr)
+	if p.Lead != nil && p.Lead.Type == rpm.LeadSource {
+		r.Arch = "src"
+	}
+
+	for _, v := range fi.Files() {
+		f := File{Name: v.Name}
+		switch {
+		case v.Flags&rpm.RPMFILE_GHOST != 0:
+			f.Type = "ghost"
+		case v.Mode&0170000 == 0040000:
+			f.Type = "dir"
+		}
+		r.files = append(r.files, f)
+		if primaryFile.MatchString(f.Name) {
+			r.Format.Files = append(r.Format.Files, f)
+		}
+	}
+
+	times, _ := int32Array(hdr, rpm.RPMTAG_CHANGELOGTIME)
+	names, _ := stringArray(hdr, rpm.RPMTAG_CHANGELOGNAME)
+	texts, _ := stringArray(hdr, rpm.RPMTAG_CHANGELOGTEXT)
+	for i := range times {
+		if i >= len(names) || i >= len(texts) {
+			break
+		}
+		r.changelog = append(r.changelog, Changelog{names[i], int64(times[i]), texts[i]})
+	}
+	return r, nil
+}
+
+func int32Array(hdr *rpm.Header, tag rpm.TagType) ([]uint32, bool) {
+	if t := hdr.Find(tag); t != nil {
+		return t.Int32()
+	}
+	return nil, false
+}
+
+func stringArray(hdr *rpm.Header, tag rpm.TagType) ([]string, bool) {
+	if t := hdr.Find(tag); t != nil {
+		return t.StringArray()
+	}
+	return nil, false
+}
@@ -0,0 +1,198 @@
+// Package repo reads and writes yum repository metadata, the repodata
+// directory of a package repository.
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var errMetadata = errors.New("repo: invalid metadata")
+
+type Checksum struct {
+	Type  string `xml:"type,attr"`
+	Pkgid string `xml:"pkgid,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type Version struct {
+	Epoch string `xml:"epoch,attr"`
+	Ver   string `xml:"ver,attr"`
+	Rel   string `xml:"rel,attr"`
+}
+
+type Time struct {
+	File  int64 `xml:"file,attr"`
+	Build int64 `xml:"build,attr"`
+}
+
+type Size struct {
+	Package   int64 `xml:"package,attr"`
+	Installed int64 `xml:"installed,attr"`
+	Archive   int64 `xml:"archive,attr"`
+}
+
+// Location is where a package is, Href relative to Base or to the
+// repository if Base is empty.
+type Location struct {
+	Base string `xml:"xml:base,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// Entry is a dependency, Flags is one of LT, GT, EQ, LE and GE.
+type Entry struct {
+	Name  string `xml:"name,attr"`
+	Flags string `xml:"flags,attr,omitempty"`
+	Epoch string `xml:"epoch,attr,omitempty"`
+	Ver   string `xml:"ver,attr,omitempty"`
+	Rel   string `xml:"rel,attr,omitempty"`
+	Pre   string `xml:"pre,attr,omitempty"`
+}
+
+// Entries is a dependency list, rpm:provides and the like, left out if
+// empty.
+type Entries []Entry
+
+type entries struct {
+	Entry []Entry `xml:"rpm:entry"`
+}
+
+func (e Entries) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(entries{e}, start)
+}
+
+func (e *Entries) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v entries
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*e = v.Entry
+	return nil
+}
+
+type HeaderRange struct {
+	Start int64 `xml:"start,attr"`
+	End   int64 `xml:"end,attr"`
+}
+
+// File is a packaged file, Type is "dir" or "ghost" for those.
+type File struct {
+	Type string `xml:"type,attr,omitempty"`
+	Name string `xml:",chardata"`
+}
+
+type Format struct {
+	License     string      `xml:"rpm:license"`
+	Vendor      string      `xml:"rpm:vendor"`
+	Group       string      `xml:"rpm:group"`
+	Buildhost   string      `xml:"rpm:buildhost"`
+	Sourcerpm   string      `xml:"rpm:sourcerpm"`
+	HeaderRange HeaderRange `xml:"rpm:header-range"`
+	Provides    Entries     `xml:"rpm:provides,omitempty"`
+	Requires    Entries     `xml:"rpm:requires,omitempty"`
+	Conflicts   Entries     `xml:"rpm:conflicts,omitempty"`
+	Obsoletes   Entries     `xml:"rpm:obsoletes,omitempty"`
+	Recommends  Entries     `xml:"rpm:recommends,omitempty"`
+	Suggests    Entries     `xml:"rpm:suggests,omitempty"`
+	Supplements Entries     `xml:"rpm:supplements,omitempty"`
+	Enhances    Entries     `xml:"rpm:enhances,omitempty"`
+	Files       []File      `xml:"file"`
+}
+
+// Package is the primary.xml record of a package.
+type Package struct {
+	XMLName     xml.Name `xml:"package"`
+	Type        string   `xml:"type,attr"`
+	Name        string   `xml:"name"`
+	Arch        string   `xml:"arch"`
+	Version     Version  `xml:"version"`
+	Checksum    Checksum `xml:"checksum"`
+	Summary     string   `xml:"summary"`
+	Description string   `xml:"description"`
+	Packager    string   `xml:"packager"`
+	URL         string   `xml:"url"`
+	Time        Time     `xml:"time"`
+	Size        Size     `xml:"size"`
+	Location    Location `xml:"location"`
+	Format      Format   `xml:"format"`
+
+	// all files for filelists.xml and the other.xml changelog
+	files     []File
+	changelog []Changelog
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress returns r decompressed if it's gzip, xz or zstd data.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(xzMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, xzMagic):
+		return xz.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return br, nil
+}
+
+// ReadPrimary reads the packages of a primary.xml, compressed or not.
+func ReadPrimary(r io.Reader) ([]*Package, error) {
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	d := newDecoder(xml.NewDecoder(dr))
+	var (
+		pkgs     []*Package
+		metadata bool
+	)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "metadata":
+			metadata = true
+		case "package":
+			p := new(Package)
+			if err := d.DecodeElement(p, &se); err != nil {
+				return nil, err
+			}
+			pkgs = append(pkgs, p)
+		default:
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !metadata {
+		return nil, errMetadata
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPrimary = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://linux.duke.edu/metadata/common" xmlns:rpm="http://linux.duke.edu/metadata/rpm" packages="1">
+<package type="rpm">
+  <name>foo</name>
+  <arch>noarch</arch>
+  <version epoch="0" ver="1.0" rel="1"/>
+  <checksum type="sha256" pkgid="YES">abcd</checksum>
+  <summary>Foo</summary>
+  <description>Foo package</description>
+  <packager></packager>
+  <url>http://example.com</url>
+  <time file="2" build="1"/>
+  <size package="100" installed="200" archive="300"/>
+  <location xml:base="http://example.com/repo" href="Packages/f/foo-1.0-1.noarch.rpm"/>
+  <format>
+    <rpm:license>MIT</rpm:license>
+    <rpm:vendor/>
+    <rpm:group>Unspecified</rpm:group>
+    <rpm:buildhost>localhost</rpm:buildhost>
+    <rpm:sourcerpm>foo-1.0-1.src.rpm</rpm:sourcerpm>
+    <rpm:header-range start="4504" end="5000"/>
+    <rpm:provides>
+      <rpm:entry name="foo" flags="EQ" epoch="0" ver="1.0" rel="1"/>
+    </rpm:provides>
+    <rpm:requires>
+      <rpm:entry name="/bin/sh" pre="1"/>
+    </rpm:requires>
+    <file>/usr/bin/foo</file>
+    <file type="dir">/etc/foo</file>
+  </format>
+</package>
+</metadata>`
+
+func TestReadPrimary(t *testing.T) {
+	gz := new(bytes.Buffer)
+	w := gzip.NewWriter(gz)
+	w.Write([]byte(testPrimary))
+	w.Close()
+
+	for _, r := range []*bytes.Reader{
+		bytes.NewReader([]byte(testPrimary)),
+		bytes.NewReader(gz.Bytes()),
+	} {
+		p, err := ReadPrimary(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p) != 1 {
+			t.Fatalf("%d packages", len(p))
+		}
+		v := p[0]
+		if v.Name != "foo" || v.Version != (Version{"0", "1.0", "1"}) ||
+			v.Checksum != (Checksum{"sha256", "YES", "abcd"}) || v.Size.Package != 100 ||
+			v.Location != (Location{"http://example.com/repo", "Packages/f/foo-1.0-1.noarch.rpm"}) ||
+			v.Format.License != "MIT" || v.Format.HeaderRange.End != 5000 {
+			t.Fatalf("%+v", v)
+		}
+		if !reflect.DeepEqual(v.Format.Provides, Entries{{"foo", "EQ", "0", "1.0", "1", ""}}) ||
+			!reflect.DeepEqual(v.Format.Requires, Entries{{Name: "/bin/sh", Pre: "1"}}) ||
+			!reflect.DeepEqual(v.Format.Files, []File{{"", "/usr/bin/foo"}, {"dir", "/etc/foo"}}) {
+			t.Fatalf("%+v", v.Format)
+		}
+	}
+
+	if _, err := ReadPrimary(strings.NewReader("<foo/>")); err != errMetadata {
+		t.Fatal(err)
+	}
+}
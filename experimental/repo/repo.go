@@ -0,0 +1,310 @@
+package repo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errChecksumType = errors.New("repo: unsupported checksum type")
+
+// checksumTypes are the supported checksum types, sha is sha1 as old
+// yum versions name it.
+var checksumTypes = map[string]func() hash.Hash{
+	"sha":    sha1.New,
+	"sha1":   sha1.New,
+	"sha224": sha256.New224,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+func newHash(typ string) (hash.Hash, error) {
+	h, ok := checksumTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errChecksumType, typ)
+	}
+	return h(), nil
+}
+
+// Data is a metadata file in repomd.xml.
+type Data struct {
+	Type         string    `xml:"type,attr"`
+	Checksum     Checksum  `xml:"checksum"`
+	OpenChecksum *Checksum `xml:"open-checksum,omitempty"`
+	Location     Location  `xml:"location"`
+	Timestamp    int64     `xml:"timestamp"`
+	Size         int64     `xml:"size"`
+	OpenSize     int64     `xml:"open-size,omitempty"`
+}
+
+// Repomd is repomd.xml, the index of the metadata files.
+type Repomd struct {
+	XMLName  xml.Name `xml:"repomd"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	XMLNSRpm string   `xml:"xmlns:rpm,attr"`
+	Revision string   `xml:"revision"`
+	Data     []Data   `xml:"data"`
+}
+
+// Repo generates the metadata of the repository in Dir.
+type Repo struct {
+	Dir string
+
+	// Checksum is the checksum type of packages and metadata files,
+	// sha256 if empty. sha1, or sha, is for old clients only.
+	Checksum string
+
+	// BaseURL is the xml:base of package locations without one, for
+	// packages served from elsewhere than the metadata.
+	BaseURL string
+
+	// Layout is the href template of Place, see Href.
+	Layout string
+
+	packages []*Package
+	extra    []Data // AddMetadata files
+}
+
+func New(dir string) *Repo {
+	return &Repo{Dir: dir}
+}
+
+func (r *Repo) checksum() string {
+	if r.Checksum == "" {
+		return "sha256"
+	}
+	return r.Checksum
+}
+
+// Add adds the package file at href, a slash separated path relative
+// to Dir.
+func (r *Repo) Add(href string) error {
+	p, err := ReadPackage(filepath.Join(r.Dir, filepath.FromSlash(href)), href, r.checksum())
+	if err != nil {
+		return fmt.Errorf("%s: %w", href, err)
+	}
+	r.packages = append(r.packages, p)
+	return nil
+}
+
+// AddPackage adds a record, its checksum should be of type Checksum.
+func (r *Repo) AddPackage(p *Package) {
+	r.packages = append(r.packages, p)
+}
+
+// Packages returns the records added.
+func (r *Repo) Packages() []*Package { return r.packages }
+
+type filelistsPackage struct {
+	XMLName xml.Name `xml:"package"`
+	Pkgid   string   `xml:"pkgid,attr"`
+	Name    string   `xml:"name,attr"`
+	Arch    string   `xml:"arch,attr"`
+	Version Version  `xml:"version"`
+	Files   []File   `xml:"file"`
+}
+
+type otherPackage struct {
+	XMLName   xml.Name    `xml:"package"`
+	Pkgid     string      `xml:"pkgid,attr"`
+	Name      string      `xml:"name,attr"`
+	Arch      string      `xml:"arch,attr"`
+	Version   Version     `xml:"version"`
+	Changelog []Changelog `xml:"changelog"`
+}
+
+// metadata writes the elements of a metadata file in root.
+func metadata(w io.Writer, root string, attr []xml.Attr, v []interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	attr = append(attr, xml.Attr{Name: xml.Name{Local: "packages"}, Value: strconv.Itoa(len(v))})
+	start := xml.StartElement{Name: xml.Name{Local: root}, Attr: attr}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, p := range v {
+		if err := e.Encode(p); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func xmlns(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
+
+// writeData writes a metadata file of type typ to repodata and returns
+// its repomd.xml entry. The file name is name prefixed by the checksum,
+// the open checksum and size are of the uncompressed data.
+func (r *Repo) writeData(typ, name string, b []byte, compress bool) (Data, error) {
+	if err := os.MkdirAll(filepath.Join(r.Dir, "repodata"), 0755); err != nil {
+		return Data{}, err
+	}
+	var d Data
+	if compress {
+		open, err := newHash(r.checksum())
+		if err != nil {
+			return Data{}, err
+		}
+		open.Write(b)
+		d.OpenChecksum = &Checksum{Type: r.checksum(), Value: hex.EncodeToString(open.Sum(nil))}
+		d.OpenSize = int64(len(b))
+
+		gz := new(bytes.Buffer)
+		zw := gzip.NewWriter(gz)
+		zw.Write(b)
+		if err := zw.Close(); err != nil {
+			return Data{}, err
+		}
+		b, name = gz.Bytes(), name+".gz"
+	}
+	h, err := newHash(r.checksum())
+	if err != nil {
+		return Data{}, err
+	}
+	h.Write(b)
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	name = sum + "-" + name
+	if err := os.WriteFile(filepath.Join(r.Dir, "repodata", name), b, 0644); err != nil {
+		return Data{}, err
+	}
+	d.Type = typ
+	d.Checksum = Checksum{Type: r.checksum(), Value: sum}
+	d.Location = Location{Href: path.Join("repodata", name)}
+	d.Timestamp = time.Now().Unix()
+	d.Size = int64(len(b))
+	return d, nil
+}
+
+// AddMetadata adds the metadata file name, modules.yaml, updateinfo.xml
+// or comps.xml for example, to the repository. Its type in repomd.xml
+// is name up to the first dot, group for comps, with _gz appended for
+// compressed comps as dnf expects.
+func (r *Repo) AddMetadata(name string, rd io.Reader, compress bool) error {
+	b, err := io.ReadAll(rd)
+	if err != nil {
+		return err
+	}
+	typ, _, _ := strings.Cut(path.Base(name), ".")
+	if typ == "comps" {
+		typ = "group"
+		if compress {
+			typ = "group_gz"
+		}
+	}
+	for _, v := range r.extra {
+		if v.Type == typ {
+			return fmt.Errorf("repo: duplicate metadata type %s", typ)
+		}
+	}
+	d, err := r.writeData(typ, path.Base(name), b, compress)
+	if err != nil {
+		return err
+	}
+	r.extra = append(r.extra, d)
+	return nil
+}
+
+// Write writes primary, filelists and other metadata and repomd.xml to
+// the repodata directory of Dir.
+func (r *Repo) Write() error {
+	if _, err := newHash(r.checksum()); err != nil {
+		return err
+	}
+	sort.SliceStable(r.packages, func(i, j int) bool {
+		return r.packages[i].Location.Href < r.packages[j].Location.Href
+	})
+
+	var primary, filelists, other []interface{}
+	for _, p := range r.packages {
+		if p.Location.Base == "" {
+			p.Location.Base = r.BaseURL
+		}
+		primary = append(primary, p)
+		filelists = append(filelists, &filelistsPackage{
+			Pkgid: p.Checksum.Value, Name: p.Name, Arch: p.Arch, Version: p.Version, Files: p.files,
+		})
+		other = append(other, &otherPackage{
+			Pkgid: p.Checksum.Value, Name: p.Name, Arch: p.Arch, Version: p.Version, Changelog: p.changelog,
+		})
+	}
+
+	md := &Repomd{XMLNS: nsRepo, XMLNSRpm: nsRpm, Revision: strconv.FormatInt(time.Now().Unix(), 10)}
+	for _, v := range []struct {
+		typ, root string
+		attr      []xml.Attr
+		elem      []interface{}
+	}{
+		{"primary", "metadata", []xml.Attr{xmlns("xmlns", nsCommon), xmlns("xmlns:rpm", nsRpm)}, primary},
+		{"filelists", "filelists", []xml.Attr{xmlns("xmlns", nsFilelists)}, filelists},
+		{"other", "otherdata", []xml.Attr{xmlns("xmlns", nsOther)}, other},
+	} {
+		b := new(bytes.Buffer)
+		if err := metadata(b, v.root, v.attr, v.elem); err != nil {
+			return err
+		}
+		d, err := r.writeData(v.typ, v.typ+".xml", b.Bytes(), true)
+		if err != nil {
+			return err
+		}
+		md.Data = append(md.Data, d)
+	}
+	md.Data = append(md.Data, r.extra...)
+	return r.writeRepomd(md)
+}
+
+// writeRepomd replaces repomd.xml, it's written last so clients never
+// see it before the files it lists.
+func (r *Repo) writeRepomd(md *Repomd) error {
+	b := new(bytes.Buffer)
+	b.WriteString(xml.Header)
+	e := xml.NewEncoder(b)
+	e.Indent("", "  ")
+	if err := e.Encode(md); err != nil {
+		return err
+	}
+	b.WriteString("\n")
+
+	name := filepath.Join(r.Dir, "repodata", "repomd.xml")
+	if err := os.WriteFile(name+".tmp", b.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// ReadRepomd reads a repomd.xml.
+func ReadRepomd(r io.Reader) (*Repomd, error) {
+	md := new(Repomd)
+	if err := newDecoder(xml.NewDecoder(r)).Decode(md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
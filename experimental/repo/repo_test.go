@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+// writeTestPackage writes a package with a file and a dependency to
+// dir/href, signed by keys.
+func writeTestPackage(t *testing.T, dir, href string, keys ...*openpgp.Entity) {
+	hdr := new(rpm.Header)
+	hdr.AddString(rpm.RPMTAG_NAME, "foo")
+	hdr.AddString(rpm.RPMTAG_VERSION, "1.0")
+	hdr.AddString(rpm.RPMTAG_RELEASE, "1")
+	hdr.AddString(rpm.RPMTAG_ARCH, "noarch")
+	hdr.AddString(rpm.RPMTAG_SOURCERPM, "foo-1.0-1.src.rpm")
+	hdr.AddStringArray(rpm.RPMTAG_PROVIDENAME, "foo")
+	hdr.AddInt32(rpm.RPMTAG_PROVIDEFLAGS, rpm.RPMSENSE_EQUAL)
+	hdr.AddStringArray(rpm.RPMTAG_PROVIDEVERSION, "1:1.0-1")
+	hdr.AddStringArray(rpm.RPMTAG_REQUIRENAME, "rpmlib(CompressedFileNames)", "/bin/sh")
+	hdr.AddInt32(rpm.RPMTAG_REQUIREFLAGS, rpm.RPMSENSE_RPMLIB, rpm.RPMSENSE_SCRIPT_PRE)
+	hdr.AddStringArray(rpm.RPMTAG_REQUIREVERSION, "3.0.4-1", "")
+	fi := rpm.NewFileIndex()
+	fi.Add(&rpm.File{Name: "/usr/bin/foo", Mode: 0100755})
+	fi.Add(&rpm.File{Name: "/usr/share/foo", Mode: 040755})
+	fi.Append(hdr)
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+
+	s := sign.NewSigner(nil)
+	s.WriteHeader(hdr)
+	sig, err := s.Signature(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("foo-1.0-1", rpm.LeadBinary), sig, hdr); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, filepath.FromSlash(href))
+	os.MkdirAll(filepath.Dir(name), 0755)
+	if err := os.WriteFile(name, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepo(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPackage(t, dir, "Packages/foo-1.0-1.noarch.rpm")
+
+	for _, sum := range []string{"", "sha512", "sha"} {
+		r := New(dir)
+		r.Checksum = sum
+		if err := r.Add("Packages/foo-1.0-1.noarch.rpm"); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Write(); err != nil {
+			t.Fatal(err)
+		}
+		if sum == "" {
+			sum = "sha256"
+		}
+
+		f, err := os.Open(filepath.Join(dir, "repodata", "repomd.xml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		md, err := ReadRepomd(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(md.Data) != 3 || md.Data[0].Type != "primary" {
+			t.Fatalf("%+v", md)
+		}
+		for _, v := range md.Data {
+			if v.Checksum.Type != sum || v.OpenChecksum.Type != sum {
+				t.Fatalf("%+v", v)
+			}
+		}
+
+		f, err = os.Open(filepath.Join(dir, filepath.FromSlash(md.Data[0].Location.Href)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ReadPrimary(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p) != 1 {
+			t.Fatalf("%d packages", len(p))
+		}
+		v := p[0]
+		if v.Name != "foo" || v.Checksum.Type != sum || v.Location.Href != "Packages/foo-1.0-1.noarch.rpm" ||
+			v.Format.HeaderRange.Start == 0 || v.Size.Package == 0 {
+			t.Fatalf("%+v", v)
+		}
+		if len(v.Format.Requires) != 1 || v.Format.Requires[0] != (Entry{Name: "/bin/sh", Pre: "1"}) ||
+			len(v.Format.Provides) != 1 || v.Format.Provides[0] != (Entry{"foo", "EQ", "1", "1.0", "1", ""}) {
+			t.Fatalf("%+v", v.Format)
+		}
+		if len(v.Format.Files) != 1 || v.Format.Files[0].Name != "/usr/bin/foo" {
+			t.Fatalf("%+v", v.Format.Files)
+		}
+	}
+
+	if err := New(dir).Add("missing.rpm"); err == nil {
+		t.Fatal("missing package added")
+	}
+	r := New(dir)
+	r.Checksum = "md5"
+	if err := r.Write(); err == nil {
+		t.Fatal("md5 accepted")
+	}
+}
+
+func TestAddMetadata(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir)
+	r.Checksum = "sha512"
+	modules := "---\ndocument: modulemd\n"
+	if err := r.AddMetadata("modules.yaml", strings.NewReader(modules), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddMetadata("comps.xml", strings.NewReader("<comps/>"), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddMetadata("modules.yaml", strings.NewReader(modules), false); err == nil {
+		t.Fatal("duplicate type added")
+	}
+	if err := r.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "repodata", "repomd.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := ReadRepomd(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(md.Data) != 5 {
+		t.Fatalf("%+v", md.Data)
+	}
+	m, g := md.Data[3], md.Data[4]
+	if m.Type != "modules" || m.OpenChecksum == nil || m.OpenSize != int64(len(modules)) ||
+		!strings.HasSuffix(m.Location.Href, "-modules.yaml.gz") {
+		t.Fatalf("%+v", m)
+	}
+	if g.Type != "group" || g.OpenChecksum != nil || g.Size != 8 ||
+		!strings.HasSuffix(g.Location.Href, "-comps.xml") {
+		t.Fatalf("%+v", g)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(m.Location.Href)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha512.Sum512(b); m.Checksum.Type != "sha512" || m.Checksum.Value != hex.EncodeToString(sum[:]) {
+		t.Fatalf("%+v", m.Checksum)
+	}
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Delta is what a mirror has to do to match a remote repository.
+type Delta struct {
+	Download []*Package
+	Delete   []string // Location.Href of local packages
+}
+
+// same reports if a and b are the same package file, checksums of
+// different types can't be compared.
+func same(a, b *Package) bool {
+	return a.Checksum.Type == b.Checksum.Type && a.Checksum.Value == b.Checksum.Value &&
+		a.Checksum.Value != ""
+}
+
+// Sync compares the packages of a local mirror, as recorded in its
+// copy of primary.xml, with the remote ones. Nothing is hashed, a
+// package is kept if its location and checksum are unchanged.
+func Sync(local, remote []*Package) Delta {
+	var d Delta
+	l := make(map[string]*Package, len(local))
+	for _, v := range local {
+		l[v.Location.Href] = v
+	}
+	r := make(map[string]bool, len(remote))
+	for _, v := range remote {
+		r[v.Location.Href] = true
+		if p, ok := l[v.Location.Href]; !ok || !same(p, v) {
+			d.Download = append(d.Download, v)
+		}
+	}
+	for _, v := range local {
+		if !r[v.Location.Href] {
+			d.Delete = append(d.Delete, v.Location.Href)
+		}
+	}
+	sort.Strings(d.Delete)
+	return d
+}
+
+// SyncDir is Sync for a mirror in dir. Kept packages are also
+// downloaded if their file is missing or of the wrong size, and .rpm
+// files not in remote are deleted even if local doesn't list them.
+func SyncDir(dir string, local, remote []*Package) (Delta, error) {
+	d := Sync(local, remote)
+	dl := make(map[string]bool, len(d.Download))
+	for _, v := range d.Download {
+		dl[v.Location.Href] = true
+	}
+	r := make(map[string]bool, len(remote))
+	for _, v := range remote {
+		r[v.Location.Href] = true
+		if dl[v.Location.Href] {
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(dir, filepath.FromSlash(v.Location.Href)))
+		if err != nil || fi.Size() != v.Size.Package {
+			dl[v.Location.Href] = true
+		}
+	}
+	d.Download = d.Download[:0]
+	for _, v := range remote {
+		if dl[v.Location.Href] {
+			d.Download = append(d.Download, v)
+		}
+	}
+
+	del := make(map[string]bool, len(d.Delete))
+	for _, v := range d.Delete {
+		del[v] = true
+	}
+	err := filepath.WalkDir(dir, func(name string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() || !strings.HasSuffix(name, ".rpm") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		if href := filepath.ToSlash(rel); !r[href] && !del[href] {
+			d.Delete = append(d.Delete, href)
+		}
+		return nil
+	})
+	if err != nil {
+		return Delta{}, err
+	}
+	sort.Strings(d.Delete)
+	return d, nil
+}
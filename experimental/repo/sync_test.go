@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testPackage(href, sum string, size int64) *Package {
+	return &Package{
+		Checksum: Checksum{Type: "sha256", Value: sum},
+		Size:     Size{Package: size},
+		Location: Location{Href: href},
+	}
+}
+
+func hrefs(p []*Package) []string {
+	var r []string
+	for _, v := range p {
+		r = append(r, v.Location.Href)
+	}
+	return r
+}
+
+func TestSync(t *testing.T) {
+	local := []*Package{
+		testPackage("a.rpm", "1", 1),
+		testPackage("b.rpm", "2", 1),
+		testPackage("c.rpm", "3", 1),
+	}
+	remote := []*Package{
+		testPackage("a.rpm", "1", 1),
+		testPackage("b.rpm", "4", 1),
+		testPackage("d.rpm", "5", 1),
+	}
+	d := Sync(local, remote)
+	if !reflect.DeepEqual(hrefs(d.Download), []string{"b.rpm", "d.rpm"}) ||
+		!reflect.DeepEqual(d.Delete, []string{"c.rpm"}) {
+		t.Fatalf("%v, %v", hrefs(d.Download), d.Delete)
+	}
+
+	// other checksum types aren't comparable
+	remote[0].Checksum.Type = "sha512"
+	if d := Sync(local, remote); len(d.Download) != 3 {
+		t.Fatalf("%v", hrefs(d.Download))
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "Packages"), 0755)
+	for name, size := range map[string]int{
+		"Packages/a.rpm": 1, // up to date
+		"Packages/b.rpm": 2, // truncated
+		"Packages/x.rpm": 1, // orphan
+	} {
+		os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644)
+	}
+
+	local := []*Package{
+		testPackage("Packages/a.rpm", "1", 1),
+		testPackage("Packages/b.rpm", "2", 3),
+		testPackage("Packages/c.rpm", "3", 1),
+	}
+	d, err := SyncDir(dir, local, local[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hrefs(d.Download), []string{"Packages/b.rpm"}) ||
+		!reflect.DeepEqual(d.Delete, []string{"Packages/c.rpm", "Packages/x.rpm"}) {
+		t.Fatalf("%v, %v", hrefs(d.Download), d.Delete)
+	}
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"encoding/xml"
+)
+
+// Repository metadata uses fixed prefixes, rpm:entry and the like, and
+// tools such as libsolv match them literally. encoding/xml can't write
+// prefixes, so the model uses the prefixed names as is and reading
+// goes through rawTokens to match.
+
+const (
+	nsCommon    = "http://linux.duke.edu/metadata/common"
+	nsRpm       = "http://linux.duke.edu/metadata/rpm"
+	nsRepo      = "http://linux.duke.edu/metadata/repo"
+	nsFilelists = "http://linux.duke.edu/metadata/filelists"
+	nsOther     = "http://linux.duke.edu/metadata/other"
+)
+
+// rawTokens returns the tokens of d with prefixed names as local
+// names, rpm:entry rather than entry in the rpm namespace.
+type rawTokens struct {
+	d *xml.Decoder
+}
+
+func literal(n xml.Name) xml.Name {
+	if n.Space == "" {
+		return n
+	}
+	return xml.Name{Local: n.Space + ":" + n.Local}
+}
+
+func (r rawTokens) Token() (xml.Token, error) {
+	t, err := r.d.RawToken()
+	switch v := t.(type) {
+	case xml.StartElement:
+		v = v.Copy()
+		v.Name = literal(v.Name)
+		for i := range v.Attr {
+			v.Attr[i].Name = literal(v.Attr[i].Name)
+		}
+		return v, err
+	case xml.EndElement:
+		v.Name = literal(v.Name)
+		return v, err
+	}
+	return xml.CopyToken(t), err
+}
+
+// newDecoder returns a decoder of prefixed names as written.
+func newDecoder(d *xml.Decoder) *xml.Decoder {
+	return xml.NewTokenDecoder(rawTokens{d})
+}
@@ -0,0 +1,48 @@
+package sign_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/experimental/sign"
+)
+
+// ExampleSigner computes the signature header of a package as its main
+// header and payload are spooled, and verifies the package.
+func ExampleSigner() {
+	payload := []byte("payload")
+	digest, _ := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	digest.Write(payload)
+
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, "hello")
+	hdr.AddString(rpm.RPMTAG_VERSION, "1.0")
+	hdr.AddString(rpm.RPMTAG_RELEASE, "1")
+	hdr.AddString(rpm.RPMTAG_ARCH, "noarch")
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, digest.Sum())
+
+	spool := new(bytes.Buffer)
+	s := sign.NewSigner(spool)
+	if _, err := s.WriteHeader(hdr); err != nil {
+		log.Fatal(err)
+	}
+	s.Write(payload)
+	sig, err := s.Signature()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("hello-1.0-1", rpm.LeadBinary), sig, spool); err != nil {
+		log.Fatal(err)
+	}
+	rep, err := sign.VerifyPackage(b, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(rep.OK(), rep.Protection)
+	// Output: true digests
+}
@@ -0,0 +1,203 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	"github.com/pschou/go-rpm"
+)
+
+// IMA appraisal checks the signature of a file digest kept in its
+// security.ima xattr. RPMSIGTAG_FILESIGNATURES holds the hex encoded
+// xattr value per file, empty for anything but regular files, and
+// RPMSIGTAG_FILESIGNATURELENGTH the length of the longest. The files
+// are signed by their RPMTAG_FILEDIGESTS.
+
+const (
+	imaDigsig = 0x03 // EVM_IMA_XATTR_DIGSIG
+	imaSigV2  = 2
+)
+
+var (
+	errIMA         = errors.New("rpm: invalid IMA signature")
+	errIMAHash     = errors.New("rpm: unsupported IMA hash algorithm")
+	errIMAKey      = errors.New("rpm: unsupported IMA key")
+	errIMASigned   = errors.New("rpm: IMA signature mismatch")
+	errFileDigests = errors.New("rpm: no file digests")
+)
+
+// imaHash maps the kernel hash_algo numbers.
+var imaHash = map[uint8]crypto.Hash{
+	1: crypto.MD5,
+	2: crypto.SHA1,
+	4: crypto.SHA256,
+	5: crypto.SHA384,
+	6: crypto.SHA512,
+	7: crypto.SHA224,
+}
+
+// IMASignature is a v2 IMA signature of a file digest.
+type IMASignature struct {
+	Hash  crypto.Hash
+	KeyID uint32
+	Sig   []byte
+}
+
+// ParseIMASignature decodes a security.ima xattr value.
+func ParseIMASignature(b []byte) (*IMASignature, error) {
+	if len(b) < 9 || b[0] != imaDigsig || b[1] != imaSigV2 {
+		return nil, errIMA
+	}
+	h, ok := imaHash[b[2]]
+	if !ok {
+		return nil, errIMAHash
+	}
+	n := int(binary.BigEndian.Uint16(b[7:]))
+	if len(b) != 9+n {
+		return nil, errIMA
+	}
+	return &IMASignature{
+		Hash:  h,
+		KeyID: binary.BigEndian.Uint32(b[3:]),
+		Sig:   b[9:],
+	}, nil
+}
+
+// Marshal returns the security.ima xattr value of s.
+func (s *IMASignature) Marshal() ([]byte, error) {
+	algo := uint8(0)
+	for k, v := range imaHash {
+		if v == s.Hash {
+			algo = k
+		}
+	}
+	if algo == 0 {
+		return nil, errIMAHash
+	}
+	b := []byte{imaDigsig, imaSigV2, algo, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[3:], s.KeyID)
+	binary.BigEndian.PutUint16(b[7:], uint16(len(s.Sig)))
+	return append(b, s.Sig...), nil
+}
+
+// Verify checks s of the file digest against pub.
+func (s *IMASignature) Verify(pub crypto.PublicKey, digest []byte) error {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, s.Hash, digest, s.Sig) != nil {
+			return errIMASigned
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, s.Sig) {
+			return errIMASigned
+		}
+	default:
+		return errIMAKey
+	}
+	return nil
+}
+
+// IMAKeyID returns the key id of pub, the last 4 bytes of the SHA1 of
+// its DER encoding as ima-evm-utils computes it.
+func IMAKeyID(pub crypto.PublicKey) (uint32, error) {
+	var der []byte
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		der = x509.MarshalPKCS1PublicKey(k)
+	case *ecdsa.PublicKey:
+		e, err := k.ECDH()
+		if err != nil {
+			return 0, err
+		}
+		der = e.Bytes()
+	default:
+		return 0, errIMAKey
+	}
+	s := sha1.Sum(der)
+	return binary.BigEndian.Uint32(s[len(s)-4:]), nil
+}
+
+// SignFiles signs the file digests of hdr with key, an RSA or ECDSA
+// key, and adds the signatures to sig.
+func SignFiles(sig, hdr *rpm.Header, key crypto.Signer) error {
+	t := hdr.Find(rpm.RPMTAG_FILEDIGESTS)
+	if t == nil {
+		return errFileDigests
+	}
+	digests, ok := t.StringArray()
+	if !ok {
+		return tagError{t, errTagType}
+	}
+	algo := uint32(rpm.PGPHASHALGO_MD5)
+	if t := hdr.Find(rpm.RPMTAG_FILEDIGESTALGO); t != nil {
+		if a, ok := t.Int32(); ok && len(a) > 0 {
+			algo = a[0]
+		}
+	}
+	h, ok := rpm.LookupHash(algo)
+	if !ok || h.Crypto == 0 {
+		return errDigestAlgo
+	}
+	id, err := IMAKeyID(key.Public())
+	if err != nil {
+		return err
+	}
+
+	r := make([]string, len(digests))
+	var longest int
+	for i, v := range digests {
+		if v == "" {
+			continue
+		}
+		d, err := hex.DecodeString(v)
+		if err != nil || len(d) != h.Crypto.Size() {
+			return tagError{t, errDigest}
+		}
+		b, err := key.Sign(rand.Reader, d, h.Crypto)
+		if err != nil {
+			return err
+		}
+		x, err := (&IMASignature{Hash: h.Crypto, KeyID: id, Sig: b}).Marshal()
+		if err != nil {
+			return err
+		}
+		r[i] = hex.EncodeToString(x)
+		longest = max(longest, len(x))
+	}
+	sig.AddStringArray(rpm.RPMSIGTAG_FILESIGNATURES, r...)
+	return sig.AddInt32(rpm.RPMSIGTAG_FILESIGNATURELENGTH, uint32(longest))
+}
+
+// FileSignatures returns the decoded IMA signatures of sig, an entry
+// per file, nil for files without one.
+func FileSignatures(sig *rpm.Header) ([]*IMASignature, error) {
+	t := sig.Find(rpm.RPMSIGTAG_FILESIGNATURES)
+	if t == nil {
+		return nil, nil
+	}
+	s, ok := t.StringArray()
+	if !ok {
+		return nil, tagError{t, errTagType}
+	}
+	r := make([]*IMASignature, len(s))
+	for i, v := range s {
+		if v == "" {
+			continue
+		}
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, tagError{t, errIMA}
+		}
+		if r[i], err = ParseIMASignature(b); err != nil {
+			return nil, tagError{t, err}
+		}
+	}
+	return r, nil
+}
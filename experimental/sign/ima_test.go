@@ -0,0 +1,74 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/pschou/go-rpm"
+)
+
+func TestSignFiles(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b"))
+	hdr := new(rpm.Header)
+	fi := rpm.NewFileIndex()
+	fi.SetDigestAlgo(rpm.PGPHASHALGO_SHA256)
+	fi.Add(&rpm.File{Name: "/a", Mode: 0100644, Digest: hex.EncodeToString(a[:])})
+	fi.Add(&rpm.File{Name: "/d", Mode: 040755})
+	fi.Add(&rpm.File{Name: "/b", Mode: 0100644, Digest: hex.EncodeToString(b[:])})
+	fi.Append(hdr)
+
+	for _, key := range []crypto.Signer{rk, ek} {
+		sig := rpm.NewSignatureHeader()
+		if err := SignFiles(sig, hdr, key); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		s, err := FileSignatures(sig)
+		if err != nil {
+			t.Fatalf("signatures: %v", err)
+		}
+		if len(s) != 3 || s[1] != nil {
+			t.Fatalf("%d signatures", len(s))
+		}
+		id, _ := IMAKeyID(key.Public())
+		if s[0].KeyID != id || s[0].Hash != crypto.SHA256 {
+			t.Fatalf("key id %x, hash %v", s[0].KeyID, s[0].Hash)
+		}
+		if err := s[0].Verify(key.Public(), a[:]); err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if err := s[2].Verify(key.Public(), a[:]); err != errIMASigned {
+			t.Fatalf("verified other digest: %v", err)
+		}
+
+		x, _ := s[2].Marshal()
+		if v, _ := sig.Find(rpm.RPMSIGTAG_FILESIGNATURELENGTH).Int32(); len(v) != 1 || int(v[0]) < len(x) {
+			t.Fatalf("signature length %v", v)
+		}
+		if x[0] != imaDigsig || x[1] != imaSigV2 || x[2] != 4 {
+			t.Fatalf("xattr %x", x[:3])
+		}
+	}
+
+	if err := SignFiles(rpm.NewSignatureHeader(), new(rpm.Header), rk); err != errFileDigests {
+		t.Fatalf("no digests: %v", err)
+	}
+	if _, err := ParseIMASignature([]byte{imaDigsig, imaSigV2, 4, 0, 0, 0, 0, 0, 2, 1}); !errors.Is(err, errIMA) {
+		t.Fatalf("short signature parsed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+var errEncryptedKey = errors.New("rpm: secret key is encrypted")
+
+// ReadKeyRing reads an armored or binary OpenPGP keyring, like the key
+// files rpm --import and gpg --export write.
+func ReadKeyRing(r io.Reader) (openpgp.EntityList, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if el, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(b)); err == nil {
+		return el, nil
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(b))
+}
+
+// ReadSigningKey reads a keyring like ReadKeyRing and returns the first
+// entity with a secret key, which must not be encrypted.
+func ReadSigningKey(r io.Reader) (*openpgp.Entity, error) {
+	el, err := ReadKeyRing(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range el {
+		if e.PrivateKey == nil {
+			continue
+		}
+		if e.PrivateKey.Encrypted {
+			return nil, errEncryptedKey
+		}
+		return e, nil
+	}
+	return nil, errSigningKey
+}
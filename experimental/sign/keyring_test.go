@@ -0,0 +1,49 @@
+package sign
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func TestReadKeyRing(t *testing.T) {
+	a, b := newKey(t, "a"), newKey(t, "b")
+
+	pub := new(bytes.Buffer)
+	a.Serialize(pub)
+	b.Serialize(pub)
+	armored := new(bytes.Buffer)
+	w, _ := armor.Encode(armored, openpgp.PublicKeyType, nil)
+	w.Write(pub.Bytes())
+	w.Close()
+	for _, v := range [][]byte{pub.Bytes(), armored.Bytes()} {
+		el, err := ReadKeyRing(bytes.NewReader(v))
+		if err != nil || len(el) != 2 || el[1].PrimaryKey.KeyId != b.PrimaryKey.KeyId {
+			t.Fatalf("keyring: %d keys, %v", len(el), err)
+		}
+	}
+	if _, err := ReadKeyRing(bytes.NewReader([]byte("junk"))); err == nil {
+		t.Fatal("junk keyring read")
+	}
+
+	if _, err := ReadSigningKey(bytes.NewReader(pub.Bytes())); err != errSigningKey {
+		t.Fatalf("public keys: %v", err)
+	}
+	sec := new(bytes.Buffer)
+	b.SerializePrivate(sec, nil)
+	e, err := ReadSigningKey(bytes.NewReader(append(pub.Bytes(), sec.Bytes()...)))
+	if err != nil || e.PrimaryKey.KeyId != b.PrimaryKey.KeyId {
+		t.Fatalf("signing key: %v", err)
+	}
+
+	if err := b.PrivateKey.Encrypt([]byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	sec.Reset()
+	b.SerializePrivateWithoutSigning(sec, nil)
+	if _, err := ReadSigningKey(sec); err != errEncryptedKey {
+		t.Fatalf("encrypted key: %v", err)
+	}
+}
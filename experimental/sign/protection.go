@@ -0,0 +1,75 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/pschou/go-rpm"
+)
+
+// Protection is how well the digests and signatures of a package protect
+// it, each level includes those below. A package below ProtectionDigests
+// can be altered without breaking a strong digest: its header only by
+// stripping the SHA256 digest, leaving MD5 or SHA1, its payload by
+// anyone able to forge the MD5 of header and payload.
+type Protection int
+
+const (
+	ProtectionNone    Protection = iota // no header digest
+	ProtectionWeak                      // only MD5 or SHA1 header digests
+	ProtectionHeader                    // a SHA256 header digest, no payload digest
+	ProtectionDigests                   // SHA256 header and payload digests
+	ProtectionSigned                    // and a header signature
+)
+
+var protectionNames = []string{"none", "weak", "header", "digests", "signed"}
+
+func (p Protection) String() string {
+	if p >= 0 && int(p) < len(protectionNames) {
+		return protectionNames[p]
+	}
+	return fmt.Sprintf("Protection(%d)", int(p))
+}
+
+func (p Protection) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Protection) UnmarshalText(b []byte) error {
+	for i, v := range protectionNames {
+		if v == string(b) {
+			*p = Protection(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("rpm: unknown protection level %q", b)
+}
+
+// protection returns the level of the digests, strong when of the
+// SHA256 or SHA3-256 header digests, weak when of MD5 or SHA1.
+func protection(strong, weak, payload, signed bool) Protection {
+	switch {
+	case strong && payload && signed:
+		return ProtectionSigned
+	case strong && payload:
+		return ProtectionDigests
+	case strong:
+		return ProtectionHeader
+	case weak:
+		return ProtectionWeak
+	}
+	return ProtectionNone
+}
+
+// SignatureProtection returns the protection the signature header sig
+// and the payload digest of hdr claim. It doesn't check the digests or
+// signatures, VerifyPackage does. A payload digest weaker than SHA256
+// doesn't count.
+func SignatureProtection(sig, hdr *rpm.Header) Protection {
+	algo, digest := rpm.PayloadDigest(hdr)
+	sigs, _ := rpm.HeaderSignatures(sig)
+	return protection(
+		sig.Find(rpm.RPMSIGTAG_SHA256) != nil || sig.Find(rpm.RPMSIGTAG_SHA3_256) != nil,
+		sig.Find(rpm.RPMSIGTAG_MD5) != nil || sig.Find(rpm.RPMSIGTAG_SHA1) != nil,
+		digest != "" && hashSize(algo) >= hashSize(rpm.PGPHASHALGO_SHA256),
+		len(sigs) > 0)
+}
@@ -0,0 +1,83 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/pschou/go-rpm"
+)
+
+var errPubkey = errors.New("rpm: not a single public key")
+
+// PubkeyHeader returns the gpg-pubkey header rpm --import stores the
+// armored public key as, gpg-pubkey-<short key id>-<creation time>. It
+// provides gpg(<user id>) and gpg(<short key id>) with the key version
+// as epoch, t is the build and install time.
+func PubkeyHeader(armored []byte, t time.Time) (*rpm.Header, error) {
+	blk, err := armor.Decode(bytes.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	if blk.Type != openpgp.PublicKeyType {
+		return nil, errPubkey
+	}
+	raw, err := io.ReadAll(blk.Body)
+	if err != nil {
+		return nil, err
+	}
+	el, err := openpgp.ReadKeyRing(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if len(el) != 1 || el[0].PrivateKey != nil {
+		return nil, errPubkey
+	}
+	pk := el[0].PrimaryKey
+	userid := "none"
+	if id := el[0].PrimaryIdentity(); id != nil {
+		userid = id.Name
+	}
+
+	d := new(bytes.Buffer)
+	w, err := armor.Encode(d, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	w.Write(raw)
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	keyid := fmt.Sprintf("%016x", pk.KeyId)
+	ver, rel := keyid[8:], fmt.Sprintf("%08x", uint32(pk.CreationTime.Unix()))
+	evr := fmt.Sprintf("%d:%s-%s", pk.Version, keyid, rel)
+	flags := uint32(rpm.RPMSENSE_KEYRING | rpm.RPMSENSE_EQUAL)
+	tid := uint32(t.Unix())
+
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddStringArray(rpm.RPMTAG_PUBKEYS, base64.StdEncoding.EncodeToString(raw))
+	hdr.AddString(rpm.RPMTAG_NAME, "gpg-pubkey")
+	hdr.AddString(rpm.RPMTAG_VERSION, ver)
+	hdr.AddString(rpm.RPMTAG_RELEASE, rel)
+	hdr.AddStringI18N(rpm.RPMTAG_SUMMARY, userid+" public key")
+	hdr.AddStringI18N(rpm.RPMTAG_DESCRIPTION, d.String())
+	hdr.AddStringI18N(rpm.RPMTAG_GROUP, "Public Keys")
+	hdr.AddString(rpm.RPMTAG_LICENSE, "pubkey")
+	hdr.AddString(rpm.RPMTAG_PACKAGER, userid)
+	hdr.AddInt32(rpm.RPMTAG_SIZE, 0)
+	hdr.AddStringArray(rpm.RPMTAG_PROVIDENAME, "gpg("+userid+")", "gpg("+ver+")")
+	hdr.AddStringArray(rpm.RPMTAG_PROVIDEVERSION, evr, evr)
+	hdr.AddInt32(rpm.RPMTAG_PROVIDEFLAGS, flags, flags)
+	hdr.AddString(rpm.RPMTAG_BUILDHOST, "localhost")
+	hdr.AddInt32(rpm.RPMTAG_BUILDTIME, tid)
+	hdr.AddString(rpm.RPMTAG_SOURCERPM, "(none)")
+	hdr.AddInt32(rpm.RPMTAG_INSTALLTIME, tid)
+	hdr.AddInt32(rpm.RPMTAG_INSTALLTID, tid)
+	return hdr, nil
+}
@@ -0,0 +1,72 @@
+package sign
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/pschou/go-rpm"
+)
+
+func TestPubkeyHeader(t *testing.T) {
+	e := newKey(t, "a")
+	b := new(bytes.Buffer)
+	w, err := armor.Encode(b, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Serialize(w)
+	w.Close()
+
+	now := time.Unix(1700000000, 0)
+	hdr, err := PubkeyHeader(b.Bytes(), now)
+	if err != nil {
+		t.Fatalf("pubkey: %v", err)
+	}
+	ver := fmt.Sprintf("%08x", uint32(e.PrimaryKey.KeyId))
+	rel := fmt.Sprintf("%08x", e.PrimaryKey.CreationTime.Unix())
+	for tag, want := range map[rpm.TagType]string{
+		rpm.RPMTAG_NAME:    "gpg-pubkey",
+		rpm.RPMTAG_VERSION: ver,
+		rpm.RPMTAG_RELEASE: rel,
+		rpm.RPMTAG_SUMMARY: "a <a@example.com> public key",
+		rpm.RPMTAG_LICENSE: "pubkey",
+	} {
+		if s, _ := hdr.StringData(tag); s != want {
+			t.Errorf("%v: %q, want %q", tag, s, want)
+		}
+	}
+
+	d, _ := hdr.StringData(rpm.RPMTAG_DESCRIPTION)
+	el, err := openpgp.ReadArmoredKeyRing(strings.NewReader(d))
+	if err != nil || len(el) != 1 || el[0].PrimaryKey.KeyId != e.PrimaryKey.KeyId {
+		t.Errorf("description: %v", err)
+	}
+
+	evr := fmt.Sprintf("4:%016x-%s", e.PrimaryKey.KeyId, rel)
+	want := []rpm.Dependency{
+		{Name: "gpg(a <a@example.com>)", Flags: rpm.RPMSENSE_KEYRING | rpm.RPMSENSE_EQUAL, Version: evr},
+		{Name: "gpg(" + ver + ")", Flags: rpm.RPMSENSE_KEYRING | rpm.RPMSENSE_EQUAL, Version: evr},
+	}
+	if p := hdr.Provides(); fmt.Sprint(p) != fmt.Sprint(want) {
+		t.Errorf("provides: %v, want %v", p, want)
+	}
+	if !hdr.HasRegion() {
+		t.Error("no region")
+	}
+	if _, err := hdr.WriteTo(new(bytes.Buffer)); err != nil {
+		t.Errorf("write: %v", err)
+	}
+
+	b.Reset()
+	w, _ = armor.Encode(b, openpgp.PrivateKeyType, nil)
+	e.SerializePrivate(w, nil)
+	w.Close()
+	if _, err := PubkeyHeader(b.Bytes(), now); err != errPubkey {
+		t.Errorf("private key: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+)
+
+var errInvalidLead = errors.New("rpm: invalid lead")
+
+// VerifyOptions are the options of VerifyPackage.
+type VerifyOptions struct {
+	// Keyring checks the header signature, it's skipped if nil.
+	Keyring openpgp.KeyRing
+
+	// Policy is checked if not nil.
+	Policy *VerifyPolicy
+}
+
+// PackageCheck is one check of a PackageReport. Present is false when
+// the package has nothing to check, Err is nil when the check passed.
+type PackageCheck struct {
+	Present bool
+	Err     error
+}
+
+// PackageReport is the result of VerifyPackage.
+type PackageReport struct {
+	Lead          PackageCheck      // version, signature and package type
+	MD5           PackageCheck      // RPMSIGTAG_MD5 of header and payload
+	SHA1          PackageCheck      // RPMSIGTAG_SHA1 of the header
+	SHA256        PackageCheck      // RPMSIGTAG_SHA256 of the header
+	Size          PackageCheck      // RPMSIGTAG_SIZE or LONGSIZE
+	PayloadDigest PackageCheck      // RPMTAG_PAYLOADDIGEST
+	Verity        PackageCheck      // RPMSIGTAG_VERITYSIGNATURES, see CheckVerity
+	Signature     PackageCheck      // header signature, with a keyring
+	Signer        *openpgp.Entity   // first of Signers
+	Signers       []*openpgp.Entity // of the valid signatures
+	KeyIDs        []uint64          // issuers of the header signatures, once each
+	Policy        PackageCheck      // with a policy
+
+	// Protection is the level of the checks that passed, signed only
+	// if a signature verified with the keyring.
+	Protection Protection
+
+	// HeaderSHA256 is the hex SHA256 of the main header, see Identity.
+	HeaderSHA256 string
+	Layout       rpm.Layout
+
+	// Warnings are the tag anomalies of both headers.
+	Warnings []rpm.Warning
+}
+
+// OK reports whether all checks passed and at least one digest of the
+// header was present.
+func (r *PackageReport) OK() bool {
+	for _, v := range r.checks() {
+		if v.Err != nil {
+			return false
+		}
+	}
+	return r.MD5.Present || r.SHA1.Present || r.SHA256.Present
+}
+
+// Err returns the error of the first failed check, errNoDigest if no
+// digest of the header was present, nil if OK.
+func (r *PackageReport) Err() error {
+	for _, v := range r.checks() {
+		if v.Err != nil {
+			return v.Err
+		}
+	}
+	if !r.OK() {
+		return errNoDigest
+	}
+	return nil
+}
+
+func (r *PackageReport) checks() []PackageCheck {
+	return []PackageCheck{r.Lead, r.MD5, r.SHA1, r.SHA256, r.Size, r.PayloadDigest, r.Verity, r.Signature, r.Policy}
+}
+
+// legacySigTags are signature tags rpm no longer writes or reads.
+var legacySigTags = map[rpm.TagType]string{
+	rpm.RPMSIGTAG_LEMD5_1:   "obsolete",
+	rpm.RPMSIGTAG_LEMD5_2:   "obsolete",
+	rpm.RPMSIGTAG_PGP5:      "obsolete",
+	rpm.RPMSIGTAG_BADSHA1_1: "obsolete",
+	rpm.RPMSIGTAG_BADSHA1_2: "obsolete",
+}
+
+// VerifyPackage reads the package from r to the end of the payload and
+// checks lead, digests, size, payload digest, fs-verity signature list
+// and, with opts.Keyring, the header signature and, with opts.Policy,
+// the policy. Digests and signature are checked against the main header
+// as read. opts may be nil. Errors reading the package are returned,
+// failed checks are in the report.
+func VerifyPackage(r io.Reader, opts *VerifyOptions) (*PackageReport, error) {
+	if opts == nil {
+		opts = new(VerifyOptions)
+	}
+	p, s, err := readPackage(r)
+	if err != nil {
+		return nil, err
+	}
+	sig, hdr := p.Signature, p.Header
+	rep := &PackageReport{Layout: p.Layout()}
+	rep.Lead = PackageCheck{true, checkLead(p.Lead)}
+
+	if _, err := io.Copy(s, r); err != nil {
+		return nil, err
+	}
+	rep.HeaderSHA256 = hex.EncodeToString(s.sha256.Sum(nil))
+
+	for _, v := range []struct {
+		c    *PackageCheck
+		tag  rpm.TagType
+		want []byte
+	}{
+		{&rep.MD5, rpm.RPMSIGTAG_MD5, s.md5.Sum(nil)},
+		{&rep.SHA1, rpm.RPMSIGTAG_SHA1, s.sha1.Sum(nil)},
+		{&rep.SHA256, rpm.RPMSIGTAG_SHA256, s.sha256.Sum(nil)},
+	} {
+		t := sig.Find(v.tag)
+		if t == nil {
+			continue
+		}
+		v.c.Present = true
+		var ok bool
+		if d, isBin := t.Bytes(); isBin {
+			ok = bytes.Equal(d, v.want)
+		} else if d, isStr := sig.StringData(v.tag); isStr {
+			ok = d == hex.EncodeToString(v.want)
+		}
+		if !ok {
+			v.c.Err = fmt.Errorf("%w: %s", errDigest, rpm.SigTagString(v.tag))
+		}
+	}
+	if size, tag, ok := sigSize(sig); ok {
+		rep.Size.Present = true
+		if size != s.size {
+			rep.Size.Err = fmt.Errorf("%w: %s", errDigest, rpm.SigTagString(tag))
+		}
+	}
+	if s.digest != "" {
+		rep.PayloadDigest.Present = true
+		if s.digest != s.PayloadDigest() {
+			rep.PayloadDigest.Err = errPayloadDigest
+		}
+	}
+	if sig.Find(rpm.RPMSIGTAG_VERITYSIGNATURES) != nil {
+		rep.Verity = PackageCheck{true, CheckVerity(sig, hdr)}
+	}
+
+	sigs, err := rpm.HeaderSignatures(sig)
+	if err != nil || len(sigs) > 0 {
+		rep.Signature.Present = true
+	}
+	for _, v := range sigs {
+		if id, ok := rpm.SignatureKeyID(v); ok && !slices.Contains(rep.KeyIDs, id) {
+			rep.KeyIDs = append(rep.KeyIDs, id)
+		}
+	}
+	if opts.Keyring != nil {
+		rep.Signers, rep.Signature.Err = verifyHeaderSigners(sig, s.header.Bytes(), opts.Keyring)
+		if len(rep.Signers) > 0 {
+			rep.Signer = rep.Signers[0]
+		}
+	}
+	algo, _ := rpm.PayloadDigest(hdr)
+	rep.Protection = protection(
+		rep.SHA256.Present && rep.SHA256.Err == nil,
+		rep.MD5.Present && rep.MD5.Err == nil || rep.SHA1.Present && rep.SHA1.Err == nil,
+		rep.PayloadDigest.Present && rep.PayloadDigest.Err == nil && hashSize(algo) >= hashSize(rpm.PGPHASHALGO_SHA256),
+		len(rep.Signers) > 0)
+	if opts.Policy != nil {
+		rep.Policy = PackageCheck{true, opts.Policy.Check(sig, hdr, opts.Keyring)}
+	}
+
+	for _, v := range sig.Tags {
+		if msg, ok := legacySigTags[v.Tag]; ok {
+			rep.Warnings = append(rep.Warnings, rpm.Warning{Tag: v.Tag, Code: "legacy-signature-tag", Msg: msg})
+		} else if !strings.HasPrefix(rpm.SigTagString(v.Tag), "RPMSIGTAG_") {
+			rep.Warnings = append(rep.Warnings, rpm.Warning{Tag: v.Tag, Code: "unknown-signature-tag", Msg: "not a signature tag"})
+		}
+	}
+	switch SignatureProtection(sig, hdr) {
+	case ProtectionWeak:
+		rep.Warnings = append(rep.Warnings, rpm.Warning{Tag: rpm.RPMSIGTAG_SHA256, Code: "weak-digests", Msg: "only MD5 or SHA1 header digests"})
+	case ProtectionHeader:
+		rep.Warnings = append(rep.Warnings, rpm.Warning{Tag: rpm.RPMTAG_PAYLOADDIGEST, Code: "no-payload-digest", Msg: "no SHA256 or stronger payload digest, the payload is only covered by MD5"})
+	}
+	if rpm.IsSource(hdr) != (p.Lead.Type == rpm.LeadSource) {
+		rep.Warnings = append(rep.Warnings, rpm.Warning{Tag: rpm.RPMTAG_SOURCEPACKAGE, Code: "lead-type", Msg: "lead type " + p.Lead.Type.String() + " doesn't match the header"})
+	}
+	rep.Warnings = append(rep.Warnings, rpm.Lint(hdr)...)
+	return rep, nil
+}
+
+// checkLead checks what rpm still checks of the lead.
+func checkLead(l *rpm.Lead) error {
+	const headerSigType = 5
+	switch {
+	case l.Major != 3 && l.Major != 4:
+		return fmt.Errorf("%w: version %d", errInvalidLead, l.Major)
+	case l.SignatureType != headerSigType:
+		return fmt.Errorf("%w: signature type %d", errInvalidLead, l.SignatureType)
+	case l.Type != rpm.LeadBinary && l.Type != rpm.LeadSource:
+		return fmt.Errorf("%w: type %d", errInvalidLead, l.Type)
+	}
+	return nil
+}
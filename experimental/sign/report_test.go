@@ -0,0 +1,189 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+func TestVerifyPackage(t *testing.T) {
+	key := newKey(t, "a")
+	payload := []byte("payload")
+	sum := sha256.Sum256(payload)
+
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum[:]))
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+
+	hb := new(bytes.Buffer)
+	s := NewSigner(hb)
+	s.WriteHeader(hdr)
+	s.Write(payload)
+	sig, err := s.Signature(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig.AddBin(rpm.RPMSIGTAG_LEMD5_1, make([]byte, 16))
+
+	pkg := func(lead *rpm.Lead, payload []byte) *bytes.Reader {
+		b := new(bytes.Buffer)
+		rpm.WriteHeaders(b, lead, sig, bytes.NewReader(hb.Bytes()[:hb.Len()-len(payload)]))
+		b.Write(payload)
+		return bytes.NewReader(b.Bytes())
+	}
+
+	rep, err := VerifyPackage(pkg(rpm.NewLead("test", rpm.LeadBinary), payload), &VerifyOptions{
+		Keyring: openpgp.EntityList{key},
+		Policy:  &VerifyPolicy{RequireSignature: true, MinHash: rpm.PGPHASHALGO_SHA256},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.KeyIDs) != 1 || rep.KeyIDs[0] != key.PrimaryKey.KeyId {
+		t.Errorf("key ids: %x", rep.KeyIDs)
+	}
+	if !rep.OK() || rep.Err() != nil || rep.Signer != key || rep.Protection != ProtectionSigned {
+		t.Fatalf("report: %+v", rep)
+	}
+	for name, c := range map[string]PackageCheck{
+		"md5": rep.MD5, "sha1": rep.SHA1, "sha256": rep.SHA256, "size": rep.Size,
+		"payload digest": rep.PayloadDigest, "signature": rep.Signature, "policy": rep.Policy,
+	} {
+		if !c.Present {
+			t.Errorf("%s not checked", name)
+		}
+	}
+	if id, _ := rpm.Identity(hdr); rep.HeaderSHA256 != id {
+		t.Errorf("header sha256: %s, want %s", rep.HeaderSHA256, id)
+	}
+	if len(rep.Warnings) != 1 || rep.Warnings[0].Code != "legacy-signature-tag" {
+		t.Errorf("warnings: %v", rep.Warnings)
+	}
+
+	lead := rpm.NewLead("test", rpm.LeadSource)
+	lead.SignatureType = 1
+	rep, err = VerifyPackage(pkg(lead, []byte("Payload")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.OK() || rep.Signature.Err != nil || rep.Signer != nil || rep.Protection != ProtectionHeader {
+		t.Fatalf("report: %+v", rep)
+	}
+	for name, v := range map[string]struct {
+		err, want error
+	}{
+		"lead":           {rep.Lead.Err, errInvalidLead},
+		"md5":            {rep.MD5.Err, errDigest},
+		"payload digest": {rep.PayloadDigest.Err, errPayloadDigest},
+		"sha256":         {rep.SHA256.Err, nil},
+	} {
+		if !errors.Is(v.err, v.want) || v.want == nil && v.err != nil {
+			t.Errorf("%s: %v, want %v", name, v.err, v.want)
+		}
+	}
+	if len(rep.Warnings) != 2 || rep.Warnings[1].Code != "lead-type" {
+		t.Errorf("warnings: %v", rep.Warnings)
+	}
+
+	// an unchecked signature doesn't count
+	rep, _ = VerifyPackage(pkg(rpm.NewLead("test", rpm.LeadBinary), payload), nil)
+	if !rep.OK() || rep.Protection != ProtectionDigests {
+		t.Errorf("no keyring: %v", rep.Protection)
+	}
+
+	// a changed payload fails with a valid header signature
+	rep, _ = VerifyPackage(pkg(rpm.NewLead("test", rpm.LeadBinary), []byte("Payload")), &VerifyOptions{Keyring: openpgp.EntityList{key}})
+	if rep.Signer != key || !errors.Is(rep.Err(), errDigest) {
+		t.Errorf("payload changed: %v", rep.Err())
+	}
+
+	rep, _ = VerifyPackage(pkg(rpm.NewLead("test", rpm.LeadBinary), payload), &VerifyOptions{Keyring: openpgp.EntityList{newKey(t, "b")}})
+	if rep.OK() || rep.Signature.Err == nil {
+		t.Fatalf("other key: %+v", rep.Signature)
+	}
+
+	// the fs-verity signatures don't match the files
+	if err := SignVerity(sig, [][]byte{NewVerityHash().Sum()}, testVeritySigner{}); err != nil {
+		t.Fatal(err)
+	}
+	rep, _ = VerifyPackage(pkg(rpm.NewLead("test", rpm.LeadBinary), payload), nil)
+	if !rep.Verity.Present || rep.Verity.Err == nil || rep.OK() || rep.Err() != rep.Verity.Err {
+		t.Errorf("verity: %+v", rep.Verity)
+	}
+	sig.Delete(rpm.RPMSIGTAG_VERITYSIGNATURES)
+	sig.Delete(rpm.RPMSIGTAG_VERITYSIGNATUREALGO)
+
+	weak := rpm.NewSignatureHeader()
+	weak.AddBin(rpm.RPMSIGTAG_MD5, s.md5.Sum(nil))
+	b := new(bytes.Buffer)
+	rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), weak, bytes.NewReader(hb.Bytes()))
+	rep, err = VerifyPackage(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.OK() || rep.Protection != ProtectionWeak || len(rep.Warnings) != 1 || rep.Warnings[0].Code != "weak-digests" {
+		t.Errorf("md5 only: %v, %v", rep.Protection, rep.Warnings)
+	}
+}
+
+func TestEmptyPackage(t *testing.T) {
+	payload := new(bytes.Buffer)
+	scpio.NewWriter(payload).Close()
+
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, "meta")
+	hdr.AddStringArray(rpm.RPMTAG_REQUIRENAME, "foo")
+	d, _ := rpm.NewDigest(rpm.PGPHASHALGO_SHA256)
+	d.Write(payload.Bytes())
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, d.Sum())
+	rpm.NewFileIndex().Append(hdr)
+	size := hdr.Find(rpm.RPMTAG_SIZE)
+	if size == nil {
+		t.Fatal("size missing")
+	}
+	if n, _ := size.Int32(); len(n) != 1 || n[0] != 0 {
+		t.Fatalf("size: %v", n)
+	}
+
+	spool := new(bytes.Buffer)
+	s := NewSigner(spool)
+	s.WriteHeader(hdr)
+	s.Write(payload.Bytes())
+	s.SetArchiveSize(int64(payload.Len()))
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("meta", rpm.LeadBinary), sig, spool); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := VerifyPackage(bytes.NewReader(b.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.OK() || len(rep.Warnings) > 0 {
+		t.Errorf("report: %+v", rep)
+	}
+	p, err := rpm.ReadPackage(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := rpm.FileIndexHeader(p.Header)
+	if err != nil || len(idx.Files()) != 0 {
+		t.Fatalf("files: %v %v", idx.Files(), err)
+	}
+	re := new(bytes.Buffer)
+	if err := rpm.Repayload(p.Header, t.TempDir(), re); err != nil || !bytes.Equal(re.Bytes(), payload.Bytes()) {
+		t.Errorf("repayload: %q %v", re.Bytes(), err)
+	}
+}
@@ -0,0 +1,516 @@
+// Package sign signs and verifies the headers of rpm packages, checks
+// their digests and signs their files for IMA and fs-verity.
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+	"slices"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pschou/go-rpm"
+)
+
+// Header signatures are detached OpenPGP signatures of the serialized
+// header. rpm 4 stores one binary signature in RPMSIGTAG_RSA or
+// RPMSIGTAG_DSA, rpm 4.19+ stores base64 encoded signatures, one per
+// key, in RPMSIGTAG_OPENPGP. Older rpm versions also check the
+// signature of header and payload in RPMSIGTAG_PGP or RPMSIGTAG_GPG.
+
+// signHash is the digest algorithm of new header signatures.
+const signHash = rpm.PGPHASHALGO_SHA256
+
+var (
+	errNoSignature = errors.New("rpm: no header signature")
+	errSigningKey  = errors.New("rpm: no signing key")
+	errSignature   = errors.New("rpm: invalid header signature")
+	errTagType     = errors.New("rpm: invalid tag type")
+	errDigestAlgo  = errors.New("rpm: unsupported digest algorithm")
+)
+
+type tagError struct {
+	t   *rpm.Tag
+	err error
+}
+
+func (t tagError) Error() string {
+	return t.err.Error() + ", tag: " + t.t.String()
+}
+
+func (t tagError) Is(err error) bool {
+	return t.err == err
+}
+
+// parseSignature parses an OpenPGP signature packet, v3 signatures
+// aren't supported.
+func parseSignature(b []byte) (*packet.Signature, bool) {
+	p, err := packet.Read(bytes.NewReader(b))
+	if err != nil {
+		return nil, false
+	}
+	s, ok := p.(*packet.Signature)
+	return s, ok
+}
+
+// SignHeader signs hdr with key and adds the signature to
+// RPMSIGTAG_OPENPGP. The first v4 signature also goes to the legacy
+// RSA or DSA tag for older rpm versions.
+func SignHeader(sig, hdr *rpm.Header, key *openpgp.Entity) error {
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		return err
+	}
+	return signHeader(sig, hb.Bytes(), key)
+}
+
+// SignHeaderKey is SignHeader with a key of NewSignerKey.
+func SignHeaderKey(sig, hdr *rpm.Header, key *packet.PrivateKey) error {
+	h, err := HeaderHash(hdr)
+	if err != nil {
+		return err
+	}
+	b, err := SignHash(h, key)
+	if err != nil {
+		return err
+	}
+	return AddHeaderSignature(sig, b)
+}
+
+// NewSignerKey returns an OpenPGP key signing with s, a key in an HSM, a
+// Yubikey or a cloud KMS for example: RSA, ECDSA on P-256, P-384 or
+// P-521, or Ed25519 as a v4 EdDSA key like gpg makes. created is the
+// creation time of the OpenPGP key, it's part of the key id.
+func NewSignerKey(s crypto.Signer, created time.Time) (*packet.PrivateKey, error) {
+	k := new(packet.PrivateKey)
+	switch pub := s.Public().(type) {
+	case *rsa.PublicKey:
+		k.PublicKey = *packet.NewRSAPublicKey(created, pub)
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		p, err := publicKeyPacket(pub, created)
+		if err != nil {
+			return nil, err
+		}
+		k.PublicKey = *p
+	default:
+		return nil, errSigningKey
+	}
+	k.PrivateKey = s
+	return k, nil
+}
+
+// HeaderHash returns the hash of hdr that header signatures sign, for
+// SignHash.
+func HeaderHash(hdr *rpm.Header) (hash.Hash, error) {
+	h, err := rpm.NewHash(signHash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := hdr.WriteTo(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SignHash signs h, a hash of HeaderHash's algorithm over the signed
+// data, and returns the binary OpenPGP signature. The digest given to
+// the crypto.Signer of key includes the signature metadata as OpenPGP
+// requires.
+func SignHash(h hash.Hash, key *packet.PrivateKey) ([]byte, error) {
+	if key.PublicKey.Version != 4 {
+		// v6 signatures hash a salt before the data
+		return nil, errSigningKey
+	}
+	info, ok := rpm.LookupHash(signHash)
+	if !ok || info.Crypto == 0 {
+		return nil, errDigestAlgo
+	}
+	if signer, ok := key.PrivateKey.(crypto.Signer); ok && key.PubKeyAlgo == packet.PubKeyAlgoEdDSA {
+		// the openpgp package only signs EdDSA with its own keys
+		return signEdDSA(h, &key.PublicKey, signer)
+	}
+	s := &packet.Signature{
+		Version:           4,
+		SigType:           packet.SigTypeBinary,
+		PubKeyAlgo:        key.PublicKey.PubKeyAlgo,
+		Hash:              info.Crypto,
+		CreationTime:      time.Now(),
+		IssuerKeyId:       &key.PublicKey.KeyId,
+		IssuerFingerprint: key.PublicKey.Fingerprint,
+	}
+	if err := s.Sign(h, key, &packet.Config{}); err != nil {
+		return nil, err
+	}
+	b := new(bytes.Buffer)
+	if err := s.Serialize(b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// signHeader signs the serialized header hb.
+func signHeader(sig *rpm.Header, hb []byte, key *openpgp.Entity) error {
+	h, ok := rpm.LookupHash(signHash)
+	if !ok || h.Crypto == 0 {
+		return errDigestAlgo
+	}
+	b := new(bytes.Buffer)
+	config := &packet.Config{DefaultHash: h.Crypto}
+	if err := openpgp.DetachSign(b, key, bytes.NewReader(hb), config); err != nil {
+		return err
+	}
+	return AddHeaderSignature(sig, b.Bytes())
+}
+
+// AddHeaderSignature adds b, a binary OpenPGP signature of the header
+// made elsewhere, to RPMSIGTAG_OPENPGP and, if it's the first v4 one,
+// to the legacy RSA or DSA tag. A signature already there is skipped.
+func AddHeaderSignature(sig *rpm.Header, b []byte) error {
+	p, err := packet.Read(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	s, ok := p.(*packet.Signature)
+	if !ok {
+		return errSignature
+	}
+
+	legacy := rpm.RPMSIGTAG_DSA
+	switch s.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSASignOnly:
+		legacy = rpm.RPMSIGTAG_RSA
+	}
+	if s.Version == 4 && sig.Find(rpm.RPMSIGTAG_RSA) == nil && sig.Find(rpm.RPMSIGTAG_DSA) == nil {
+		if err := sig.AddBin(legacy, b); err != nil {
+			return err
+		}
+	}
+
+	enc := base64.StdEncoding.EncodeToString(b)
+	t := sig.Find(rpm.RPMSIGTAG_OPENPGP)
+	if t == nil {
+		return sig.AddStringArray(rpm.RPMSIGTAG_OPENPGP, enc)
+	}
+	s2, ok := t.StringArray()
+	if !ok {
+		return tagError{t, errTagType}
+	}
+	if slices.Contains(s2, enc) {
+		return nil
+	}
+	sig.Delete(rpm.RPMSIGTAG_OPENPGP)
+	return sig.AddStringArray(rpm.RPMSIGTAG_OPENPGP, append(s2, enc)...)
+}
+
+// CopySignatures adds the header signatures of src to dst, each once.
+// Signing a package again with SignHeader after copying keeps the
+// signatures it has, of a vendor and a distributor for example. v4
+// signatures go to RPMSIGTAG_OPENPGP, the legacy tags are copied as
+// they are if dst has none.
+func CopySignatures(dst, src *rpm.Header) error {
+	sigs, err := rpm.HeaderSignatures(src)
+	if err != nil {
+		return err
+	}
+	for _, v := range sigs {
+		// v3 signatures are only kept in their legacy tag
+		if _, ok := parseSignature(v); !ok {
+			continue
+		}
+		if err := AddHeaderSignature(dst, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range [][]rpm.TagType{
+		{rpm.RPMSIGTAG_RSA, rpm.RPMSIGTAG_DSA},
+		{rpm.RPMSIGTAG_PGP},
+		{rpm.RPMSIGTAG_GPG},
+	} {
+		if dst.Find(v[0]) != nil || len(v) > 1 && dst.Find(v[1]) != nil {
+			continue
+		}
+		for _, tag := range v {
+			t := src.Find(tag)
+			if t == nil {
+				continue
+			}
+			b, ok := t.Bytes()
+			if !ok {
+				return tagError{t, errTagType}
+			}
+			if err := dst.AddBin(tag, bytes.Clone(b)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// signPayload adds the signature of h, the hash of header and payload,
+// to RPMSIGTAG_PGP for RSA keys or RPMSIGTAG_GPG for others. rpm only
+// reads v4 signatures there, other keys are skipped.
+func signPayload(sig *rpm.Header, h hash.Hash, key *packet.PrivateKey) error {
+	if key.PublicKey.Version != 4 {
+		return nil
+	}
+
+	// h is still written to, sign a copy
+	m, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return errDigestAlgo
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	hc, err := rpm.NewHash(signHash)
+	if err != nil {
+		return err
+	}
+	if err := hc.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return err
+	}
+	b, err := SignHash(hc, key)
+	if err != nil {
+		return err
+	}
+
+	tag := rpm.RPMSIGTAG_GPG
+	switch key.PublicKey.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSASignOnly:
+		tag = rpm.RPMSIGTAG_PGP
+	}
+	if sig.Find(tag) != nil {
+		return nil
+	}
+	return sig.AddBin(tag, b)
+}
+
+// signingKey returns the private signing key of e.
+func signingKey(e *openpgp.Entity) (*packet.PrivateKey, error) {
+	k, ok := e.SigningKey(time.Now())
+	if !ok || k.PrivateKey == nil {
+		return nil, errSigningKey
+	}
+	return k.PrivateKey, nil
+}
+
+// VerifyPayload checks the header and payload signature in sig against
+// keyring, r is the main header followed by the payload.
+func VerifyPayload(sig *rpm.Header, r io.Reader, keyring openpgp.KeyRing) (*openpgp.Entity, error) {
+	var b []byte
+	for _, v := range []rpm.TagType{rpm.RPMSIGTAG_PGP, rpm.RPMSIGTAG_GPG} {
+		if t := sig.Find(v); t != nil {
+			var ok bool
+			if b, ok = t.Bytes(); !ok {
+				return nil, tagError{t, errTagType}
+			}
+			break
+		}
+	}
+	if b == nil {
+		return nil, errNoSignature
+	}
+	return openpgp.CheckDetachedSignature(keyring, r, bytes.NewReader(b), nil)
+}
+
+// VerifyHeader checks the header signatures in sig against keyring and
+// returns the signer of the first valid one.
+func VerifyHeader(sig, hdr *rpm.Header, keyring openpgp.KeyRing) (*openpgp.Entity, error) {
+	e, err := VerifyHeaderSigners(sig, hdr, keyring)
+	if err != nil {
+		return nil, err
+	}
+	return e[0], nil
+}
+
+// VerifyHeaderSigners checks all header signatures in sig against
+// keyring and returns the signers of the valid ones, each once, in
+// signature order. Signatures by keys not in keyring are skipped, it
+// fails only if none is valid.
+func VerifyHeaderSigners(sig, hdr *rpm.Header, keyring openpgp.KeyRing) ([]*openpgp.Entity, error) {
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		return nil, err
+	}
+	return verifyHeaderSigners(sig, hb.Bytes(), keyring)
+}
+
+// verifyHeaderSigners is VerifyHeaderSigners of the main header hb as
+// stored.
+func verifyHeaderSigners(sig *rpm.Header, hb []byte, keyring openpgp.KeyRing) ([]*openpgp.Entity, error) {
+	sigs, err := rpm.HeaderSignatures(sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, errNoSignature
+	}
+
+	var r []*openpgp.Entity
+	for _, v := range sigs {
+		e, err2 := openpgp.CheckDetachedSignature(keyring,
+			bytes.NewReader(hb), bytes.NewReader(v), nil)
+		if err2 != nil {
+			err = err2
+			continue
+		}
+		if !slices.Contains(r, e) {
+			r = append(r, e)
+		}
+	}
+	if len(r) == 0 {
+		return nil, err
+	}
+	return r, nil
+}
+
+// signatureTags hold header, or header and payload, signatures.
+var signatureTags = map[rpm.TagType]bool{
+	rpm.RPMSIGTAG_PGP:     true,
+	rpm.RPMSIGTAG_GPG:     true,
+	rpm.RPMSIGTAG_PGP5:    true,
+	rpm.RPMSIGTAG_RSA:     true,
+	rpm.RPMSIGTAG_DSA:     true,
+	rpm.RPMSIGTAG_OPENPGP: true,
+}
+
+// DeleteSignatures removes the OpenPGP signatures from sig, like
+// rpmsign --delsign, the digests are kept. It reports if there were
+// any.
+func DeleteSignatures(sig *rpm.Header) bool {
+	var r bool
+	for v := range signatureTags {
+		if sig.Delete(v) {
+			r = true
+		}
+	}
+	return r
+}
+
+// OIDs of the curves of NewSignerKey, RFC 6637 and RFC 9580.
+var (
+	oidP256    = []byte{0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+	oidP384    = []byte{0x2b, 0x81, 0x04, 0x00, 0x22}
+	oidP521    = []byte{0x2b, 0x81, 0x04, 0x00, 0x23}
+	oidEd25519 = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xda, 0x47, 0x0f, 0x01}
+)
+
+// mpi appends b as an OpenPGP multiprecision integer.
+func mpi(r, b []byte) []byte {
+	b = bytes.TrimLeft(b, "\x00")
+	n := len(b) * 8
+	if len(b) > 0 {
+		n -= bits.LeadingZeros8(b[0])
+	}
+	r = binary.BigEndian.AppendUint16(r, uint16(n))
+	return append(r, b...)
+}
+
+// appendPacket appends the packet tag with body in the new format.
+func appendPacket(r []byte, tag byte, body []byte) []byte {
+	r = append(r, 0xc0|tag)
+	switch n := len(body); {
+	case n < 192:
+		r = append(r, byte(n))
+	case n < 8384:
+		n -= 192
+		r = append(r, byte(n>>8)+192, byte(n))
+	default:
+		r = append(r, 0xff)
+		r = binary.BigEndian.AppendUint32(r, uint32(n))
+	}
+	return append(r, body...)
+}
+
+// publicKeyPacket returns the v4 public key packet of an ECDSA or
+// Ed25519 key, the openpgp package only makes them of its own keys.
+func publicKeyPacket(pub crypto.PublicKey, created time.Time) (*packet.PublicKey, error) {
+	var (
+		algo       packet.PublicKeyAlgorithm
+		oid, point []byte
+	)
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			oid = oidP256
+		case elliptic.P384():
+			oid = oidP384
+		case elliptic.P521():
+			oid = oidP521
+		default:
+			return nil, errSigningKey
+		}
+		k, err := pub.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		algo, point = packet.PubKeyAlgoECDSA, k.Bytes()
+	case ed25519.PublicKey:
+		// native point format, prefixed with 0x40
+		algo, oid, point = packet.PubKeyAlgoEdDSA, oidEd25519, append([]byte{0x40}, pub...)
+	default:
+		return nil, errSigningKey
+	}
+
+	body := []byte{4}
+	body = binary.BigEndian.AppendUint32(body, uint32(created.Unix()))
+	body = append(body, byte(algo), byte(len(oid)))
+	body = append(body, oid...)
+	body = mpi(body, point)
+	p, err := packet.Read(bytes.NewReader(appendPacket(nil, 6, body)))
+	if err != nil {
+		return nil, err
+	}
+	pk, ok := p.(*packet.PublicKey)
+	if !ok {
+		return nil, errSigningKey
+	}
+	return pk, nil
+}
+
+// signEdDSA signs h, of signHash, with the Ed25519 signer of pub and
+// returns the v4 binary signature packet, laid out like SignHash's.
+func signEdDSA(h hash.Hash, pub *packet.PublicKey, signer crypto.Signer) ([]byte, error) {
+	// creation time and issuer fingerprint hashed, issuer key id not
+	sub := []byte{5, 2}
+	sub = binary.BigEndian.AppendUint32(sub, uint32(time.Now().Unix()))
+	sub = append(sub, byte(2+len(pub.Fingerprint)), 33, byte(pub.Version))
+	sub = append(sub, pub.Fingerprint...)
+	hashed := []byte{4, byte(packet.SigTypeBinary), byte(pub.PubKeyAlgo), signHash}
+	hashed = binary.BigEndian.AppendUint16(hashed, uint16(len(sub)))
+	hashed = append(hashed, sub...)
+
+	h.Write(hashed)
+	h.Write([]byte{4, 0xff})
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(hashed))))
+	digest := h.Sum(nil)
+	b, err := signer.Sign(rand.Reader, digest, crypto.Hash(0))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != ed25519.SignatureSize {
+		return nil, errSignature
+	}
+
+	body := append(hashed, 0, 10, 9, 16)
+	body = binary.BigEndian.AppendUint64(body, pub.KeyId)
+	body = append(body, digest[:2]...)
+	body = mpi(body, b[:32])
+	body = mpi(body, b[32:])
+	return appendPacket(nil, 2, body), nil
+}
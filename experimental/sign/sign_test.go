@@ -0,0 +1,313 @@
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pschou/go-rpm"
+)
+
+func newKey(t *testing.T, name string) *openpgp.Entity {
+	e, err := openpgp.NewEntity(name, "", name+"@example.com",
+		&packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	return e
+}
+
+func makeHdr() *rpm.Header {
+	hdr := new(rpm.Header)
+	hdr.AddString(1, "foo")
+	hdr.AddStringI18N(1, "I18N")
+	hdr.AddStringArray(2, "foo", "bar", "baz")
+	hdr.AddInt16(3, 0x1122, 0x3344, 0x5566)
+	hdr.AddInt32(4, 0x11223344, 0x55667788, 0x99112233)
+	hdr.AddInt64(5, 0x1122334455667788, 0x99, 0xff)
+	hdr.AddBin(6, []byte("foo"))
+	return hdr
+}
+
+func TestSignHeader(t *testing.T) {
+	a, b, c := newKey(t, "a"), newKey(t, "b"), newKey(t, "c")
+
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	sig := rpm.NewSignatureHeader()
+	if _, err := VerifyHeader(sig, hdr, openpgp.EntityList{a}); err != errNoSignature {
+		t.Fatalf("unsigned: %v", err)
+	}
+
+	for _, v := range []*openpgp.Entity{a, b} {
+		if err := SignHeader(sig, hdr, v); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	}
+	if sig.Find(rpm.RPMSIGTAG_DSA) == nil || sig.Find(rpm.RPMSIGTAG_RSA) != nil {
+		t.Fatalf("legacy tag missing")
+	}
+	if s, _ := sig.Find(rpm.RPMSIGTAG_OPENPGP).StringArray(); len(s) != 2 {
+		t.Fatalf("openpgp: %d signatures", len(s))
+	}
+
+	// round trip the signature header
+	pb := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(pb, rpm.NewLead("test", rpm.LeadBinary), sig, hdr); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p, err := rpm.ReadPackage(pb)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, v := range []*openpgp.Entity{a, b} {
+		e, err := VerifyHeader(p.Signature, p.Header, openpgp.EntityList{v})
+		if err != nil || e != v {
+			t.Fatalf("verify: %v", err)
+		}
+	}
+	if _, err := VerifyHeader(p.Signature, p.Header, openpgp.EntityList{c}); err == nil {
+		t.Fatalf("verified with unknown key")
+	}
+
+	hdr.AddString(rpm.RPMTAG_URL, "changed")
+	if _, err := VerifyHeader(sig, hdr, openpgp.EntityList{a}); err == nil {
+		t.Fatalf("verified changed header")
+	}
+}
+
+// hsm is a crypto.Signer that only hands out digests to sign.
+type hsm struct {
+	key     *rsa.PrivateKey
+	digests int
+}
+
+func (h *hsm) Public() crypto.PublicKey { return &h.key.PublicKey }
+
+func (h *hsm) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	h.digests++
+	return rsa.SignPKCS1v15(rand, h.key, opts.HashFunc(), digest)
+}
+
+func TestSignHeaderKey(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &hsm{key: rk}
+	key, err := NewSignerKey(s, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	sig := rpm.NewSignatureHeader()
+	if err := SignHeaderKey(sig, hdr, key); err != nil {
+		t.Fatal(err)
+	}
+	if s.digests != 1 || sig.Find(rpm.RPMSIGTAG_RSA) == nil || sig.Find(rpm.RPMSIGTAG_OPENPGP) == nil {
+		t.Fatalf("%d digests signed", s.digests)
+	}
+
+	b, _ := sig.Find(rpm.RPMSIGTAG_RSA).Bytes()
+	p, err := packet.Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := p.(*packet.Signature)
+	h, _ := HeaderHash(hdr)
+	if err := key.PublicKey.VerifySignature(h, ps); err != nil || *ps.IssuerKeyId != key.KeyId {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// header and payload through the Signer
+	sr := NewSigner(nil)
+	sr.WriteHeader(hdr)
+	sr.Write([]byte("payload"))
+	sig, err = sr.SignatureKeys(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.digests != 3 || sig.Find(rpm.RPMSIGTAG_PGP) == nil {
+		t.Fatalf("%d digests signed", s.digests)
+	}
+}
+
+// opaque hides the type of a crypto.Signer, like an HSM key.
+type opaque struct{ crypto.Signer }
+
+func TestSignHeaderKeyCurves(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, ed, _ := ed25519.GenerateKey(rand.Reader)
+	for name, v := range map[string]struct {
+		s    crypto.Signer
+		algo packet.PublicKeyAlgorithm
+	}{
+		"p256":    {p256, packet.PubKeyAlgoECDSA},
+		"p384":    {p384, packet.PubKeyAlgoECDSA},
+		"ed25519": {ed, packet.PubKeyAlgoEdDSA},
+	} {
+		key, err := NewSignerKey(opaque{v.s}, time.Unix(1700000000, 0))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if key.PubKeyAlgo != v.algo {
+			t.Fatalf("%s: algorithm %v", name, key.PubKeyAlgo)
+		}
+		for i := 0; i < 8; i++ {
+			hdr := makeHdr()
+			hdr.AddString(rpm.RPMTAG_RELEASE, strconv.Itoa(i))
+			sig := rpm.NewSignatureHeader()
+			if err := SignHeaderKey(sig, hdr, key); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			b, ok := sig.Find(rpm.RPMSIGTAG_DSA).Bytes()
+			if !ok || sig.Find(rpm.RPMSIGTAG_OPENPGP) == nil {
+				t.Fatalf("%s: signature tags", name)
+			}
+			p, err := packet.Read(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			ps := p.(*packet.Signature)
+			h, _ := HeaderHash(hdr)
+			if err := key.PublicKey.VerifySignature(h, ps); err != nil || *ps.IssuerKeyId != key.KeyId {
+				t.Fatalf("%s: verify: %v", name, err)
+			}
+		}
+	}
+
+	p224, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if _, err := NewSignerKey(p224, time.Now()); !errors.Is(err, errSigningKey) {
+		t.Errorf("p224: %v", err)
+	}
+}
+
+func TestDeleteSignatures(t *testing.T) {
+	payload := []byte("payload")
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	key := newKey(t, "a")
+
+	spool := new(bytes.Buffer)
+	s := NewSigner(spool)
+	s.WriteHeader(hdr)
+	s.Write(payload)
+	sig, err := s.Signature(key)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), sig, spool); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p, err := rpm.ReadPackage(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if !DeleteSignatures(p.Signature) {
+		t.Fatalf("no signatures deleted")
+	}
+	if DeleteSignatures(p.Signature) {
+		t.Fatalf("signatures deleted twice")
+	}
+	for _, v := range []rpm.TagType{rpm.RPMSIGTAG_GPG, rpm.RPMSIGTAG_DSA, rpm.RPMSIGTAG_OPENPGP} {
+		if p.Signature.Find(v) != nil {
+			t.Fatalf("%s not deleted", rpm.SigTagString(v))
+		}
+	}
+
+	// the rewritten package still has its digests
+	rb := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(rb, p.Lead, p.Signature, p.Header); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	rb.Write(payload)
+	p, err = rpm.ReadPackage(bytes.NewReader(rb.Bytes()))
+	if err != nil {
+		t.Fatalf("reread: %v", err)
+	}
+	if _, err := VerifyHeader(p.Signature, p.Header, openpgp.EntityList{key}); err != errNoSignature {
+		t.Fatalf("verify: %v", err)
+	}
+	if _, err := VerifyDigests(rb); err != nil {
+		t.Fatalf("digests: %v", err)
+	}
+}
+
+func TestCopySignatures(t *testing.T) {
+	vendor, distributor, other := newKey(t, "vendor"), newKey(t, "distributor"), newKey(t, "other")
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+
+	s := NewSigner(nil)
+	s.WriteHeader(hdr)
+	s.Write([]byte("payload"))
+	old, err := s.Signature(vendor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sign again, keeping the vendor signature
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CopySignatures(sig, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopySignatures(sig, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignHeader(sig, hdr, distributor); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := sig.Find(rpm.RPMSIGTAG_OPENPGP).StringArray(); len(s) != 2 {
+		t.Fatalf("openpgp: %d signatures", len(s))
+	}
+	for _, v := range []rpm.TagType{rpm.RPMSIGTAG_DSA, rpm.RPMSIGTAG_GPG} {
+		t1, t2 := sig.Find(v), old.Find(v)
+		if t1 == nil {
+			t.Errorf("%s not copied", rpm.SigTagString(v))
+			continue
+		}
+		b1, _ := t1.Bytes()
+		b2, _ := t2.Bytes()
+		if !bytes.Equal(b1, b2) {
+			t.Errorf("%s not copied", rpm.SigTagString(v))
+		}
+	}
+
+	for _, v := range []struct {
+		keyring openpgp.EntityList
+		want    []*openpgp.Entity
+	}{
+		{openpgp.EntityList{vendor, distributor, other}, []*openpgp.Entity{vendor, distributor}},
+		{openpgp.EntityList{distributor}, []*openpgp.Entity{distributor}},
+		{openpgp.EntityList{other}, nil},
+	} {
+		e, err := VerifyHeaderSigners(sig, hdr, v.keyring)
+		if (err == nil) != (v.want != nil) || len(e) != len(v.want) {
+			t.Fatalf("signers %v: %v", e, err)
+		}
+		for i := range e {
+			if e[i] != v.want[i] {
+				t.Errorf("signer %d: %v", i, e[i].PrimaryKey.KeyId)
+			}
+		}
+	}
+}
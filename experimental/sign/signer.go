@@ -0,0 +1,327 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pschou/go-rpm"
+)
+
+var (
+	errSignerHeader  = errors.New("rpm: signer header not written")
+	errReservedSpace = errors.New("rpm: not enough reserved space")
+	errDigest        = errors.New("rpm: digest mismatch")
+	errNoDigest      = errors.New("rpm: no digest")
+	errPayloadDigest = errors.New("rpm: payload digest mismatch")
+)
+
+// Signer tees the main header and payload of a package, as they are
+// written, through every digest of the signature header: SHA1 and
+// SHA256 of the header, MD5 of header and payload, the payload digest
+// and the OpenPGP signature inputs of the header and of header and
+// payload. Nothing is read twice.
+//
+//	s := NewSigner(spool)
+//	s.WriteHeader(hdr)
+//	io.Copy(s, payload)
+//	sig, err := s.Signature(keys...)
+//	WriteHeaders(w, lead, sig, spool)
+type Signer struct {
+	w       io.Writer
+	header  *bytes.Buffer
+	sha1    hash.Hash
+	sha256  hash.Hash
+	md5     hash.Hash
+	pgp     hash.Hash // header and payload signature input
+	payload *rpm.Digest
+	digest  string // RPMTAG_PAYLOADDIGEST of the header
+	size    int64
+	archive int64
+	reserve int
+}
+
+// NewSigner returns a Signer writing through to w, w may be nil.
+func NewSigner(w io.Writer) *Signer {
+	if w == nil {
+		w = io.Discard
+	}
+	return &Signer{
+		w:      w,
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+		md5:    md5.New(),
+	}
+}
+
+func (s *Signer) pgpHash() (hash.Hash, error) {
+	if s.pgp == nil {
+		h, ok := rpm.LookupHash(signHash)
+		if !ok {
+			return nil, errDigestAlgo
+		}
+		s.pgp = h.New()
+	}
+	return s.pgp, nil
+}
+
+// WriteHeader writes the main header, it comes before the payload.
+func (s *Signer) WriteHeader(hdr *rpm.Header) (int64, error) {
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		return 0, err
+	}
+	return s.writeHeader(hdr, b.Bytes())
+}
+
+// writeHeader writes b, the main header hdr as stored.
+func (s *Signer) writeHeader(hdr *rpm.Header, b []byte) (int64, error) {
+	algo, digest := rpm.PayloadDigest(hdr)
+	d, err := rpm.NewDigest(algo)
+	if err != nil {
+		return 0, err
+	}
+	s.digest = digest
+
+	pgp, err := s.pgpHash()
+	if err != nil {
+		return 0, err
+	}
+
+	s.header = bytes.NewBuffer(b)
+	n, err := io.MultiWriter(s.sha1, s.sha256, s.md5, pgp, s.w).Write(b)
+	s.size += int64(n)
+	s.payload = d
+	return int64(n), err
+}
+
+// ReserveSpace adds RPMSIGTAG_RESERVEDSPACE of n bytes to the
+// signature header, as rpmbuild does, signatures added later with
+// FitReservedSpace then leave the payload offset unchanged.
+func (s *Signer) ReserveSpace(n int) {
+	s.reserve = n
+}
+
+// SetArchiveSize adds the uncompressed payload size n to the signature
+// header, in RPMSIGTAG_PAYLOADSIZE, or RPMSIGTAG_LONGARCHIVESIZE above
+// 4GB. The Signer only sees the compressed payload.
+func (s *Signer) SetArchiveSize(n int64) {
+	s.archive = n
+}
+
+// Write writes payload data.
+func (s *Signer) Write(b []byte) (int, error) {
+	if s.header == nil {
+		return 0, errSignerHeader
+	}
+	n, err := s.w.Write(b)
+	s.md5.Write(b[:n])
+	s.pgp.Write(b[:n])
+	s.payload.Write(b[:n])
+	s.size += int64(n)
+	return n, err
+}
+
+// PayloadDigest returns the hex digest of the payload written so far.
+func (s *Signer) PayloadDigest() string {
+	if s.payload == nil {
+		return ""
+	}
+	return s.payload.Sum()
+}
+
+// Signature returns the signature header of everything written, signed
+// by keys. The first key also signs header and payload, for rpm
+// versions that check RPMSIGTAG_PGP or RPMSIGTAG_GPG. It fails if the
+// payload doesn't match the payload digest of the header.
+func (s *Signer) Signature(keys ...*openpgp.Entity) (*rpm.Header, error) {
+	sig, err := s.signature()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range keys {
+		if err := signHeader(sig, s.header.Bytes(), v); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			continue
+		}
+		k, err := signingKey(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := signPayload(sig, s.pgp, k); err != nil {
+			return nil, err
+		}
+	}
+	return sig, nil
+}
+
+// SignatureKeys is Signature with keys of NewSignerKey.
+func (s *Signer) SignatureKeys(keys ...*packet.PrivateKey) (*rpm.Header, error) {
+	sig, err := s.signature()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range keys {
+		h, err := rpm.NewHash(signHash)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(s.header.Bytes())
+		b, err := SignHash(h, v)
+		if err != nil {
+			return nil, err
+		}
+		if err := AddHeaderSignature(sig, b); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			if err := signPayload(sig, s.pgp, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sig, nil
+}
+
+func (s *Signer) signature() (*rpm.Header, error) {
+	if s.header == nil {
+		return nil, errSignerHeader
+	}
+	if s.digest != "" && s.digest != s.payload.Sum() {
+		return nil, errPayloadDigest
+	}
+
+	sig := rpm.NewSignatureHeader()
+	sig.AddString(rpm.RPMSIGTAG_SHA1, hex.EncodeToString(s.sha1.Sum(nil)))
+	sig.AddString(rpm.RPMSIGTAG_SHA256, hex.EncodeToString(s.sha256.Sum(nil)))
+	sig.AddBin(rpm.RPMSIGTAG_MD5, s.md5.Sum(nil))
+	if s.size > math.MaxUint32 {
+		sig.AddInt64(rpm.RPMSIGTAG_LONGSIZE, uint64(s.size))
+	} else {
+		sig.AddInt32(rpm.RPMSIGTAG_SIZE, uint32(s.size))
+	}
+	if s.archive > math.MaxUint32 {
+		sig.AddInt64(rpm.RPMSIGTAG_LONGARCHIVESIZE, uint64(s.archive))
+	} else if s.archive > 0 {
+		sig.AddInt32(rpm.RPMSIGTAG_PAYLOADSIZE, uint32(s.archive))
+	}
+	if s.reserve > 0 {
+		sig.AddBin(rpm.RPMSIGTAG_RESERVEDSPACE, make([]byte, s.reserve))
+	}
+	return sig, nil
+}
+
+// FitReservedSpace resizes RPMSIGTAG_RESERVEDSPACE so sig is written,
+// with the padding after it, in size bytes: the size of the signature
+// header it replaces. The main header and payload then keep their
+// offsets and the package can be rewritten in place.
+func FitReservedSpace(sig *rpm.Header, size int64) error {
+	t := sig.Find(rpm.RPMSIGTAG_RESERVEDSPACE)
+	if t == nil {
+		return errReservedSpace
+	}
+	n := int(t.Count)
+
+	// last, resizing it doesn't move the data of other tags
+	sig.Delete(rpm.RPMSIGTAG_RESERVEDSPACE)
+	sig.AddBin(rpm.RPMSIGTAG_RESERVEDSPACE, make([]byte, n))
+	cur, err := sig.WriteTo(io.Discard)
+	if err != nil {
+		return err
+	}
+	n += int(size - cur)
+	if n < 1 {
+		return errReservedSpace
+	}
+	sig.Delete(rpm.RPMSIGTAG_RESERVEDSPACE)
+	return sig.AddBin(rpm.RPMSIGTAG_RESERVEDSPACE, make([]byte, n))
+}
+
+// VerifyDigests reads the package from r to the end of the payload and
+// checks the digests and size in its signature header, and the payload
+// digest of its main header, against the main header as read and the
+// payload. At least one digest must be present.
+func VerifyDigests(r io.Reader) (*rpm.Package, error) {
+	p, s, err := readPackage(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(s, r); err != nil {
+		return nil, err
+	}
+	want, err := s.Signature()
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	if s.digest != "" {
+		n++
+	}
+	for _, v := range []rpm.TagType{rpm.RPMSIGTAG_SHA1, rpm.RPMSIGTAG_SHA256} {
+		if d, ok := p.Signature.StringData(v); ok {
+			n++
+			if w, _ := want.StringData(v); d != w {
+				return nil, fmt.Errorf("%w: %s", errDigest, rpm.SigTagString(v))
+			}
+		}
+	}
+	if t := p.Signature.Find(rpm.RPMSIGTAG_MD5); t != nil {
+		n++
+		d, _ := t.Bytes()
+		w, _ := want.Find(rpm.RPMSIGTAG_MD5).Bytes()
+		if !bytes.Equal(d, w) {
+			return nil, fmt.Errorf("%w: %s", errDigest, rpm.SigTagString(rpm.RPMSIGTAG_MD5))
+		}
+	}
+	if size, tag, ok := sigSize(p.Signature); ok && size != s.size {
+		return nil, fmt.Errorf("%w: %s", errDigest, rpm.SigTagString(tag))
+	}
+	if n == 0 {
+		return nil, errNoDigest
+	}
+	return p, nil
+}
+
+// readPackage reads the package from r to the end of the main header,
+// which a new Signer gets as read and not written again.
+func readPackage(r io.Reader) (*rpm.Package, *Signer, error) {
+	raw := new(bytes.Buffer)
+	p, err := rpm.ReadPackage(io.TeeReader(r, raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	l := p.Layout().Header
+	s := NewSigner(nil)
+	if _, err := s.writeHeader(p.Header, raw.Bytes()[l.Off:l.Off+l.Len]); err != nil {
+		return nil, nil, err
+	}
+	return p, s, nil
+}
+
+// sigSize returns the header+payload size from the signature header
+// and the tag it's in.
+func sigSize(sig *rpm.Header) (int64, rpm.TagType, bool) {
+	if t := sig.Find(rpm.RPMSIGTAG_LONGSIZE); t != nil {
+		if v, ok := t.Int64(); ok && len(v) > 0 {
+			return int64(v[0]), rpm.RPMSIGTAG_LONGSIZE, true
+		}
+	}
+	if t := sig.Find(rpm.RPMSIGTAG_SIZE); t != nil {
+		if v, ok := t.Int32(); ok && len(v) > 0 {
+			return int64(v[0]), rpm.RPMSIGTAG_SIZE, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,367 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pschou/go-rpm"
+)
+
+func TestSigner(t *testing.T) {
+	payload := []byte("payload")
+	sum := sha256.Sum256(payload)
+
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum[:]))
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+
+	key := newKey(t, "a")
+	spool := new(bytes.Buffer)
+	s := NewSigner(spool)
+	if _, err := s.Write(payload); err != errSignerHeader {
+		t.Fatalf("payload before header: %v", err)
+	}
+	if _, err := s.WriteHeader(hdr); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	s.Write(payload)
+	sig, err := s.Signature(key)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+
+	md := md5.Sum(spool.Bytes())
+	if id, ok := rpm.PkgID(sig); !ok || id != hex.EncodeToString(md[:]) {
+		t.Fatalf("md5: %s", id)
+	}
+	id, _ := rpm.Identity(hdr)
+	if v, _ := sig.StringData(rpm.RPMSIGTAG_SHA256); v != id {
+		t.Fatalf("sha256: want %s, have %s", id, v)
+	}
+
+	signed := append([]byte(nil), spool.Bytes()...)
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), sig, spool); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p, err := rpm.ReadPackage(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if l := p.Layout(); l.Payload.Len != int64(len(payload)) {
+		t.Fatalf("payload length: %d", l.Payload.Len)
+	}
+	if _, err := VerifyHeader(p.Signature, p.Header, openpgp.EntityList{key}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if p.Signature.Find(rpm.RPMSIGTAG_GPG) == nil || p.Signature.Find(rpm.RPMSIGTAG_PGP) != nil {
+		t.Fatalf("header+payload signature missing")
+	}
+	if _, err := VerifyPayload(p.Signature, bytes.NewReader(signed), openpgp.EntityList{key}); err != nil {
+		t.Fatalf("verify payload: %v", err)
+	}
+	signed[len(signed)-1]++
+	if _, err := VerifyPayload(p.Signature, bytes.NewReader(signed), openpgp.EntityList{key}); err == nil {
+		t.Fatalf("verified modified payload")
+	}
+
+	s = NewSigner(nil)
+	s.WriteHeader(hdr)
+	s.Write([]byte("other"))
+	if _, err := s.Signature(); err != errPayloadDigest {
+		t.Fatalf("payload digest: %v", err)
+	}
+}
+
+// writePackage returns the package of sig, hdr and payload.
+func writePackage(t *testing.T, sig *rpm.Header, hdr io.WriterTo, payload []byte) *bytes.Reader {
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), sig, hdr); err != nil {
+		t.Fatal(err)
+	}
+	b.Write(payload)
+	return bytes.NewReader(b.Bytes())
+}
+
+func TestVerifyDigests(t *testing.T) {
+	payload := []byte("payload")
+	sum := sha256.Sum256(payload)
+
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, hex.EncodeToString(sum[:]))
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+
+	s := NewSigner(nil)
+	s.WriteHeader(hdr)
+	s.Write(payload)
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyDigests(writePackage(t, sig, hdr, payload)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyDigests(writePackage(t, sig, hdr, []byte("Payload"))); !errors.Is(err, errPayloadDigest) {
+		t.Fatalf("payload: %v", err)
+	}
+	nodigest := rpm.NewSignatureHeader()
+	nodigest.AddInt32(rpm.RPMSIGTAG_PAYLOADSIZE, 7)
+	if _, err := VerifyDigests(writePackage(t, nodigest, makeHdr(), payload)); !errors.Is(err, errNoDigest) {
+		t.Fatalf("no digest: %v", err)
+	}
+
+	// without the payload digest the md5 catches it
+	hdr = makeHdr()
+	s = NewSigner(nil)
+	s.WriteHeader(hdr)
+	s.Write(payload)
+	sig, _ = s.Signature()
+	if _, err := VerifyDigests(writePackage(t, sig, hdr, []byte("Payload"))); !errors.Is(err, errDigest) {
+		t.Fatalf("md5: %v", err)
+	}
+
+	// the digests are of the header as read: with its index entries
+	// swapped it's read sorted and written again differently
+	hdr = rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, "foo")
+	hdr.AddString(rpm.RPMTAG_VERSION, "1")
+	hb := new(bytes.Buffer)
+	hdr.WriteTo(hb)
+	b := hb.Bytes()
+	e1, e2 := b[16+16:16+32], b[16+32:16+48]
+	tmp := bytes.Clone(e1)
+	copy(e1, e2)
+	copy(e2, tmp)
+	if hdr, err = rpm.NewReader(bytes.NewReader(b)).Next(); err != nil {
+		t.Fatal(err)
+	}
+	rb := new(bytes.Buffer)
+	if hdr.WriteTo(rb); bytes.Equal(rb.Bytes(), b) {
+		t.Fatal("header written as read")
+	}
+	s = NewSigner(nil)
+	s.writeHeader(hdr, b)
+	s.Write(payload)
+	sig, _ = s.Signature()
+	if _, err := VerifyDigests(writePackage(t, sig, bytes.NewReader(b), payload)); err != nil {
+		t.Fatalf("as read: %v", err)
+	}
+}
+
+func TestArchiveSize(t *testing.T) {
+	hdr := makeHdr()
+	for _, n := range []int64{0, 1234, 5 << 30} {
+		s := NewSigner(nil)
+		s.WriteHeader(hdr)
+		s.SetArchiveSize(n)
+		sig, err := s.Signature()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, ok := rpm.ArchiveSize(sig, hdr); have != n || ok != (n > 0) {
+			t.Errorf("%d: have %d %v", n, have, ok)
+		}
+		if n > 0 && (sig.Find(rpm.RPMSIGTAG_PAYLOADSIZE) == nil) != (n > math.MaxUint32) {
+			t.Errorf("%d: payload size tag", n)
+		}
+	}
+
+	// rpm before 4.12 keeps it in the main header
+	hdr.AddInt32(rpm.RPMTAG_ARCHIVESIZE, 99)
+	if n, ok := rpm.ArchiveSize(rpm.NewSignatureHeader(), hdr); n != 99 || !ok {
+		t.Errorf("main header: %d %v", n, ok)
+	}
+}
+
+func TestLongSize(t *testing.T) {
+	hdr := makeHdr()
+	s := NewSigner(nil)
+	s.WriteHeader(hdr)
+	s.Write([]byte("payload"))
+	s.size += math.MaxUint32 // no 4GiB of payload
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Find(rpm.RPMSIGTAG_SIZE) != nil {
+		t.Error("RPMSIGTAG_SIZE above 4GiB")
+	}
+
+	b := new(bytes.Buffer)
+	if _, err := sig.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	sig, err = rpm.NewReader(b).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, tag, ok := sigSize(sig); n != s.size || tag != rpm.RPMSIGTAG_LONGSIZE || !ok {
+		t.Errorf("read back: %d %v %v", n, tag, ok)
+	}
+	_, err = VerifyDigests(writePackage(t, sig, hdr, []byte("payload")))
+	if !errors.Is(err, errDigest) || !strings.Contains(err.Error(), "LONGSIZE") {
+		t.Errorf("size mismatch: %v", err)
+	}
+}
+
+func TestSignerRSA(t *testing.T) {
+	key, err := openpgp.NewEntity("rsa", "", "rsa@example.com",
+		&packet.Config{Algorithm: packet.PubKeyAlgoRSA, RSABits: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+	spool := new(bytes.Buffer)
+	s := NewSigner(spool)
+	s.WriteHeader(hdr)
+	s.Write([]byte("payload"))
+	sig, err := s.Signature(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Find(rpm.RPMSIGTAG_RSA) == nil || sig.Find(rpm.RPMSIGTAG_PGP) == nil {
+		t.Fatalf("rsa signatures missing")
+	}
+	if _, err := VerifyPayload(sig, spool, openpgp.EntityList{key}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReserveSpace(t *testing.T) {
+	payload := []byte("payload")
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+
+	write := func(reserve int) ([]byte, *rpm.Package) {
+		t.Helper()
+		spool := new(bytes.Buffer)
+		s := NewSigner(spool)
+		s.ReserveSpace(reserve)
+		s.WriteHeader(hdr)
+		s.Write(payload)
+		sig, err := s.Signature()
+		if err != nil {
+			t.Fatalf("signature: %v", err)
+		}
+		b := new(bytes.Buffer)
+		if _, err := rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), sig, spool); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		data := append([]byte(nil), b.Bytes()...)
+		p, err := rpm.ReadPackage(b)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return data, p
+	}
+
+	data, p := write(4096)
+	if r := p.Signature.Find(rpm.RPMSIGTAG_RESERVEDSPACE); r == nil || r.Count != 4096 {
+		t.Fatalf("no reserved space")
+	}
+	l := p.Layout()
+	size := l.Header.Off - l.Signature.Off
+	key := newKey(t, "a")
+	if err := SignHeader(p.Signature, p.Header, key); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := FitReservedSpace(p.Signature, size); err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, p.Lead, p.Signature, p.Header); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	b.Write(payload)
+	if b.Len() != len(data) || !bytes.Equal(b.Bytes()[l.Header.Off:], data[l.Header.Off:]) {
+		t.Fatalf("header moved: %d bytes, want %d", b.Len(), len(data))
+	}
+	p2, err := rpm.ReadPackage(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("reread: %v", err)
+	}
+	if _, err := VerifyHeader(p2.Signature, p2.Header, openpgp.EntityList{key}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if _, err := VerifyDigests(b); err != nil {
+		t.Fatalf("digests: %v", err)
+	}
+
+	// a signature doesn't fit in a few bytes
+	_, p = write(8)
+	l = p.Layout()
+	SignHeader(p.Signature, p.Header, key)
+	if err := FitReservedSpace(p.Signature, l.Header.Off-l.Signature.Off); err != errReservedSpace {
+		t.Fatalf("fit: %v", err)
+	}
+}
+
+func TestPackageLargeSignature(t *testing.T) {
+	payload := []byte("payload")
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+
+	s := NewSigner(nil)
+	s.ReserveSpace(100 << 10)
+	s.WriteHeader(hdr)
+	s.Write(payload)
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	// about 600k of IMA style signatures, odd lengths to pad after
+	fs := make([]string, 2000)
+	for i := range fs {
+		fs[i] = strings.Repeat("ab", 150) + strconv.Itoa(i)
+	}
+	sig.AddStringArray(rpm.RPMSIGTAG_FILESIGNATURES, fs...)
+	sig.AddInt32(rpm.RPMSIGTAG_FILESIGNATURELENGTH, 150)
+
+	b := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(b, rpm.NewLead("test", rpm.LeadBinary), sig, hdr); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	b.Write(payload)
+	data := append([]byte(nil), b.Bytes()...)
+
+	// short reads on every boundary
+	p, err := rpm.ReadPackage(iotest.HalfReader(b))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	l := p.Layout()
+	if l.Signature.Len < 600<<10 || l.Header.Off&0x7 != 0 {
+		t.Fatalf("signature %+v, header %+v", l.Signature, l.Header)
+	}
+	if v, _ := p.Signature.Find(rpm.RPMSIGTAG_FILESIGNATURES).StringArray(); len(v) != len(fs) || v[1999] != fs[1999] {
+		t.Fatalf("%d file signatures", len(v))
+	}
+	if _, err := VerifyDigests(bytes.NewReader(data)); err != nil {
+		t.Fatalf("digests: %v", err)
+	}
+
+	rb := new(bytes.Buffer)
+	if _, err := rpm.WriteHeaders(rb, p.Lead, p.Signature, p.Header); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	rb.Write(payload)
+	if !bytes.Equal(rb.Bytes(), data) {
+		t.Fatalf("rewrite mismatch")
+	}
+}
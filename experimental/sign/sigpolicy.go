@@ -0,0 +1,106 @@
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+)
+
+var errVerifyPolicy = errors.New("rpm: rejected by verify policy")
+
+// VerifyPolicy are acceptance rules for signatures and digests, the
+// zero value accepts any package. A header signature is acceptable if
+// it verifies with the keyring, is made by a key in KeyIDs, if any, and
+// with a hash at least as strong as MinHash.
+type VerifyPolicy struct {
+	// RequireSignature rejects packages without an acceptable
+	// signature, KeyIDs implies it.
+	RequireSignature bool `json:",omitempty"`
+
+	// MinHash is the weakest PGPHASHALGO_* algorithm accepted for
+	// signatures and the payload digest, by digest size.
+	MinHash uint32 `json:",omitempty"`
+
+	// KeyIDs are the keys, or signing subkeys, signatures are accepted
+	// from.
+	KeyIDs []uint64 `json:",omitempty"`
+
+	// RejectSHA1Only rejects packages whose strongest header digest is
+	// RPMSIGTAG_SHA1.
+	RejectSHA1Only bool `json:",omitempty"`
+
+	// MinProtection rejects packages whose SignatureProtection is
+	// lower, ProtectionSigned implies RequireSignature.
+	MinProtection Protection `json:",omitempty"`
+}
+
+// hashSize returns the digest size of algo, 0 if unknown.
+func hashSize(algo uint32) int {
+	if h, ok := rpm.LookupHash(algo); ok && h.Crypto != 0 {
+		return h.Crypto.Size()
+	}
+	return 0
+}
+
+func (p *VerifyPolicy) hashOK(h crypto.Hash) bool {
+	return p.MinHash == 0 || h.Available() && h.Size() >= hashSize(p.MinHash)
+}
+
+func (p *VerifyPolicy) keyOK(id uint64) bool {
+	if len(p.KeyIDs) == 0 {
+		return true
+	}
+	for _, v := range p.KeyIDs {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Check checks the signature header sig of hdr against p, signatures
+// are verified with keyring. Without a keyring the signature rules
+// fail, nothing would be verified. It doesn't check the digests match,
+// see VerifyDigests.
+func (p *VerifyPolicy) Check(sig, hdr *rpm.Header, keyring openpgp.KeyRing) error {
+	if p.RejectSHA1Only && sig.Find(rpm.RPMSIGTAG_SHA1) != nil &&
+		sig.Find(rpm.RPMSIGTAG_SHA256) == nil && sig.Find(rpm.RPMSIGTAG_SHA3_256) == nil {
+		return fmt.Errorf("%w: only a SHA1 header digest", errVerifyPolicy)
+	}
+	if algo, digest := rpm.PayloadDigest(hdr); digest != "" && p.MinHash != 0 && hashSize(algo) < hashSize(p.MinHash) {
+		return fmt.Errorf("%w: payload digest algorithm %d", errVerifyPolicy, algo)
+	}
+	if l := SignatureProtection(sig, hdr); l < p.MinProtection {
+		return fmt.Errorf("%w: protection %s, want %s", errVerifyPolicy, l, p.MinProtection)
+	}
+	if !p.RequireSignature && len(p.KeyIDs) == 0 && p.MinProtection < ProtectionSigned {
+		return nil
+	}
+	if keyring == nil {
+		return fmt.Errorf("%w: no keyring to verify signatures", errVerifyPolicy)
+	}
+
+	sigs, err := rpm.HeaderSignatures(sig)
+	if err != nil {
+		return err
+	}
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		return err
+	}
+	for _, v := range sigs {
+		s, ok := parseSignature(v)
+		if !ok || s.IssuerKeyId == nil || !p.keyOK(*s.IssuerKeyId) || !p.hashOK(s.Hash) {
+			continue
+		}
+		if _, _, err := openpgp.VerifyDetachedSignature(keyring,
+			bytes.NewReader(hb.Bytes()), bytes.NewReader(v), nil); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no acceptable signature", errVerifyPolicy)
+}
@@ -0,0 +1,82 @@
+package sign
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pschou/go-rpm"
+)
+
+func TestVerifyPolicy(t *testing.T) {
+	a, b := newKey(t, "a"), newKey(t, "b")
+	hdr := makeHdr()
+	hdr.SetRegion(rpm.HEADER_IMMUTABLE)
+
+	s := NewSigner(nil)
+	s.WriteHeader(hdr)
+	unsigned, _ := s.Signature()
+	signed, err := s.Signature(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha1Only := rpm.NewSignatureHeader()
+	sha1Only.AddString(rpm.RPMSIGTAG_SHA1, "x")
+
+	for i, v := range []struct {
+		p       VerifyPolicy
+		sig     *rpm.Header
+		keyring openpgp.KeyRing
+		ok      bool
+	}{
+		{VerifyPolicy{}, unsigned, nil, true},
+		{VerifyPolicy{RequireSignature: true}, unsigned, openpgp.EntityList{a}, false},
+		{VerifyPolicy{RequireSignature: true}, signed, nil, false},
+		{VerifyPolicy{RequireSignature: true}, signed, openpgp.EntityList{a}, true},
+		{VerifyPolicy{RequireSignature: true}, signed, openpgp.EntityList{b}, false},
+		{VerifyPolicy{KeyIDs: []uint64{a.PrimaryKey.KeyId}}, signed, openpgp.EntityList{a}, true},
+		{VerifyPolicy{KeyIDs: []uint64{a.PrimaryKey.KeyId}}, signed, nil, false},
+		{VerifyPolicy{KeyIDs: []uint64{b.PrimaryKey.KeyId}}, signed, openpgp.EntityList{a, b}, false},
+		{VerifyPolicy{RequireSignature: true, MinHash: rpm.PGPHASHALGO_SHA256}, signed, openpgp.EntityList{a}, true},
+		{VerifyPolicy{RequireSignature: true, MinHash: rpm.PGPHASHALGO_SHA512}, signed, openpgp.EntityList{a}, false},
+		{VerifyPolicy{RejectSHA1Only: true}, signed, nil, true},
+		{VerifyPolicy{RejectSHA1Only: true}, sha1Only, nil, false},
+		{VerifyPolicy{MinProtection: ProtectionWeak}, sha1Only, nil, true},
+		{VerifyPolicy{MinProtection: ProtectionHeader}, sha1Only, nil, false},
+		{VerifyPolicy{MinProtection: ProtectionHeader}, unsigned, nil, true},
+		{VerifyPolicy{MinProtection: ProtectionDigests}, unsigned, nil, false},
+		{VerifyPolicy{MinProtection: ProtectionSigned}, signed, nil, false},
+	} {
+		err := v.p.Check(v.sig, hdr, v.keyring)
+		if (err == nil) != v.ok || err != nil && !errors.Is(err, errVerifyPolicy) {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, "x")
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA1)
+	p := VerifyPolicy{MinHash: rpm.PGPHASHALGO_SHA256}
+	if err := p.Check(unsigned, hdr, nil); !errors.Is(err, errVerifyPolicy) {
+		t.Errorf("payload digest: %v", err)
+	}
+	if l := SignatureProtection(signed, hdr); l != ProtectionHeader {
+		t.Errorf("SHA1 payload digest: %v", l)
+	}
+
+	hdr = makeHdr()
+	hdr.AddStringArray(rpm.RPMTAG_PAYLOADDIGEST, "x")
+	hdr.AddInt32(rpm.RPMTAG_PAYLOADDIGESTALGO, rpm.PGPHASHALGO_SHA256)
+	p = VerifyPolicy{MinProtection: ProtectionSigned}
+	if err := p.Check(signed, hdr, openpgp.EntityList{b}); !errors.Is(err, errVerifyPolicy) {
+		t.Errorf("signed by another key: %v", err)
+	}
+
+	var l Protection
+	if err := json.Unmarshal([]byte(`"digests"`), &l); err != nil || l != ProtectionDigests {
+		t.Errorf("unmarshal: %v, %v", l, err)
+	}
+	if err := json.Unmarshal([]byte(`"strong"`), &l); err == nil {
+		t.Error("unmarshal strong: no error")
+	}
+}
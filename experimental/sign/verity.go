@@ -0,0 +1,188 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/pschou/go-rpm"
+)
+
+// fs-verity measures a file by the root of a SHA256 merkle tree over
+// 4k blocks, the kernel checks a PKCS#7 signature of that measurement.
+// RPMSIGTAG_VERITYSIGNATURES holds one base64 encoded signature per
+// file, empty for anything but regular files.
+
+const (
+	FSVERITY_HASH_ALG_SHA256 = 1
+
+	verityBlockSize = 4096
+)
+
+var errVerity = errors.New("rpm: invalid verity signatures")
+
+// VerityHash computes the fs-verity digest of the data written to it.
+type VerityHash struct {
+	block  []byte
+	hashes []byte // of the data blocks
+	size   uint64
+}
+
+func NewVerityHash() *VerityHash {
+	return &VerityHash{block: make([]byte, 0, verityBlockSize)}
+}
+
+func (v *VerityHash) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		c := copy(v.block[len(v.block):verityBlockSize], b)
+		v.block = v.block[:len(v.block)+c]
+		b = b[c:]
+		if len(v.block) == verityBlockSize {
+			v.flush()
+		}
+	}
+	v.size += uint64(n)
+	return n, nil
+}
+
+func (v *VerityHash) flush() {
+	s := sha256.Sum256(v.block)
+	v.hashes = append(v.hashes, s[:]...)
+	v.block = v.block[:0]
+}
+
+// level hashes the zero padded blocks of b.
+func level(b []byte) []byte {
+	var r []byte
+	for len(b) > 0 {
+		var blk [verityBlockSize]byte
+		n := copy(blk[:], b)
+		b = b[n:]
+		s := sha256.Sum256(blk[:])
+		r = append(r, s[:]...)
+	}
+	return r
+}
+
+// Sum returns the fs-verity file digest.
+func (v *VerityHash) Sum() []byte {
+	hashes := v.hashes
+	if len(v.block) > 0 {
+		var blk [verityBlockSize]byte
+		copy(blk[:], v.block)
+		s := sha256.Sum256(blk[:])
+		hashes = append(hashes[:len(hashes):len(hashes)], s[:]...)
+	}
+	for len(hashes) > sha256.Size {
+		hashes = level(hashes)
+	}
+
+	// struct fsverity_descriptor
+	var d [256]byte
+	d[0] = 1 // version
+	d[1] = FSVERITY_HASH_ALG_SHA256
+	d[2] = 12 // log2 block size
+	binary.LittleEndian.PutUint64(d[8:], v.size)
+	copy(d[16:], hashes)
+	s := sha256.Sum256(d[:])
+	return s[:]
+}
+
+// FormatVerityDigest returns the struct fsverity_formatted_digest the
+// signature of a file covers.
+func FormatVerityDigest(digest []byte) []byte {
+	b := append([]byte("FSVerity"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint16(b[8:], FSVERITY_HASH_ALG_SHA256)
+	binary.LittleEndian.PutUint16(b[10:], uint16(len(digest)))
+	return append(b, digest...)
+}
+
+// VeritySigner returns the PKCS#7 signature of a formatted fs-verity
+// digest, see FormatVerityDigest.
+type VeritySigner interface {
+	SignVerity(formatted []byte) ([]byte, error)
+}
+
+// SignVerity signs the fs-verity digests of the files with s and adds
+// them to sig. digests has an entry per file in header order, nil for
+// files that aren't regular files.
+func SignVerity(sig *rpm.Header, digests [][]byte, s VeritySigner) error {
+	r := make([]string, len(digests))
+	for i, v := range digests {
+		if v == nil {
+			continue
+		}
+		b, err := s.SignVerity(FormatVerityDigest(v))
+		if err != nil {
+			return err
+		}
+		r[i] = base64.StdEncoding.EncodeToString(b)
+	}
+	sig.AddStringArray(rpm.RPMSIGTAG_VERITYSIGNATURES, r...)
+	return sig.AddInt32(rpm.RPMSIGTAG_VERITYSIGNATUREALGO, FSVERITY_HASH_ALG_SHA256)
+}
+
+// VeritySignatures returns the decoded fs-verity signatures of sig, an
+// entry per file, and their hash algorithm.
+func VeritySignatures(sig *rpm.Header) ([][]byte, uint32, error) {
+	t := sig.Find(rpm.RPMSIGTAG_VERITYSIGNATURES)
+	if t == nil {
+		return nil, 0, nil
+	}
+	s, ok := t.StringArray()
+	if !ok {
+		return nil, 0, tagError{t, errTagType}
+	}
+
+	algo := uint32(FSVERITY_HASH_ALG_SHA256)
+	if a := sig.Find(rpm.RPMSIGTAG_VERITYSIGNATUREALGO); a != nil {
+		v, ok := a.Int32()
+		if !ok || len(v) == 0 {
+			return nil, 0, tagError{a, errTagType}
+		}
+		algo = v[0]
+	}
+
+	r := make([][]byte, len(s))
+	for i, v := range s {
+		if v == "" {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, 0, tagError{t, errVerity}
+		}
+		r[i] = b
+	}
+	return r, algo, nil
+}
+
+// CheckVerity checks the fs-verity signatures of sig against the files
+// of hdr as rpm does on install: one entry per file, signatures only
+// for regular files and a supported algorithm.
+func CheckVerity(sig, hdr *rpm.Header) error {
+	s, algo, err := VeritySignatures(sig)
+	if err != nil || s == nil {
+		return err
+	}
+	t := sig.Find(rpm.RPMSIGTAG_VERITYSIGNATURES)
+	if algo != FSVERITY_HASH_ALG_SHA256 {
+		return tagError{sig.Find(rpm.RPMSIGTAG_VERITYSIGNATUREALGO), errVerity}
+	}
+	fi, err := rpm.FileIndexHeader(hdr)
+	if err != nil {
+		return err
+	}
+	files := fi.Files()
+	if len(files) != len(s) {
+		return tagError{t, errVerity}
+	}
+	for i, v := range files {
+		if s[i] != nil && v.Mode&0170000 != 0100000 {
+			return tagError{t, errVerity}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pschou/go-rpm"
+)
+
+func TestVerityHash(t *testing.T) {
+	v := NewVerityHash()
+	// fsverity digest of an empty file
+	if a, b := hex.EncodeToString(v.Sum()), "3d248ca542a24fc62d1c43b916eae5016878e2533c88238480b26128a1f1af95"; a != b {
+		t.Fatalf("empty: want %s, have %s", b, a)
+	}
+
+	// chunked writes hash the same
+	data := bytes.Repeat([]byte("verity"), 100000)
+	a := NewVerityHash()
+	a.Write(data)
+	b := NewVerityHash()
+	for i := 0; i < len(data); i += 1000 {
+		b.Write(data[i:min(i+1000, len(data))])
+	}
+	if !bytes.Equal(a.Sum(), b.Sum()) || !bytes.Equal(a.Sum(), a.Sum()) {
+		t.Fatalf("chunked sum mismatch")
+	}
+
+	f := FormatVerityDigest(a.Sum())
+	if !bytes.HasPrefix(f, []byte("FSVerity\x01\x00\x20\x00")) || len(f) != 44 {
+		t.Fatalf("formatted digest: %x", f)
+	}
+}
+
+type testVeritySigner struct{}
+
+func (testVeritySigner) SignVerity(b []byte) ([]byte, error) {
+	return append([]byte("sig:"), b...), nil
+}
+
+func TestSignVerity(t *testing.T) {
+	sig := rpm.NewSignatureHeader()
+	if s, _, err := VeritySignatures(sig); s != nil || err != nil {
+		t.Fatalf("unsigned: %v", err)
+	}
+
+	d := NewVerityHash().Sum()
+	if err := SignVerity(sig, [][]byte{d, nil}, testVeritySigner{}); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	s, algo, err := VeritySignatures(sig)
+	if err != nil || algo != FSVERITY_HASH_ALG_SHA256 || len(s) != 2 {
+		t.Fatalf("signatures: %d %d %v", len(s), algo, err)
+	}
+	if want := append([]byte("sig:"), FormatVerityDigest(d)...); !bytes.Equal(s[0], want) || s[1] != nil {
+		t.Fatalf("signatures: %q", s)
+	}
+}
+
+func TestCheckVerity(t *testing.T) {
+	hdr := new(rpm.Header)
+	fi := rpm.NewFileIndex()
+	fi.Add(&rpm.File{Name: "/a", Mode: 0100644})
+	fi.Add(&rpm.File{Name: "/d", Mode: 040755})
+	fi.Append(hdr)
+
+	d := NewVerityHash().Sum()
+	for _, v := range []struct {
+		digests [][]byte
+		ok      bool
+	}{
+		{[][]byte{d, nil}, true},
+		{[][]byte{d}, false},
+		{[][]byte{d, d}, false},
+	} {
+		sig := rpm.NewSignatureHeader()
+		if err := SignVerity(sig, v.digests, testVeritySigner{}); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if err := CheckVerity(sig, hdr); (err == nil) != v.ok {
+			t.Errorf("%d digests: %v", len(v.digests), err)
+		}
+	}
+	if err := CheckVerity(rpm.NewSignatureHeader(), hdr); err != nil {
+		t.Errorf("unsigned: %v", err)
+	}
+}
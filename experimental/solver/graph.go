@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type Node struct {
+	ID   string
+	Name string
+	EVR  string
+	Arch string
+}
+
+// Edge is a requirement of From provided by To. Unresolved edges point
+// to the requirement itself.
+type Edge struct {
+	From       string
+	To         string
+	Requires   string
+	Unresolved bool `json:",omitempty"`
+}
+
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Graph returns the requires/provides relationships between pkgs, or
+// between all packages of s if pkgs is empty. Edges to providers
+// outside of pkgs are left out, rpmlib() requirements are skipped.
+func (s *Set) Graph(pkgs ...*Package) *Graph {
+	if len(pkgs) == 0 {
+		pkgs = s.pkgs
+	}
+	in := make(map[*Package]bool)
+	for _, v := range pkgs {
+		in[v] = true
+	}
+
+	g := new(Graph)
+	for _, v := range pkgs {
+		g.Nodes = append(g.Nodes, Node{
+			ID:   v.String(),
+			Name: v.Name,
+			EVR:  v.EVR.String(),
+			Arch: v.Arch,
+		})
+	}
+	for _, v := range pkgs {
+		edge := make(map[*Package]bool)
+		for _, d := range v.Requires {
+			if d.RPMLib() {
+				continue
+			}
+			pr := s.WhatProvides(d)
+			if len(pr) == 0 {
+				g.Edges = append(g.Edges, Edge{
+					From:       v.String(),
+					To:         d.String(),
+					Requires:   d.String(),
+					Unresolved: true,
+				})
+				continue
+			}
+			for _, p := range pr {
+				if !in[p] || p == v || edge[p] {
+					continue
+				}
+				edge[p] = true
+				g.Edges = append(g.Edges, Edge{
+					From:     v.String(),
+					To:       p.String(),
+					Requires: d.String(),
+				})
+			}
+		}
+	}
+	return g
+}
+
+// DependsOn returns the nodes with a direct or indirect edge to a node
+// named name.
+func (g *Graph) DependsOn(name string) []string {
+	names := make(map[string]string)
+	rev := make(map[string][]string)
+	for _, v := range g.Nodes {
+		names[v.ID] = v.Name
+	}
+	for _, v := range g.Edges {
+		if !v.Unresolved {
+			rev[v.To] = append(rev[v.To], v.From)
+		}
+	}
+
+	var next []string
+	for _, v := range g.Nodes {
+		if v.Name == name {
+			next = append(next, v.ID)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var r []string
+	for len(next) > 0 {
+		id := next[0]
+		next = next[1:]
+		for _, v := range rev[id] {
+			if seen[v] || names[v] == name {
+				continue
+			}
+			seen[v] = true
+			r = append(r, v)
+			next = append(next, v)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+func (g *Graph) WriteDot(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "digraph rpmdeps {")
+	for _, v := range g.Nodes {
+		fmt.Fprintf(b, "\t%q;\n", v.ID)
+	}
+	for _, v := range g.Edges {
+		if v.Unresolved {
+			fmt.Fprintf(b, "\t%q -> %q [style=dashed, color=red];\n", v.From, v.To)
+			continue
+		}
+		fmt.Fprintf(b, "\t%q -> %q [label=%q];\n", v.From, v.To, v.Requires)
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
@@ -0,0 +1,175 @@
+// Package solver resolves the dependencies of sets of packages and
+// compares sets for upgrades.
+package solver
+
+import (
+	"sort"
+
+	"github.com/pschou/go-rpm"
+)
+
+type Package struct {
+	Name string
+	Arch string
+	EVR  rpm.EVR
+
+	Requires  []rpm.Dependency
+	Provides  []rpm.Dependency
+	Obsoletes []rpm.Dependency
+	Files     []string
+
+	Header *rpm.Header
+}
+
+func (p *Package) String() string {
+	return p.Name + "-" + p.EVR.String() + "." + p.Arch
+}
+
+// NewPackage collects the dependency information of hdr, the package
+// name is added as a provide if the header has none for it.
+func NewPackage(hdr *rpm.Header) *Package {
+	p := &Package{
+		EVR:       rpm.HeaderEVR(hdr),
+		Requires:  hdr.Requires(),
+		Provides:  hdr.Provides(),
+		Obsoletes: hdr.Obsoletes(),
+		Header:    hdr,
+	}
+	p.Name, _ = hdr.StringData(rpm.RPMTAG_NAME)
+	p.Arch, _ = hdr.StringData(rpm.RPMTAG_ARCH)
+	if fi, err := rpm.FileIndexHeader(hdr); err == nil {
+		p.Files = fi.Filenames()
+	}
+
+	for _, v := range p.Provides {
+		if v.Name == p.Name {
+			return p
+		}
+	}
+	p.Provides = append(p.Provides, rpm.Dependency{
+		Name:    p.Name,
+		Flags:   rpm.RPMSENSE_EQUAL,
+		Version: p.EVR.String(),
+	})
+	return p
+}
+
+type provide struct {
+	pkg *Package
+	dep rpm.Dependency
+}
+
+type Set struct {
+	pkgs     []*Package
+	provides map[string][]provide
+	files    map[string][]*Package
+}
+
+func New() *Set {
+	return &Set{
+		provides: make(map[string][]provide),
+		files:    make(map[string][]*Package),
+	}
+}
+
+func (s *Set) Add(p *Package) {
+	s.pkgs = append(s.pkgs, p)
+	for _, v := range p.Provides {
+		s.provides[v.Name] = append(s.provides[v.Name], provide{p, v})
+	}
+	for _, v := range p.Files {
+		s.files[v] = append(s.files[v], p)
+	}
+}
+
+func (s *Set) Packages() []*Package { return s.pkgs }
+
+// Find returns the packages named name, newest first.
+func (s *Set) Find(name string) []*Package {
+	var r []*Package
+	for _, v := range s.pkgs {
+		if v.Name == name {
+			r = append(r, v)
+		}
+	}
+	sortPackages(r)
+	return r
+}
+
+func sortPackages(p []*Package) {
+	sort.SliceStable(p, func(i, j int) bool {
+		if p[i].Name != p[j].Name {
+			return p[i].Name < p[j].Name
+		}
+		return p[i].EVR.Compare(p[j].EVR) > 0
+	})
+}
+
+// WhatProvides returns the packages satisfying req, newest first.
+// Requirements on absolute paths also match file lists.
+func (s *Set) WhatProvides(req rpm.Dependency) []*Package {
+	var r []*Package
+	seen := make(map[*Package]bool)
+	for _, v := range s.provides[req.Name] {
+		if !seen[v.pkg] && v.dep.Satisfies(req) {
+			seen[v.pkg] = true
+			r = append(r, v.pkg)
+		}
+	}
+	for _, v := range s.files[req.Name] {
+		if !seen[v] {
+			seen[v] = true
+			r = append(r, v)
+		}
+	}
+	sortPackages(r)
+	return r
+}
+
+type Unresolved struct {
+	Package *Package
+	Dep     rpm.Dependency
+}
+
+// Closure returns the packages needed to install p, including p, and the
+// requirements no package in s provides. rpmlib() requirements are
+// skipped and the newest provider is picked when there are several.
+func (s *Set) Closure(p *Package) ([]*Package, []Unresolved) {
+	var (
+		r    []*Package
+		u    []Unresolved
+		seen = map[*Package]bool{p: true}
+		next = []*Package{p}
+	)
+	for len(next) > 0 {
+		p := next[0]
+		next = next[1:]
+		r = append(r, p)
+
+		for _, v := range p.Requires {
+			if v.RPMLib() {
+				continue
+			}
+			pr := s.WhatProvides(v)
+			if len(pr) == 0 {
+				u = append(u, Unresolved{p, v})
+				continue
+			}
+			if anySeen(pr, seen) {
+				continue
+			}
+			seen[pr[0]] = true
+			next = append(next, pr[0])
+		}
+	}
+	return r, u
+}
+
+func anySeen(p []*Package, seen map[*Package]bool) bool {
+	for _, v := range p {
+		if seen[v] {
+			return true
+		}
+	}
+	return false
+}
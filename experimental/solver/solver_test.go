@@ -0,0 +1,165 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/pschou/go-rpm"
+)
+
+func pkg(name, version string, req, prov []string, files ...string) *rpm.Header {
+	hdr := new(rpm.Header)
+	hdr.AddString(rpm.RPMTAG_NAME, name)
+	hdr.AddString(rpm.RPMTAG_VERSION, version)
+	hdr.AddString(rpm.RPMTAG_RELEASE, "1")
+	hdr.AddString(rpm.RPMTAG_ARCH, "noarch")
+	if len(req) > 0 {
+		hdr.AddStringArray(rpm.RPMTAG_REQUIRENAME, req...)
+		hdr.AddInt32(rpm.RPMTAG_REQUIREFLAGS, make([]uint32, len(req))...)
+		hdr.AddStringArray(rpm.RPMTAG_REQUIREVERSION, make([]string, len(req))...)
+	}
+	if len(prov) > 0 {
+		hdr.AddStringArray(rpm.RPMTAG_PROVIDENAME, prov...)
+		hdr.AddInt32(rpm.RPMTAG_PROVIDEFLAGS, make([]uint32, len(prov))...)
+		hdr.AddStringArray(rpm.RPMTAG_PROVIDEVERSION, make([]string, len(prov))...)
+	}
+	if len(files) > 0 {
+		fi := rpm.NewFileIndex()
+		for _, v := range files {
+			fi.Add(&rpm.File{Name: v})
+		}
+		fi.Append(hdr)
+	}
+	return hdr
+}
+
+func testSet() *Set {
+	s := New()
+	for _, v := range []*rpm.Header{
+		pkg("app", "1", []string{"libfoo", "/bin/sh", "rpmlib(PayloadIsXz)"}, nil),
+		pkg("foo", "1", nil, []string{"libfoo"}),
+		pkg("foo", "2", []string{"missing"}, []string{"libfoo"}),
+		pkg("bash", "5", []string{"foo"}, nil, "/bin/sh", "/bin/bash"),
+		pkg("other", "1", nil, nil),
+	} {
+		s.Add(NewPackage(v))
+	}
+	return s
+}
+
+func TestFind(t *testing.T) {
+	s := testSet()
+	p := s.Find("foo")
+	if len(p) != 2 || p[0].EVR.Version != "2" {
+		t.Fatalf("find: %v", p)
+	}
+	if p := s.WhatProvides(rpm.Dependency{Name: "/bin/sh"}); len(p) != 1 || p[0].Name != "bash" {
+		t.Fatalf("file provide: %v", p)
+	}
+}
+
+func TestClosure(t *testing.T) {
+	s := testSet()
+	c, u := s.Closure(s.Find("app")[0])
+
+	var names []string
+	for _, v := range c {
+		names = append(names, v.String())
+	}
+	want := []string{"app-1-1.noarch", "foo-2-1.noarch", "bash-5-1.noarch"}
+	if len(names) != len(want) {
+		t.Fatalf("closure: %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("closure: %v", names)
+		}
+	}
+
+	if len(u) != 1 || u[0].Dep.Name != "missing" || u[0].Package.Name != "foo" {
+		t.Fatalf("unresolved: %v", u)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	s := testSet()
+	g := s.Graph()
+	if len(g.Nodes) != 5 {
+		t.Fatalf("nodes: %v", g.Nodes)
+	}
+
+	var unresolved int
+	for _, v := range g.Edges {
+		if v.Unresolved {
+			unresolved++
+		}
+	}
+	if unresolved != 1 {
+		t.Errorf("unresolved: %d", unresolved)
+	}
+
+	dep := g.DependsOn("foo")
+	want := []string{"app-1-1.noarch", "bash-5-1.noarch"}
+	if len(dep) != len(want) || dep[0] != want[0] || dep[1] != want[1] {
+		t.Errorf("depends on: %v", dep)
+	}
+	if dep := g.DependsOn("app"); len(dep) != 0 {
+		t.Errorf("depends on: %v", dep)
+	}
+}
+
+func TestUpgradeProblems(t *testing.T) {
+	arch := func(hdr *rpm.Header, arch string) *rpm.Header {
+		hdr.Delete(rpm.RPMTAG_ARCH)
+		hdr.AddString(rpm.RPMTAG_ARCH, arch)
+		return hdr
+	}
+	old := New()
+	for _, v := range []*rpm.Header{
+		pkg("keep", "1", nil, nil),
+		pkg("down", "2", nil, nil),
+		pkg("gone", "1", nil, nil),
+		pkg("renamed", "1", nil, nil),
+		pkg("epoch", "2", nil, nil),
+		pkg("moved", "1", nil, nil),
+		pkg("multilib", "1", nil, nil),
+		arch(pkg("multilib", "1", nil, nil), "x86_64"),
+	} {
+		old.Add(NewPackage(v))
+	}
+
+	next := New()
+	epoch := pkg("epoch", "1", nil, nil)
+	epoch.AddInt32(rpm.RPMTAG_EPOCH, 1)
+	repl := pkg("replacement", "1", nil, nil)
+	repl.AddStringArray(rpm.RPMTAG_OBSOLETENAME, "renamed")
+	repl.AddInt32(rpm.RPMTAG_OBSOLETEFLAGS, rpm.RPMSENSE_LESS|rpm.RPMSENSE_EQUAL)
+	repl.AddStringArray(rpm.RPMTAG_OBSOLETEVERSION, "1-1")
+	for _, v := range []*rpm.Header{
+		pkg("keep", "1.1", nil, nil),
+		pkg("down", "1", nil, nil),
+		epoch,
+		repl,
+		arch(pkg("moved", "1.1", nil, nil), "x86_64"),
+		arch(pkg("multilib", "1.1", nil, nil), "x86_64"),
+	} {
+		next.Add(NewPackage(v))
+	}
+
+	p := UpgradeProblems(old, next)
+	if len(p) != 4 {
+		t.Fatalf("problems: %v", p)
+	}
+	if p[0].Kind != Downgraded || p[0].Old.Name != "down" || p[0].New == nil {
+		t.Errorf("downgrade: %v", p[0])
+	}
+	if p[1].Kind != Removed || p[1].Old.Name != "gone" {
+		t.Errorf("removed: %v", p[1])
+	}
+	if p[2].Kind != ArchChanged || p[2].Old.Arch != "noarch" || p[2].New == nil || p[2].New.Arch != "x86_64" {
+		t.Errorf("arch changed: %v", p[2])
+	}
+	// the x86_64 package is the successor of its own arch only
+	if p[3].Kind != Removed || p[3].Old.Name != "multilib" || p[3].Old.Arch != "noarch" {
+		t.Errorf("multilib: %v", p[3])
+	}
+}
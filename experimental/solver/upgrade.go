@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pschou/go-rpm"
+)
+
+type ProblemKind int
+
+const (
+	// Removed packages have no successor and no obsoleter.
+	Removed ProblemKind = iota + 1
+	// Downgraded packages have a lower EVR in the new set.
+	Downgraded
+	// ArchChanged packages have no successor of their arch, but one of
+	// an arch the old set doesn't have the package in.
+	ArchChanged
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case Removed:
+		return "removed"
+	case Downgraded:
+		return "downgraded"
+	case ArchChanged:
+		return "arch changed"
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+type Problem struct {
+	Kind ProblemKind
+	Old  *Package
+	New  *Package
+}
+
+func (p Problem) String() string {
+	if p.New == nil {
+		return fmt.Sprintf("%s: %s", p.Kind, p.Old)
+	}
+	return fmt.Sprintf("%s: %s -> %s", p.Kind, p.Old, p.New)
+}
+
+func newest(s *Set) map[string]*Package {
+	r := make(map[string]*Package)
+	for _, v := range s.pkgs {
+		k := v.Name + "." + v.Arch
+		if p, ok := r[k]; !ok || v.EVR.Compare(p.EVR) > 0 {
+			r[k] = v
+		}
+	}
+	return r
+}
+
+// Obsoleters returns the packages of s obsoleting p.
+func (s *Set) Obsoleters(p *Package) []*Package {
+	prov := rpm.Dependency{
+		Name:    p.Name,
+		Flags:   rpm.RPMSENSE_EQUAL,
+		Version: p.EVR.String(),
+	}
+	var r []*Package
+	for _, v := range s.pkgs {
+		for _, o := range v.Obsoletes {
+			if prov.Satisfies(o) {
+				r = append(r, v)
+				break
+			}
+		}
+	}
+	sortPackages(r)
+	return r
+}
+
+// moved returns the newest package of next named like v in an arch of
+// none of the packages of that name in old.
+func moved(v *Package, old, next map[string]*Package) *Package {
+	var r *Package
+	for k, p := range next {
+		if _, ok := old[k]; ok || p.Name != v.Name {
+			continue
+		}
+		if r == nil || p.EVR.Compare(r.EVR) > 0 || p.EVR.Compare(r.EVR) == 0 && p.Arch < r.Arch {
+			r = p
+		}
+	}
+	return r
+}
+
+// UpgradeProblems compares the newest packages of each name and arch
+// in old and next and reports the ones that can't be upgraded.
+func UpgradeProblems(old, next *Set) []Problem {
+	var r []Problem
+	o, n := newest(old), newest(next)
+	for k, v := range o {
+		p, ok := n[k]
+		switch {
+		case ok:
+			if p.EVR.Compare(v.EVR) < 0 {
+				r = append(r, Problem{Kind: Downgraded, Old: v, New: p})
+			}
+		case len(next.Obsoleters(v)) > 0:
+		default:
+			if p := moved(v, o, n); p != nil {
+				r = append(r, Problem{Kind: ArchChanged, Old: v, New: p})
+			} else {
+				r = append(r, Problem{Kind: Removed, Old: v})
+			}
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Old.String() < r[j].Old.String()
+	})
+	return r
+}
@@ -0,0 +1,81 @@
+package rpm
+
+import (
+	"errors"
+	"strings"
+)
+
+type NEVRA struct {
+	Name string
+	EVR
+	Arch string
+}
+
+// String returns name-[epoch:]version-release.arch.
+func (n NEVRA) String() string {
+	return n.Name + "-" + n.EVR.String() + "." + n.Arch
+}
+
+// IsSource reports if hdr is a source package, rpmbuild marks them
+// with RPMTAG_SOURCEPACKAGE.
+func IsSource(hdr *Header) bool {
+	return hdr.Find(RPMTAG_SOURCEPACKAGE) != nil
+}
+
+// HeaderNEVRA returns the NEVRA of hdr, source packages have the arch
+// src, or nosrc if sources or patches are left out.
+func HeaderNEVRA(hdr *Header) NEVRA {
+	r := NEVRA{EVR: HeaderEVR(hdr)}
+	r.Name, _ = hdr.StringData(RPMTAG_NAME)
+	r.Arch, _ = hdr.StringData(RPMTAG_ARCH)
+	if IsSource(hdr) {
+		r.Arch = "src"
+		if hdr.Find(RPMTAG_NOSOURCE) != nil || hdr.Find(RPMTAG_NOPATCH) != nil {
+			r.Arch = "nosrc"
+		}
+	}
+	return r
+}
+
+// FileName returns the canonical file name of hdr,
+// %{name}-%{version}-%{release}.%{arch}.rpm.
+func FileName(hdr *Header) string {
+	n := HeaderNEVRA(hdr)
+	return n.Name + "-" + n.Version + "-" + n.Release + "." + n.Arch + ".rpm"
+}
+
+var errFileName = errors.New("rpm: invalid package file name")
+
+// ParseFileName parses a canonical package file name, the epoch is not
+// part of file names and is left zero.
+func ParseFileName(name string) (NEVRA, error) {
+	var r NEVRA
+	if i := strings.LastIndexByte(name, '/'); i != -1 {
+		name = name[i+1:]
+	}
+	if !strings.HasSuffix(name, ".rpm") {
+		return r, errFileName
+	}
+	name = strings.TrimSuffix(name, ".rpm")
+
+	i := strings.LastIndexByte(name, '.')
+	if i == -1 {
+		return r, errFileName
+	}
+	name, r.Arch = name[:i], name[i+1:]
+
+	if i = strings.LastIndexByte(name, '-'); i == -1 {
+		return r, errFileName
+	}
+	name, r.Release = name[:i], name[i+1:]
+
+	if i = strings.LastIndexByte(name, '-'); i == -1 {
+		return r, errFileName
+	}
+	r.Name, r.Version = name[:i], name[i+1:]
+
+	if r.Name == "" || r.Version == "" || r.Release == "" || r.Arch == "" {
+		return r, errFileName
+	}
+	return r, nil
+}
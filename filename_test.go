@@ -0,0 +1,59 @@
+package rpm
+
+import "testing"
+
+func TestFileName(t *testing.T) {
+	hdr := new(Header)
+	hdr.AddString(RPMTAG_NAME, "foo-bar")
+	hdr.AddInt32(RPMTAG_EPOCH, 1)
+	hdr.AddString(RPMTAG_VERSION, "1.2")
+	hdr.AddString(RPMTAG_RELEASE, "3.el9")
+	hdr.AddString(RPMTAG_ARCH, "x86_64")
+	if n := FileName(hdr); n != "foo-bar-1.2-3.el9.x86_64.rpm" {
+		t.Errorf("binary: %s", n)
+	}
+	if n := HeaderNEVRA(hdr).String(); n != "foo-bar-1:1.2-3.el9.x86_64" {
+		t.Errorf("nevra: %s", n)
+	}
+
+	hdr.AddInt32(RPMTAG_SOURCEPACKAGE, 1)
+	if n := FileName(hdr); n != "foo-bar-1.2-3.el9.src.rpm" {
+		t.Errorf("source: %s", n)
+	}
+	hdr.AddInt32(RPMTAG_NOSOURCE, 0)
+	if n := FileName(hdr); n != "foo-bar-1.2-3.el9.nosrc.rpm" {
+		t.Errorf("nosrc: %s", n)
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	for _, v := range []struct {
+		name string
+		want NEVRA
+	}{
+		{"foo-1.0-1.noarch.rpm", NEVRA{"foo", EVR{0, "1.0", "1"}, "noarch"}},
+		{"/repo/foo-bar-1.2-3.el9.x86_64.rpm", NEVRA{"foo-bar", EVR{0, "1.2", "3.el9"}, "x86_64"}},
+		{"foo-bar-1.2-3.el9.src.rpm", NEVRA{"foo-bar", EVR{0, "1.2", "3.el9"}, "src"}},
+	} {
+		have, err := ParseFileName(v.name)
+		if err != nil {
+			t.Errorf("%s: %v", v.name, err)
+			continue
+		}
+		if have != v.want {
+			t.Errorf("%s: %+v != %+v", v.name, have, v.want)
+		}
+	}
+
+	for _, v := range []string{
+		"foo-1.0-1.noarch",
+		"foo.rpm",
+		"foo-1.noarch.rpm",
+		"-1.0-1.noarch.rpm",
+		"foo-1.0-1..rpm",
+	} {
+		if _, err := ParseFileName(v); err == nil {
+			t.Errorf("%s: no error", v)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path"
-	"path/filepath"
+	"slices"
+	"strings"
 	"text/tabwriter"
 	"time"
 )
@@ -23,7 +25,8 @@ type prefixMap struct {
 }
 
 func (p *prefixMap) index(file string) (string, int) {
-	d, f := filepath.Split(file)
+	// rpm paths always use slashes, whatever the host OS
+	d, f := path.Split(file)
 	if d == "" {
 		d = "/"
 	}
@@ -56,8 +59,13 @@ type FileIndex struct {
 	verify     []uint32   // RPMTAG_FILEVERIFYFLAGS, all -1
 	size       []uint32   // RPMTAG_FILESIZES
 	lsize      []uint64   // RPMTAG_LONGFILESIZES
+	caps       []string   // RPMTAG_FILECAPS
+	contexts   []string   // RPMTAG_FILECONTEXTS
 	rpmsize    uint32     // RPMTAG_SIZE
 	rpmlsize   uint64     // RPMTAG_LONGSIZE
+	legacy     bool       // RPMTAG_OLDFILENAMES instead of the above triple
+	algo       uint32     // RPMTAG_FILEDIGESTALGO, 0 for the md5 default
+	lookup     map[string]int
 }
 
 func NewFileIndex() *FileIndex {
@@ -75,9 +83,14 @@ type File struct {
 	NoVerify uint32
 	Size     uint64
 	Flags    uint32 // %ghost/config etc
+	Caps     string // text form, see CapabilityText
+	Context  string // selinux context
 }
 
-var errInvalidFileMode = errors.New("rpm: invalid filemode")
+var (
+	errInvalidFileMode = errors.New("rpm: invalid filemode")
+	errFileIndex       = errors.New("rpm: invalid file index")
+)
 
 func Mode(mode os.FileMode) (uint16, error) {
 	var r uint16
@@ -94,8 +107,20 @@ func Mode(mode os.FileMode) (uint16, error) {
 	return r<<12 | uint16(mode&os.ModePerm), nil
 }
 
+// CleanName returns name as stored in the header, absolute. Payload
+// names may start with "./", "/" or neither.
+func CleanName(name string) string {
+	if strings.HasPrefix(name, "./") {
+		name = name[1:]
+	} else if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	return path.Clean(name)
+}
+
 func (f *FileIndex) Add(r *File) {
-	name, di := f.dirNames.index(r.Name)
+	f.lookup = nil
+	name, di := f.dirNames.index(CleanName(r.Name))
 	f.dirIndexes = append(f.dirIndexes, uint32(di))
 	f.name = append(f.name, name)
 	f.mode = append(f.mode, r.Mode)
@@ -104,37 +129,153 @@ func (f *FileIndex) Add(r *File) {
 	f.linkto = append(f.linkto, r.LinkTo)
 	f.digest = append(f.digest, r.Digest)
 	f.flags = append(f.flags, r.Flags)
+	f.caps = append(f.caps, r.Caps)
+	f.contexts = append(f.contexts, r.Context)
 
 	// this can be empty string but rpm throws a warning
 	// "user  does not exist - using root"
 	f.user = append(f.user, def(r.User, "", "root"))
 	f.group = append(f.group, def(r.Group, "", "root"))
 
-	// TODO: fallback to 32b when used
 	f.lsize = append(f.lsize, r.Size)
 	f.rpmlsize += r.Size
 }
 
+// SetDigestAlgo sets the PGPHASHALGO_* algorithm of the file digests.
+func (f *FileIndex) SetDigestAlgo(algo uint32) error {
+	if _, ok := LookupHash(algo); !ok {
+		return errDigestAlgo
+	}
+	f.algo = algo
+	return nil
+}
+
+// DigestAlgo returns the PGPHASHALGO_* algorithm of the file digests.
+func (f *FileIndex) DigestAlgo() uint32 {
+	if f.algo == 0 {
+		return PGPHASHALGO_MD5
+	}
+	return f.algo
+}
+
+// ExpandFilenames makes Append emit RPMTAG_OLDFILENAMES instead of the
+// dirnames triple, like expandFilelist in librpm.
+func (f *FileIndex) ExpandFilenames() { f.legacy = true }
+
+// CompressFilenames makes Append emit RPMTAG_DIRNAMES, RPMTAG_BASENAMES
+// and RPMTAG_DIRINDEXES, like compressFilelist in librpm.
+func (f *FileIndex) CompressFilenames() { f.legacy = false }
+
+func (f *FileIndex) Filenames() []string {
+	r := make([]string, len(f.name))
+	for i, v := range f.name {
+		r[i] = f.dirNames.s[f.dirIndexes[i]] + v
+	}
+	return r
+}
+
+// Files returns the entries of the index.
+func (f *FileIndex) Files() []File {
+	r := make([]File, len(f.name))
+	for i := range f.name {
+		v := &r[i]
+		v.Name = f.dirNames.s[f.dirIndexes[i]] + f.name[i]
+		v.Size = f.fsize(i)
+		for _, s := range []struct {
+			dst *string
+			src []string
+		}{
+			{&v.User, f.user}, {&v.Group, f.group}, {&v.LinkTo, f.linkto},
+			{&v.Digest, f.digest}, {&v.Caps, f.caps}, {&v.Context, f.contexts},
+		} {
+			if i < len(s.src) {
+				*s.dst = s.src[i]
+			}
+		}
+		if i < len(f.mode) {
+			v.Mode = f.mode[i]
+		}
+		if i < len(f.mtime) {
+			v.MTime = f.mtime[i]
+		}
+		if i < len(f.flags) {
+			v.Flags = f.flags[i]
+		}
+		if i < len(f.verify) {
+			v.NoVerify = ^f.verify[i]
+		}
+	}
+	return r
+}
+
+// Lookup returns the index of the file name, a payload name in either
+// convention, see CleanName.
+func (f *FileIndex) Lookup(name string) (int, bool) {
+	if f.lookup == nil {
+		f.lookup = make(map[string]int, len(f.name))
+		for i, v := range f.Filenames() {
+			if _, ok := f.lookup[v]; !ok {
+				f.lookup[v] = i
+			}
+		}
+	}
+	i, ok := f.lookup[CleanName(name)]
+	return i, ok
+}
+
+// Append adds the file tags and RPMTAG_SIZE to hdr. Without files,
+// for a metadata-only package, only RPMTAG_SIZE 0 is added, the
+// payload then has just the cpio trailer.
 func (f *FileIndex) Append(hdr *Header) {
 	if len(f.name) == 0 {
+		hdr.AddInt32(RPMTAG_SIZE, 0)
 		return
 	}
-	hdr.AddStringArray(RPMTAG_DIRNAMES, f.dirNames.s...)
-	hdr.AddStringArray(RPMTAG_BASENAMES, f.name...)
+	if f.legacy {
+		hdr.AddStringArray(RPMTAG_OLDFILENAMES, f.Filenames()...)
+	} else {
+		hdr.AddStringArray(RPMTAG_DIRNAMES, f.dirNames.s...)
+		hdr.AddStringArray(RPMTAG_BASENAMES, f.name...)
+		hdr.AddInt32(RPMTAG_DIRINDEXES, f.dirIndexes...)
+	}
 	hdr.AddStringArray(RPMTAG_FILEUSERNAME, f.user...)
 	hdr.AddStringArray(RPMTAG_FILEGROUPNAME, f.group...)
 	hdr.AddStringArray(RPMTAG_FILELINKTOS, f.linkto...)
 	hdr.AddStringArray(RPMTAG_FILEDIGESTS, f.digest...)
-	hdr.AddInt32(RPMTAG_DIRINDEXES, f.dirIndexes...)
 	hdr.AddInt32(RPMTAG_FILEMTIMES, f.mtime...)
 	hdr.AddInt16(RPMTAG_FILEMODES, f.mode...)
 	hdr.AddInt32(RPMTAG_FILEFLAGS, f.flags...)
 	hdr.AddInt32(RPMTAG_FILEVERIFYFLAGS, f.verify...)
-	if f.lsize != nil {
+	if f.algo != 0 {
+		hdr.AddInt32(RPMTAG_FILEDIGESTALGO, f.algo)
+	}
+	if anySet(f.caps) {
+		hdr.AddStringArray(RPMTAG_FILECAPS, f.caps...)
+	}
+	if anySet(f.contexts) {
+		hdr.AddStringArray(RPMTAG_FILECONTEXTS, f.contexts...)
+	}
+	// 64b sizes only for files, or a total, that don't fit 32b, as
+	// rpmbuild does
+	large := slices.ContainsFunc(f.lsize, func(v uint64) bool { return v >= math.MaxUint32 })
+	switch {
+	case large:
 		hdr.AddInt64(RPMTAG_LONGFILESIZES, f.lsize...)
-		hdr.AddInt64(RPMTAG_LONGSIZE, f.rpmlsize)
-	} else {
+	case f.lsize != nil:
+		size := make([]uint32, len(f.lsize))
+		for i, v := range f.lsize {
+			size[i] = uint32(v)
+		}
+		hdr.AddInt32(RPMTAG_FILESIZES, size...)
+	default:
 		hdr.AddInt32(RPMTAG_FILESIZES, f.size...)
+	}
+	switch {
+	case f.rpmlsize >= math.MaxUint32:
+		hdr.AddInt64(RPMTAG_LONGSIZE, f.rpmlsize)
+	case f.lsize != nil:
+		hdr.AddInt32(RPMTAG_SIZE, uint32(f.rpmlsize))
+	default:
 		hdr.AddInt32(RPMTAG_SIZE, f.rpmsize)
 	}
 }
@@ -144,6 +285,7 @@ func FileIndexHeader(hdr *Header) (*FileIndex, error) {
 	var (
 		ok  bool
 		err error = errTagType
+		old []string
 	)
 
 	for _, v := range hdr.Tags {
@@ -158,6 +300,8 @@ func FileIndexHeader(hdr *Header) (*FileIndex, error) {
 				}
 				idx.dirNames.m[v] = i
 			}
+		case RPMTAG_OLDFILENAMES:
+			old, ok = v.StringArray()
 		case RPMTAG_BASENAMES:
 			idx.name, ok = v.StringArray()
 		case RPMTAG_FILEUSERNAME:
@@ -168,6 +312,10 @@ func FileIndexHeader(hdr *Header) (*FileIndex, error) {
 			idx.linkto, ok = v.StringArray()
 		case RPMTAG_FILEDIGESTS:
 			idx.digest, ok = v.StringArray()
+		case RPMTAG_FILECAPS:
+			idx.caps, ok = v.StringArray()
+		case RPMTAG_FILECONTEXTS:
+			idx.contexts, ok = v.StringArray()
 		case RPMTAG_DIRINDEXES:
 			idx.dirIndexes, ok = v.data.(tagUint32)
 		case RPMTAG_FILEDEVICES:
@@ -191,6 +339,11 @@ func FileIndexHeader(hdr *Header) (*FileIndex, error) {
 			if sz, ok = v.data.(tagUint32); ok {
 				idx.rpmsize = sz[0]
 			}
+		case RPMTAG_FILEDIGESTALGO:
+			var a tagUint32
+			if a, ok = v.data.(tagUint32); ok && len(a) > 0 {
+				idx.algo = a[0]
+			}
 		case RPMTAG_LONGSIZE:
 			var sz tagUint64
 			if sz, ok = v.data.(tagUint64); ok {
@@ -204,6 +357,24 @@ func FileIndexHeader(hdr *Header) (*FileIndex, error) {
 		}
 	}
 
+	// pre rpm-3 packages only have full paths
+	if idx.name == nil && old != nil {
+		idx.legacy = true
+		for _, v := range old {
+			name, di := idx.dirNames.index(v)
+			idx.dirIndexes = append(idx.dirIndexes, uint32(di))
+			idx.name = append(idx.name, name)
+		}
+	}
+
+	if len(idx.dirIndexes) != len(idx.name) {
+		return nil, fmt.Errorf("%w: %d dir indexes for %d base names", errFileIndex, len(idx.dirIndexes), len(idx.name))
+	}
+	for _, v := range idx.dirIndexes {
+		if int(v) >= len(idx.dirNames.s) {
+			return nil, fmt.Errorf("%w: dir index %d of %d dir names", errFileIndex, v, len(idx.dirNames.s))
+		}
+	}
 	return idx, nil
 }
 
@@ -250,6 +421,16 @@ func hexEncode(m uint32) string {
 	return string(r[:b])
 }
 
+// anySet reports if s has a non-empty string.
+func anySet(s []string) bool {
+	for _, v := range s {
+		if v != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func def(a, b, d string) string {
 	if a == b {
 		return d
@@ -2,6 +2,10 @@ package rpm
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +22,7 @@ func TestPrefixMap(t *testing.T) {
 		{"/file3", 0, "file3"},
 		{"/dir2/file4", 2, "file4"},
 		{"nosep", 0, "nosep"},
+		{"/dir1/back\\slash", 1, "back\\slash"},
 	} {
 		n, i := pm.index(v.add)
 		if v.idx != i {
@@ -63,3 +68,189 @@ func TestFileIndex(t *testing.T) {
 
 	diff(t, idx, fi)
 }
+
+func TestFileIndexExpand(t *testing.T) {
+	fi := NewFileIndex()
+	for _, v := range []string{"/usr/bin/foo", "/etc/foo.conf", "/usr/bin/bar"} {
+		fi.Add(&File{Name: v})
+	}
+
+	fi.ExpandFilenames()
+	hdr := new(Header)
+	fi.Append(hdr)
+	if hdr.Find(RPMTAG_OLDFILENAMES) == nil || hdr.Find(RPMTAG_BASENAMES) != nil {
+		t.Fatalf("expected legacy file list")
+	}
+
+	idx, err := FileIndexHeader(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := idx.Filenames(), fi.Filenames(); !reflect.DeepEqual(a, b) {
+		t.Fatalf("filenames: %q != %q", a, b)
+	}
+
+	idx.CompressFilenames()
+	hdr = new(Header)
+	idx.Append(hdr)
+	for _, v := range []TagType{
+		RPMTAG_DIRNAMES,
+		RPMTAG_BASENAMES,
+		RPMTAG_DIRINDEXES,
+	} {
+		if hdr.Find(v) == nil {
+			t.Fatalf("missing %s", v)
+		}
+	}
+	if hdr.Find(RPMTAG_OLDFILENAMES) != nil {
+		t.Fatalf("unexpected legacy file list")
+	}
+}
+
+func TestFileIndexLookup(t *testing.T) {
+	fi := NewFileIndex()
+	for _, v := range []string{"/usr/bin/foo", "./etc/foo.conf", "usr/.hidden"} {
+		fi.Add(&File{Name: v})
+	}
+	if a, b := fi.Filenames(), []string{"/usr/bin/foo", "/etc/foo.conf", "/usr/.hidden"}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("filenames: %q != %q", a, b)
+	}
+
+	for _, v := range []struct {
+		name string
+		idx  int
+	}{
+		{"/usr/bin/foo", 0},
+		{"./usr/bin/foo", 0},
+		{"usr/bin/foo", 0},
+		{"./etc/foo.conf", 1},
+		{"/etc//foo.conf", 1},
+		{".hidden", -1},
+		{"./usr/.hidden", 2},
+		{"/usr/bin", -1},
+	} {
+		i, ok := fi.Lookup(v.name)
+		if !ok {
+			i = -1
+		}
+		if i != v.idx {
+			t.Fatalf("%s: want %d, have %d", v.name, v.idx, i)
+		}
+	}
+}
+
+func TestFileIndexLongNames(t *testing.T) {
+	var names []string
+	for n := 250; n < 260; n++ {
+		names = append(names, "/usr/share/"+strings.Repeat("a", n))
+	}
+	dir := ""
+	for i := 0; i < 200; i++ {
+		dir += "/d" + strconv.Itoa(i)
+		names = append(names, dir)
+	}
+	names = append(names, dir+"/"+strings.Repeat("f", 255))
+
+	fi := NewFileIndex()
+	for _, v := range names {
+		fi.Add(&File{Name: v})
+	}
+	hdr := new(Header)
+	fi.Append(hdr)
+	hdr.SetRegion(HEADER_IMMUTABLE)
+
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(b)
+	h, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := FileIndexHeader(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := idx.Filenames(); !reflect.DeepEqual(a, names) {
+		t.Fatalf("filenames differ")
+	}
+	for i, v := range names {
+		if j, ok := idx.Lookup("." + v); !ok || j != i {
+			t.Fatalf("lookup %d: %d", len(v), j)
+		}
+	}
+}
+
+func TestFileIndexLargeFiles(t *testing.T) {
+	for _, v := range []struct {
+		sizes   []uint64
+		fileTag TagType
+		sizeTag TagType
+	}{
+		{[]uint64{1, 2}, RPMTAG_FILESIZES, RPMTAG_SIZE},
+		{[]uint64{3 << 30, 3 << 30}, RPMTAG_FILESIZES, RPMTAG_LONGSIZE},
+		{[]uint64{1, 4 << 30}, RPMTAG_LONGFILESIZES, RPMTAG_LONGSIZE},
+	} {
+		fi := NewFileIndex()
+		for i, size := range v.sizes {
+			fi.Add(&File{Name: "/f" + strconv.Itoa(i), Size: size})
+		}
+		hdr := new(Header)
+		fi.Append(hdr)
+		if hdr.Find(v.fileTag) == nil || hdr.Find(v.sizeTag) == nil {
+			t.Errorf("%d: want %s and %s", v.sizes, v.fileTag, v.sizeTag)
+		}
+		idx, err := FileIndexHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, size := range v.sizes {
+			if idx.fsize(i) != size {
+				t.Errorf("%d: size %d: %d", v.sizes, i, idx.fsize(i))
+			}
+		}
+	}
+}
+
+func TestFileIndexFiles(t *testing.T) {
+	files := []File{
+		{Name: "/etc/foo.conf", User: "root", Group: "root", Mode: 0100644, Size: 3, Flags: RPMFILE_CONFIG},
+		{Name: "/usr/bin/foo", User: "root", Group: "wheel", Mode: 0100755, Digest: "00", MTime: 1},
+		{Name: "/usr/bin/bar", User: "root", Group: "root", Mode: 0120777, LinkTo: "foo", NoVerify: 1},
+	}
+	fi := NewFileIndex()
+	for i := range files {
+		fi.Add(&files[i])
+	}
+	hdr := new(Header)
+	fi.Append(hdr)
+	fi, err := FileIndexHeader(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := fi.Files(); !reflect.DeepEqual(r, files) {
+		t.Fatalf("%+v", r)
+	}
+}
+
+func TestFileIndexHeaderInvalid(t *testing.T) {
+	for name, v := range map[string]struct {
+		dirs, bases []string
+		idx         []uint32
+	}{
+		"index":       {[]string{"/usr/"}, []string{"a"}, []uint32{5}},
+		"short index": {[]string{"/usr/"}, []string{"a", "b"}, []uint32{0}},
+		"no names":    {[]string{"/usr/"}, nil, []uint32{0}},
+	} {
+		hdr := new(Header)
+		hdr.AddStringArray(RPMTAG_DIRNAMES, v.dirs...)
+		if v.bases != nil {
+			hdr.AddStringArray(RPMTAG_BASENAMES, v.bases...)
+		}
+		hdr.AddInt32(RPMTAG_DIRINDEXES, v.idx...)
+		if _, err := FileIndexHeader(hdr); !errors.Is(err, errFileIndex) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
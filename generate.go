@@ -0,0 +1,3 @@
+package rpm
+
+//go:generate go run ./internal/gentypes -o types.gen.go internal/gentypes/rpm-6.0.0
@@ -1,3 +1,16 @@
 module github.com/pschou/go-rpm
 
-go 1.14
+go 1.22
+
+require (
+	github.com/ProtonMail/go-crypto v1.1.6
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/crypto v0.17.0
+	google.golang.org/protobuf v1.36.7
+)
+
+require (
+	github.com/cloudflare/circl v1.3.7 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
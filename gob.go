@@ -0,0 +1,63 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// The gob encodings use the rpm on-disk formats, which are stable.
+
+func (hdr *Header) GobEncode() ([]byte, error) {
+	if len(hdr.Tags) == 0 {
+		return nil, nil
+	}
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (hdr *Header) GobDecode(b []byte) error {
+	if len(b) == 0 {
+		*hdr = Header{}
+		return nil
+	}
+	h, err := NewReader(bytes.NewReader(b)).Next()
+	if err != nil {
+		return err
+	}
+	*hdr = *h
+	return nil
+}
+
+func (l *Lead) GobEncode() ([]byte, error) {
+	b := new(bytes.Buffer)
+	if _, err := l.WriteTo(b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (l *Lead) GobDecode(b []byte) error {
+	return short(binary.Read(bytes.NewReader(b), binary.BigEndian, l))
+}
+
+func (f *FileIndex) GobEncode() ([]byte, error) {
+	hdr := new(Header)
+	f.Append(hdr)
+	return hdr.GobEncode()
+}
+
+func (f *FileIndex) GobDecode(b []byte) error {
+	hdr := new(Header)
+	if err := hdr.GobDecode(b); err != nil {
+		return err
+	}
+	idx, err := FileIndexHeader(hdr)
+	if err != nil {
+		return err
+	}
+	*f = *idx
+	return nil
+}
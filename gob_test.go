@@ -0,0 +1,44 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+func TestGob(t *testing.T) {
+	p, err := ReadPackage(makePackage(t, nil))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	fi := NewFileIndex()
+	fi.Add(&File{Name: "/usr/bin/foo", Size: 3})
+	fi.Add(&File{Name: "/etc/foo", User: "foo"})
+
+	b := new(bytes.Buffer)
+	if err := gob.NewEncoder(b).Encode(p); err != nil {
+		t.Fatalf("encode package: %v", err)
+	}
+	if err := gob.NewEncoder(b).Encode(fi); err != nil {
+		t.Fatalf("encode index: %v", err)
+	}
+
+	var (
+		pd  Package
+		fid FileIndex
+	)
+	if err := gob.NewDecoder(b).Decode(&pd); err != nil {
+		t.Fatalf("decode package: %v", err)
+	}
+	if err := gob.NewDecoder(b).Decode(&fid); err != nil {
+		t.Fatalf("decode index: %v", err)
+	}
+
+	if *pd.Lead != *p.Lead {
+		t.Fatalf("lead mismatch")
+	}
+	hdrEq(t, p.Signature, pd.Signature)
+	hdrEq(t, p.Header, pd.Header)
+	diff(t, fi, &fid)
+}
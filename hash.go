@@ -0,0 +1,70 @@
+package rpm
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var errDigestAlgo = errors.New("rpm: unsupported digest algorithm")
+
+// Hash is a digest algorithm of the PGPHASHALGO_* table, used for file
+// and payload digests and header signatures.
+type Hash struct {
+	Algo   uint32 // PGPHASHALGO_*
+	Name   string
+	Crypto crypto.Hash // 0 if crypto has no such hash
+	New    func() hash.Hash
+}
+
+var (
+	hashMu sync.RWMutex
+	hashes = make(map[uint32]Hash)
+)
+
+func init() {
+	for _, v := range []Hash{
+		{PGPHASHALGO_MD5, "md5", crypto.MD5, md5.New},
+		{PGPHASHALGO_SHA1, "sha1", crypto.SHA1, sha1.New},
+		{PGPHASHALGO_SHA224, "sha224", crypto.SHA224, sha256.New224},
+		{PGPHASHALGO_SHA256, "sha256", crypto.SHA256, sha256.New},
+		{PGPHASHALGO_SHA384, "sha384", crypto.SHA384, sha512.New384},
+		{PGPHASHALGO_SHA512, "sha512", crypto.SHA512, sha512.New},
+		{PGPHASHALGO_SHA3_256, "sha3-256", crypto.SHA3_256, sha3.New256},
+		{PGPHASHALGO_SHA3_512, "sha3-512", crypto.SHA3_512, sha3.New512},
+	} {
+		RegisterHash(v)
+	}
+}
+
+// RegisterHash adds or replaces the implementation of h.Algo.
+func RegisterHash(h Hash) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	hashes[h.Algo] = h
+}
+
+// LookupHash returns the implementation of algo.
+func LookupHash(algo uint32) (Hash, bool) {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	h, ok := hashes[algo]
+	return h, ok && h.New != nil
+}
+
+// NewHash returns a new hash for algo, one of the PGPHASHALGO_*
+// constants.
+func NewHash(algo uint32) (hash.Hash, error) {
+	h, ok := LookupHash(algo)
+	if !ok {
+		return nil, errDigestAlgo
+	}
+	return h.New(), nil
+}
@@ -0,0 +1,51 @@
+package rpm
+
+import (
+	"encoding/hex"
+	"hash"
+	"hash/fnv"
+	"testing"
+)
+
+func TestHashRegistry(t *testing.T) {
+	for _, v := range []struct {
+		algo uint32
+		sum  string
+	}{
+		{PGPHASHALGO_MD5, "d41d8cd98f00b204e9800998ecf8427e"},
+		{PGPHASHALGO_SHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{PGPHASHALGO_SHA3_256, "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+	} {
+		h, err := NewHash(v.algo)
+		if err != nil {
+			t.Fatalf("%d: %v", v.algo, err)
+		}
+		if s := hex.EncodeToString(h.Sum(nil)); s != v.sum {
+			t.Errorf("%d: want %s, have %s", v.algo, v.sum, s)
+		}
+	}
+
+	const algo = 100
+	if _, err := NewHash(algo); err != errDigestAlgo {
+		t.Fatalf("unregistered: %v", err)
+	}
+	RegisterHash(Hash{Algo: algo, Name: "fnv", New: func() hash.Hash { return fnv.New32() }})
+	defer func() {
+		hashMu.Lock()
+		delete(hashes, algo)
+		hashMu.Unlock()
+	}()
+
+	d, err := NewDigest(algo)
+	if err != nil {
+		t.Fatalf("digest: %v", err)
+	}
+	d.Write([]byte("a"))
+	if s := d.Sum(); s != "050c5d7e" {
+		t.Errorf("fnv: %s", s)
+	}
+	idx := NewFileIndex()
+	if err := idx.SetDigestAlgo(algo); err != nil || idx.DigestAlgo() != algo {
+		t.Errorf("file index: %v", err)
+	}
+}
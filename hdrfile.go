@@ -0,0 +1,45 @@
+package rpm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// ReadHeader reads a header file, a single header without lead and
+// payload like the .hdr files yum caches. The header may be gzip
+// compressed and may lack the magic, see Header.Export. WriteTo
+// writes a header file.
+func ReadHeader(r io.Reader) (*Header, error) {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(2); bytes.Equal(b, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+
+	rd := NewReader(br)
+	if b, _ := br.Peek(3); !bytes.Equal(b, rpmHeaderMagic[:3]) {
+		rd.SetNoMagic(true)
+	}
+	hdr, err := rd.Next()
+	if errors.Is(err, io.EOF) {
+		err = rd.err(errUnexpectedEOF)
+	}
+	return hdr, err
+}
+
+// Export returns hdr without the magic and reserved bytes, the blob rpm
+// keeps in its database.
+func (hdr *Header) Export() ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := hdr.WriteTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes()[len(rpmHeaderMagic):], nil
+}
@@ -0,0 +1,61 @@
+package rpm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+)
+
+func TestReadHeader(t *testing.T) {
+	hdr := makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	var b bytes.Buffer
+	if _, err := hdr.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := b.Bytes()
+
+	blob, err := hdr.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blob, want[8:]) {
+		t.Fatalf("export:\n%x\n%x", blob, want)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(want)
+	zw.Close()
+
+	for _, v := range []struct {
+		name string
+		data []byte
+	}{
+		{"magic", want},
+		{"export", blob},
+		{"gzip", gz.Bytes()},
+	} {
+		h, err := ReadHeader(bytes.NewReader(v.data))
+		if err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		if h.Kind() != KindMain {
+			t.Errorf("%s: kind %v", v.name, h.Kind())
+		}
+		var out bytes.Buffer
+		if _, err := h.WriteTo(&out); err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		if !bytes.Equal(out.Bytes(), want) {
+			t.Errorf("%s: round trip:\n%x\n%x", v.name, out.Bytes(), want)
+		}
+	}
+
+	if _, err := ReadHeader(bytes.NewReader(blob[:len(blob)-1])); !errors.Is(err, errUnexpectedEOF) {
+		t.Errorf("short blob: %v", err)
+	}
+	if _, err := ReadHeader(bytes.NewReader(nil)); !errors.Is(err, errUnexpectedEOF) {
+		t.Errorf("empty: %v", err)
+	}
+}
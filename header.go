@@ -32,7 +32,55 @@ type Header struct {
 	rpmHeaderPre
 	off    uint32
 	region *Tag
+	kind   Kind
 	Tags   []*Tag
+
+	// dribble is set when tags follow the region data, old signing
+	// tools appended them after the region was sealed. The region
+	// keeps its offset and only covers the tags before it.
+	dribble bool
+
+	// dirty is set when tags were removed, the offsets are
+	// recomputed before they're used
+	dirty bool
+
+	typeCheck bool // see SetTypeCheck
+}
+
+// Kind is the role of a header in a package.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindSignature
+	KindMain
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindSignature:
+		return "signature"
+	case KindMain:
+		return "header"
+	}
+	return "unknown"
+}
+
+// Kind returns the role of hdr, from its position in the package when
+// read after a lead, otherwise from the region tag.
+func (hdr *Header) Kind() Kind {
+	if hdr.kind != KindUnknown {
+		return hdr.kind
+	}
+	if hdr.region != nil {
+		switch hdr.region.Tag {
+		case HEADER_SIGNATURES:
+			return KindSignature
+		case HEADER_IMMUTABLE:
+			return KindMain
+		}
+	}
+	return KindUnknown
 }
 
 func NewSignatureHeader() *Header {
@@ -134,6 +182,28 @@ func (hdr *Header) AddBin(tag TagType, data []byte) error {
 	})
 }
 
+func (hdr *Header) Find(tag TagType) *Tag {
+	for _, v := range hdr.Tags {
+		if v.Tag == tag {
+			return v
+		}
+	}
+	return nil
+}
+
+func (hdr *Header) StringData(tag TagType) (string, bool) {
+	if t := hdr.Find(tag); t != nil {
+		return t.StringData()
+	}
+	return "", false
+}
+
+var errRegionTag = errors.New("rpm: region tag in header tags")
+
+// SetRegion sets the region tag, HEADER_IMMUTABLE for main headers and
+// HEADER_SIGNATURES for signature headers. The region covers all tags,
+// added before or after, setting it again replaces it. Tags numbered
+// like the region can't be added.
 func (hdr *Header) SetRegion(tag TagType) {
 	hdr.region = &Tag{
 		tagHeader: tagHeader{
@@ -142,21 +212,43 @@ func (hdr *Header) SetRegion(tag TagType) {
 			Count: tagSize,
 		},
 	}
+	hdr.dribble = false
+}
+
+// regionTag returns a tag of Tags that would be read as the region or
+// shares its number.
+func (hdr *Header) regionTag() *Tag {
+	for _, v := range hdr.Tags {
+		if isRegion(v) || hdr.region != nil && v.Tag == hdr.region.Tag {
+			return v
+		}
+	}
+	return nil
 }
 
 func (hdr *Header) setRegion(pre *rpmHeaderPre) error {
 	if hdr.region == nil {
 		return nil
 	}
-	hdr.region.Offset = hdr.off
-	pre.Length += tagSize
+	n := len(hdr.Tags)
+	if hdr.dribble {
+		n = 0
+		for _, v := range hdr.Tags {
+			if v.Offset < hdr.region.Offset {
+				n++
+			}
+		}
+	} else {
+		hdr.region.Offset = hdr.off
+		pre.Length += tagSize
+	}
 	pre.Count++
 
 	data := new(bytes.Buffer)
 	if err := binary.Write(data, binary.BigEndian, &tagHeader{
 		Tag:    hdr.region.Tag,
 		Type:   RPM_BIN_TYPE,
-		Offset: uint32(-int32(len(hdr.Tags)+1) * tagSize),
+		Offset: uint32(-int32(n+1) * tagSize),
 		Count:  tagSize,
 	}); err != nil {
 		return err
@@ -166,7 +258,90 @@ func (hdr *Header) setRegion(pre *rpmHeaderPre) error {
 	return nil
 }
 
+// HasRegion reports if hdr has a region tag, minimal packages may have
+// none in either header.
+func (hdr *Header) HasRegion() bool { return hdr.region != nil }
+
+var errRegionTrailer = errors.New("rpm: invalid region trailer")
+
+// checkRegion checks the trailer of the region tag t, its data: an
+// entry like t whose negative offset counts the entries of the region,
+// t and the tags with data before it. Like librpm, a signature region
+// may name HEADER_IMAGE in its trailer, as rpm 4.0.x wrote it.
+func (hdr *Header) checkRegion(t *Tag) error {
+	b, ok := t.Bytes()
+	if !ok || len(b) < tagSize {
+		return errRegionTrailer
+	}
+	var th tagHeader
+	if err := binary.Read(bytes.NewReader(b), binary.BigEndian, &th); err != nil {
+		return err
+	}
+	n := 1
+	for _, v := range hdr.Tags {
+		if v != t && v.Offset < t.Offset {
+			n++
+		}
+	}
+	if t.Tag == HEADER_SIGNATURES && th.Tag == HEADER_IMAGE {
+		th.Tag = HEADER_SIGNATURES
+	}
+	if th.Tag != t.Tag || th.Type != RPM_BIN_TYPE || th.Count != tagSize ||
+		int32(th.Offset) != -int32(n)*tagSize {
+		return errRegionTrailer
+	}
+	return nil
+}
+
+// takeRegion moves the region tag t out of Tags, end is the end of the
+// tag data.
+func (hdr *Header) takeRegion(t *Tag, end uint32) {
+	tags := hdr.Tags[:0]
+	for _, v := range hdr.Tags {
+		if v != t {
+			tags = append(tags, v)
+		}
+	}
+	hdr.Tags = tags
+	hdr.SetRegion(t.Tag)
+	hdr.off = t.Offset
+	if n := len(tags); n > 0 && tags[n-1].Offset > t.Offset {
+		hdr.dribble = true
+		hdr.region.Offset = t.Offset
+		hdr.off = end
+	}
+}
+
+// dataOrder returns the tags in the order of their data, setRegion has
+// to be called first.
+func (hdr *Header) dataOrder() []*Tag {
+	if hdr.region == nil {
+		return hdr.Tags
+	}
+	i := len(hdr.Tags)
+	if hdr.dribble {
+		i = sort.Search(len(hdr.Tags), func(i int) bool {
+			return hdr.Tags[i].Offset > hdr.region.Offset
+		})
+	}
+	tags := make([]*Tag, 0, len(hdr.Tags)+1)
+	tags = append(tags, hdr.Tags[:i]...)
+	tags = append(tags, hdr.region)
+	return append(tags, hdr.Tags[i:]...)
+}
+
+// isRegion reports if t, the last tag in offset order, is a region tag
+// rather than a tag that happens to share its number.
+func isRegion(t *Tag) bool {
+	switch t.Tag {
+	case HEADER_IMMUTABLE, HEADER_SIGNATURES:
+		return t.Type == RPM_BIN_TYPE && t.Count == tagSize
+	}
+	return false
+}
+
 func (hdr *Header) Region() (*Tag, error) {
+	hdr.settle()
 	if err := hdr.setRegion(new(rpmHeaderPre)); err != nil {
 		return nil, err
 	}
@@ -180,14 +355,8 @@ func (hdr *Header) writeRegionHeader(w io.Writer) error {
 	return hdr.region.writeHeader(w)
 }
 
-func (hdr *Header) writeRegionData(w io.Writer) (int64, error) {
-	if hdr.region == nil {
-		return 0, nil
-	}
-	return hdr.region.data.WriteTo(w)
-}
-
 func (hdr *Header) MarshalJSON() ([]byte, error) {
+	hdr.settle()
 	if err := hdr.setRegion(new(rpmHeaderPre)); err != nil {
 		return nil, err
 	}
@@ -205,17 +374,20 @@ func (hdr *Header) UnmarshalJSON(b []byte) error {
 		return nil
 	}
 
-	// region tag should be the last tag in offset order
+	// MarshalJSON writes the region tag first, its data is last in
+	// offset order unless tags were appended after it
+	rt := hdr.Tags[0]
 	sort.Sort(hdr)
 
 	lt := hdr.Tags[len(hdr.Tags)-1]
-	switch lt.Tag {
-	case HEADER_IMMUTABLE, HEADER_SIGNATURES:
-		hdr.SetRegion(lt.Tag)
-		hdr.Tags = hdr.Tags[:len(hdr.Tags)-1]
-		hdr.off = lt.Offset
+	end := lt.Offset + uint32(lt.data.Len())
+	switch {
+	case isRegion(rt):
+		hdr.takeRegion(rt, end)
+	case isRegion(lt):
+		hdr.takeRegion(lt, end)
 	default:
-		hdr.off = lt.Offset + uint32(lt.data.Len())
+		hdr.off = end
 	}
 	return nil
 }
@@ -224,22 +396,119 @@ func (hdr *Header) align(n uint32) uint32 {
 	return (hdr.off + n) &^ n
 }
 
-// TODO: this
-var errHeaderOverflow = errors.New("rpm: header offset overflow")
+// Relayout recomputes the tag offsets and alignment padding in the
+// order of Tags, after tag data changed length or tags were removed
+// from Tags. WriteTo does it when the offsets can't be written as they
+// are. Tags appended after the region stay after it.
+func (hdr *Header) Relayout() {
+	tags, region := hdr.Tags, uint32(0)
+	if hdr.dribble {
+		region = hdr.region.Offset
+	}
+	hdr.Tags, hdr.off, hdr.dirty = nil, 0, false
+	dribble := hdr.dribble
+	hdr.dribble = false
+	for _, v := range tags {
+		if dribble && !hdr.dribble && v.Offset > region {
+			hdr.region.Offset = hdr.off
+			hdr.off += tagSize
+			hdr.dribble = true
+		}
+		hdr.add(v)
+	}
+}
 
-func (hdr *Header) Add(tag *Tag) error {
-	switch tag.Type {
+// Delete removes the tags numbered tag, it reports if there were any.
+func (hdr *Header) Delete(tag TagType) bool {
+	tags := hdr.Tags[:0]
+	for _, v := range hdr.Tags {
+		if v.Tag != tag {
+			tags = append(tags, v)
+		}
+	}
+	if len(tags) == len(hdr.Tags) {
+		return false
+	}
+	clear(hdr.Tags[len(tags):])
+	hdr.Tags = tags
+	hdr.dirty = true
+	return true
+}
+
+// settle relays out hdr if it's dirty or its offsets can't be written,
+// valid layouts, gaps included, are kept byte for byte.
+func (hdr *Header) settle() {
+	if hdr.dirty || hdr.stale() {
+		hdr.Relayout()
+		return
+	}
+	// tags moved past the end by the caller
+	for _, v := range hdr.Tags {
+		hdr.off = max(hdr.off, v.Offset+uint32(v.data.Len()))
+	}
+}
+
+// stale reports if the tag offsets, in the order of Tags, overlap or
+// are misaligned. Gaps between tag data are kept.
+func (hdr *Header) stale() bool {
+	var cur uint32
+	crossed := !hdr.dribble
+	for _, v := range hdr.Tags {
+		if !crossed && v.Offset > hdr.region.Offset {
+			if cur > hdr.region.Offset {
+				return true
+			}
+			cur, crossed = hdr.region.Offset+tagSize, true
+		}
+		if v.Offset < cur || v.Offset&alignment(v.Type) != 0 {
+			return true
+		}
+		cur = v.Offset + uint32(v.data.Len())
+	}
+	return false
+}
+
+// alignment returns the alignment mask of tag data of type t.
+func alignment(t uint32) uint32 {
+	switch t {
 	case RPM_INT16_TYPE:
-		hdr.off = hdr.align(0x1)
+		return 0x1
 	case RPM_INT32_TYPE:
-		hdr.off = hdr.align(0x3)
+		return 0x3
 	case RPM_INT64_TYPE:
-		hdr.off = hdr.align(0x7)
+		return 0x7
+	}
+	return 0
+}
+
+// TODO: this
+var errHeaderOverflow = errors.New("rpm: header offset overflow")
+
+func (hdr *Header) Add(tag *Tag) error {
+	if err := hdr.checkAdd(tag); err != nil {
+		return err
+	}
+	hdr.add(tag)
+	return nil
+}
+
+// checkAdd returns the error of Add for tag.
+func (hdr *Header) checkAdd(tag *Tag) error {
+	if isRegion(tag) || hdr.region != nil && tag.Tag == hdr.region.Tag {
+		return tagError{tag, errRegionTag}
 	}
+	if hdr.typeCheck && tagMismatch(hdr.Kind(), tag) != "" {
+		return tagError{tag, errTagMismatch}
+	}
+	return nil
+}
+
+// add appends tag after the data of the others.
+func (hdr *Header) add(tag *Tag) {
+	hdr.off = hdr.align(alignment(tag.Type))
 	tag.Offset = hdr.off
 	hdr.off += uint32(tag.data.Len())
 	hdr.Tags = append(hdr.Tags, tag)
-	return nil
 }
 
 const zs = 8
@@ -248,15 +517,23 @@ var zb [zs]byte
 
 var errInvalidOffset = errors.New("rpm: invalid tag offset")
 
-func (hdr *Header) pad(w io.Writer, off uint32, cur int64) (int, error) {
-	if int64(off) < cur {
+// pad writes the zeros between tag data ending at cur and the next at
+// off, alignment or a gap left by the caller. Data can't overlap.
+func (hdr *Header) pad(w io.Writer, off uint32, cur int64) (int64, error) {
+	n := int64(off) - cur
+	if n < 0 {
 		return 0, errInvalidOffset
 	}
-	if n := int64(off) - cur; n > zs {
-		return 0, errInvalidOffset
-	} else {
-		return w.Write(zb[:n])
+	var r int64
+	for n > 0 {
+		m, err := w.Write(zb[:min(n, zs)])
+		r += int64(m)
+		n -= int64(m)
+		if err != nil {
+			return r, err
+		}
 	}
+	return r, nil
 }
 
 var (
@@ -268,6 +545,10 @@ func (hdr *Header) WriteTo(w io.Writer) (int64, error) {
 	if len(hdr.Tags) == 0 {
 		return 0, errNoTags
 	}
+	if t := hdr.regionTag(); t != nil {
+		return 0, tagError{t, errRegionTag}
+	}
+	hdr.settle()
 
 	pre := &rpmHeaderPre{
 		Magic:  rpmHeaderMagic,
@@ -277,70 +558,76 @@ func (hdr *Header) WriteTo(w io.Writer) (int64, error) {
 	if err := hdr.setRegion(pre); err != nil {
 		return 0, err
 	}
-	if err := binary.Write(w, binary.BigEndian, pre); err != nil {
-		return 0, err
+
+	cw := &countWriter{w: w}
+	if err := binary.Write(cw, binary.BigEndian, pre); err != nil {
+		return cw.n, err
 	}
 
 	// "region tag" needs to get written out first
-	if err := hdr.writeRegionHeader(w); err != nil {
-		return 0, err
+	if err := hdr.writeRegionHeader(cw); err != nil {
+		return cw.n, err
 	}
 
 	// write out tags and data in offset order
 	sort.Sort(hdr)
 
 	for _, v := range hdr.Tags {
-		if err := v.writeHeader(w); err != nil {
-			return 0, err
+		if err := v.writeHeader(cw); err != nil {
+			return cw.n, err
 		}
 	}
 
 	var cur int64
-	for _, v := range hdr.Tags {
-		n1, err := hdr.pad(w, v.Offset, cur)
+	for _, v := range hdr.dataOrder() {
+		n1, err := hdr.pad(cw, v.Offset, cur)
 		if err != nil {
-			return 0, err
+			return cw.n, err
 		}
 
-		n2, err := v.data.WriteTo(w)
+		n2, err := v.data.WriteTo(cw)
 		if err != nil {
-			return 0, err
+			return cw.n, err
 		}
 
-		cur += int64(n1) + n2
+		cur += n1 + n2
 	}
-
-	n, err := hdr.writeRegionData(w)
+	// a gap after the last tag data
+	n, err := hdr.pad(cw, pre.Length, cur)
 	if err != nil {
-		return 0, err
+		return cw.n, err
 	}
+	cur += n
 
-	if n+cur != int64(pre.Length) {
-		return 0, errDataLen
+	if cur != int64(pre.Length) {
+		return cw.n, errDataLen
 	}
+	return cw.n, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
 
-	r := pre.Count * tagSize
-	r += pre.Length
-	r += 16 // len(pre)
-	return int64(r), nil
+func (c *countWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
 }
 
 func WriteHeaders(w io.Writer, hdr ...io.WriterTo) (int64, error) {
-	var r int64
+	cw := &countWriter{w: w}
 	for _, v := range hdr {
-		// headers need to be 8b aligned
-		p := (r + 0x7) &^ 0x7
-		a, err := w.Write(zb[:p-r])
-		if err != nil {
-			return 0, err
+		// headers need to be 8b aligned, padding is computed from
+		// the bytes actually written instead of what WriteTo returns
+		p := (cw.n + 0x7) &^ 0x7
+		if _, err := cw.Write(zb[:p-cw.n]); err != nil {
+			return cw.n, err
 		}
-		r += int64(a)
-
-		n, err := v.WriteTo(w)
-		if err != nil {
-			return 0, err
+		if _, err := v.WriteTo(cw); err != nil {
+			return cw.n, err
 		}
-		r += n
 	}
-	return r, nil
+	return cw.n, nil
 }
@@ -5,7 +5,11 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -181,3 +185,312 @@ func TestWriteHeaders(t *testing.T) {
 		})
 	}
 }
+
+type badWriterTo struct{ io.WriterTo }
+
+func (b badWriterTo) WriteTo(w io.Writer) (int64, error) {
+	n, err := b.WriterTo.WriteTo(w)
+	return n + 3, err
+}
+
+func TestWriteHeadersAlign(t *testing.T) {
+	for i := 1; i <= 8; i++ {
+		t.Run("sig+"+strconv.Itoa(i), func(t *testing.T) {
+			sig := NewSignatureHeader()
+			sig.AddString(RPMSIGTAG_SHA256, strings.Repeat("x", i))
+			hdr := makeHdr()
+			hdr.SetRegion(HEADER_IMMUTABLE)
+
+			b := new(bytes.Buffer)
+			n, err := WriteHeaders(b,
+				NewLead("test", LeadBinary), badWriterTo{sig}, hdr,
+			)
+			if err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if a, b := n, int64(b.Len()); a != b {
+				t.Fatalf("length: want %d, have %d", b, a)
+			}
+
+			p, err := ReadPackage(b)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			l := p.Layout()
+			if l.Header.Off&0x7 != 0 {
+				t.Fatalf("header offset not aligned: 0x%x", l.Header.Off)
+			}
+			if a, b := l.Padding.Len, -l.Signature.End()&0x7; a != b {
+				t.Fatalf("padding: want %d, have %d", b, a)
+			}
+		})
+	}
+}
+
+func TestHeaderPartialWrite(t *testing.T) {
+	hdr := makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	testPartialWrite(t, hdr)
+	testPartialWrite(t, NewLead("test", LeadBinary))
+}
+
+func TestHeaderNoRegion(t *testing.T) {
+	sig := new(Header)
+	sig.AddString(RPMSIGTAG_SHA256, "odd")
+	// shares the region tag number but isn't one
+	sig.AddString(HEADER_SIGNATURES, "not a region")
+	hdr := makeHdr()
+
+	b := new(bytes.Buffer)
+	if _, err := WriteHeaders(b, NewLead("test", LeadBinary), sig, hdr); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data := append([]byte(nil), b.Bytes()...)
+
+	p, err := ReadPackage(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, v := range []*Header{p.Signature, p.Header} {
+		if v.HasRegion() {
+			t.Fatalf("%s: unexpected region", v.Kind())
+		}
+		testHeaderJSON(t, v)
+	}
+	if n := p.Signature.Len(); n != 2 {
+		t.Fatalf("signature tags: %d", n)
+	}
+
+	// rewrites byte for byte
+	b.Reset()
+	if _, err := WriteHeaders(b, NewLead("test", LeadBinary), p.Signature, p.Header); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if !bytes.Equal(b.Bytes(), data) {
+		t.Fatalf("rewrite mismatch\n%s\n%s", hex.Dump(b.Bytes()), hex.Dump(data))
+	}
+}
+
+func TestHeaderDribble(t *testing.T) {
+	// a region of two tags followed by one appended after it
+	b := new(bytes.Buffer)
+	binary.Write(b, binary.BigEndian, &rpmHeaderPre{rpmHeaderMagic, 4, 28})
+	for _, v := range []tagHeader{
+		{HEADER_IMMUTABLE, RPM_BIN_TYPE, 8, tagSize},
+		{RPMTAG_NAME, RPM_STRING_TYPE, 0, 1},
+		{RPMTAG_EPOCH, RPM_INT32_TYPE, 4, 1},
+		{RPMTAG_SOURCERPM, RPM_STRING_TYPE, 24, 1},
+	} {
+		binary.Write(b, binary.BigEndian, &v)
+	}
+	b.WriteString("foo\x00")
+	binary.Write(b, binary.BigEndian, uint32(1))
+	trailer := int32(-3 * tagSize)
+	binary.Write(b, binary.BigEndian, &tagHeader{HEADER_IMMUTABLE, RPM_BIN_TYPE, uint32(trailer), tagSize})
+	b.WriteString("bar\x00")
+	data := append([]byte(nil), b.Bytes()...)
+
+	hdr, err := NewReader(b).Next()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !hdr.HasRegion() || hdr.Kind() != KindMain || hdr.Len() != 3 {
+		t.Fatalf("region %v, kind %s, %d tags", hdr.HasRegion(), hdr.Kind(), hdr.Len())
+	}
+	if s, _ := hdr.StringData(RPMTAG_SOURCERPM); s != "bar" {
+		t.Fatalf("appended tag: %q", s)
+	}
+
+	rewrite := func() []byte {
+		t.Helper()
+		b := new(bytes.Buffer)
+		if _, err := hdr.WriteTo(b); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return b.Bytes()
+	}
+	if b := rewrite(); !bytes.Equal(b, data) {
+		t.Fatalf("rewrite mismatch\n%s\n%s", hex.Dump(b), hex.Dump(data))
+	}
+	testHeaderJSON(t, hdr)
+
+	var tags []TagType
+	for _, v := range hdr.SizeBreakdown() {
+		tags = append(tags, v.Tag)
+	}
+	if want := []TagType{RPMTAG_NAME, RPMTAG_EPOCH, HEADER_IMMUTABLE, RPMTAG_SOURCERPM}; !slices.Equal(tags, want) {
+		t.Fatalf("size breakdown %v, want %v", tags, want)
+	}
+
+	// tags added later are appended too, the region is unchanged
+	hdr.AddInt64(RPMTAG_LONGSIZE, 1)
+	have, err := NewReader(bytes.NewReader(rewrite())).Next()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if have.Len() != 4 || have.Length != 40 {
+		t.Fatalf("%d tags, length %d", have.Len(), have.Length)
+	}
+	// the data of the region and its trailer, after one more index entry
+	if b := rewrite(); !bytes.Equal(b[96:120], data[80:104]) {
+		t.Fatalf("region changed\n%s", hex.Dump(b))
+	}
+
+	// the region shrinks, the appended tags stay after it
+	hdr.Delete(RPMTAG_EPOCH)
+	have, err = NewReader(bytes.NewReader(rewrite())).Next()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !have.dribble || have.region.Offset != 4 || have.Len() != 3 {
+		t.Fatalf("region at %d, %d tags", have.region.Offset, have.Len())
+	}
+	if w := lintDribble(have); len(w) != 1 || w[0].Code != "dribble" {
+		t.Fatalf("lint: %v", w)
+	}
+}
+
+func TestRelayout(t *testing.T) {
+	roundTrip := func(hdr *Header) *Header {
+		t.Helper()
+		b := new(bytes.Buffer)
+		if _, err := hdr.WriteTo(b); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		have, err := NewReader(b).Next()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return have
+	}
+
+	hdr := makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	// data grows in place, the offsets after it are stale
+	t1 := hdr.Find(1)
+	t1.data = &tagString{data: []string{"a much longer string"}}
+	if !hdr.stale() {
+		t.Fatalf("not stale")
+	}
+	have := roundTrip(hdr)
+	if s, _ := have.StringData(1); s != "a much longer string" {
+		t.Fatalf("tag 1: %q", s)
+	}
+	if v, _ := have.Find(5).Int64(); len(v) != 3 || v[0] != 0x1122334455667788 {
+		t.Fatalf("tag 5: %x", v)
+	}
+
+	if !hdr.Delete(3) || hdr.Delete(3) {
+		t.Fatalf("delete")
+	}
+	have = roundTrip(hdr)
+	if have.Len() != hdr.Len() || have.Find(3) != nil || have.Find(4) == nil {
+		t.Fatalf("%d tags after delete", have.Len())
+	}
+	if hdr.dirty || hdr.stale() || have.stale() {
+		t.Fatalf("stale after write")
+	}
+}
+
+func TestHeaderRegionTag(t *testing.T) {
+	trailer := make([]byte, tagSize)
+
+	hdr := NewPayloadHeader()
+	if err := hdr.AddBin(HEADER_IMMUTABLE, trailer); !errors.Is(err, errRegionTag) {
+		t.Fatalf("region tag added: %v", err)
+	}
+	if err := hdr.AddString(HEADER_IMMUTABLE, "foo"); !errors.Is(err, errRegionTag) {
+		t.Fatalf("region number added: %v", err)
+	}
+	// only a region elsewhere would be read as one
+	sig := new(Header)
+	if err := sig.AddBin(HEADER_SIGNATURES, trailer); !errors.Is(err, errRegionTag) {
+		t.Fatalf("region tag added: %v", err)
+	}
+	if err := sig.AddString(HEADER_SIGNATURES, "foo"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	sig.SetRegion(HEADER_SIGNATURES)
+	if _, err := sig.WriteTo(io.Discard); !errors.Is(err, errRegionTag) {
+		t.Fatalf("region number written: %v", err)
+	}
+
+	// the last region set covers the tags added before
+	hdr = makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	hdr.SetRegion(HEADER_SIGNATURES)
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	have, err := NewReader(b).Next()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if have.Kind() != KindSignature || have.Len() != hdr.Len() || have.dribble {
+		t.Fatalf("kind %s, %d tags", have.Kind(), have.Len())
+	}
+}
+
+func TestHeaderGaps(t *testing.T) {
+	for _, v := range []struct {
+		name    string
+		offsets []uint32 // of tags 1, 2 and 3: "a", int16 and int32
+		relaid  bool
+	}{
+		{"packed", []uint32{0, 2, 4}, false},
+		{"aligned", []uint32{0, 2, 8}, false},
+		{"gap of 8", []uint32{0, 10, 12}, false},
+		{"gap of 100", []uint32{0, 102, 104}, false},
+		{"gap at start", []uint32{20, 22, 24}, false},
+		{"overlap", []uint32{0, 0, 4}, true},
+		{"misaligned int16", []uint32{0, 3, 8}, true},
+		{"misaligned int32", []uint32{0, 2, 6}, true},
+		{"out of order", []uint32{8, 0, 4}, true},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			for _, region := range []bool{false, true} {
+				hdr := new(Header)
+				hdr.AddString(1, "a")
+				hdr.AddInt16(2, 0x1122)
+				hdr.AddInt32(3, 0x33445566)
+				if region {
+					hdr.SetRegion(HEADER_IMMUTABLE)
+				}
+				for i, off := range v.offsets {
+					hdr.Tags[i].Offset = off
+				}
+
+				b := new(bytes.Buffer)
+				if _, err := hdr.WriteTo(b); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+				have, err := NewReader(bytes.NewReader(b.Bytes())).Next()
+				if err != nil {
+					t.Fatalf("read: %v", err)
+				}
+				if v, _ := have.Find(3).Int32(); len(v) != 1 || v[0] != 0x33445566 {
+					t.Fatalf("tag 3: %x", v)
+				}
+				if have.HasRegion() != region {
+					t.Fatalf("region %v", have.HasRegion())
+				}
+				kept := true
+				for i, off := range v.offsets {
+					kept = kept && hdr.Find(TagType(i+1)).Offset == off
+				}
+				if kept == v.relaid {
+					t.Fatalf("offsets kept %v", kept)
+				}
+
+				rb := new(bytes.Buffer)
+				if _, err := have.WriteTo(rb); err != nil {
+					t.Fatalf("rewrite: %v", err)
+				}
+				if !bytes.Equal(rb.Bytes(), b.Bytes()) {
+					t.Fatalf("rewrite mismatch\n%s\n%s", hex.Dump(rb.Bytes()), hex.Dump(b.Bytes()))
+				}
+			}
+		})
+	}
+}
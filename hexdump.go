@@ -0,0 +1,99 @@
+package rpm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// peeker is a reader that buffers, like *bufio.Reader.
+type peeker interface {
+	Buffered() int
+	Peek(n int) ([]byte, error)
+}
+
+type histReader struct {
+	r    io.Reader
+	peek peeker // the underlying reader if it buffers
+	buf  []byte
+	max  int
+	off  int64
+}
+
+func (h *histReader) Read(b []byte) (int, error) {
+	n, err := h.r.Read(b)
+	h.off += int64(n)
+	h.buf = append(h.buf, b[:n]...)
+	if i := len(h.buf) - h.max; i > 0 {
+		h.buf = h.buf[:copy(h.buf, h.buf[i:])]
+	}
+	return n, err
+}
+
+// DumpError carries the bytes read before and after a parse error.
+type DumpError struct {
+	Off  int64 // offset of Data[0]
+	Pos  int64 // offset where reading stopped
+	Data []byte
+	Err  error
+}
+
+func (e *DumpError) Error() string { return e.Err.Error() }
+func (e *DumpError) Unwrap() error { return e.Err }
+
+func (e *DumpError) Hexdump() string {
+	var b strings.Builder
+	for i := 0; i < len(e.Data); i += 16 {
+		j := i + 16
+		if j > len(e.Data) {
+			j = len(e.Data)
+		}
+		line := e.Data[i:j]
+
+		mark := ' '
+		if p := e.Pos - e.Off; p >= int64(i) && p < int64(j) {
+			mark = '>'
+		}
+		fmt.Fprintf(&b, "%c%08x  % -47x  |", mark, e.Off+int64(i), line)
+		for _, c := range line {
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		b.WriteString("|\n")
+	}
+	return b.String()
+}
+
+// SetHexdump makes errors returned by r carry a *DumpError with up to
+// n bytes before the failing position. The bytes after it are only
+// those already buffered by the underlying reader, a *bufio.Reader for
+// example, nothing more is read.
+func (r *Reader) SetHexdump(n int) {
+	if n <= 0 {
+		return
+	}
+	h := &histReader{r: r.r, max: n}
+	h.peek, _ = r.cr.r.(peeker)
+	r.r, r.lr.R, r.hist = h, h, h
+}
+
+func (r *Reader) dump(err error) error {
+	h := r.hist
+	if h == nil || err == nil {
+		return err
+	}
+	e := &DumpError{
+		Off: h.off - int64(len(h.buf)),
+		Pos: h.off,
+		Err: err,
+	}
+	e.Data = append(e.Data, h.buf...)
+	if h.peek != nil && !errors.Is(err, io.EOF) {
+		after, _ := h.peek.Peek(min(h.max, h.peek.Buffered()))
+		e.Data = append(e.Data, after...)
+	}
+	return e
+}
@@ -0,0 +1,86 @@
+package rpm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHexdump(t *testing.T) {
+	b := makePackage(t, nil)
+	data := b.Bytes()
+	// corrupt the signature header magic
+	copy(data[96:], "\xde\xad\xbe\xef")
+
+	r := NewReader(bufio.NewReader(bytes.NewReader(data)))
+	r.SetHexdump(32)
+	if _, err := r.Lead(); err != nil {
+		t.Fatalf("lead: %v", err)
+	}
+
+	_, err := r.Next()
+	if !errors.Is(err, errInvalidHeader) {
+		t.Fatalf("expected header error, got: %v", err)
+	}
+
+	var de *DumpError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected dump error, got: %T", err)
+	}
+	if a, b := de.Off, int64(96+16-32); a != b {
+		t.Fatalf("dump offset: want %d, have %d", b, a)
+	}
+	if a, b := de.Data, data[de.Off:de.Off+64]; !bytes.Equal(a, b) {
+		t.Fatalf("dump data:\n%x\n%x", a, b)
+	}
+
+	d := de.Hexdump()
+	if !strings.Contains(d, ">00000070  ") {
+		t.Fatalf("missing position marker:\n%s", d)
+	}
+	if !strings.Contains(d, "de ad be ef") {
+		t.Fatalf("missing magic:\n%s", d)
+	}
+}
+
+func TestHexdumpUnbuffered(t *testing.T) {
+	b := makePackage(t, nil)
+	data := b.Bytes()
+	copy(data[96:], "\xde\xad\xbe\xef")
+
+	// nothing after the failing position is read
+	br := bytes.NewReader(data)
+	r := NewReader(br)
+	r.SetHexdump(32)
+	r.Lead()
+	_, err := r.Next()
+	var de *DumpError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected dump error, got: %v", err)
+	}
+	if a, b := de.Data, data[de.Off:de.Pos]; !bytes.Equal(a, b) {
+		t.Fatalf("dump data:\n%x\n%x", a, b)
+	}
+	if a, b := br.Len(), len(data)-int(de.Pos); a != b {
+		t.Fatalf("read ahead: %d bytes left, want %d", a, b)
+	}
+
+	// nor at the end of the input
+	r = NewReader(bufio.NewReader(bytes.NewReader(data[:96])))
+	r.SetHexdump(32)
+	r.Lead()
+	if _, err := r.Next(); !errors.As(err, &de) || !errors.Is(err, io.EOF) || int64(len(de.Data)) != de.Pos-de.Off {
+		t.Fatalf("end of input: %v", err)
+	}
+}
+
+func TestHexdumpDisabled(t *testing.T) {
+	_, err := NewReader(strings.NewReader("short")).Lead()
+	var de *DumpError
+	if errors.As(err, &de) {
+		t.Fatalf("unexpected dump error")
+	}
+}
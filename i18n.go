@@ -0,0 +1,81 @@
+package rpm
+
+// I18NTable returns the locales of RPMTAG_HEADERI18NTABLE, "C" if the
+// header has none.
+func (hdr *Header) I18NTable() []string {
+	if t := hdr.Find(RPMTAG_HEADERI18NTABLE); t != nil {
+		if r, ok := t.StringArray(); ok && len(r) > 0 {
+			return r
+		}
+	}
+	return []string{"C"}
+}
+
+func (hdr *Header) setI18NTable(locales []string) {
+	t := hdr.Find(RPMTAG_HEADERI18NTABLE)
+	if t == nil {
+		hdr.AddStringArray(RPMTAG_HEADERI18NTABLE, locales...)
+		return
+	}
+	t.Count = uint32(len(locales))
+	t.data = &tagString{data: locales}
+	hdr.Relayout()
+}
+
+// i18n remaps the I18N strings of tags from src to the locale table of
+// hdr, locales only found in src are appended to the table of hdr.
+// Missing translations fall back to the first, "C", value as in rpm.
+func (hdr *Header) i18n(src *Header, tags []*Tag) []*Tag {
+	dl, sl := hdr.I18NTable(), src.I18NTable()
+
+	idx := make(map[string]int, len(dl))
+	for i, v := range dl {
+		idx[v] = i
+	}
+	table := dl
+	for _, v := range sl {
+		if _, ok := idx[v]; !ok {
+			idx[v] = len(table)
+			table = append(table, v)
+		}
+	}
+	if len(table) != len(dl) {
+		hdr.setI18NTable(table)
+	}
+
+	r := make([]*Tag, len(tags))
+	for i, v := range tags {
+		s, ok := v.StringArray()
+		if v.Type != RPM_I18NSTRING_TYPE || !ok || len(s) == 0 {
+			r[i] = v
+			continue
+		}
+
+		// only as long as the last translation in src
+		var n int
+		for j, l := range sl {
+			if j < len(s) && idx[l]+1 > n {
+				n = idx[l] + 1
+			}
+		}
+		data := make([]string, n)
+		for j := range data {
+			data[j] = s[0]
+		}
+		for j, l := range sl {
+			if j < len(s) {
+				data[idx[l]] = s[j]
+			}
+		}
+
+		r[i] = &Tag{
+			tagHeader: tagHeader{
+				Tag:   v.Tag,
+				Type:  RPM_I18NSTRING_TYPE,
+				Count: uint32(n),
+			},
+			data: &tagString{data: data},
+		}
+	}
+	return r
+}
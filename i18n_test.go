@@ -0,0 +1,61 @@
+package rpm
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func i18nHdr(locales []string, data ...string) *Header {
+	hdr := new(Header)
+	hdr.AddStringArray(RPMTAG_HEADERI18NTABLE, locales...)
+	hdr.Add(&Tag{
+		tagHeader: tagHeader{
+			Tag:   RPMTAG_SUMMARY,
+			Type:  RPM_I18NSTRING_TYPE,
+			Count: uint32(len(data)),
+		},
+		data: &tagString{data: data},
+	})
+	return hdr
+}
+
+func TestI18N(t *testing.T) {
+	dst := i18nHdr([]string{"C", "de"}, "summary", "zusammenfassung")
+	src := i18nHdr([]string{"C", "fi", "de"}, "desc", "kuvaus", "beschreibung")
+
+	tags := dst.i18n(src, src.Tags[1:])
+
+	if a, b := dst.I18NTable(), []string{"C", "de", "fi"}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("table: want %q, have %q", b, a)
+	}
+
+	s, _ := tags[0].StringArray()
+	if a, b := s, []string{"desc", "beschreibung", "kuvaus"}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("data: want %q, have %q", b, a)
+	}
+	if tags[0].Count != 3 {
+		t.Fatalf("count: %d", tags[0].Count)
+	}
+
+	// table offsets were recomputed after growing
+	dst.Add(tags[0])
+	if _, err := dst.WriteTo(ioutil.Discard); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestI18NFallback(t *testing.T) {
+	dst := new(Header)
+	dst.AddStringI18N(RPMTAG_SUMMARY, "summary")
+	src := i18nHdr([]string{"C", "fi"}, "desc")
+
+	tags := dst.i18n(src, src.Tags[1:])
+	s, _ := tags[0].StringArray()
+	if a, b := s, []string{"desc"}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("data: want %q, have %q", b, a)
+	}
+	if a, b := dst.I18NTable(), []string{"C", "fi"}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("table: want %q, have %q", b, a)
+	}
+}
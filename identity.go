@@ -0,0 +1,29 @@
+package rpm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Identity returns the hex SHA256 of the serialized header, the same
+// digest stored in RPMSIGTAG_SHA256.
+func Identity(hdr *Header) (string, error) {
+	h := sha256.New()
+	if _, err := hdr.WriteTo(h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// PkgID returns the hex header+payload MD5 from the signature header.
+func PkgID(sig *Header) (string, bool) {
+	t := sig.Find(RPMSIGTAG_MD5)
+	if t == nil {
+		return "", false
+	}
+	b, ok := t.Bytes()
+	if !ok || len(b) != 16 {
+		return "", false
+	}
+	return hex.EncodeToString(b), true
+}
@@ -0,0 +1,48 @@
+package rpm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	b := makePackage(t, []byte("payload"))
+	data := b.Bytes()
+
+	p, err := ReadPackage(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	id, err := Identity(p.Header)
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+
+	l := p.Layout()
+	sum := sha256.Sum256(data[l.Header.Off:l.Header.End()])
+	if want := hex.EncodeToString(sum[:]); id != want {
+		t.Fatalf("identity: want %s, have %s", want, id)
+	}
+
+	hdr := makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	if a, err := Identity(hdr); err != nil || a != id {
+		t.Fatalf("identity mismatch: %s != %s, %v", a, id, err)
+	}
+}
+
+func TestPkgID(t *testing.T) {
+	sig := NewSignatureHeader()
+	if _, ok := PkgID(sig); ok {
+		t.Fatalf("unexpected pkgid")
+	}
+	md5 := bytes.Repeat([]byte{0xab}, 16)
+	sig.AddBin(RPMSIGTAG_MD5, md5)
+	id, ok := PkgID(sig)
+	if !ok || id != hex.EncodeToString(md5) {
+		t.Fatalf("pkgid: %s", id)
+	}
+}
@@ -0,0 +1,27 @@
+package rpm
+
+// PkgInfo is a summary of a package for inventory and compliance tools.
+type PkgInfo struct {
+	Name string
+	EVR
+	Arch    string
+	Summary string
+
+	// License is RPMTAG_LICENSE as is, LicenseExpression its SPDX
+	// form or empty if it can't be normalized.
+	License           string
+	LicenseExpression string `json:",omitempty"`
+}
+
+// PkgInfoHeader returns the PkgInfo of hdr.
+func PkgInfoHeader(hdr *Header) PkgInfo {
+	r := PkgInfo{EVR: HeaderEVR(hdr)}
+	r.Name, _ = hdr.StringData(RPMTAG_NAME)
+	r.Arch, _ = hdr.StringData(RPMTAG_ARCH)
+	r.Summary, _ = hdr.StringData(RPMTAG_SUMMARY)
+	r.License, _ = hdr.StringData(RPMTAG_LICENSE)
+	if r.License != "" {
+		r.LicenseExpression, _ = NormalizeLicense(r.License)
+	}
+	return r
+}
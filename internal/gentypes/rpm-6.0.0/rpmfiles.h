@@ -0,0 +1,38 @@
+/* Excerpt of lib/rpmfiles.h from rpm-6.0.0, only the parts read by gentypes. */
+
+enum rpmfileAttrs_e {
+    RPMFILE_NONE	= 0,
+    RPMFILE_CONFIG	= (1 <<  0),	/*!< from %%config */
+    RPMFILE_DOC		= (1 <<  1),	/*!< from %%doc */
+    RPMFILE_ICON	= (1 <<  2),	/*!< from %%donotuse. */
+    RPMFILE_MISSINGOK	= (1 <<  3),	/*!< from %%config(missingok) */
+    RPMFILE_NOREPLACE	= (1 <<  4),	/*!< from %%config(noreplace) */
+    RPMFILE_SPECFILE	= (1 <<  5),	/*!< @todo (unnecessary) marks 1st file in srpm. */
+    RPMFILE_GHOST	= (1 <<  6),	/*!< from %%ghost */
+    RPMFILE_LICENSE	= (1 <<  7),	/*!< from %%license */
+    RPMFILE_README	= (1 <<  8),	/*!< from %%readme */
+    /* bits 9-10 unused */
+    RPMFILE_PUBKEY	= (1 << 11),	/*!< from %%pubkey */
+    RPMFILE_ARTIFACT	= (1 << 12),	/*!< from %%artifact */
+};
+
+enum rpmVerifyAttrs_e {
+    RPMVERIFY_NONE	= 0,		/*!< */
+    RPMVERIFY_FILEDIGEST = (1 << 0),	/*!< from %verify(filedigest) */
+    RPMVERIFY_FILESIZE	= (1 << 1),	/*!< from %verify(size) */
+    RPMVERIFY_LINKTO	= (1 << 2),	/*!< from %verify(link) */
+    RPMVERIFY_USER	= (1 << 3),	/*!< from %verify(user) */
+    RPMVERIFY_GROUP	= (1 << 4),	/*!< from %verify(group) */
+    RPMVERIFY_MTIME	= (1 << 5),	/*!< from %verify(mtime) */
+    RPMVERIFY_MODE	= (1 << 6),	/*!< from %verify(mode) */
+    RPMVERIFY_RDEV	= (1 << 7),	/*!< from %verify(rdev) */
+    RPMVERIFY_CAPS	= (1 << 8),	/*!< from %verify(caps) */
+    /* bits 9-14 unused, reserved for rpmVerifyAttrs */
+    RPMVERIFY_CONTEXTS	= (1 << 15),	/*!< verify: from --nocontexts */
+    /* bits 16-22 used in rpmVerifyFlags */
+    /* bits 23-27 used in rpmQueryFlags */
+    RPMVERIFY_READLINKFAIL= (1 << 28),	/*!< readlink failed */
+    RPMVERIFY_READFAIL	= (1 << 29),	/*!< file read failed */
+    RPMVERIFY_LSTATFAIL	= (1 << 30),	/*!< lstat failed */
+    RPMVERIFY_LGETFILECONFAIL	= (1 << 31)	/*!< lgetfilecon failed */
+};
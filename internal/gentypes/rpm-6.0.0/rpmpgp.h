@@ -0,0 +1,16 @@
+/* Excerpt of include/rpm/rpmpgp.h from rpm-6.0.0, only the parts read by gentypes. */
+
+typedef enum pgpHashAlgo_e {
+    PGPHASHALGO_MD5		=  1,	/*!< MD5 */
+    PGPHASHALGO_SHA1		=  2,	/*!< SHA1 */
+    PGPHASHALGO_RIPEMD160	=  3,	/*!< RIPEMD160 */
+    PGPHASHALGO_MD2		=  5,	/*!< MD2 */
+    PGPHASHALGO_TIGER192	=  6,	/*!< TIGER192 */
+    PGPHASHALGO_HAVAL_5_160	=  7,	/*!< HAVAL-5-160 */
+    PGPHASHALGO_SHA256		=  8,	/*!< SHA256 */
+    PGPHASHALGO_SHA384		=  9,	/*!< SHA384 */
+    PGPHASHALGO_SHA512		= 10,	/*!< SHA512 */
+    PGPHASHALGO_SHA224		= 11,	/*!< SHA224 */
+    PGPHASHALGO_SHA3_256	= 12,	/*!< SHA3-256 */
+    PGPHASHALGO_SHA3_512	= 14,	/*!< SHA3-512 */
+} pgpHashAlgo;
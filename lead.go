@@ -3,8 +3,11 @@ package rpm
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
+	"strconv"
 )
 
 var leadMagic = [...]byte{0xed, 0xab, 0xee, 0xdb}
@@ -16,6 +19,40 @@ const (
 	LeadSource
 )
 
+func (t LeadType) String() string {
+	switch t {
+	case LeadBinary:
+		return "binary"
+	case LeadSource:
+		return "source"
+	}
+	return strconv.Itoa(int(t))
+}
+
+func (t LeadType) MarshalJSON() ([]byte, error) {
+	switch t {
+	case LeadBinary, LeadSource:
+		return json.Marshal(t.String())
+	}
+	return json.Marshal(uint16(t))
+}
+
+func (t *LeadType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return json.Unmarshal(b, (*uint16)(t))
+	}
+	switch s {
+	case "binary":
+		*t = LeadBinary
+	case "source":
+		*t = LeadSource
+	default:
+		return fmt.Errorf("rpm: invalid lead type: %q", s)
+	}
+	return nil
+}
+
 type leadName [66]byte
 
 func (l leadName) MarshalJSON() ([]byte, error) {
@@ -31,7 +68,12 @@ func (l *leadName) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &name); err != nil {
 		return err
 	}
-	copy((*l)[:], name)
+	// NUL terminated
+	if len(name) >= len(l) {
+		return fmt.Errorf("rpm: lead name longer than %d bytes: %q", len(l)-1, name)
+	}
+	*l = leadName{}
+	copy(l[:], name)
 	return nil
 }
 
@@ -48,6 +90,82 @@ type Lead struct {
 	_ [16]byte
 }
 
+// leadCanon is an arch or os number formatted with its canonical name.
+type leadCanon struct {
+	c   []canon
+	num *uint16
+}
+
+func (l leadCanon) MarshalJSON() ([]byte, error) {
+	if v, ok := canonName(l.c, *l.num); ok {
+		return json.Marshal(v)
+	}
+	return json.Marshal(*l.num)
+}
+
+func (l leadCanon) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return json.Unmarshal(b, l.num)
+	}
+	v, ok := canonNum(l.c, s)
+	if !ok {
+		return fmt.Errorf("rpm: unknown lead arch/os: %q", s)
+	}
+	*l.num = v
+	return nil
+}
+
+type leadMagicJSON [4]byte
+
+func (m leadMagicJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(m[:]))
+}
+
+func (m *leadMagicJSON) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return json.Unmarshal(b, (*[4]byte)(m))
+	}
+	if hex.DecodedLen(len(s)) != len(m) {
+		return fmt.Errorf("rpm: invalid lead magic: %q", s)
+	}
+	_, err := hex.Decode(m[:], []byte(s))
+	return err
+}
+
+type jsonLead struct {
+	Magic         *leadMagicJSON
+	Major         *uint8
+	Minor         *uint8
+	Type          *LeadType
+	ArchNum       leadCanon
+	Name          *leadName
+	OsNum         leadCanon
+	SignatureType *uint16
+}
+
+func (l *Lead) json() *jsonLead {
+	return &jsonLead{
+		Magic:         (*leadMagicJSON)(&l.Magic),
+		Major:         &l.Major,
+		Minor:         &l.Minor,
+		Type:          &l.Type,
+		ArchNum:       leadCanon{archCanon, &l.ArchNum},
+		Name:          &l.Name,
+		OsNum:         leadCanon{osCanon, &l.OsNum},
+		SignatureType: &l.SignatureType,
+	}
+}
+
+func (l Lead) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.json())
+}
+
+func (l *Lead) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, l.json())
+}
+
 func NewLead(name string, lt LeadType) *Lead {
 	// defined as 5 in lib/rpmlead.c, 3.0 signature type
 	const headerSigType = 5
@@ -68,5 +186,17 @@ func NewLead(name string, lt LeadType) *Lead {
 }
 
 func (l *Lead) WriteTo(w io.Writer) (int64, error) {
-	return 96, binary.Write(w, binary.BigEndian, l)
+	cw := &countWriter{w: w}
+	err := binary.Write(cw, binary.BigEndian, l)
+	return cw.n, err
+}
+
+// SetArch sets ArchNum from an rpm arch name, unknown names and noarch
+// leave it as is.
+func (l *Lead) SetArch(arch string) bool {
+	n, ok := ArchNum(arch)
+	if ok {
+		l.ArchNum = n
+	}
+	return ok
 }
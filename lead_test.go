@@ -3,6 +3,8 @@ package rpm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -32,9 +34,13 @@ func TestLeadMagic(t *testing.T) {
 	}
 
 	_, err := NewReader(b).Lead()
-	if err != errInvalidLead {
+	if !errors.Is(err, errInvalidLead) {
 		t.Fatalf("expected invalid lead: got %v", err)
 	}
+	var oe *OffsetError
+	if !errors.As(err, &oe) || oe.Off != 0 {
+		t.Fatalf("expected offset 0: got %v", err)
+	}
 }
 
 func TestLeadJSON(t *testing.T) {
@@ -53,3 +59,76 @@ func TestLeadJSON(t *testing.T) {
 		t.Fatalf("la != lb")
 	}
 }
+
+func TestLeadJSONInvalid(t *testing.T) {
+	for _, v := range []string{
+		`{"Magic":"edabeedb00"}`,
+		`{"Magic":"edabee"}`,
+		`{"Magic":"edabeedg"}`,
+		`{"Name":"` + strings.Repeat("x", 66) + `"}`,
+	} {
+		var l Lead
+		if err := json.Unmarshal([]byte(v), &l); err == nil {
+			t.Errorf("%s: no error", v)
+		}
+	}
+
+	l := NewLead(strings.Repeat("x", 65), LeadBinary)
+	if err := json.Unmarshal([]byte(`{"Name":"short"}`), l); err != nil {
+		t.Fatal(err)
+	}
+	if *l != *NewLead("short", LeadBinary) {
+		t.Errorf("name %q", l.Name[:])
+	}
+}
+
+func TestLeadJSONSymbolic(t *testing.T) {
+	la := NewLead("lead", LeadSource)
+	b, err := json.Marshal(la)
+	if err != nil {
+		t.Fatalf("json marshal: %v", err)
+	}
+	const want = `{"Magic":"edabeedb","Major":3,"Minor":0,"Type":"source",` +
+		`"ArchNum":"x86_64","Name":"lead","OsNum":"linux","SignatureType":5}`
+	if string(b) != want {
+		t.Fatalf("json:\nwant: %s\nhave: %s", want, b)
+	}
+
+	for _, v := range []string{
+		want,
+		`{"Magic":[237,171,238,219],"Major":3,"Minor":0,"Type":1,` +
+			`"ArchNum":1,"Name":"lead","OsNum":1,"SignatureType":5}`,
+		`{"Magic":"edabeedb","Major":3,"Minor":0,"Type":"source",` +
+			`"ArchNum":"i686","Name":"lead","OsNum":"Linux","SignatureType":5}`,
+	} {
+		var lb Lead
+		if err := json.Unmarshal([]byte(v), &lb); err != nil {
+			t.Fatalf("json unmarshal: %v\n%s", err, v)
+		}
+		if *la != lb {
+			t.Fatalf("la != lb\n%s", v)
+		}
+	}
+
+	la.ArchNum, la.OsNum = 0xfff, 0xfff
+	if b, err = json.Marshal(la); err != nil {
+		t.Fatalf("json marshal: %v", err)
+	}
+	var lb Lead
+	if err := json.Unmarshal(b, &lb); err != nil {
+		t.Fatalf("json unmarshal: %v", err)
+	}
+	if *la != lb {
+		t.Fatalf("la != lb\n%s", b)
+	}
+}
+
+func TestLeadSetArch(t *testing.T) {
+	l := NewLead("foo", LeadBinary)
+	if !l.SetArch("aarch64") || l.ArchNum != 19 {
+		t.Fatalf("aarch64: %d", l.ArchNum)
+	}
+	if l.SetArch("noarch") || l.ArchNum != 19 {
+		t.Fatalf("noarch: %d", l.ArchNum)
+	}
+}
@@ -0,0 +1,298 @@
+package rpm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errLicenseSyntax  = errors.New("rpm: invalid license expression")
+	errLicenseUnknown = errors.New("rpm: unknown license")
+)
+
+// spdxLicenses are the SPDX license identifiers known to ParseLicense,
+// the common ones of the SPDX license list.
+var spdxLicenses = []string{
+	"0BSD", "AFL-2.1", "AFL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later",
+	"Apache-1.0", "Apache-1.1", "Apache-2.0", "APSL-2.0", "Artistic-1.0",
+	"Artistic-1.0-Perl", "Artistic-2.0", "Beerware", "BlueOak-1.0.0",
+	"BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause",
+	"BSD-3-Clause-Clear", "BSD-4-Clause", "BSD-Source-Code", "BSL-1.0",
+	"bzip2-1.0.6", "CC-BY-3.0", "CC-BY-4.0", "CC-BY-SA-3.0", "CC-BY-SA-4.0",
+	"CC0-1.0", "CDDL-1.0", "CDDL-1.1", "CECILL-2.1", "ClArtistic", "CPL-1.0",
+	"curl", "ECL-2.0", "EFL-2.0", "EPL-1.0", "EPL-2.0", "EUPL-1.1", "EUPL-1.2",
+	"FSFAP", "FSFUL", "FSFULLR", "FTL", "GFDL-1.1-only", "GFDL-1.1-or-later",
+	"GFDL-1.2-only", "GFDL-1.2-or-later", "GFDL-1.3-only", "GFDL-1.3-or-later",
+	"GPL-1.0-only", "GPL-1.0-or-later", "GPL-2.0-only", "GPL-2.0-or-later",
+	"GPL-3.0-only", "GPL-3.0-or-later", "HPND", "ICU", "IJG", "ImageMagick",
+	"Info-ZIP", "IPA", "ISC", "JSON", "LGPL-2.0-only", "LGPL-2.0-or-later",
+	"LGPL-2.1-only", "LGPL-2.1-or-later", "LGPL-3.0-only", "LGPL-3.0-or-later",
+	"Libpng", "libpng-2.0", "libtiff", "LPL-1.02", "LPPL-1.3c", "MirOS", "MIT",
+	"MIT-0", "MIT-CMU", "MPL-1.0", "MPL-1.1", "MPL-2.0",
+	"MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL", "MulanPSL-2.0", "NCSA",
+	"Net-SNMP", "NTP", "OFL-1.0", "OFL-1.1", "OLDAP-2.8", "OpenSSL", "OSL-3.0",
+	"PHP-3.0", "PHP-3.01", "PostgreSQL", "PSF-2.0", "Python-2.0",
+	"Python-2.0.1", "QPL-1.0", "Ruby", "SGI-B-2.0", "Sleepycat", "SMLNJ",
+	"SSPL-1.0", "TCL", "Unicode-3.0", "Unicode-DFS-2016", "Unlicense",
+	"UPL-1.0", "Vim", "W3C", "WTFPL", "X11", "XFree86-1.1", "Zlib",
+	"zlib-acknowledgement", "ZPL-2.0", "ZPL-2.1",
+}
+
+// spdxExceptions are the identifiers allowed after WITH.
+var spdxExceptions = []string{
+	"Autoconf-exception-2.0", "Autoconf-exception-3.0",
+	"Bison-exception-2.2", "Classpath-exception-2.0", "Font-exception-2.0",
+	"GCC-exception-2.0", "GCC-exception-3.1", "LGPL-3.0-linking-exception",
+	"Linux-syscall-note", "LLVM-exception", "OCaml-LGPL-linking-exception",
+	"OpenJDK-assembly-exception-1.0", "openvpn-openssl-exception",
+	"Qt-GPL-exception-1.0", "Qt-LGPL-exception-1.1", "u-boot-exception-2.0",
+	"WxWindows-exception-3.1",
+}
+
+// spdxDeprecated maps deprecated identifiers to their replacement.
+var spdxDeprecated = map[string]string{
+	"AGPL-3.0":   "AGPL-3.0-only",
+	"GPL-1.0":    "GPL-1.0-only",
+	"GPL-1.0+":   "GPL-1.0-or-later",
+	"GPL-2.0":    "GPL-2.0-only",
+	"GPL-2.0+":   "GPL-2.0-or-later",
+	"GPL-3.0":    "GPL-3.0-only",
+	"GPL-3.0+":   "GPL-3.0-or-later",
+	"LGPL-2.0":   "LGPL-2.0-only",
+	"LGPL-2.0+":  "LGPL-2.0-or-later",
+	"LGPL-2.1":   "LGPL-2.1-only",
+	"LGPL-2.1+":  "LGPL-2.1-or-later",
+	"LGPL-3.0":   "LGPL-3.0-only",
+	"LGPL-3.0+":  "LGPL-3.0-or-later",
+	"GFDL-1.3":   "GFDL-1.3-only",
+	"Nunit":      "zlib-acknowledgement",
+	"StandardML": "SMLNJ",
+}
+
+// legacyLicenses maps the short names of the old Fedora license list,
+// used by many existing packages, to SPDX.
+var legacyLicenses = map[string]string{
+	"GPL+":               "GPL-1.0-or-later",
+	"GPLv2":              "GPL-2.0-only",
+	"GPLv2+":             "GPL-2.0-or-later",
+	"GPLv3":              "GPL-3.0-only",
+	"GPLv3+":             "GPL-3.0-or-later",
+	"LGPLv2":             "LGPL-2.0-only",
+	"LGPLv2+":            "LGPL-2.0-or-later",
+	"LGPLv2.1":           "LGPL-2.1-only",
+	"LGPLv2.1+":          "LGPL-2.1-or-later",
+	"LGPLv3":             "LGPL-3.0-only",
+	"LGPLv3+":            "LGPL-3.0-or-later",
+	"AGPLv3":             "AGPL-3.0-only",
+	"AGPLv3+":            "AGPL-3.0-or-later",
+	"GFDL":               "GFDL-1.1-or-later",
+	"ASL 1.1":            "Apache-1.1",
+	"ASL 2.0":            "Apache-2.0",
+	"MPLv1.1":            "MPL-1.1",
+	"MPLv2.0":            "MPL-2.0",
+	"Artistic 2.0":       "Artistic-2.0",
+	"Artistic clarified": "ClArtistic",
+	"Boost":              "BSL-1.0",
+	"CC0":                "CC0-1.0",
+	"CDDL":               "CDDL-1.0",
+	"EPL":                "EPL-1.0",
+	"OFL":                "OFL-1.1",
+	"Python":             "Python-2.0",
+	"Public Domain":      "LicenseRef-Fedora-Public-Domain",
+	"zlib":               "Zlib",
+}
+
+var spdxLicenseIDs, spdxExceptionIDs = spdxIndex(spdxLicenses), spdxIndex(spdxExceptions)
+
+// spdxIndex maps lower case identifiers to the canonical ones, they
+// match case insensitively.
+func spdxIndex(ids []string) map[string]string {
+	r := make(map[string]string, len(ids))
+	for _, v := range ids {
+		r[strings.ToLower(v)] = v
+	}
+	return r
+}
+
+// licenseTokens splits s into parentheses and words.
+func licenseTokens(s string) []string {
+	var r []string
+	for _, f := range strings.Fields(s) {
+		for f != "" {
+			i := strings.IndexAny(f, "()")
+			switch {
+			case i == -1:
+				r, f = append(r, f), ""
+			case i == 0:
+				r, f = append(r, f[:1]), f[1:]
+			default:
+				r, f = append(r, f[:i]), f[i:]
+			}
+		}
+	}
+	return r
+}
+
+type licenseParser struct {
+	tok    []string
+	legacy bool
+	out    []string
+}
+
+func (p *licenseParser) peek() string {
+	if len(p.tok) == 0 {
+		return ""
+	}
+	return p.tok[0]
+}
+
+// operator reports if t is op, lower case operators are legacy.
+func (p *licenseParser) operator(t, op string) bool {
+	return t == op || p.legacy && strings.ToUpper(t) == op
+}
+
+func (p *licenseParser) isOperator(t string) bool {
+	for _, v := range []string{"AND", "OR", "WITH"} {
+		if p.operator(t, v) {
+			return true
+		}
+	}
+	return t == "(" || t == ")"
+}
+
+// id reads a license or, after WITH, an exception. Legacy names may
+// span words.
+func (p *licenseParser) id(exception bool) error {
+	var words []string
+	for len(p.tok) > 0 && !p.isOperator(p.peek()) {
+		words = append(words, p.tok[0])
+		p.tok = p.tok[1:]
+		if !p.legacy {
+			break
+		}
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("%w: missing license", errLicenseSyntax)
+	}
+	s := strings.Join(words, " ")
+
+	ids := spdxLicenseIDs
+	if exception {
+		ids = spdxExceptionIDs
+	}
+	switch {
+	case ids[strings.ToLower(s)] != "":
+		s = ids[strings.ToLower(s)]
+	case !exception && spdxDeprecated[s] != "":
+		s = spdxDeprecated[s]
+	case !exception && p.legacy && legacyLicenses[s] != "":
+		s = legacyLicenses[s]
+	case strings.HasPrefix(s, "LicenseRef-") || strings.HasPrefix(s, "DocumentRef-"):
+		if strings.ContainsAny(s, " ") {
+			return fmt.Errorf("%w: %q", errLicenseSyntax, s)
+		}
+	case !exception && strings.HasSuffix(s, "+") && spdxLicenseIDs[strings.ToLower(s[:len(s)-1])] != "":
+		s = spdxLicenseIDs[strings.ToLower(s[:len(s)-1])] + "+"
+	default:
+		return fmt.Errorf("%w: %q", errLicenseUnknown, s)
+	}
+	p.out = append(p.out, s)
+	return nil
+}
+
+// term := "(" expr ")" | id [WITH id]
+func (p *licenseParser) term() error {
+	if p.peek() == "(" {
+		p.tok = p.tok[1:]
+		p.out = append(p.out, "(")
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if p.peek() != ")" {
+			return fmt.Errorf("%w: missing )", errLicenseSyntax)
+		}
+		p.tok = p.tok[1:]
+		p.out = append(p.out, ")")
+		return nil
+	}
+	if err := p.id(false); err != nil {
+		return err
+	}
+	if p.operator(p.peek(), "WITH") {
+		p.tok = p.tok[1:]
+		p.out = append(p.out, "WITH")
+		return p.id(true)
+	}
+	return nil
+}
+
+// expr := term { (AND | OR) term }
+func (p *licenseParser) expr() error {
+	if err := p.term(); err != nil {
+		return err
+	}
+	for {
+		t := p.peek()
+		op := ""
+		switch {
+		case p.operator(t, "AND"):
+			op = "AND"
+		case p.operator(t, "OR"):
+			op = "OR"
+		default:
+			return nil
+		}
+		p.tok = p.tok[1:]
+		p.out = append(p.out, op)
+		if err := p.term(); err != nil {
+			return err
+		}
+	}
+}
+
+func parseLicense(s string, legacy bool) (string, error) {
+	p := &licenseParser{tok: licenseTokens(s), legacy: legacy}
+	if err := p.expr(); err != nil {
+		return "", err
+	}
+	if len(p.tok) > 0 {
+		return "", fmt.Errorf("%w: unexpected %q", errLicenseSyntax, p.tok[0])
+	}
+	r := strings.Join(p.out, " ")
+	r = strings.ReplaceAll(r, "( ", "(")
+	return strings.ReplaceAll(r, " )", ")"), nil
+}
+
+// ParseLicense parses an SPDX license expression and returns it with
+// canonical identifiers, deprecated ones replaced.
+func ParseLicense(s string) (string, error) {
+	return parseLicense(s, false)
+}
+
+// NormalizeLicense is ParseLicense that also accepts the legacy Fedora
+// license names and lower case operators, e.g. "GPLv2+ and ASL 2.0".
+func NormalizeLicense(s string) (string, error) {
+	return parseLicense(s, true)
+}
+
+func lintLicense(hdr *Header) []Warning {
+	s, ok := hdr.StringData(RPMTAG_LICENSE)
+	if !ok {
+		return nil
+	}
+	if n, err := ParseLicense(s); err == nil {
+		if n != s {
+			return []Warning{{RPMTAG_LICENSE, "legacy-license", "use " + n}}
+		}
+		return nil
+	}
+	n, err := NormalizeLicense(s)
+	switch {
+	case err == nil:
+		return []Warning{{RPMTAG_LICENSE, "legacy-license", "use " + n}}
+	case errors.Is(err, errLicenseUnknown):
+		return []Warning{{RPMTAG_LICENSE, "unknown-license", err.Error()}}
+	}
+	return []Warning{{RPMTAG_LICENSE, "invalid-license", err.Error()}}
+}
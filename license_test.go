@@ -0,0 +1,86 @@
+package rpm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLicense(t *testing.T) {
+	for _, v := range []struct {
+		in, out string
+		err     error
+	}{
+		{"MIT", "MIT", nil},
+		{"mit", "MIT", nil},
+		{"GPL-2.0", "GPL-2.0-only", nil},
+		{"Apache-2.0+", "Apache-2.0+", nil},
+		{"MIT OR (Apache-2.0 AND BSD-3-Clause)", "MIT OR (Apache-2.0 AND BSD-3-Clause)", nil},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", nil},
+		{"LicenseRef-Proprietary", "LicenseRef-Proprietary", nil},
+		{"(MIT", "", errLicenseSyntax},
+		{"MIT AND", "", errLicenseSyntax},
+		{"MIT Apache-2.0", "", errLicenseSyntax},
+		{"", "", errLicenseSyntax},
+		{"MIT WITH LLVM", "", errLicenseUnknown},
+		{"GPLv2+", "", errLicenseUnknown},
+		{"MIT and BSD-3-Clause", "", errLicenseSyntax},
+	} {
+		s, err := ParseLicense(v.in)
+		if s != v.out || !errors.Is(err, v.err) || (v.err == nil) != (err == nil) {
+			t.Errorf("%q: %q, %v", v.in, s, err)
+		}
+	}
+}
+
+func TestNormalizeLicense(t *testing.T) {
+	for _, v := range []struct {
+		in, out string
+	}{
+		{"GPLv2+", "GPL-2.0-or-later"},
+		{"GPLv2+ and ASL 2.0", "GPL-2.0-or-later AND Apache-2.0"},
+		{"(LGPLv2.1 or MPLv1.1) and Public Domain", "(LGPL-2.1-only OR MPL-1.1) AND LicenseRef-Fedora-Public-Domain"},
+		{"MIT", "MIT"},
+	} {
+		s, err := NormalizeLicense(v.in)
+		if err != nil || s != v.out {
+			t.Errorf("%q: %q, %v", v.in, s, err)
+		}
+	}
+	if _, err := NormalizeLicense("BSD"); !errors.Is(err, errLicenseUnknown) {
+		t.Fatal(err)
+	}
+}
+
+func TestLintLicense(t *testing.T) {
+	for _, v := range []struct {
+		license, code string
+	}{
+		{"MIT", ""},
+		{"GPL-2.0", "legacy-license"},
+		{"GPLv2", "legacy-license"},
+		{"BSD", "unknown-license"},
+		{"MIT AND (", "invalid-license"},
+	} {
+		hdr := new(Header)
+		hdr.AddString(RPMTAG_LICENSE, v.license)
+		w := lintLicense(hdr)
+		if v.code == "" && len(w) != 0 || v.code != "" && (len(w) != 1 || w[0].Code != v.code) {
+			t.Errorf("%q: %v", v.license, w)
+		}
+	}
+}
+
+func TestPkgInfoHeader(t *testing.T) {
+	hdr := new(Header)
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddString(RPMTAG_VERSION, "1.0")
+	hdr.AddString(RPMTAG_RELEASE, "1")
+	hdr.AddString(RPMTAG_ARCH, "noarch")
+	hdr.AddString(RPMTAG_LICENSE, "GPLv2+ or MIT")
+
+	i := PkgInfoHeader(hdr)
+	if i.Name != "foo" || i.EVR.String() != "1.0-1" || i.Arch != "noarch" ||
+		i.License != "GPLv2+ or MIT" || i.LicenseExpression != "GPL-2.0-or-later OR MIT" {
+		t.Fatalf("%+v", i)
+	}
+}
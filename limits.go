@@ -0,0 +1,146 @@
+package rpm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var errOverLimit = errors.New("rpm: limit exceeded")
+
+// Limits bound what a Reader reads, zero fields are unlimited.
+type Limits struct {
+	Tags       int   // tags per header
+	HeaderData int64 // tag data bytes per header
+	Size       int64 // bytes read from the underlying reader
+
+	// Timeout is the time ScanUntrusted takes at most
+	Timeout time.Duration
+}
+
+// DefaultLimits are the header limits of rpm itself, for packages of
+// about any size, with a timeout for slow uploads.
+var DefaultLimits = Limits{
+	Tags:       0xffff,
+	HeaderData: 0x0fffffff,
+	Size:       1 << 30,
+	Timeout:    30 * time.Second,
+}
+
+// SetLimits makes r fail with reads beyond l, before reading the
+// data of a header that exceeds them. It replaces the limits of an
+// earlier call.
+func (r *Reader) SetLimits(l Limits) {
+	r.limits = l
+	if r.size == nil && l.Size > 0 {
+		r.size = &sizeReader{r: r.cr.r, n: r.cr.n}
+		r.cr.r = r.size
+	}
+	if r.size != nil {
+		r.size.max = l.Size
+	}
+}
+
+// SetStrict makes r reject known tags of the wrong type, or with
+// several values where rpm expects one.
+func (r *Reader) SetStrict(v bool) { r.strict = v }
+
+// SetContext makes r fail once ctx is done. A Read of the underlying
+// reader that blocks isn't interrupted, set a deadline on it as well.
+func (r *Reader) SetContext(ctx context.Context) {
+	r.cr.r = &ctxReader{r: r.cr.r, ctx: ctx}
+}
+
+// checkLimits checks the tag count and data length of hdr.
+func (r *Reader) checkLimits(hdr *Header) error {
+	switch l := r.limits; {
+	case l.Tags > 0 && int64(hdr.Count) > int64(l.Tags):
+		return fmt.Errorf("%w: %d tags", errOverLimit, hdr.Count)
+	case l.HeaderData > 0 && int64(hdr.Length) > l.HeaderData:
+		return fmt.Errorf("%w: %d bytes of tag data", errOverLimit, hdr.Length)
+	}
+	return nil
+}
+
+// checkStrict checks the tags of hdr, its kind known.
+func (r *Reader) checkStrict(hdr *Header) error {
+	if !r.strict {
+		return nil
+	}
+	for _, v := range hdr.Tags {
+		if msg := tagMismatch(hdr.Kind(), v); msg != "" {
+			return r.err(tagError{v, errTagMismatch})
+		}
+	}
+	return nil
+}
+
+type sizeReader struct {
+	r   io.Reader
+	n   int64 // bytes read
+	max int64 // 0 for no limit
+}
+
+func (s *sizeReader) Read(b []byte) (int, error) {
+	if s.max > 0 {
+		if s.n >= s.max {
+			return 0, errOverLimit
+		}
+		if left := s.max - s.n; int64(len(b)) > left {
+			b = b[:left]
+		}
+	}
+	n, err := s.r.Read(b)
+	s.n += int64(n)
+	return n, err
+}
+
+type ctxReader struct {
+	r   io.Reader
+	ctx context.Context
+}
+
+func (c *ctxReader) Read(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(b)
+}
+
+// ScanUntrusted reads the lead and headers of a package from untrusted
+// input, like an upload, in strict mode within limit and its timeout. The
+// main header, as read, must match the SHA256 digest of the signature
+// header if there is one. The payload isn't read, nor are signatures
+// checked.
+func ScanUntrusted(r io.Reader, limit Limits) (PkgInfo, error) {
+	ctx := context.Background()
+	if limit.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit.Timeout)
+		defer cancel()
+	}
+	// the headers as read, the digest is of the main header's bytes
+	raw := new(bytes.Buffer)
+	rd := NewReader(io.TeeReader(r, raw))
+	rd.SetLimits(limit)
+	rd.SetContext(ctx)
+	rd.SetStrict(true)
+
+	p, err := rd.pkg()
+	if err != nil {
+		return PkgInfo{}, err
+	}
+	if want, ok := p.Signature.StringData(RPMSIGTAG_SHA256); ok {
+		l := p.layout.Header
+		sum := sha256.Sum256(raw.Bytes()[l.Off : l.Off+l.Len])
+		if hex.EncodeToString(sum[:]) != want {
+			return PkgInfo{}, fmt.Errorf("%w: %s", errDigest, SigTagString(RPMSIGTAG_SHA256))
+		}
+	}
+	return PkgInfoHeader(p.Header), nil
+}
@@ -0,0 +1,136 @@
+package rpm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func untrustedPackage(t *testing.T, name uint32) []byte {
+	hdr := NewPayloadHeader()
+	if name != 0 {
+		hdr.AddInt32(RPMTAG_NAME, name)
+	} else {
+		hdr.AddString(RPMTAG_NAME, "foo")
+	}
+	hdr.AddString(RPMTAG_VERSION, "1")
+	hdr.AddString(RPMTAG_RELEASE, "2")
+	hdr.AddString(RPMTAG_ARCH, "noarch")
+
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(hb.Bytes())
+	sig := NewSignatureHeader()
+	sig.AddString(RPMSIGTAG_SHA256, hex.EncodeToString(sum[:]))
+	sig.AddInt32(RPMSIGTAG_SIZE, uint32(hb.Len()+len("payload")))
+	b := new(bytes.Buffer)
+	if _, err := WriteHeaders(b, NewLead("foo", LeadBinary), sig, hb); err != nil {
+		t.Fatal(err)
+	}
+	b.WriteString("payload")
+	return b.Bytes()
+}
+
+func TestScanUntrusted(t *testing.T) {
+	good := untrustedPackage(t, 0)
+	i, err := ScanUntrusted(bytes.NewReader(good), DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Name != "foo" || i.Version != "1" || i.Release != "2" || i.Arch != "noarch" {
+		t.Errorf("info: %+v", i)
+	}
+
+	for _, v := range []struct {
+		name  string
+		data  []byte
+		limit Limits
+		want  error
+	}{
+		{"tags", good, Limits{Tags: 2}, errOverLimit},
+		{"data", good, Limits{HeaderData: 16}, errOverLimit},
+		{"size", good, Limits{Size: int64(len(good) - 8)}, errOverLimit},
+		{"type", untrustedPackage(t, 1), DefaultLimits, errTagMismatch},
+		{"digest", makePackage(t, nil).Bytes(), DefaultLimits, errDigest},
+	} {
+		if _, err := ScanUntrusted(bytes.NewReader(v.data), v.limit); !errors.Is(err, v.want) {
+			t.Errorf("%s: %v, want %v", v.name, err, v.want)
+		}
+	}
+
+	// the payload isn't read
+	if _, err := ScanUntrusted(bytes.NewReader(good[:len(good)-len("payload")]), DefaultLimits); err != nil {
+		t.Errorf("without payload: %v", err)
+	}
+}
+
+func TestScanUntrustedRaw(t *testing.T) {
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddString(RPMTAG_VERSION, "1")
+	hdr.AddString(RPMTAG_RELEASE, "2")
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		t.Fatal(err)
+	}
+
+	// swap the index entries of NAME and VERSION, the header is read
+	// sorted and written again differently
+	b := hb.Bytes()
+	e1, e2 := b[16+16:16+32], b[16+32:16+48]
+	tmp := bytes.Clone(e1)
+	copy(e1, e2)
+	copy(e2, tmp)
+	have, err := NewReader(bytes.NewReader(b)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb := new(bytes.Buffer)
+	have.WriteTo(rb)
+	if bytes.Equal(rb.Bytes(), b) {
+		t.Fatal("header written as read")
+	}
+
+	sum := sha256.Sum256(b)
+	sig := NewSignatureHeader()
+	sig.AddString(RPMSIGTAG_SHA256, hex.EncodeToString(sum[:]))
+	pb := new(bytes.Buffer)
+	if _, err := WriteHeaders(pb, NewLead("foo", LeadBinary), sig, bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanUntrusted(pb, DefaultLimits); err != nil {
+		t.Errorf("unsorted: %v", err)
+	}
+}
+
+func TestReaderSetLimits(t *testing.T) {
+	good := untrustedPackage(t, 0)
+	r := NewReader(bytes.NewReader(good))
+	r.SetLimits(Limits{Size: 16})
+	r.SetLimits(Limits{Size: int64(len(good))})
+	if _, err := r.pkg(); err != nil {
+		t.Errorf("raised: %v", err)
+	}
+
+	r = NewReader(bytes.NewReader(good))
+	r.SetLimits(DefaultLimits)
+	r.SetLimits(Limits{Size: 16})
+	if _, err := r.pkg(); !errors.Is(err, errOverLimit) {
+		t.Errorf("lowered: %v", err)
+	}
+}
+
+func TestReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := NewReader(bytes.NewReader(untrustedPackage(t, 0)))
+	r.SetContext(ctx)
+	if _, err := r.Lead(); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: %v", err)
+	}
+}
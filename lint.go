@@ -0,0 +1,76 @@
+package rpm
+
+import "fmt"
+
+type Warning struct {
+	Tag  TagType
+	Code string
+	Msg  string
+}
+
+func (w Warning) String() string {
+	if w.Tag == 0 {
+		return fmt.Sprintf("%s: %s", w.Code, w.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", w.Code, w.Tag, w.Msg)
+}
+
+type lintFunc func(hdr *Header) []Warning
+
+var lintChecks = []lintFunc{
+	lintLegacy,
+	lintPayloadDigest,
+	lintLicense,
+	lintText,
+	lintTagTypes,
+	lintDribble,
+}
+
+// Lint checks a payload header for legacy and problematic constructs.
+func Lint(hdr *Header) []Warning {
+	var r []Warning
+	for _, f := range lintChecks {
+		r = append(r, f(hdr)...)
+	}
+	return r
+}
+
+var legacyTags = map[TagType]string{
+	RPMTAG_OLDFILENAMES:  "pre rpm-3 file list, use DIRNAMES/BASENAMES/DIRINDEXES",
+	RPMTAG_FILENAMES:     "extension tag stored in header, use DIRNAMES/BASENAMES/DIRINDEXES",
+	RPMTAG_PREREQ:        "obsolete, use REQUIREFLAGS with RPMSENSE_PREREQ",
+	RPMTAG_BUILDPREREQ:   "obsolete, use BUILDREQUIRES",
+	RPMTAG_GIF:           "obsolete",
+	RPMTAG_XPM:           "obsolete",
+	RPMTAG_ICON:          "obsolete",
+	RPMTAG_ROOT:          "obsolete",
+	RPMTAG_DEFAULTPREFIX: "obsolete, use PREFIXES",
+	RPMTAG_BROKENMD5:     "obsolete",
+}
+
+func lintLegacy(hdr *Header) []Warning {
+	var r []Warning
+	for _, v := range hdr.Tags {
+		if msg, ok := legacyTags[v.Tag]; ok {
+			r = append(r, Warning{v.Tag, "legacy-tag", msg})
+		}
+	}
+	return r
+}
+
+func lintDribble(hdr *Header) []Warning {
+	if !hdr.dribble {
+		return nil
+	}
+	return []Warning{{0, "dribble", "tags after the region are not signed"}}
+}
+
+func lintPayloadDigest(hdr *Header) []Warning {
+	if hdr.Find(RPMTAG_PAYLOADDIGEST) != nil {
+		return nil
+	}
+	return []Warning{{
+		RPMTAG_PAYLOADDIGEST, "missing-payload-digest",
+		"payload cannot be verified without the header",
+	}}
+}
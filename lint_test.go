@@ -0,0 +1,83 @@
+package rpm
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lintCodes(hdr *Header) map[string]int {
+	r := make(map[string]int)
+	for _, v := range Lint(hdr) {
+		r[v.Code]++
+	}
+	return r
+}
+
+func TestLintLegacy(t *testing.T) {
+	hdr := new(Header)
+	hdr.AddStringArray(RPMTAG_OLDFILENAMES, "/usr/bin/foo")
+	hdr.AddString(RPMTAG_PREREQ, "bar")
+
+	c := lintCodes(hdr)
+	if c["legacy-tag"] != 2 || c["missing-payload-digest"] != 1 {
+		t.Fatalf("lint: %v", c)
+	}
+
+	hdr = new(Header)
+	hdr.AddStringArray(RPMTAG_PAYLOADDIGEST, "00")
+	if w := Lint(hdr); len(w) != 0 {
+		t.Fatalf("lint: %v", w)
+	}
+}
+
+func TestFileIndexOldFilenames(t *testing.T) {
+	files := []string{"/usr/bin/foo", "/usr/bin/bar", "/etc/foo.conf"}
+
+	old := new(Header)
+	old.AddStringArray(RPMTAG_OLDFILENAMES, files...)
+
+	fi := NewFileIndex()
+	for _, v := range files {
+		fi.Add(&File{Name: v})
+	}
+	hdr := new(Header)
+	fi.Append(hdr)
+
+	a, err := FileIndexHeader(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := FileIndexHeader(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, v := range files {
+		if x := path.Join(a.dirNames.s[a.dirIndexes[i]], a.name[i]); x != v {
+			t.Fatalf("file %d: %s != %s", i, x, v)
+		}
+	}
+	if x, y := a.dirNames.s, b.dirNames.s; !reflect.DeepEqual(x, y) {
+		t.Fatalf("dirnames: %q != %q", x, y)
+	}
+}
+
+func TestLintText(t *testing.T) {
+	hdr := new(Header)
+	hdr.AddString(RPMTAG_SUMMARY, "two\nlines ")
+	hdr.AddString(RPMTAG_DESCRIPTION, strings.Repeat("word ", 20)+"\n"+"http://"+strings.Repeat("x", 80))
+
+	c := lintCodes(hdr)
+	if c["summary-newline"] != 1 || c["trailing-whitespace"] != 2 || c["description-line-too-long"] != 1 {
+		t.Fatalf("lint: %v", c)
+	}
+
+	hdr = new(Header)
+	hdr.AddString(RPMTAG_SUMMARY, "a summary")
+	hdr.AddString(RPMTAG_DESCRIPTION, "http://"+strings.Repeat("x", 80))
+	if w := lintText(hdr); len(w) != 0 {
+		t.Fatalf("lint: %v", w)
+	}
+}
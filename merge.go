@@ -0,0 +1,64 @@
+package rpm
+
+import "errors"
+
+type MergePolicy int
+
+const (
+	// MergeKeep keeps tags already in the header.
+	MergeKeep MergePolicy = iota
+	// MergeReplace replaces tags with the ones being merged.
+	MergeReplace
+	// MergeError fails on tags present in both headers.
+	MergeError
+)
+
+var errDuplicateTag = errors.New("rpm: duplicate tag")
+
+// Merge adds copies of the tags of other to hdr, I18N strings are
+// remapped to the locale table of hdr. other is not modified, nor is hdr
+// if a tag can't be added.
+func (hdr *Header) Merge(other *Header, policy MergePolicy) error {
+	var tags []*Tag
+	for _, v := range other.Tags {
+		if v.Tag == RPMTAG_HEADERI18NTABLE {
+			continue
+		}
+		if hdr.Find(v.Tag) != nil {
+			switch policy {
+			case MergeKeep:
+				continue
+			case MergeError:
+				return tagError{v, errDuplicateTag}
+			}
+		}
+		if err := hdr.checkAdd(v); err != nil {
+			return err
+		}
+		tags = append(tags, v)
+	}
+
+	tags = hdr.i18n(other, tags)
+
+	if policy == MergeReplace {
+		rm := make(map[TagType]bool, len(tags))
+		for _, v := range tags {
+			rm[v.Tag] = true
+		}
+		r := hdr.Tags[:0]
+		for _, v := range hdr.Tags {
+			if !rm[v.Tag] {
+				r = append(r, v)
+			}
+		}
+		if len(r) != len(hdr.Tags) {
+			hdr.Tags = r
+			hdr.Relayout()
+		}
+	}
+
+	for _, v := range tags {
+		hdr.add(v.clone())
+	}
+	return nil
+}
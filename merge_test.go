@@ -0,0 +1,92 @@
+package rpm
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func mergeHdrs() (*Header, *Header) {
+	tmpl := NewPayloadHeader()
+	tmpl.AddString(RPMTAG_LICENSE, "BSD")
+	tmpl.AddString(RPMTAG_VENDOR, "vendor")
+	tmpl.AddInt32(RPMTAG_BUILDTIME, 1)
+
+	gen := new(Header)
+	gen.AddInt32(RPMTAG_BUILDTIME, 2)
+	gen.AddStringArray(RPMTAG_BASENAMES, "foo", "bar")
+	return tmpl, gen
+}
+
+func buildtime(t *testing.T, hdr *Header) uint32 {
+	r, ok := hdr.Find(RPMTAG_BUILDTIME).Int32()
+	if !ok {
+		t.Fatalf("buildtime type")
+	}
+	return r[0]
+}
+
+func TestMerge(t *testing.T) {
+	for _, v := range []struct {
+		policy MergePolicy
+		time   uint32
+		err    error
+	}{
+		{MergeKeep, 1, nil},
+		{MergeReplace, 2, nil},
+		{MergeError, 0, errDuplicateTag},
+	} {
+		tmpl, gen := mergeHdrs()
+		off := gen.Tags[0].Offset
+
+		err := tmpl.Merge(gen, v.policy)
+		if !errors.Is(err, v.err) {
+			t.Fatalf("policy %d: expected %v, got %v", v.policy, v.err, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		if a := buildtime(t, tmpl); a != v.time {
+			t.Fatalf("policy %d: buildtime %d", v.policy, a)
+		}
+		if tmpl.Len() != 4 {
+			t.Fatalf("policy %d: tags %d", v.policy, tmpl.Len())
+		}
+		if gen.Tags[0].Offset != off {
+			t.Fatalf("policy %d: source modified", v.policy)
+		}
+		if _, err := tmpl.WriteTo(ioutil.Discard); err != nil {
+			t.Fatalf("policy %d: write: %v", v.policy, err)
+		}
+	}
+}
+
+func TestMergeCopy(t *testing.T) {
+	tmpl, gen := mergeHdrs()
+	if err := tmpl.Merge(gen, MergeReplace); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := gen.Find(RPMTAG_BASENAMES).StringArray()
+	s[0] = "changed"
+	gen.Find(RPMTAG_BUILDTIME).data.(tagUint32)[0] = 3
+	if s, _ := tmpl.Find(RPMTAG_BASENAMES).StringArray(); s[0] != "foo" {
+		t.Errorf("basenames shared: %q", s)
+	}
+	if a := buildtime(t, tmpl); a != 2 {
+		t.Errorf("buildtime shared: %d", a)
+	}
+}
+
+func TestMergeUnchanged(t *testing.T) {
+	tmpl, gen := mergeHdrs()
+	tmpl.SetTypeCheck(true)
+	gen.AddInt32(RPMTAG_NAME, 1)
+	n := tmpl.Len()
+	if err := tmpl.Merge(gen, MergeReplace); !errors.Is(err, errTagMismatch) {
+		t.Fatalf("expected %v, got %v", errTagMismatch, err)
+	}
+	if tmpl.Len() != n || tmpl.Find(RPMTAG_BASENAMES) != nil || buildtime(t, tmpl) != 1 {
+		t.Fatalf("partly merged: %d tags", tmpl.Len())
+	}
+}
@@ -0,0 +1,91 @@
+package rpm
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives parse events from Readers, one per package read or
+// failed, implementations must be safe for concurrent use. A package
+// ends with the main header, a header stream without leads or regions
+// reports every header other than a signature.
+type Metrics interface {
+	PackageParsed()
+	ParseError(kind string)
+	BytesRead(n int64)
+	ParseDuration(d time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) PackageParsed()              {}
+func (nopMetrics) ParseError(string)           {}
+func (nopMetrics) BytesRead(int64)             {}
+func (nopMetrics) ParseDuration(time.Duration) {}
+
+type metricsValue struct{ Metrics }
+
+var metrics atomic.Value
+
+func init() { metrics.Store(metricsValue{nopMetrics{}}) }
+
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = nopMetrics{}
+	}
+	metrics.Store(metricsValue{m})
+}
+
+func getMetrics() Metrics {
+	return metrics.Load().(metricsValue).Metrics
+}
+
+// ErrorKind classifies errors returned by the Reader for metrics labels.
+func ErrorKind(err error) string {
+	var te tagError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errUnexpectedEOF), errors.Is(err, io.EOF):
+		return "eof"
+	case errors.Is(err, errInvalidLead):
+		return "lead"
+	case errors.Is(err, errInvalidHeader):
+		return "header"
+	case errors.As(err, &te):
+		return "tag"
+	}
+	return "other"
+}
+
+// begin starts a package at its lead or first header.
+func (r *Reader) begin() {
+	if r.mstart.IsZero() {
+		r.mstart, r.moff = time.Now(), r.cr.n
+	}
+}
+
+// observe reports the package to the metrics once hdr is its main
+// header or reading failed, the end of a stream between packages isn't
+// an error.
+func (r *Reader) observe(hdr *Header, err error) {
+	switch {
+	case errors.Is(err, io.EOF) && !r.mpart:
+		r.mstart = time.Time{}
+		return
+	case err == nil && (hdr == nil || hdr.Kind() == KindSignature):
+		r.mpart = true
+		return
+	}
+	m := getMetrics()
+	m.BytesRead(r.cr.n - r.moff)
+	m.ParseDuration(time.Since(r.mstart))
+	r.mstart, r.mpart = time.Time{}, false
+	if err != nil {
+		m.ParseError(ErrorKind(err))
+		return
+	}
+	m.PackageParsed()
+}
@@ -0,0 +1,113 @@
+package rpm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	sync.Mutex
+	parsed int
+	errors map[string]int
+	bytes  int64
+	calls  int
+}
+
+func (m *testMetrics) PackageParsed() {
+	m.Lock()
+	m.parsed++
+	m.Unlock()
+}
+
+func (m *testMetrics) ParseError(kind string) {
+	m.Lock()
+	m.errors[kind]++
+	m.Unlock()
+}
+
+func (m *testMetrics) BytesRead(n int64) {
+	m.Lock()
+	m.bytes += n
+	m.Unlock()
+}
+
+func (m *testMetrics) ParseDuration(d time.Duration) {
+	m.Lock()
+	m.calls++
+	m.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	m := &testMetrics{errors: make(map[string]int)}
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	data := makePackage(t, nil).Bytes()
+	if _, err := ReadPackage(bytes.NewReader(data)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	ReadPackage(bytes.NewReader(data[:200]))
+	ReadPackage(bytes.NewReader(make([]byte, 96)))
+
+	if m.parsed != 1 || m.calls != 3 {
+		t.Fatalf("parsed: %d, calls: %d", m.parsed, m.calls)
+	}
+	if m.errors["eof"] != 1 || m.errors["lead"] != 1 {
+		t.Fatalf("errors: %v", m.errors)
+	}
+	if m.bytes < int64(len(data)) {
+		t.Fatalf("bytes: %d", m.bytes)
+	}
+}
+
+// TestMetricsReader checks streaming reads are reported like ReadPackage.
+func TestMetricsReader(t *testing.T) {
+	m := &testMetrics{errors: make(map[string]int)}
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	data := makePackage(t, nil).Bytes()
+	p, err := ReadPackage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	if _, err := r.Lead(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m.parsed != 2 || m.calls != 2 || m.bytes != 2*r.Offset() {
+		t.Fatalf("package: parsed %d, calls %d, bytes %d", m.parsed, m.calls, m.bytes)
+	}
+
+	// a stream of headers, its end isn't an error
+	hdr := writeBytes(t, p.Header)
+	hdr = append(hdr, make([]byte, -len(hdr)&7)...)
+	stream := bytes.Repeat(hdr, 3)
+	r = NewReader(bytes.NewReader(stream))
+	for {
+		if _, err = r.Next(); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.EOF) || m.parsed != 5 || len(m.errors) != 0 {
+		t.Fatalf("stream: %v, parsed %d, errors %v", err, m.parsed, m.errors)
+	}
+	r = NewReader(bytes.NewReader(stream[:len(hdr)+100]))
+	for {
+		if _, err = r.Next(); err != nil {
+			break
+		}
+	}
+	if m.parsed != 6 || m.errors["eof"] != 1 || m.calls != 7 {
+		t.Fatalf("truncated: %v, parsed %d, calls %d, errors %v", err, m.parsed, m.calls, m.errors)
+	}
+}
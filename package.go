@@ -0,0 +1,133 @@
+package rpm
+
+import (
+	"fmt"
+	"io"
+)
+
+type Package struct {
+	Lead      *Lead
+	Signature *Header
+	Header    *Header
+	layout    Layout
+}
+
+type Range struct {
+	Off int64
+	Len int64
+}
+
+func (r Range) End() int64 {
+	if r.Len < 0 {
+		return -1
+	}
+	return r.Off + r.Len
+}
+
+// Layout describes the byte ranges of a package file. Payload.Len is -1
+// when the signature header has no size tag.
+type Layout struct {
+	Lead      Range
+	Signature Range
+	Padding   Range
+	Header    Range
+	Payload   Range
+}
+
+func (l Layout) Dump(w io.Writer) error {
+	for _, v := range []struct {
+		name string
+		r    Range
+	}{
+		{"lead", l.Lead},
+		{"signature", l.Signature},
+		{"padding", l.Padding},
+		{"header", l.Header},
+		{"payload", l.Payload},
+	} {
+		var err error
+		if v.r.Len < 0 {
+			_, err = fmt.Fprintf(w, "%-10s 0x%08x-%-10s %s\n",
+				v.name, v.r.Off, "?", "?")
+		} else {
+			_, err = fmt.Fprintf(w, "%-10s 0x%08x-0x%08x %d\n",
+				v.name, v.r.Off, v.r.End(), v.r.Len)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sigSize returns the header+payload size from the signature header
+// and the tag it's in, RPMSIGTAG_LONGSIZE for packages above 4GiB.
+func sigSize(sig *Header) (int64, TagType, bool) {
+	n, t, ok := sizeTag(sig, RPMSIGTAG_SIZE, RPMSIGTAG_LONGSIZE)
+	return int64(n), t, ok
+}
+
+// ArchiveSize returns the uncompressed payload size from the signature
+// header, or from the main header as packages before rpm 4.12 have it.
+func ArchiveSize(sig, hdr *Header) (int64, bool) {
+	for _, v := range []struct {
+		hdr *Header
+		tag TagType
+	}{
+		{sig, RPMSIGTAG_LONGARCHIVESIZE},
+		{sig, RPMSIGTAG_PAYLOADSIZE},
+		{hdr, RPMTAG_LONGARCHIVESIZE},
+		{hdr, RPMTAG_ARCHIVESIZE},
+	} {
+		t := v.hdr.Find(v.tag)
+		if t == nil {
+			continue
+		}
+		if r, ok := t.Int64(); ok && len(r) > 0 {
+			return int64(r[0]), true
+		}
+		if r, ok := t.Int32(); ok && len(r) > 0 {
+			return int64(r[0]), true
+		}
+	}
+	return 0, false
+}
+
+// ReadPackage reads the lead and both headers, r is left at the
+// start of the payload.
+func ReadPackage(r io.Reader) (*Package, error) {
+	return NewReader(r).pkg()
+}
+
+func (r *Reader) pkg() (*Package, error) {
+	var (
+		err error
+		p   = new(Package)
+	)
+
+	if p.Lead, err = r.Lead(); err != nil {
+		return nil, err
+	}
+	p.layout.Lead = Range{0, int64(r.off)}
+
+	start := int64(r.off+0x7) &^ 0x7
+	if p.Signature, err = r.Signature(); err != nil {
+		return nil, err
+	}
+	p.layout.Signature = Range{start, int64(r.off) - start}
+
+	start = int64(r.off+0x7) &^ 0x7
+	p.layout.Padding = Range{int64(r.off), start - int64(r.off)}
+	if p.Header, err = r.Header(); err != nil {
+		return nil, err
+	}
+	p.layout.Header = Range{start, int64(r.off) - start}
+
+	p.layout.Payload = Range{int64(r.off), -1}
+	if n, _, ok := sigSize(p.Signature); ok {
+		p.layout.Payload.Len = n - p.layout.Header.Len
+	}
+	return p, nil
+}
+
+func (p *Package) Layout() Layout { return p.layout }
@@ -0,0 +1,180 @@
+package rpm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func makePackage(t *testing.T, payload []byte) *bytes.Buffer {
+	hdr := makeHdr()
+	hdr.SetRegion(HEADER_IMMUTABLE)
+	hb := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(hb); err != nil {
+		t.Fatalf("hdr write: %v", err)
+	}
+
+	sig := NewSignatureHeader()
+	sig.AddString(RPMSIGTAG_SHA256, "odd")
+	sig.AddInt32(RPMSIGTAG_SIZE, uint32(hb.Len()+len(payload)))
+
+	b := new(bytes.Buffer)
+	if _, err := WriteHeaders(b,
+		NewLead("test", LeadBinary), sig, hb,
+	); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	b.Write(payload)
+	return b
+}
+
+func TestPackageLayout(t *testing.T) {
+	payload := []byte("payload")
+	b := makePackage(t, payload)
+	size := int64(b.Len())
+
+	p, err := ReadPackage(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	l := p.Layout()
+
+	if a := l.Lead; a != (Range{0, 96}) {
+		t.Fatalf("lead: %+v", a)
+	}
+	if a, b := l.Signature.Off, l.Lead.End(); a != b {
+		t.Fatalf("signature offset: want %d, have %d", b, a)
+	}
+	if a, b := l.Padding.Off, l.Signature.End(); a != b {
+		t.Fatalf("padding offset: want %d, have %d", b, a)
+	}
+	if a, b := l.Header.Off, l.Padding.End(); a != b || a&0x7 != 0 {
+		t.Fatalf("header offset: want %d, have %d", b, a)
+	}
+	if a, b := l.Payload, (Range{l.Header.End(), int64(len(payload))}); a != b {
+		t.Fatalf("payload: want %+v, have %+v", b, a)
+	}
+	if a := l.Payload.End(); a != size {
+		t.Fatalf("payload end: want %d, have %d", size, a)
+	}
+	if a := b.String(); a != string(payload) {
+		t.Fatalf("payload data: %q", a)
+	}
+}
+
+func TestPackageTruncated(t *testing.T) {
+	data := makePackage(t, nil).Bytes()
+	for i := 0; i < len(data); i++ {
+		_, err := ReadPackage(bytes.NewReader(data[:i]))
+		if !errors.Is(err, errUnexpectedEOF) {
+			t.Fatalf("length %d: expected unexpected EOF, got: %v", i, err)
+		}
+		var oe *OffsetError
+		if !errors.As(err, &oe) {
+			t.Fatalf("length %d: expected offset, got: %v", i, err)
+		}
+		if oe.Off > int64(i) {
+			t.Fatalf("length %d: offset past end: 0x%x", i, oe.Off)
+		}
+	}
+	if _, err := ReadPackage(bytes.NewReader(data)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+}
+
+func TestPackageOffsets(t *testing.T) {
+	data := makePackage(t, []byte("payload")).Bytes()
+	p, err := ReadPackage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	l := p.Layout()
+
+	// corrupt the header magic and an entry of the main header
+	for _, v := range []struct {
+		off int64
+		err error
+	}{
+		{l.Signature.Off, errInvalidHeader},
+		{l.Header.Off, errInvalidHeader},
+		{l.Header.Off + tagSize, errOffsetOOB},
+	} {
+		b := append([]byte(nil), data...)
+		if v.err == errOffsetOOB {
+			copy(b[v.off+8:], []byte{0xff, 0xff, 0xff, 0xff})
+		} else {
+			b[v.off] ^= 0xff
+		}
+		_, err := ReadPackage(bytes.NewReader(b))
+		if !errors.Is(err, v.err) {
+			t.Fatalf("0x%x: expected %v, got: %v", v.off, v.err, err)
+		}
+		var oe *OffsetError
+		if !errors.As(err, &oe) || oe.Off != v.off {
+			t.Fatalf("0x%x: expected offset, got: %v", v.off, err)
+		}
+	}
+
+	r := NewReader(bytes.NewReader(data))
+	if _, err := r.Lead(); err != nil {
+		t.Fatalf("lead: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != nil {
+			t.Fatalf("hdr%d: %v", i+1, err)
+		}
+	}
+	if a, b := r.Offset(), l.Payload.Off; a != b {
+		t.Fatalf("payload offset: want 0x%x, have 0x%x", b, a)
+	}
+}
+
+func TestReaderEOF(t *testing.T) {
+	data := makePackage(t, nil).Bytes()
+	r := NewReader(bytes.NewReader(data))
+	if _, err := r.Lead(); err != nil {
+		t.Fatalf("lead: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != nil {
+			t.Fatalf("hdr%d: %v", i+1, err)
+		}
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got: %v", err)
+	}
+}
+
+func TestHeaderKind(t *testing.T) {
+	// neither header has a region tag
+	sig := new(Header)
+	sig.AddString(RPMSIGTAG_SHA256, "odd")
+	hdr := makeHdr()
+	b := new(bytes.Buffer)
+	if _, err := WriteHeaders(b, NewLead("test", LeadBinary), sig, hdr); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data := b.Bytes()
+
+	p, err := ReadPackage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if a, b := p.Signature.Kind(), p.Header.Kind(); a != KindSignature || b != KindMain {
+		t.Fatalf("kinds: %s, %s", a, b)
+	}
+
+	r := NewReader(bytes.NewReader(data))
+	r.Lead()
+	if _, err := r.Header(); !errors.Is(err, errHeaderKind) {
+		t.Fatalf("header first: %v", err)
+	}
+
+	if k := NewSignatureHeader().Kind(); k != KindSignature {
+		t.Fatalf("region kind: %s", k)
+	}
+	if k := makeHdr().Kind(); k != KindUnknown {
+		t.Fatalf("no region kind: %s", k)
+	}
+}
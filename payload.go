@@ -0,0 +1,65 @@
+package rpm
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+var errCompressor = errors.New("rpm: unsupported payload compressor")
+
+// DecompressPayload returns the cpio archive of the payload r, by the
+// RPMTAG_PAYLOADCOMPRESSOR of hdr: xz, zstd, bzip2 and lzma of old
+// packages, or gzip. Without the tag, an archive is read as is and
+// anything else as gzip, like rpm does. The payload digest is of r, see
+// PayloadReader.
+func DecompressPayload(hdr *Header, r io.Reader) (io.ReadCloser, error) {
+	c, ok := hdr.StringData(RPMTAG_PAYLOADCOMPRESSOR)
+	if !ok {
+		// tar2rpm -compress none writes no compressor, like rpm's gzdio
+		// read an uncompressed archive as is
+		br := bufio.NewReader(r)
+		if magic, _ := br.Peek(4); string(magic) == "0707" {
+			return io.NopCloser(br), nil
+		}
+		r, c = br, "gzip"
+	}
+	switch c {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "xz":
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case "zstd":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case "bzip2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case "lzma":
+		lr, err := lzma.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(lr), nil
+	}
+	return nil, fmt.Errorf("%w: %q", errCompressor, c)
+}
+
+// Payload returns the cpio archive of the payload that follows the main
+// header hdr, see DecompressPayload.
+func (r *Reader) Payload(hdr *Header) (io.ReadCloser, error) {
+	return DecompressPayload(hdr, r.r)
+}
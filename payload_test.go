@@ -0,0 +1,126 @@
+package rpm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pschou/go-rpm/scpio"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+func TestDecompressPayload(t *testing.T) {
+	archive := new(bytes.Buffer)
+	cw := scpio.NewWriter(archive)
+	cw.WriteHeader(0)
+	io.WriteString(cw, "data")
+	cw.Close()
+
+	for _, v := range []struct {
+		name string
+		w    func(io.Writer) (io.WriteCloser, error)
+	}{
+		{"", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }},
+		{"gzip", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }},
+		{"xz", func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) }},
+		{"zstd", func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }},
+		{"zstd", func(w io.Writer) (io.WriteCloser, error) {
+			// like Fedora's w19.zstdio, with a larger window
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(19)), zstd.WithWindowSize(64<<20))
+		}},
+	} {
+		hdr := NewPayloadHeader()
+		hdr.AddString(RPMTAG_NAME, "test")
+		if v.name != "" {
+			hdr.AddString(RPMTAG_PAYLOADCOMPRESSOR, v.name)
+		}
+		spool := new(bytes.Buffer)
+		if _, err := hdr.WriteTo(spool); err != nil {
+			t.Fatal(err)
+		}
+		w, err := v.w(spool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(archive.Bytes())
+		w.Close()
+		sig := NewSignatureHeader()
+		sig.AddString(RPMSIGTAG_SHA256, "odd")
+		b := new(bytes.Buffer)
+		if _, err := WriteHeaders(b, NewLead("test", LeadBinary), sig, spool); err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewReader(b)
+		r.Lead()
+		r.Signature()
+		h, err := r.Header()
+		if err != nil {
+			t.Fatalf("%q: %v", v.name, err)
+		}
+		pr, err := r.Payload(h)
+		if err != nil {
+			t.Fatalf("%q: %v", v.name, err)
+		}
+		data, err := io.ReadAll(pr)
+		pr.Close()
+		if err != nil || !bytes.Equal(data, archive.Bytes()) {
+			t.Errorf("%q: %q, %v", v.name, data, err)
+		}
+	}
+
+	// tar2rpm -compress none
+	pr, err := DecompressPayload(NewPayloadHeader(), bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(pr); err != nil || !bytes.Equal(data, archive.Bytes()) {
+		t.Errorf("uncompressed: %q, %v", data, err)
+	}
+
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_PAYLOADCOMPRESSOR, "lz4")
+	if _, err := DecompressPayload(hdr, nil); !errors.Is(err, errCompressor) {
+		t.Errorf("lz4: %v", err)
+	}
+}
+
+// bzip2Archive is the archive of TestDecompressPayload by bzip2 -9,
+// there's no bzip2 writer in Go.
+const bzip2Archive = "425a68393141592653598a841d8f0000105f8044002800688022241440340004002000314c989906460d3449a7a26329e8a5148b061a508392e186499750d9281e371131ed556f9d1772453850908a841d8f"
+
+func TestDecompressLegacyPayload(t *testing.T) {
+	archive := new(bytes.Buffer)
+	cw := scpio.NewWriter(archive)
+	cw.WriteHeader(0)
+	io.WriteString(cw, "data")
+	cw.Close()
+
+	bz, _ := hex.DecodeString(bzip2Archive)
+	lz := new(bytes.Buffer)
+	w, err := lzma.NewWriter(lz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(archive.Bytes())
+	w.Close()
+
+	for name, payload := range map[string][]byte{"bzip2": bz, "lzma": lz.Bytes()} {
+		hdr := NewPayloadHeader()
+		hdr.AddString(RPMTAG_PAYLOADCOMPRESSOR, name)
+		r, err := DecompressPayload(hdr, bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || !bytes.Equal(data, archive.Bytes()) {
+			t.Errorf("%s: %q, %v", name, data, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package rpm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy configures the content checks of Check, zero limits are
+// unchecked. Setuid, setgid and world writable files are violations
+// unless allowed by path.
+type Policy struct {
+	MaxSize            uint64   `json:",omitempty"` // header and payload
+	MaxInstalledSize   uint64   `json:",omitempty"`
+	ForbiddenPaths     []string `json:",omitempty"` // and anything below
+	AllowSetuid        []string `json:",omitempty"`
+	AllowWorldWritable []string `json:",omitempty"`
+}
+
+// DefaultPolicy forbids files below /usr/local, which belongs to the
+// local administrator.
+var DefaultPolicy = Policy{
+	ForbiddenPaths: []string{"/usr/local"},
+}
+
+// Violation is a failed policy check, File is empty for checks of the
+// whole package.
+type Violation struct {
+	Warning
+	File string
+}
+
+func (v Violation) String() string {
+	if v.File == "" {
+		return v.Warning.String()
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Code, v.File, v.Msg)
+}
+
+func sizeTag(h *Header, tag, long TagType) (uint64, TagType, bool) {
+	if t := h.Find(long); t != nil {
+		if v, ok := t.Int64(); ok && len(v) > 0 {
+			return v[0], long, true
+		}
+	}
+	if t := h.Find(tag); t != nil {
+		if v, ok := t.Int32(); ok && len(v) > 0 {
+			return uint64(v[0]), tag, true
+		}
+	}
+	return 0, 0, false
+}
+
+func below(name, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+	return name == dir || strings.HasPrefix(name, dir+"/")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the violations of p by the package of sig and hdr, sig
+// may be nil.
+func (p *Policy) Check(sig, hdr *Header) []Violation {
+	var r []Violation
+	if sig != nil && p.MaxSize > 0 {
+		// signature tags would print as header tags
+		if n, _, ok := sizeTag(sig, RPMSIGTAG_SIZE, RPMSIGTAG_LONGSIZE); ok && n > p.MaxSize {
+			r = append(r, Violation{Warning: Warning{0, "package-size",
+				fmt.Sprintf("%d bytes, more than %d", n, p.MaxSize)}})
+		}
+	}
+	if p.MaxInstalledSize > 0 {
+		if n, t, ok := sizeTag(hdr, RPMTAG_SIZE, RPMTAG_LONGSIZE); ok && n > p.MaxInstalledSize {
+			r = append(r, Violation{Warning: Warning{t, "installed-size",
+				fmt.Sprintf("%d bytes, more than %d", n, p.MaxInstalledSize)}})
+		}
+	}
+
+	f, err := FileIndexHeader(hdr)
+	if err != nil {
+		return append(r, Violation{Warning: Warning{0, "file-index", err.Error()}})
+	}
+	for i, name := range f.Filenames() {
+		for _, v := range p.ForbiddenPaths {
+			if below(name, v) {
+				r = append(r, Violation{Warning{RPMTAG_BASENAMES, "forbidden-path",
+					"below " + v}, name})
+				break
+			}
+		}
+		if i >= len(f.mode) {
+			continue
+		}
+		mode := f.mode[i]
+		if mode&06000 != 0 && !contains(p.AllowSetuid, name) {
+			r = append(r, Violation{Warning{RPMTAG_FILEMODES, "setuid",
+				fmt.Sprintf("mode %04o not allowed", mode&07777)}, name})
+		}
+		// symlinks are always 0777, the sticky bit protects directories
+		world := mode&0002 != 0 && mode>>12 != typeSymlink &&
+			!(mode>>12 == typeDir && mode&01000 != 0)
+		if world && !contains(p.AllowWorldWritable, name) {
+			r = append(r, Violation{Warning{RPMTAG_FILEMODES, "world-writable",
+				fmt.Sprintf("mode %04o not allowed", mode&07777)}, name})
+		}
+	}
+	return r
+}
@@ -0,0 +1,56 @@
+package rpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	fi := NewFileIndex()
+	for _, v := range []*File{
+		{Name: "/usr/bin/su", Mode: typeRegular<<12 | 04755, Size: 10},
+		{Name: "/usr/bin/ping", Mode: typeRegular<<12 | 02755, Size: 10},
+		{Name: "/usr/local/bin/foo", Mode: typeRegular<<12 | 0755, Size: 10},
+		{Name: "/usr/localfoo", Mode: typeRegular<<12 | 0644},
+		{Name: "/var/spool/foo", Mode: typeDir<<12 | 01777},
+		{Name: "/var/lib/foo", Mode: typeDir<<12 | 0777},
+		{Name: "/usr/bin/link", Mode: typeSymlink<<12 | 0777, LinkTo: "su"},
+	} {
+		fi.Add(v)
+	}
+	hdr := new(Header)
+	fi.Append(hdr)
+	sig := NewSignatureHeader()
+	sig.AddInt32(RPMSIGTAG_SIZE, 1000)
+
+	codes := func(v []Violation) []string {
+		var r []string
+		for _, v := range v {
+			r = append(r, v.Code+" "+v.File)
+		}
+		return r
+	}
+
+	p := DefaultPolicy
+	p.MaxSize = 999
+	p.MaxInstalledSize = 30
+	if a, b := codes(p.Check(sig, hdr)), []string{
+		"package-size ",
+		"setuid /usr/bin/su",
+		"setuid /usr/bin/ping",
+		"forbidden-path /usr/local/bin/foo",
+		"world-writable /var/lib/foo",
+	}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("violations:\n%q\n%q", a, b)
+	}
+
+	p = Policy{
+		MaxSize:            1000,
+		MaxInstalledSize:   29,
+		AllowSetuid:        []string{"/usr/bin/su", "/usr/bin/ping"},
+		AllowWorldWritable: []string{"/var/lib/foo"},
+	}
+	if a, b := codes(p.Check(nil, hdr)), []string{"installed-size "}; !reflect.DeepEqual(a, b) {
+		t.Fatalf("violations:\n%q\n%q", a, b)
+	}
+}
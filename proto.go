@@ -0,0 +1,574 @@
+package rpm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The protocol buffer encodings are the messages of rpm.proto, version 1
+// of the schema. Decoding skips unknown fields, so messages of later
+// versions can be read, and fails on values out of range of their Go
+// type.
+
+var errProto = errors.New("rpm: invalid protocol buffer")
+
+// protoField is a field of a message, x is the value of a varint, b of
+// a length delimited field.
+type protoField struct {
+	num protowire.Number
+	typ protowire.Type
+	x   uint64
+	b   []byte
+}
+
+// protoRange calls f for each field of the message b.
+func protoRange(b []byte, f func(v protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errProto
+		}
+		b = b[n:]
+		v := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			v.x, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			v.b, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errProto
+		}
+		b = b[n:]
+		if err := f(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoDecoder converts fields, keeping the first error.
+type protoDecoder struct{ err error }
+
+func (d *protoDecoder) fail(v protoField) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: field %d", errProto, v.num)
+	}
+}
+
+func (d *protoDecoder) uint(v protoField, limit uint64) uint64 {
+	if v.typ != protowire.VarintType || v.x > limit {
+		d.fail(v)
+		return 0
+	}
+	return v.x
+}
+
+func (d *protoDecoder) uint32(v protoField) uint32 {
+	return uint32(d.uint(v, math.MaxUint32))
+}
+
+func (d *protoDecoder) bytes(v protoField) []byte {
+	if v.typ != protowire.BytesType {
+		d.fail(v)
+		return nil
+	}
+	return v.b
+}
+
+func (d *protoDecoder) string(v protoField) string { return string(d.bytes(v)) }
+
+// uints appends the values of a repeated field, packed or not.
+func (d *protoDecoder) uints(r []uint64, v protoField, limit uint64) []uint64 {
+	if v.typ != protowire.BytesType {
+		return append(r, d.uint(v, limit))
+	}
+	for b := v.b; len(b) > 0; {
+		x, n := protowire.ConsumeVarint(b)
+		if n < 0 || x > limit {
+			d.fail(v)
+			return r
+		}
+		r = append(r, x)
+		b = b[n:]
+	}
+	return r
+}
+
+func appendProtoUint(b []byte, num protowire.Number, x uint64) []byte {
+	if x == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, x)
+}
+
+func appendProtoBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendProtoUints appends a packed repeated field.
+func appendProtoUints[T uint16 | uint32 | uint64](b []byte, num protowire.Number, v []T) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	var p []byte
+	for _, x := range v {
+		p = protowire.AppendVarint(p, uint64(x))
+	}
+	return appendProtoBytes(b, num, p)
+}
+
+func (t *Tag) marshalProto() ([]byte, error) {
+	b := appendProtoUint(nil, 1, uint64(t.Tag))
+	b = appendProtoUint(b, 2, uint64(t.Type))
+	var ok bool
+	switch t.Type {
+	case RPM_STRING_TYPE, RPM_STRING_ARRAY_TYPE, RPM_I18NSTRING_TYPE:
+		var s []string
+		if s, ok = t.StringArray(); ok {
+			for _, v := range s {
+				b = protowire.AppendTag(b, 5, protowire.BytesType)
+				b = protowire.AppendString(b, v)
+			}
+		}
+	case RPM_INT16_TYPE:
+		var i []uint16
+		i, ok = t.Int16()
+		b = appendProtoUints(b, 3, i)
+	case RPM_INT32_TYPE:
+		var i []uint32
+		i, ok = t.Int32()
+		b = appendProtoUints(b, 3, i)
+	case RPM_INT64_TYPE:
+		var i []uint64
+		i, ok = t.Int64()
+		b = appendProtoUints(b, 4, i)
+	case RPM_CHAR_TYPE, RPM_INT8_TYPE, RPM_BIN_TYPE:
+		var d []byte
+		if d, ok = t.Bytes(); ok && len(d) > 0 {
+			b = appendProtoBytes(b, 6, d)
+		}
+	}
+	if !ok {
+		return nil, tagError{t, errTagType}
+	}
+	return b, nil
+}
+
+// unmarshalProtoTag decodes a Tag message, only the data field of its
+// type may be set.
+func unmarshalProtoTag(b []byte) (*Tag, error) {
+	var (
+		d     protoDecoder
+		t     = new(Tag)
+		ints  []uint64
+		strs  []string
+		bin   []byte
+		field = make(map[protowire.Number]bool)
+	)
+	err := protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			t.Tag = TagType(d.uint32(v))
+		case 2:
+			t.Type = d.uint32(v)
+		case 3:
+			ints = d.uints(ints, v, math.MaxUint32)
+		case 4:
+			ints = d.uints(ints, v, math.MaxUint64)
+		case 5:
+			strs = append(strs, d.string(v))
+		case 6:
+			bin = d.bytes(v)
+		}
+		field[v.num] = true
+		return d.err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	want := protowire.Number(6)
+	switch t.Type {
+	case RPM_STRING_TYPE, RPM_I18NSTRING_TYPE:
+		if len(strs) == 0 || t.Type == RPM_STRING_TYPE && len(strs) > 1 {
+			return nil, tagError{t, errProto}
+		}
+		fallthrough
+	case RPM_STRING_ARRAY_TYPE:
+		want = 5
+		t.data = &tagString{data: strs}
+		t.Count = uint32(len(strs))
+	case RPM_INT16_TYPE:
+		want = 3
+		r := make(tagUint16, len(ints))
+		for i, v := range ints {
+			if v > math.MaxUint16 {
+				return nil, tagError{t, errProto}
+			}
+			r[i] = uint16(v)
+		}
+		t.data, t.Count = r, uint32(len(r))
+	case RPM_INT32_TYPE:
+		want = 3
+		r := make(tagUint32, len(ints))
+		for i, v := range ints {
+			r[i] = uint32(v)
+		}
+		t.data, t.Count = r, uint32(len(r))
+	case RPM_INT64_TYPE:
+		want = 4
+		t.data, t.Count = tagUint64(ints), uint32(len(ints))
+	case RPM_CHAR_TYPE, RPM_INT8_TYPE, RPM_BIN_TYPE:
+		t.data = &tagBytes{b: bytes.NewBuffer(bytes.Clone(bin))}
+		t.Count = uint32(len(bin))
+	default:
+		return nil, tagError{t, errTagType}
+	}
+	for _, v := range []protowire.Number{3, 4, 5, 6} {
+		if field[v] && v != want {
+			return nil, tagError{t, errProto}
+		}
+	}
+	return t, nil
+}
+
+// MarshalProto returns hdr as a Header message of rpm.proto.
+func (hdr *Header) MarshalProto() ([]byte, error) {
+	hdr.settle()
+	var b []byte
+	if hdr.region != nil {
+		b = appendProtoUint(b, 1, uint64(hdr.region.Tag))
+	}
+	for _, v := range hdr.Tags {
+		t, err := v.marshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 2, t)
+	}
+	return b, nil
+}
+
+// UnmarshalProto sets hdr to the Header message b. The tags are added
+// like with Add, the region must be HEADER_IMMUTABLE or
+// HEADER_SIGNATURES.
+func (hdr *Header) UnmarshalProto(b []byte) error {
+	var (
+		d      protoDecoder
+		region uint32
+		tags   []*Tag
+	)
+	err := protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			region = d.uint32(v)
+		case 2:
+			b := d.bytes(v)
+			if d.err != nil {
+				return d.err
+			}
+			t, err := unmarshalProtoTag(b)
+			if err != nil {
+				return err
+			}
+			tags = append(tags, t)
+		}
+		return d.err
+	})
+	if err != nil {
+		return err
+	}
+
+	h := new(Header)
+	switch region {
+	case 0:
+	case HEADER_IMMUTABLE, HEADER_SIGNATURES:
+		h.SetRegion(TagType(region))
+	default:
+		return fmt.Errorf("%w: region %d", errProto, region)
+	}
+	for _, v := range tags {
+		if err := h.Add(v); err != nil {
+			return err
+		}
+	}
+	*hdr = *h
+	return nil
+}
+
+// MarshalProto returns l as a Lead message of rpm.proto.
+func (l *Lead) MarshalProto() ([]byte, error) {
+	b := appendProtoUint(nil, 1, uint64(l.Major))
+	b = appendProtoUint(b, 2, uint64(l.Minor))
+	b = appendProtoUint(b, 3, uint64(l.Type))
+	b = appendProtoUint(b, 4, uint64(l.ArchNum))
+	b = appendProtoString(b, 5, string(bytes.TrimRight(l.Name[:], "\x00")))
+	b = appendProtoUint(b, 6, uint64(l.OsNum))
+	b = appendProtoUint(b, 7, uint64(l.SignatureType))
+	return b, nil
+}
+
+// UnmarshalProto sets l to the Lead message b, with the lead magic.
+func (l *Lead) UnmarshalProto(b []byte) error {
+	var (
+		d protoDecoder
+		r = Lead{Magic: leadMagic}
+	)
+	err := protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			r.Major = uint8(d.uint(v, math.MaxUint8))
+		case 2:
+			r.Minor = uint8(d.uint(v, math.MaxUint8))
+		case 3:
+			r.Type = LeadType(d.uint(v, math.MaxUint16))
+		case 4:
+			r.ArchNum = uint16(d.uint(v, math.MaxUint16))
+		case 5:
+			// the name is NUL terminated
+			if s := d.bytes(v); len(s) < len(r.Name) {
+				copy(r.Name[:], s)
+			} else {
+				d.fail(v)
+			}
+		case 6:
+			r.OsNum = uint16(d.uint(v, math.MaxUint16))
+		case 7:
+			r.SignatureType = uint16(d.uint(v, math.MaxUint16))
+		}
+		return d.err
+	})
+	if err != nil {
+		return err
+	}
+	*l = r
+	return nil
+}
+
+// protoMessage is implemented by the types with a message in rpm.proto.
+type protoMessage interface {
+	MarshalProto() ([]byte, error)
+	UnmarshalProto(b []byte) error
+}
+
+// MarshalProto returns p as a Package message of rpm.proto.
+func (p *Package) MarshalProto() ([]byte, error) {
+	var b []byte
+	for i, v := range []struct {
+		m  protoMessage
+		ok bool
+	}{
+		{p.Lead, p.Lead != nil},
+		{p.Signature, p.Signature != nil},
+		{p.Header, p.Header != nil},
+	} {
+		if !v.ok {
+			continue
+		}
+		m, err := v.m.MarshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, protowire.Number(i+1), m)
+	}
+	return b, nil
+}
+
+// UnmarshalProto sets p to the Package message b, absent fields are
+// nil.
+func (p *Package) UnmarshalProto(b []byte) error {
+	var (
+		d protoDecoder
+		r Package
+	)
+	err := protoRange(b, func(v protoField) error {
+		var m protoMessage
+		switch v.num {
+		case 1:
+			r.Lead = new(Lead)
+			m = r.Lead
+		case 2:
+			r.Signature = new(Header)
+			m = r.Signature
+		case 3:
+			r.Header = new(Header)
+			m = r.Header
+		default:
+			return nil
+		}
+		b := d.bytes(v)
+		if d.err != nil {
+			return d.err
+		}
+		return m.UnmarshalProto(b)
+	})
+	if err != nil {
+		return err
+	}
+	*p = r
+	return nil
+}
+
+func (f *File) marshalProto() []byte {
+	b := appendProtoString(nil, 1, f.Name)
+	b = appendProtoString(b, 2, f.User)
+	b = appendProtoString(b, 3, f.Group)
+	b = appendProtoUint(b, 4, uint64(f.Mode))
+	b = appendProtoString(b, 5, f.LinkTo)
+	b = appendProtoUint(b, 6, uint64(f.MTime))
+	b = appendProtoString(b, 7, f.Digest)
+	b = appendProtoUint(b, 8, uint64(f.NoVerify))
+	b = appendProtoUint(b, 9, f.Size)
+	b = appendProtoUint(b, 10, uint64(f.Flags))
+	b = appendProtoString(b, 11, f.Caps)
+	return appendProtoString(b, 12, f.Context)
+}
+
+func (f *File) unmarshalProto(b []byte) error {
+	var d protoDecoder
+	return protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			f.Name = d.string(v)
+		case 2:
+			f.User = d.string(v)
+		case 3:
+			f.Group = d.string(v)
+		case 4:
+			f.Mode = uint16(d.uint(v, math.MaxUint16))
+		case 5:
+			f.LinkTo = d.string(v)
+		case 6:
+			f.MTime = d.uint32(v)
+		case 7:
+			f.Digest = d.string(v)
+		case 8:
+			f.NoVerify = d.uint32(v)
+		case 9:
+			f.Size = d.uint(v, math.MaxUint64)
+		case 10:
+			f.Flags = d.uint32(v)
+		case 11:
+			f.Caps = d.string(v)
+		case 12:
+			f.Context = d.string(v)
+		}
+		return d.err
+	})
+}
+
+// MarshalProto returns f as a FileIndex message of rpm.proto.
+func (f *FileIndex) MarshalProto() ([]byte, error) {
+	b := appendProtoUint(nil, 1, uint64(f.algo))
+	if f.legacy {
+		b = appendProtoUint(b, 2, 1)
+	}
+	for _, v := range f.Files() {
+		b = appendProtoBytes(b, 3, v.marshalProto())
+	}
+	return b, nil
+}
+
+// UnmarshalProto sets f to the FileIndex message b, the files are added
+// like with Add.
+func (f *FileIndex) UnmarshalProto(b []byte) error {
+	var (
+		d      protoDecoder
+		r      = NewFileIndex()
+		algo   uint32
+		legacy bool
+	)
+	err := protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			algo = d.uint32(v)
+		case 2:
+			legacy = d.uint(v, 1) == 1
+		case 3:
+			var file File
+			b := d.bytes(v)
+			if d.err != nil {
+				return d.err
+			}
+			if err := file.unmarshalProto(b); err != nil {
+				return err
+			}
+			r.Add(&file)
+		}
+		return d.err
+	})
+	if err != nil {
+		return err
+	}
+	if algo != 0 {
+		if err := r.SetDigestAlgo(algo); err != nil {
+			return err
+		}
+	}
+	r.legacy = legacy
+	*f = *r
+	return nil
+}
+
+// MarshalProto returns i as a PkgInfo message of rpm.proto.
+func (i *PkgInfo) MarshalProto() ([]byte, error) {
+	b := appendProtoString(nil, 1, i.Name)
+	b = appendProtoUint(b, 2, uint64(i.Epoch))
+	b = appendProtoString(b, 3, i.Version)
+	b = appendProtoString(b, 4, i.Release)
+	b = appendProtoString(b, 5, i.Arch)
+	b = appendProtoString(b, 6, i.Summary)
+	b = appendProtoString(b, 7, i.License)
+	return appendProtoString(b, 8, i.LicenseExpression), nil
+}
+
+// UnmarshalProto sets i to the PkgInfo message b.
+func (i *PkgInfo) UnmarshalProto(b []byte) error {
+	var (
+		d protoDecoder
+		r PkgInfo
+	)
+	err := protoRange(b, func(v protoField) error {
+		switch v.num {
+		case 1:
+			r.Name = d.string(v)
+		case 2:
+			r.Epoch = d.uint32(v)
+		case 3:
+			r.Version = d.string(v)
+		case 4:
+			r.Release = d.string(v)
+		case 5:
+			r.Arch = d.string(v)
+		case 6:
+			r.Summary = d.string(v)
+		case 7:
+			r.License = d.string(v)
+		case 8:
+			r.LicenseExpression = d.string(v)
+		}
+		return d.err
+	})
+	if err != nil {
+		return err
+	}
+	*i = r
+	return nil
+}
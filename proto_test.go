@@ -0,0 +1,141 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeBytes returns the on-disk bytes of hdr.
+func writeBytes(t *testing.T, hdr *Header) []byte {
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestProto(t *testing.T) {
+	p, err := ReadPackage(makePackage(t, nil))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	b, err := p.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pd Package
+	if err := pd.UnmarshalProto(b); err != nil {
+		t.Fatal(err)
+	}
+	if *pd.Lead != *p.Lead {
+		t.Fatalf("lead mismatch")
+	}
+	for _, v := range [][2]*Header{{p.Signature, pd.Signature}, {p.Header, pd.Header}} {
+		if !bytes.Equal(writeBytes(t, v[0]), writeBytes(t, v[1])) {
+			t.Errorf("%v header bytes differ", v[0].Kind())
+		}
+		hdrEq(t, v[0], v[1])
+	}
+
+	fi := NewFileIndex()
+	fi.SetDigestAlgo(PGPHASHALGO_SHA256)
+	fi.Add(&File{Name: "/usr/bin/foo", Size: 3, Mode: 0o100755, Digest: "00", MTime: 1})
+	fi.Add(&File{Name: "/etc/foo", User: "foo", Flags: RPMFILE_CONFIG, Caps: "cap_net_raw=ep", NoVerify: 4})
+	fi.Add(&File{Name: "/usr/lib/big", Size: 1 << 33})
+	fi.ExpandFilenames()
+	if b, err = fi.MarshalProto(); err != nil {
+		t.Fatal(err)
+	}
+	var fid FileIndex
+	if err := fid.UnmarshalProto(b); err != nil {
+		t.Fatal(err)
+	}
+	diff(t, fi, &fid)
+	h1, h2 := new(Header), new(Header)
+	fi.Append(h1)
+	fid.Append(h2)
+	if !bytes.Equal(writeBytes(t, h1), writeBytes(t, h2)) {
+		t.Errorf("file index tags differ")
+	}
+
+	info := PkgInfo{Name: "foo", EVR: EVR{1, "2", "3"}, Arch: "noarch", License: "MIT", LicenseExpression: "MIT"}
+	if b, err = info.MarshalProto(); err != nil {
+		t.Fatal(err)
+	}
+	var id PkgInfo
+	if err := id.UnmarshalProto(b); err != nil || id != info {
+		t.Fatalf("pkginfo: %+v, %v", id, err)
+	}
+}
+
+// TestProtoWire pins the encoding to rpm.proto.
+func TestProtoWire(t *testing.T) {
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddInt32(RPMTAG_SIZE, 1, 300)
+	b, err := hdr.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// region 63, tag 1000 type 6 string "foo", tag 1009 type 4 int
+	// packed 1, 300
+	want := "083f" + "120a08e80710062a03666f6f" + "120a08f10710041a0301ac02"
+	if h := hex.EncodeToString(b); h != want {
+		t.Fatalf("have %s\nwant %s", h, want)
+	}
+
+	// unpacked ints and unknown fields are read
+	b, _ = hex.DecodeString("083f" + "120d08f1071004180118ac02f80101" + "a00601")
+	var hd Header
+	if err := hd.UnmarshalProto(b); err != nil {
+		t.Fatal(err)
+	}
+	if i, _ := hd.Find(RPMTAG_SIZE).Int32(); len(i) != 2 || i[1] != 300 || hd.Kind() != KindMain {
+		t.Fatalf("size %v", i)
+	}
+}
+
+func TestProtoInvalid(t *testing.T) {
+	tag := func(fields ...[]byte) []byte {
+		return protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), bytes.Join(fields, nil))
+	}
+	num := func(n protowire.Number, x uint64) []byte {
+		return protowire.AppendVarint(protowire.AppendTag(nil, n, protowire.VarintType), x)
+	}
+	str := func(n protowire.Number, s string) []byte {
+		return protowire.AppendString(protowire.AppendTag(nil, n, protowire.BytesType), s)
+	}
+	for name, v := range map[string]struct {
+		b   []byte
+		err error
+	}{
+		"truncated":    {tag(num(1, 1000))[:3], errProto},
+		"region":       {num(1, 61), errProto},
+		"region tag":   {append(num(1, 63), tag(num(1, 63), num(2, RPM_BIN_TYPE))...), errRegionTag},
+		"int16":        {tag(num(1, 1000), num(2, RPM_INT16_TYPE), num(3, 1<<16)), errProto},
+		"int32":        {tag(num(1, 1000), num(2, RPM_INT32_TYPE), num(3, 1<<32)), errProto},
+		"string field": {tag(num(1, 1000), num(2, RPM_INT32_TYPE), str(5, "foo")), errProto},
+		"two strings":  {tag(num(1, 1000), num(2, RPM_STRING_TYPE), str(5, "a"), str(5, "b")), errProto},
+		"no string":    {tag(num(1, 1000), num(2, RPM_STRING_TYPE)), errProto},
+		"null type":    {tag(num(1, 1000), str(5, "a")), errTagType},
+		"wire type":    {tag(str(1, "foo")), errProto},
+	} {
+		var hdr Header
+		if err := hdr.UnmarshalProto(v.b); !errors.Is(err, v.err) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	var l Lead
+	if err := l.UnmarshalProto(str(5, string(make([]byte, 66)))); !errors.Is(err, errProto) {
+		t.Errorf("lead name: %v", err)
+	}
+	var fi FileIndex
+	if err := fi.UnmarshalProto(num(1, 99)); !errors.Is(err, errDigestAlgo) {
+		t.Errorf("digest algo: %v", err)
+	}
+}
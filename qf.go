@@ -0,0 +1,488 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	tagNames     map[string]TagType
+	tagNamesOnce sync.Once
+)
+
+// ParseTagType parses a tag name with or without the RPMTAG_ prefix.
+func ParseTagType(name string) (TagType, bool) {
+	tagNamesOnce.Do(func() {
+		tagNames = make(map[string]TagType)
+		for i := TagType(0); i < 0x2000; i++ {
+			if s := i.String(); strings.HasPrefix(s, "RPMTAG_") {
+				tagNames[s[len("RPMTAG_"):]] = i
+			}
+		}
+	})
+	t, ok := tagNames[strings.TrimPrefix(strings.ToUpper(name), "RPMTAG_")]
+	return t, ok
+}
+
+// SigTagString returns the RPMSIGTAG_ name of a signature header tag,
+// its RPMTAG_ name if it has none. Signature tags below RPMTAG_SIG_BASE
+// share numbers with main header tags.
+func SigTagString(tag TagType) string {
+	if s, ok := sigTagString[tag]; ok {
+		return s
+	}
+	return tag.String()
+}
+
+// ParseSigTagType parses a signature tag name with or without the
+// RPMSIGTAG_ prefix.
+func ParseSigTagType(name string) (TagType, bool) {
+	name = "RPMSIGTAG_" + strings.TrimPrefix(strings.ToUpper(name), "RPMSIGTAG_")
+	for t, s := range sigTagString {
+		if s == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+type qfNode interface{}
+
+type qfText string
+
+type qfTag struct {
+	tag    TagType
+	width  string
+	format string
+	first  bool
+}
+
+type qfArray []qfNode
+
+type qfCond struct {
+	tag       TagType
+	then, els []qfNode
+}
+
+var errQueryFormat = errors.New("rpm: invalid query format")
+
+type qfParser struct {
+	s string
+	i int
+}
+
+func (p *qfParser) errorf(f string, a ...interface{}) error {
+	return fmt.Errorf("%w: offset %d: %s", errQueryFormat, p.i, fmt.Sprintf(f, a...))
+}
+
+func qfEscape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case 'a':
+		return '\a'
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'v':
+		return '\v'
+	}
+	return c
+}
+
+// parse reads nodes until one of the bytes in end.
+func (p *qfParser) parse(end string) ([]qfNode, error) {
+	var (
+		r    []qfNode
+		text []byte
+	)
+	flush := func() {
+		if len(text) > 0 {
+			r = append(r, qfText(text))
+			text = nil
+		}
+	}
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if strings.IndexByte(end, c) != -1 {
+			flush()
+			return r, nil
+		}
+		p.i++
+		switch c {
+		case '\\':
+			if p.i < len(p.s) {
+				text = append(text, qfEscape(p.s[p.i]))
+				p.i++
+			}
+		case '[':
+			flush()
+			n, err := p.parse("]")
+			if err != nil {
+				return nil, err
+			}
+			if p.i >= len(p.s) {
+				return nil, p.errorf("missing ]")
+			}
+			p.i++
+			r = append(r, qfArray(n))
+		case '%':
+			if p.i < len(p.s) && p.s[p.i] == '%' {
+				text = append(text, '%')
+				p.i++
+				continue
+			}
+			flush()
+			n, err := p.tag()
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, n)
+		default:
+			text = append(text, c)
+		}
+	}
+	if end != "" {
+		return nil, p.errorf("missing %q", end)
+	}
+	flush()
+	return r, nil
+}
+
+func (p *qfParser) name(end string) (string, error) {
+	i := strings.IndexAny(p.s[p.i:], end)
+	if i == -1 {
+		return "", p.errorf("missing %q", end)
+	}
+	r := p.s[p.i : p.i+i]
+	p.i += i
+	return r, nil
+}
+
+func (p *qfParser) lookup(name string) (TagType, error) {
+	t, ok := ParseTagType(name)
+	if !ok {
+		return 0, p.errorf("unknown tag: %q", name)
+	}
+	return t, nil
+}
+
+func (p *qfParser) expect(c byte) error {
+	if p.i >= len(p.s) || p.s[p.i] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.i++
+	return nil
+}
+
+func (p *qfParser) tag() (qfNode, error) {
+	if p.i < len(p.s) && p.s[p.i] == '|' {
+		p.i++
+		return p.cond()
+	}
+
+	var r qfTag
+	w, err := p.name("{")
+	if err != nil {
+		return nil, err
+	}
+	r.width = w
+	p.i++
+
+	name, err := p.name("}")
+	if err != nil {
+		return nil, err
+	}
+	p.i++
+
+	if strings.HasPrefix(name, "=") {
+		r.first = true
+		name = name[1:]
+	}
+	if i := strings.IndexByte(name, ':'); i != -1 {
+		name, r.format = name[:i], name[i+1:]
+	}
+	r.tag, err = p.lookup(name)
+	return r, err
+}
+
+func (p *qfParser) cond() (qfNode, error) {
+	var (
+		r   qfCond
+		err error
+	)
+	name, err := p.name("?")
+	if err != nil {
+		return nil, err
+	}
+	p.i++
+	if r.tag, err = p.lookup(name); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	if r.then, err = p.parse("}"); err != nil {
+		return nil, err
+	}
+	p.i++
+
+	if p.i < len(p.s) && p.s[p.i] == ':' {
+		p.i++
+		if err := p.expect('{'); err != nil {
+			return nil, err
+		}
+		if r.els, err = p.parse("}"); err != nil {
+			return nil, err
+		}
+		p.i++
+	}
+	return r, p.expect('|')
+}
+
+// queryFormat caches tag values for one header.
+type queryFormat struct {
+	hdr    *Header
+	values map[qfTag][]string
+}
+
+func qfDepFlags(f uint32) string {
+	var r string
+	if f&RPMSENSE_LESS != 0 {
+		r += "<"
+	}
+	if f&RPMSENSE_GREATER != 0 {
+		r += ">"
+	}
+	if f&RPMSENSE_EQUAL != 0 {
+		r += "="
+	}
+	return r
+}
+
+func qfPerms(m uint64) string {
+	const rwx = "rwxrwxrwx"
+	var b [10]byte
+	switch uint16(m) >> 12 {
+	case typeDir:
+		b[0] = 'd'
+	case typeSymlink:
+		b[0] = 'l'
+	case 002:
+		b[0] = 'c'
+	case 006:
+		b[0] = 'b'
+	case 001:
+		b[0] = 'p'
+	case 014:
+		b[0] = 's'
+	default:
+		b[0] = '-'
+	}
+	for i := 0; i < 9; i++ {
+		if m&(1<<uint(8-i)) != 0 {
+			b[i+1] = rwx[i]
+		} else {
+			b[i+1] = '-'
+		}
+	}
+	if m&04000 != 0 {
+		b[3] = "Ss"[m>>6&1]
+	}
+	if m&02000 != 0 {
+		b[6] = "Ss"[m>>3&1]
+	}
+	if m&01000 != 0 {
+		b[9] = "Tt"[m&1]
+	}
+	return string(b[:])
+}
+
+func qfInt(v uint64, format string) string {
+	switch format {
+	case "date":
+		return time.Unix(int64(v), 0).Format("Mon Jan _2 15:04:05 2006")
+	case "day":
+		return time.Unix(int64(v), 0).Format("Mon Jan 02 2006")
+	case "octal":
+		return strconv.FormatUint(v, 8)
+	case "hex":
+		return strconv.FormatUint(v, 16)
+	case "perms":
+		return qfPerms(v)
+	case "depflags":
+		return qfDepFlags(uint32(v))
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+func (q *queryFormat) extension(tag TagType) ([]string, bool) {
+	str := func(t TagType) string {
+		s, _ := q.hdr.StringData(t)
+		return s
+	}
+	evr := func() string {
+		r := str(RPMTAG_VERSION) + "-" + str(RPMTAG_RELEASE)
+		if t := q.hdr.Find(RPMTAG_EPOCH); t != nil {
+			if e, ok := t.Int32(); ok && len(e) > 0 {
+				r = strconv.Itoa(int(e[0])) + ":" + r
+			}
+		}
+		return r
+	}
+
+	switch tag {
+	case RPMTAG_FILENAMES:
+		idx, err := FileIndexHeader(q.hdr)
+		if err != nil {
+			return nil, false
+		}
+		r := idx.Filenames()
+		return r, len(r) > 0
+	case RPMTAG_EVR:
+		return []string{evr()}, true
+	case RPMTAG_NVR:
+		return []string{str(RPMTAG_NAME) + "-" +
+			str(RPMTAG_VERSION) + "-" + str(RPMTAG_RELEASE)}, true
+	case RPMTAG_NVRA:
+		return []string{str(RPMTAG_NAME) + "-" + str(RPMTAG_VERSION) +
+			"-" + str(RPMTAG_RELEASE) + "." + str(RPMTAG_ARCH)}, true
+	case RPMTAG_NEVR:
+		return []string{str(RPMTAG_NAME) + "-" + evr()}, true
+	case RPMTAG_NEVRA:
+		return []string{str(RPMTAG_NAME) + "-" + evr() +
+			"." + str(RPMTAG_ARCH)}, true
+	}
+	return nil, false
+}
+
+func (q *queryFormat) get(t qfTag) []string {
+	t.width, t.first = "", false
+	if r, ok := q.values[t]; ok {
+		return r
+	}
+
+	var r []string
+	tag := q.hdr.Find(t.tag)
+	switch {
+	case tag == nil:
+		r, _ = q.extension(t.tag)
+	case tag.Type == RPM_I18NSTRING_TYPE:
+		if s, ok := tag.StringData(); ok {
+			r = []string{s}
+		}
+	case tag.Type == RPM_STRING_TYPE, tag.Type == RPM_STRING_ARRAY_TYPE:
+		r, _ = tag.StringArray()
+	case tag.Type == RPM_BIN_TYPE:
+		if b, ok := tag.Bytes(); ok {
+			r = []string{hex.EncodeToString(b)}
+		}
+	default:
+		for _, v := range tagInts(tag) {
+			r = append(r, qfInt(v, t.format))
+		}
+	}
+	q.values[t] = r
+	return r
+}
+
+func tagInts(t *Tag) []uint64 {
+	var r []uint64
+	switch d := t.data.(type) {
+	case tagUint16:
+		for _, v := range d {
+			r = append(r, uint64(v))
+		}
+	case tagUint32:
+		for _, v := range d {
+			r = append(r, uint64(v))
+		}
+	case tagUint64:
+		r = append(r, d...)
+	case *tagBytes:
+		for _, v := range d.b.Bytes() {
+			r = append(r, uint64(v))
+		}
+	}
+	return r
+}
+
+// count returns the number of elements of the array tags in nodes.
+func (q *queryFormat) count(nodes []qfNode) int {
+	var n int
+	for _, v := range nodes {
+		switch v := v.(type) {
+		case qfTag:
+			if l := len(q.get(v)); !v.first && l > n {
+				n = l
+			}
+		case qfCond:
+			if l := q.count(v.then); l > n {
+				n = l
+			}
+			if l := q.count(v.els); l > n {
+				n = l
+			}
+		}
+	}
+	return n
+}
+
+func (q *queryFormat) exec(w *bytes.Buffer, nodes []qfNode, idx int) {
+	for _, v := range nodes {
+		switch v := v.(type) {
+		case qfText:
+			w.WriteString(string(v))
+		case qfTag:
+			vals, s := q.get(v), "(none)"
+			switch {
+			case len(vals) == 0:
+			case v.first || idx < 0 || len(vals) == 1:
+				s = vals[0]
+			case idx < len(vals):
+				s = vals[idx]
+			}
+			fmt.Fprintf(w, "%"+v.width+"s", s)
+		case qfArray:
+			n := q.count(v)
+			for i := 0; i < n; i++ {
+				q.exec(w, v, i)
+			}
+		case qfCond:
+			if len(q.get(qfTag{tag: v.tag})) > 0 {
+				q.exec(w, v.then, idx)
+			} else {
+				q.exec(w, v.els, idx)
+			}
+		}
+	}
+}
+
+// Format writes the header formatted with an rpm query format string,
+// e.g. "[%{BASENAMES}\n]".
+func (hdr *Header) Format(w io.Writer, format string) error {
+	p := &qfParser{s: format}
+	nodes, err := p.parse("")
+	if err != nil {
+		return err
+	}
+
+	q := &queryFormat{hdr: hdr, values: make(map[qfTag][]string)}
+	b := new(bytes.Buffer)
+	q.exec(b, nodes, -1)
+	_, err = b.WriteTo(w)
+	return err
+}
@@ -0,0 +1,96 @@
+package rpm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func qfHdr() *Header {
+	hdr := new(Header)
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddString(RPMTAG_VERSION, "1.0")
+	hdr.AddString(RPMTAG_RELEASE, "1")
+	hdr.AddString(RPMTAG_ARCH, "x86_64")
+	hdr.AddInt32(RPMTAG_EPOCH, 2)
+	hdr.AddInt16(RPMTAG_FILEMODES, 0100755, 040750, 0120777)
+	hdr.AddStringArray(RPMTAG_REQUIRENAME, "bar", "baz")
+	hdr.AddInt32(RPMTAG_REQUIREFLAGS, RPMSENSE_GREATER|RPMSENSE_EQUAL, 0)
+	hdr.AddStringArray(RPMTAG_REQUIREVERSION, "2", "")
+	hdr.AddString(RPMTAG_POSTIN, "true")
+	return hdr
+}
+
+func TestFormat(t *testing.T) {
+	hdr := qfHdr()
+	for _, v := range []struct {
+		qf, want string
+	}{
+		{"%{NAME}-%{VERSION}\\n", "foo-1.0\n"},
+		{"%{rpmtag_name} 100%%", "foo 100%"},
+		{"%{NEVRA}", "foo-2:1.0-1.x86_64"},
+		{"%{NVR}", "foo-1.0-1"},
+		{"[%{REQUIRENAME} %{REQUIREFLAGS:depflags} %{REQUIREVERSION};]",
+			"bar >= 2;baz  ;"},
+		{"[%{=NAME}:%{REQUIRENAME} ]", "foo:bar foo:baz "},
+		{"[%{FILEMODES:perms} ]", "-rwxr-xr-x drwxr-x--- lrwxrwxrwx "},
+		{"[%{FILEMODES:octal} ]", "100755 40750 120777 "},
+		{"%-5{NAME}|%5{NAME}", "foo  |  foo"},
+		{"%{SUMMARY}", "(none)"},
+		{"%|POSTIN?{post: %{POSTIN}}:{none}|", "post: true"},
+		{"%|PREIN?{pre: %{PREIN}}:{none}|", "none"},
+		{"%|PREIN?{pre}|", ""},
+	} {
+		var b strings.Builder
+		if err := hdr.Format(&b, v.qf); err != nil {
+			t.Errorf("%q: %v", v.qf, err)
+			continue
+		}
+		if b.String() != v.want {
+			t.Errorf("%q: %q != %q", v.qf, b.String(), v.want)
+		}
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	hdr := qfHdr()
+	for _, v := range []string{
+		"%{NAME",
+		"%{NOSUCHTAG}",
+		"[%{NAME}",
+		"%|NAME?{x}",
+		"%|NAME{x}|",
+	} {
+		var b strings.Builder
+		if err := hdr.Format(&b, v); !errors.Is(err, errQueryFormat) {
+			t.Errorf("%q: %v", v, err)
+		}
+	}
+}
+
+func TestSigTagType(t *testing.T) {
+	for _, v := range []struct {
+		name string
+		tag  TagType
+	}{
+		{"RPMSIGTAG_SIZE", RPMSIGTAG_SIZE},
+		{"longarchivesize", RPMSIGTAG_LONGARCHIVESIZE},
+		{"FILESIGNATURES", RPMSIGTAG_FILESIGNATURES},
+		{"RPMSIGTAG_RESERVEDSPACE", RPMSIGTAG_RESERVEDSPACE},
+		{"BADSHA1_2", RPMSIGTAG_BADSHA1_2},
+	} {
+		tag, ok := ParseSigTagType(v.name)
+		if !ok || tag != v.tag {
+			t.Errorf("%s: %d %v, want %d", v.name, tag, ok, v.tag)
+		}
+		if s := SigTagString(tag); !strings.HasSuffix(s, strings.ToUpper(v.name)) {
+			t.Errorf("%d: %s, want %s", tag, s, v.name)
+		}
+	}
+	if _, ok := ParseSigTagType("NAME"); ok {
+		t.Error("NAME is not a signature tag")
+	}
+	if s := SigTagString(RPMTAG_PUBKEYS); s != "RPMTAG_PUBKEYS" {
+		t.Errorf("pubkeys: %s", s)
+	}
+}
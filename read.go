@@ -7,21 +7,45 @@ import (
 	"io"
 	"io/ioutil"
 	"sort"
+	"time"
 )
 
 type Reader struct {
-	r   io.Reader
-	lr  *io.LimitedReader
-	off int
+	r    io.Reader
+	lr   *io.LimitedReader
+	cr   *countReader // bytes consumed, for error offsets
+	size *sizeReader  // see SetLimits
+	off  int
+	hist *histReader
+
+	// headers read after the lead, -1 without a lead
+	nhdr int
+
+	noMagic bool // see SetNoMagic
+	strict  bool // see SetStrict
+	limits  Limits
+
+	// start time and offset of the package being read, for metrics,
+	// mpart is set once its lead or signature is read
+	mstart time.Time
+	moff   int64
+	mpart  bool
 }
 
 func NewReader(r io.Reader) *Reader {
+	cr := &countReader{r: r}
 	return &Reader{
-		r:  r,
-		lr: &io.LimitedReader{R: r},
+		r:    cr,
+		lr:   &io.LimitedReader{R: cr},
+		cr:   cr,
+		nhdr: -1,
 	}
 }
 
+// Offset returns the number of bytes read from the underlying reader,
+// the file offset of the payload after ReadPackage.
+func (r *Reader) Offset() int64 { return r.cr.n }
+
 type tagError struct {
 	t   *Tag
 	err error
@@ -38,16 +62,41 @@ func (t tagError) Is(err error) bool {
 var errInvalidLead = errors.New("rpm: invalid lead")
 
 func (r *Reader) Lead() (*Lead, error) {
+	r.begin()
+	l, err := r.lead()
+	r.observe(nil, err)
+	return l, r.dump(err)
+}
+
+// read maps short reads to errUnexpectedEOF, io.EOF is only returned
+// if eof is set and nothing was read.
+func (r *Reader) read(v interface{}, eof bool) error {
+	err := binary.Read(r.r, binary.BigEndian, v)
+	if err == io.EOF && eof {
+		return err
+	}
+	return short(err)
+}
+
+func short(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errUnexpectedEOF
+	}
+	return err
+}
+
+func (r *Reader) lead() (*Lead, error) {
 	l := new(Lead)
-	if err := binary.Read(r.r, binary.BigEndian, l); err != nil {
-		return nil, err
+	if err := r.read(l, false); err != nil {
+		return nil, r.err(err)
 	}
+	const leadsz = 96
 	if l.Magic != leadMagic {
-		return nil, errInvalidLead
+		return nil, r.errAt(r.cr.n-leadsz, errInvalidLead)
 	}
 
-	const leadsz = 96
 	r.off += leadsz
+	r.nhdr = 0
 	return l, nil
 }
 
@@ -60,41 +109,55 @@ func (r *Reader) align() error {
 	if err != nil {
 		return err
 	}
+	r.off += int(n)
 	if r.lr.N != 0 {
-		return errBadAlign
+		if n == 0 {
+			return io.EOF
+		}
+		return errUnexpectedEOF
 	}
-	r.off += int(n)
-	return err
+	return nil
 }
 
 var errInvalidHeader = errors.New("rpm: invalid header")
 
+// SetNoMagic makes r read headers without the magic and reserved
+// bytes, as rpm exports them to its database. See Header.Export.
+func (r *Reader) SetNoMagic(v bool) { r.noMagic = v }
+
 func (r *Reader) header() (*Header, error) {
 	hdr := new(Header)
-	if err := binary.Read(
-		r.r, binary.BigEndian, &hdr.rpmHeaderPre,
-	); err != nil {
+	if r.noMagic {
+		n := []uint32{0, 0}
+		if err := r.read(n, true); err != nil {
+			return nil, err
+		}
+		hdr.Magic, hdr.Count, hdr.Length = rpmHeaderMagic, n[0], n[1]
+		r.off += 8
+		return hdr, r.checkLimits(hdr)
+	}
+	if err := r.read(&hdr.rpmHeaderPre, true); err != nil {
 		return nil, err
 	}
 	if hdr.Magic != rpmHeaderMagic {
-		return nil, errInvalidHeader
+		return nil, r.errAt(r.cr.n-tagSize, errInvalidHeader)
 	}
 	r.off += tagSize
-	return hdr, nil
+	return hdr, r.checkLimits(hdr)
 }
 
 func (r *Reader) tags(hdr *Header) error {
 	th := new(tagHeader)
 	for i := 0; i < int(hdr.Count); i++ {
-		if err := binary.Read(r.r, binary.BigEndian, th); err != nil {
-			return err
+		if err := r.read(th, false); err != nil {
+			return r.err(err)
 		}
 		t := &Tag{
 			tagHeader: *th,
 			idx:       i,
 		}
 		if t.Offset > hdr.Length {
-			return r.err(tagError{t, errOffsetOOB})
+			return r.errAt(r.cr.n-tagSize, tagError{t, errOffsetOOB})
 		}
 		hdr.Tags = append(hdr.Tags, t)
 		r.off += tagSize
@@ -119,27 +182,100 @@ var (
 	errOffsetOOB     = errors.New("rpm: offset out of bounds")
 )
 
-type offsetError struct {
-	off int
-	err error
+// OffsetError is a read error at Off, the absolute offset where
+// reading stopped.
+type OffsetError struct {
+	Off int64
+	Err error
 }
 
-func (e offsetError) Error() string {
-	return fmt.Sprintf("offset: 0x%x, %v", e.off, e.err)
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("offset: 0x%x, %v", e.Off, e.Err)
 }
 
-func (e offsetError) Unwrap() error {
-	return e.err
+func (e *OffsetError) Unwrap() error {
+	return e.Err
 }
 
 func (r *Reader) err(err error) error {
-	return offsetError{r.off, err}
+	return r.errAt(r.cr.n, err)
+}
+
+// errAt reports err at off, the start of a malformed structure rather
+// than the end of the read.
+func (r *Reader) errAt(off int64, err error) error {
+	if _, ok := err.(*OffsetError); ok {
+		return err
+	}
+	return &OffsetError{off, err}
 }
 
+// Next reads the next header. After Lead its Kind is known from the
+// position in the package, otherwise only from the region tag.
 func (r *Reader) Next() (*Header, error) {
-	if err := r.align(); err != nil {
+	r.begin()
+	hdr, err := r.next()
+	r.observe(hdr, err)
+	return hdr, r.dump(err)
+}
+
+var errHeaderKind = errors.New("rpm: unexpected header kind")
+
+// Signature reads the signature header, it follows the lead.
+func (r *Reader) Signature() (*Header, error) {
+	return r.nextKind(KindSignature)
+}
+
+// Header reads the main header, it follows the signature header.
+func (r *Reader) Header() (*Header, error) {
+	return r.nextKind(KindMain)
+}
+
+func (r *Reader) nextKind(k Kind) (*Header, error) {
+	r.begin()
+	hdr, err := r.next()
+	if errors.Is(err, io.EOF) {
+		err = r.err(errUnexpectedEOF)
+	}
+	if err == nil {
+		switch hdr.Kind() {
+		case KindUnknown:
+			hdr.kind = k
+		case k:
+		default:
+			err = r.err(errHeaderKind)
+		}
+	}
+	r.observe(hdr, err)
+	return hdr, r.dump(err)
+}
+
+func (r *Reader) next() (*Header, error) {
+	hdr, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	// the region may be missing, the position after the lead is not
+	switch r.nhdr {
+	case 0:
+		hdr.kind = KindSignature
+	case 1:
+		hdr.kind = KindMain
+	}
+	if r.nhdr >= 0 {
+		r.nhdr++
+	}
+	if err := r.checkStrict(hdr); err != nil {
 		return nil, err
 	}
+	return hdr, nil
+}
+
+func (r *Reader) readHeader() (*Header, error) {
+	if err := r.align(); err != nil {
+		return nil, r.err(err)
+	}
 
 	hdr, err := r.header()
 	if err != nil {
@@ -156,6 +292,19 @@ func (r *Reader) Next() (*Header, error) {
 	// TODO: remove and read tag data in unsorted order
 	sort.Sort(hdr)
 
+	// a gap before the first tag data
+	if n := hdr.Tags[0].Offset; n > 0 {
+		r.lr.N = int64(n)
+		dn, err := io.Copy(ioutil.Discard, r.lr)
+		if err != nil {
+			return nil, r.err(err)
+		}
+		if dn != int64(n) {
+			return nil, r.err(errUnexpectedEOF)
+		}
+		r.off += int(dn)
+	}
+
 	for i, v := range hdr.Tags {
 		if !r.tagaligned(v) {
 			return nil, r.err(tagError{v, errBadAlign})
@@ -189,14 +338,11 @@ func (r *Reader) Next() (*Header, error) {
 		r.lr.N = int64(nr)
 		w, err := v.data.ReadFrom(r.lr)
 		if err != nil {
-			return nil, r.err(tagError{v, err})
+			return nil, r.err(tagError{v, short(err)})
 		}
 
 		if r.lr.N != 0 {
-			// padding should always be less than 8b
-			if r.lr.N >= 8 {
-				return nil, r.err(tagError{v, errUnexpectedEOF})
-			}
+			// alignment padding or a gap before the next tag
 			dn, err := io.Copy(ioutil.Discard, r.lr)
 			if err != nil {
 				return nil, r.err(tagError{v, err})
@@ -212,14 +358,17 @@ func (r *Reader) Next() (*Header, error) {
 		r.off += int(w)
 	}
 
-	lt := hdr.Tags[len(hdr.Tags)-1]
-	switch lt.Tag {
-	case HEADER_IMMUTABLE, HEADER_SIGNATURES:
-		hdr.SetRegion(lt.Tag)
-		hdr.Tags = hdr.Tags[:len(hdr.Tags)-1]
-		hdr.off = lt.Offset
-	default:
-		hdr.off = hdr.Length
+	// the region tag is the first entry with its data last, unless
+	// tags were appended after it
+	hdr.off = hdr.Length
+	for _, v := range hdr.Tags {
+		if v.idx == 0 && isRegion(v) {
+			if err := hdr.checkRegion(v); err != nil {
+				return nil, r.err(tagError{v, err})
+			}
+			hdr.takeRegion(v, hdr.Length)
+			break
+		}
 	}
 
 	return hdr, nil
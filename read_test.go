@@ -190,3 +190,66 @@ func TestReader(t *testing.T) {
 		})
 	}
 }
+
+func TestRegionTrailer(t *testing.T) {
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddString(RPMTAG_VERSION, "1")
+	b := new(bytes.Buffer)
+	if _, err := hdr.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	// the region data, a tag entry, is last
+	trailer := b.Len() - tagSize
+
+	for _, v := range []struct {
+		name string
+		off  int
+		val  int32
+		err  error
+	}{
+		{"ok", 0, HEADER_IMMUTABLE, nil},
+		{"tag", 0, HEADER_SIGNATURES, errRegionTrailer},
+		{"type", 4, RPM_INT32_TYPE, errRegionTrailer},
+		{"offset", 8, -4 * tagSize, errRegionTrailer},
+		{"offset/positive", 8, tagSize, errRegionTrailer},
+		{"count", 12, 1, errRegionTrailer},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			p := bytes.Clone(b.Bytes())
+			binary.BigEndian.PutUint32(p[trailer+v.off:], uint32(v.val))
+			_, err := NewReader(bytes.NewReader(p)).Next()
+			if !errors.Is(err, v.err) {
+				t.Fatalf("want %v, got %v", v.err, err)
+			}
+		})
+	}
+}
+
+// rpm40SigHeader is a signature header laid out like rpm 4.0.x wrote it,
+// its region trailer names HEADER_IMAGE: region 62, RPMSIGTAG_SIZE 1234
+// and RPMSIGTAG_MD5 00..0f.
+const rpm40SigHeader = "8eade8010000000000000003000000240000003e000000070000001400000010" +
+	"000003e8000000040000000000000001000003ec000000070000000400000010" +
+	"000004d2000102030405060708090a0b0c0d0e0f0000003d00000007ffffffd0" +
+	"00000010"
+
+func TestReadLegacySignatureRegion(t *testing.T) {
+	b, _ := hex.DecodeString(rpm40SigHeader)
+	hdr, err := NewReader(bytes.NewReader(b)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := hdr.Region(); err != nil || r.Tag != HEADER_SIGNATURES {
+		t.Errorf("region: %v, %v", r, err)
+	}
+	if size, _, ok := sigSize(hdr); !ok || size != 1234 {
+		t.Errorf("size: %d", size)
+	}
+
+	// only a signature region may name HEADER_IMAGE
+	binary.BigEndian.PutUint32(b[16:], HEADER_IMMUTABLE)
+	if _, err := NewReader(bytes.NewReader(b)).Next(); !errors.Is(err, errRegionTrailer) {
+		t.Errorf("immutable region: %v", err)
+	}
+}
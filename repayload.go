@@ -0,0 +1,118 @@
+package rpm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pschou/go-rpm/scpio"
+)
+
+var errFileChanged = errors.New("rpm: file differs from the header")
+
+// earlyLinks returns the files that are hardlinks to a later file, the
+// payload has the data of a hardlink set with its last member.
+func (f *FileIndex) earlyLinks() map[int]bool {
+	if len(f.ino) != len(f.name) || len(f.dev) != len(f.name) {
+		return nil
+	}
+	last := make(map[[2]uint32]int)
+	for i := range f.name {
+		last[[2]uint32{f.dev[i], f.ino[i]}] = i
+	}
+	r := make(map[int]bool)
+	for i := range f.name {
+		if last[[2]uint32{f.dev[i], f.ino[i]}] != i {
+			r[i] = true
+		}
+	}
+	return r
+}
+
+// Repayload writes the uncompressed payload of hdr, a stripped cpio
+// archive, with the files installed below root. Ghost files have no
+// entry, symlinks carry their target if the header gives them a size.
+// Regular files must match their size and digest in the header, w then
+// has a partial payload. The payload digest of hdr is of the
+// compressed payload and can't be checked.
+func Repayload(hdr *Header, root string, w io.Writer) error {
+	f, err := FileIndexHeader(hdr)
+	if err != nil {
+		return err
+	}
+	if len(f.mode) != len(f.name) || len(f.flags) != len(f.name) {
+		return errFileIndex
+	}
+	algo, ok := LookupHash(f.DigestAlgo())
+	if !ok {
+		return tagError{hdr.Find(RPMTAG_FILEDIGESTALGO), errDigestAlgo}
+	}
+	links := f.earlyLinks()
+
+	cw := scpio.NewWriter(w)
+	for i := range f.name {
+		if f.flags[i]&RPMFILE_GHOST != 0 {
+			continue
+		}
+		if err := cw.WriteHeader(uint32(i)); err != nil {
+			return err
+		}
+		name := f.dirNames.s[f.dirIndexes[i]] + f.name[i]
+		p := filepath.Join(root, filepath.FromSlash(name))
+		size := f.fsize(i)
+
+		switch f.mode[i] >> 12 {
+		case typeRegular:
+			if links[i] {
+				break
+			}
+			var digest string
+			if i < len(f.digest) {
+				digest = f.digest[i]
+			}
+			if err := copyFile(cw, p, size, digest, algo); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		case typeSymlink:
+			if size == 0 {
+				break
+			}
+			l, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if i < len(f.linkto) && l != f.linkto[i] || uint64(len(l)) != size {
+				return fmt.Errorf("%w: %s: link target", errFileChanged, name)
+			}
+			if _, err := io.WriteString(cw, l); err != nil {
+				return err
+			}
+		}
+	}
+	return cw.Close()
+}
+
+// copyFile writes the size bytes of the file name to w, checking them
+// against the hex digest unless it's empty.
+func copyFile(w io.Writer, name string, size uint64, digest string, algo Hash) error {
+	fd, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	h := algo.New()
+	n, err := io.Copy(io.MultiWriter(w, h), io.LimitReader(fd, int64(size)+1))
+	switch {
+	case err != nil:
+		return err
+	case uint64(n) != size:
+		return fmt.Errorf("%w: size", errFileChanged)
+	case digest != "" && hex.EncodeToString(h.Sum(nil)) != digest:
+		return fmt.Errorf("%w: digest", errFileChanged)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package rpm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pschou/go-rpm/scpio"
+)
+
+func TestRepayload(t *testing.T) {
+	root := t.TempDir()
+	files := []struct {
+		name  string
+		mode  uint16
+		data  string
+		flags uint32
+	}{
+		{"/etc", 040755, "", 0},
+		{"/etc/foo.conf", 0100644, "foo=1\n", RPMFILE_CONFIG},
+		{"/etc/foo.log", 0100644, "", RPMFILE_GHOST},
+		{"/usr/bin/foo", 0100755, "#!/bin/sh\n", 0},
+		{"/usr/bin/bar", 0120777, "foo", 0},
+	}
+
+	idx := NewFileIndex()
+	idx.SetDigestAlgo(PGPHASHALGO_SHA256)
+	want := new(bytes.Buffer)
+	cw := scpio.NewWriter(want)
+	for i, v := range files {
+		p := filepath.Join(root, v.name)
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		f := &File{Name: v.name, Mode: v.mode, Flags: v.flags}
+		switch v.mode >> 12 {
+		case typeDir:
+			os.Mkdir(p, 0o755)
+		case typeSymlink:
+			os.Symlink(v.data, p)
+			f.LinkTo, f.Size = v.data, uint64(len(v.data))
+		case typeRegular:
+			if v.flags&RPMFILE_GHOST != 0 {
+				break
+			}
+			os.WriteFile(p, []byte(v.data), 0o644)
+			d := sha256.Sum256([]byte(v.data))
+			f.Digest, f.Size = hex.EncodeToString(d[:]), uint64(len(v.data))
+		}
+		idx.Add(f)
+		if v.flags&RPMFILE_GHOST == 0 {
+			cw.WriteHeader(uint32(i))
+			io.WriteString(cw, v.data)
+		}
+	}
+	cw.Close()
+	hdr := NewPayloadHeader()
+	idx.Append(hdr)
+
+	b := new(bytes.Buffer)
+	if err := Repayload(hdr, root, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), want.Bytes()) {
+		t.Fatalf("payload:\n%q\nwant:\n%q", b.Bytes(), want.Bytes())
+	}
+
+	for _, data := range []string{"foo=2\n", "foo=10\n"} {
+		os.WriteFile(filepath.Join(root, "etc/foo.conf"), []byte(data), 0o644)
+		if err := Repayload(hdr, root, io.Discard); !errors.Is(err, errFileChanged) {
+			t.Errorf("%q: %v", data, err)
+		}
+	}
+	os.Remove(filepath.Join(root, "etc/foo.conf"))
+	if err := Repayload(hdr, root, io.Discard); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing: %v", err)
+	}
+
+	bad := new(Header)
+	bad.AddStringArray(RPMTAG_DIRNAMES, "/etc/")
+	bad.AddStringArray(RPMTAG_BASENAMES, "foo.conf", "bar.conf")
+	bad.AddInt32(RPMTAG_DIRINDEXES, 0)
+	if err := Repayload(bad, root, io.Discard); !errors.Is(err, errFileIndex) {
+		t.Errorf("dir indexes: %v", err)
+	}
+}
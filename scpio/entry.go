@@ -0,0 +1,39 @@
+package scpio
+
+import (
+	"strings"
+)
+
+// Name prefixes of newc entries. rpm2cpio writes "./", some cpio
+// tools write absolute names.
+const (
+	PrefixDot   = "./"
+	PrefixSlash = "/"
+)
+
+// nameMax is the longest name with its NUL, PATH_MAX on linux.
+const nameMax = 4096
+
+// Entry is an archive entry. Stripped entries only carry Index, the
+// position of the file in the header, newc entries as written by
+// rpm2cpio carry the file itself.
+type Entry struct {
+	Index     uint32
+	Name      string
+	Ino       uint32
+	Mode      uint32
+	UID       uint32
+	GID       uint32
+	Nlink     uint32
+	Mtime     uint32
+	Size      int64
+	Devmajor  uint32
+	Devminor  uint32
+	Rdevmajor uint32
+	Rdevminor uint32
+}
+
+// trimPrefix strips either prefix from name.
+func trimPrefix(name string) string {
+	return strings.TrimLeft(strings.TrimPrefix(name, PrefixDot), "/")
+}
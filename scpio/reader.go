@@ -10,23 +10,32 @@ import (
 )
 
 type Reader struct {
-	r   io.Reader
-	off int
+	r    io.Reader
+	off  int
+	base int64
 }
 
 func NewReader(r io.Reader) *Reader {
 	return &Reader{r: r}
 }
 
+// SetOffset sets the offset of the archive in the file, errors report
+// offsets relative to it. For an uncompressed payload this is the
+// payload offset of the package.
+func (r *Reader) SetOffset(off int64) {
+	r.base = off
+}
+
 var (
 	errUnexpectedEOF  = errors.New("scpio: unexpected EOF")
 	errBadMagic       = errors.New("scpio: bad magic")
 	errInvalidTrailer = errors.New("scpio: invalid trailer")
+	errNameSize       = errors.New("scpio: invalid name size")
 )
 
 func (r *Reader) align() error {
 	i := (r.off + 0x3) &^ 0x3
-	lr := &io.LimitedReader{r.r, int64(i - r.off)}
+	lr := &io.LimitedReader{R: r.r, N: int64(i - r.off)}
 	n, err := io.Copy(ioutil.Discard, lr)
 	if err != nil {
 		return err
@@ -49,17 +58,48 @@ func (r *Reader) trailer() error {
 		return err
 	}
 	if string(b[n-len(trailer):]) != trailer {
-		return errInvalidTrailer
+		return r.errAt(r.off-16, errInvalidTrailer)
 	}
 	r.off += n
 	return r.align()
 }
 
+// OffsetError is a read error at Off, the offset in the archive plus
+// the one set with SetOffset.
+type OffsetError struct {
+	Off int64
+	Err error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("offset: 0x%x, %v", e.Off, e.Err)
+}
+
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
+
 func (r *Reader) err(err error) error {
+	return r.errAt(r.off, err)
+}
+
+// errAt reports err at off, the start of a malformed entry rather than
+// the end of the read.
+func (r *Reader) errAt(off int, err error) error {
 	if err == nil {
 		return nil
 	}
-	return fmt.Errorf("offset: 0x%x, %v", r.off, err)
+	if _, ok := err.(*OffsetError); ok {
+		return err
+	}
+	return &OffsetError{r.base + int64(off), err}
+}
+
+// Data returns a reader for the next n bytes of entry data, pass n to
+// the following Next. The *io.LimitedReader is recognized by
+// (*os.File).ReadFrom when copying between files.
+func (r *Reader) Data(n int64) io.Reader {
+	return &io.LimitedReader{R: r.r, N: n}
 }
 
 func (r *Reader) Next(sz int) (uint32, error) {
@@ -73,6 +113,7 @@ func (r *Reader) Next(sz int) (uint32, error) {
 	if err != nil {
 		return 0, r.err(err)
 	}
+	start := r.off
 	r.off += n
 
 	switch string(b[:6]) {
@@ -80,13 +121,91 @@ func (r *Reader) Next(sz int) (uint32, error) {
 	case newcMagic:
 		return 0, r.err(r.trailer())
 	default:
-		return 0, r.err(errBadMagic)
+		return 0, r.errAt(start, errBadMagic)
 	}
 
 	var d [4]byte
 	if _, err := hex.Decode(d[:], b[6:14]); err != nil {
-		return 0, r.err(err)
+		return 0, r.errAt(start+6, err)
 	}
 
 	return binary.BigEndian.Uint32(d[:]), nil
 }
+
+// NextEntry is Next for archives of stripped or newc entries, for the
+// latter the name is returned as written. It returns io.EOF after the
+// trailer.
+func (r *Reader) NextEntry(sz int) (*Entry, error) {
+	r.off += sz
+	if err := r.align(); err != nil {
+		return nil, r.err(err)
+	}
+
+	start := r.off
+	b := make([]byte, 6+13*8)
+	n, err := io.ReadFull(r.r, b[:6+8+2])
+	if err != nil {
+		return nil, r.err(err)
+	}
+	r.off += n
+
+	switch string(b[:6]) {
+	case scpioMagic:
+		var d [4]byte
+		if _, err := hex.Decode(d[:], b[6:14]); err != nil {
+			return nil, r.errAt(start+6, err)
+		}
+		return &Entry{Index: binary.BigEndian.Uint32(d[:])}, nil
+	case newcMagic:
+	default:
+		return nil, r.errAt(start, errBadMagic)
+	}
+
+	n, err = io.ReadFull(r.r, b[n:])
+	if err != nil {
+		return nil, r.err(err)
+	}
+	r.off += n
+
+	var u [13]uint32
+	for i := range u {
+		var d [4]byte
+		if _, err := hex.Decode(d[:], b[6+i*8:6+i*8+8]); err != nil {
+			return nil, r.errAt(start+6+i*8, err)
+		}
+		u[i] = binary.BigEndian.Uint32(d[:])
+	}
+
+	// the name is NUL terminated
+	if u[11] == 0 || u[11] > nameMax {
+		return nil, r.errAt(start+6+11*8, errNameSize)
+	}
+	name := make([]byte, u[11])
+	n, err = io.ReadFull(r.r, name)
+	if err != nil {
+		return nil, r.err(err)
+	}
+	r.off += n
+	if err := r.align(); err != nil {
+		return nil, r.err(err)
+	}
+
+	e := &Entry{
+		Name:      string(name[:len(name)-1]),
+		Ino:       u[0],
+		Mode:      u[1],
+		UID:       u[2],
+		GID:       u[3],
+		Nlink:     u[4],
+		Mtime:     u[5],
+		Size:      int64(u[6]),
+		Devmajor:  u[7],
+		Devminor:  u[8],
+		Rdevmajor: u[9],
+		Rdevminor: u[10],
+	}
+	if e.Name == "TRAILER!!!" {
+		return nil, io.EOF
+	}
+	return e, nil
+}
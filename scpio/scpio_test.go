@@ -3,8 +3,10 @@ package scpio
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -89,3 +91,174 @@ func TestWriter(t *testing.T) {
 			w.off, hex.Dump(data.Bytes()))
 	}
 }
+
+func TestCopy(t *testing.T) {
+	b := new(bytes.Buffer)
+	w := NewWriter(b)
+	for _, v := range cases {
+		w.WriteHeader(v.ino)
+		if _, err := io.Copy(w, strings.NewReader(v.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	if a, b := b.Bytes(), makeData().Bytes(); !bytes.Equal(a, b) {
+		t.Fatalf("want != have\nhave:\n%s\nwant:\n%s", hex.Dump(a), hex.Dump(b))
+	}
+
+	r := NewReader(b)
+	var last int
+	for i, v := range cases {
+		if _, err := r.Next(last); err != nil {
+			t.Fatalf("read error, %d: %v", i, err)
+		}
+		var data bytes.Buffer
+		if _, err := io.Copy(&data, r.Data(int64(len(v.data)))); err != nil {
+			t.Fatal(err)
+		}
+		if a, b := data.String(), v.data; a != b {
+			t.Fatalf("data != want, %d: %q != %q", i, a, b)
+		}
+		last = data.Len()
+	}
+	if _, err := r.Next(last); err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+}
+
+func TestReaderOffset(t *testing.T) {
+	data := makeData().Bytes()
+	// corrupt the magic of the third entry
+	off := bytes.Index(data, []byte(scpioMagic+"00000000\x00\x00bar"))
+	data[off] = 'x'
+
+	const base = 0x1000
+	r := NewReader(bytes.NewReader(data))
+	r.SetOffset(base)
+	var last int
+	var err error
+	for _, v := range cases {
+		if _, err = r.Next(last); err != nil {
+			break
+		}
+		io.Copy(io.Discard, r.Data(int64(len(v.data))))
+		last = len(v.data)
+	}
+	if !errors.Is(err, errBadMagic) {
+		t.Fatalf("expected bad magic, got: %v", err)
+	}
+	var oe *OffsetError
+	if !errors.As(err, &oe) {
+		t.Fatalf("expected offset, got: %v", err)
+	}
+	if a, b := oe.Off, int64(base+off); a != b {
+		t.Fatalf("offset: want 0x%x, have 0x%x", b, a)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	for _, prefix := range []string{PrefixDot, PrefixSlash} {
+		b := new(bytes.Buffer)
+		w := NewWriter(b)
+		w.SetPrefix(prefix)
+		names := []string{"./usr/bin/foo", "/etc/foo.conf", "usr/lib/bar"}
+		for i, v := range names {
+			w.WriteEntry(&Entry{Name: v, Ino: uint32(i + 1), Mode: 0100644, Nlink: 1, Size: int64(i)})
+			io.WriteString(w, strings.Repeat("x", i))
+		}
+		w.Close()
+
+		r := NewReader(b)
+		var last int
+		for i, v := range names {
+			e, err := r.NextEntry(last)
+			if err != nil {
+				t.Fatalf("%s: read error, %d: %v", prefix, i, err)
+			}
+			if a, b := e.Name, prefix+trimPrefix(v); a != b {
+				t.Fatalf("%s: name: want %q, have %q", prefix, b, a)
+			}
+			if e.Ino != uint32(i+1) || e.Mode != 0100644 || e.Size != int64(i) {
+				t.Fatalf("%s: entry: %+v", prefix, e)
+			}
+			data, _ := io.ReadAll(r.Data(e.Size))
+			if a, b := string(data), strings.Repeat("x", i); a != b {
+				t.Fatalf("%s: data: want %q, have %q", prefix, b, a)
+			}
+			last = len(data)
+		}
+		if _, err := r.NextEntry(last); err != io.EOF {
+			t.Fatalf("%s: expected EOF, got: %v", prefix, err)
+		}
+	}
+
+	// stripped entries
+	r := NewReader(makeData())
+	var last int
+	for i, v := range cases {
+		e, err := r.NextEntry(last)
+		if err != nil {
+			t.Fatalf("read error, %d: %v", i, err)
+		}
+		if e.Index != v.ino || e.Name != "" {
+			t.Fatalf("entry, %d: %+v", i, e)
+		}
+		r.r.(*bytes.Buffer).Next(len(v.data))
+		last = len(v.data)
+	}
+	if _, err := r.NextEntry(last); err != io.EOF {
+		t.Fatalf("expected EOF, got: %v", err)
+	}
+}
+
+func TestEntryLongNames(t *testing.T) {
+	var names []string
+	for n := 250; n < 260; n++ {
+		names = append(names, "/"+strings.Repeat("a", n))
+	}
+	// deep trees, every name length modulo 4
+	for depth := 100; depth < 104; depth++ {
+		names = append(names, strings.Repeat("/d", depth)+"/f")
+	}
+	long := strings.Repeat("/"+strings.Repeat("x", 99), 41)
+	names = append(names, long[:nameMax-len(PrefixDot)])
+
+	b := new(bytes.Buffer)
+	w := NewWriter(b)
+	for i, v := range names {
+		if err := w.WriteEntry(&Entry{Name: v, Size: int64(i % 5)}); err != nil {
+			t.Fatalf("write %d: %v", len(v), err)
+		}
+		io.WriteString(w, strings.Repeat("x", i%5))
+	}
+	if err := w.WriteEntry(&Entry{Name: long[:nameMax]}); err != errNameSize {
+		t.Fatalf("expected name size error, got: %v", err)
+	}
+	w.Close()
+	if b.Len()&0x3 != 0 {
+		t.Fatalf("archive not aligned: %d", b.Len())
+	}
+
+	r := NewReader(b)
+	var last int
+	for i, v := range names {
+		e, err := r.NextEntry(last)
+		if err != nil {
+			t.Fatalf("read %d: %v", len(v), err)
+		}
+		if r.off&0x3 != 0 {
+			t.Fatalf("data not aligned after %d: 0x%x", len(v), r.off)
+		}
+		if a, b := e.Name, PrefixDot+trimPrefix(v); a != b {
+			t.Fatalf("name %d: have %d", len(b), len(a))
+		}
+		data, _ := io.ReadAll(r.Data(e.Size))
+		if len(data) != i%5 {
+			t.Fatalf("data %d: %q", len(v), data)
+		}
+		last = len(data)
+	}
+	if _, err := r.NextEntry(last); err != io.EOF {
+		t.Fatalf("expected EOF, got: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package scpio
 import (
 	"errors"
 	"io"
+	"math"
 )
 
 const (
@@ -27,12 +28,18 @@ func newHeader(magic string, u ...uint32) []byte {
 }
 
 type Writer struct {
-	off int
-	w   io.Writer
+	off    int
+	w      io.Writer
+	prefix string
 }
 
 func NewWriter(w io.Writer) *Writer {
-	return &Writer{w: w}
+	return &Writer{w: w, prefix: PrefixDot}
+}
+
+// SetPrefix sets the name prefix of newc entries, PrefixDot by default.
+func (s *Writer) SetPrefix(prefix string) {
+	s.prefix = prefix
 }
 
 var errShortWrite = errors.New("scpio: short write")
@@ -49,6 +56,14 @@ func (s *Writer) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// ReadFrom copies r to the underlying writer with io.Copy, so an
+// *os.File source and destination can use sendfile or copy_file_range.
+func (s *Writer) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.Copy(s.w, r)
+	s.off += int(n)
+	return n, err
+}
+
 var zb [4]byte
 
 func (s *Writer) align() error {
@@ -81,6 +96,33 @@ func (s *Writer) WriteHeader(ino uint32) error {
 	return s.writeHeader(ino, false)
 }
 
+var errEntrySize = errors.New("scpio: entry too large")
+
+// WriteEntry writes a newc header for e, whose name is written with the
+// prefix of s whether it starts with "./", "/" or neither. The Size
+// bytes of data follow.
+func (s *Writer) WriteEntry(e *Entry) error {
+	if e.Size < 0 || e.Size > math.MaxUint32 {
+		return errEntrySize
+	}
+	name := s.prefix + trimPrefix(e.Name)
+	if len(name)+1 > nameMax {
+		return errNameSize
+	}
+	if err := s.align(); err != nil {
+		return err
+	}
+	_, err := s.Write(append(newHeader(newcMagic,
+		e.Ino, e.Mode, e.UID, e.GID, e.Nlink, e.Mtime, uint32(e.Size),
+		e.Devmajor, e.Devminor, e.Rdevmajor, e.Rdevminor,
+		uint32(len(name)+1), 0,
+	), name+"\x00"...))
+	if err != nil {
+		return err
+	}
+	return s.align()
+}
+
 func (s *Writer) Close() error {
 	return s.writeHeader(0, true)
 }
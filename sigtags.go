@@ -0,0 +1,76 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+var errSignature = errors.New("rpm: invalid header signature")
+
+// HeaderSignatures returns the signatures in sig, RPMSIGTAG_OPENPGP
+// first.
+func HeaderSignatures(sig *Header) ([][]byte, error) {
+	var r [][]byte
+	if t := sig.Find(RPMSIGTAG_OPENPGP); t != nil {
+		s, ok := t.StringArray()
+		if !ok {
+			return nil, tagError{t, errTagType}
+		}
+		for _, v := range s {
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, tagError{t, errSignature}
+			}
+			r = append(r, b)
+		}
+	}
+	for _, v := range []TagType{RPMSIGTAG_RSA, RPMSIGTAG_DSA} {
+		if t := sig.Find(v); t != nil {
+			b, ok := t.Bytes()
+			if !ok {
+				return nil, tagError{t, errTagType}
+			}
+			r = append(r, b)
+		}
+	}
+	return r, nil
+}
+
+// parseSignature parses an OpenPGP signature packet, v3 signatures
+// aren't supported.
+func parseSignature(b []byte) (*packet.Signature, bool) {
+	p, err := packet.Read(bytes.NewReader(b))
+	if err != nil {
+		return nil, false
+	}
+	s, ok := p.(*packet.Signature)
+	return s, ok
+}
+
+// SignatureKeyID returns the issuer of an OpenPGP signature packet.
+func SignatureKeyID(b []byte) (uint64, bool) {
+	if s, ok := parseSignature(b); ok {
+		if s.IssuerKeyId == nil {
+			return 0, false
+		}
+		return *s.IssuerKeyId, true
+	}
+
+	// v3 signatures, still common in old packages, aren't supported by
+	// packet.Read. The old format header has a 1, 2 or 4 byte length.
+	if len(b) == 0 || b[0]&0xc0 != 0x80 || b[0]&0x3 == 3 {
+		return 0, false
+	}
+	n := 1 + 1<<(b[0]&0x3)
+	if len(b) < n+19 {
+		return 0, false
+	}
+	if b = b[n:]; b[0] != 3 || b[1] != 5 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(b[7:15]), true
+}
@@ -0,0 +1,12 @@
+package rpm
+
+import "testing"
+
+func TestSignatureKeyIDV3(t *testing.T) {
+	b := []byte{0x89, 0, 22, 3, 5, 0, 0, 0, 0, 0,
+		1, 2, 3, 4, 5, 6, 7, 8, 1, 8, 0, 0}
+	b = append(b, make([]byte, 3)...)
+	if id, ok := SignatureKeyID(b); !ok || id != 0x0102030405060708 {
+		t.Fatalf("%x, %v", id, ok)
+	}
+}
@@ -0,0 +1,48 @@
+package rpm
+
+import "sort"
+
+type TagSize struct {
+	Tag     TagType
+	Index   int
+	Data    int
+	Padding int // alignment before the data
+}
+
+func (t TagSize) Total() int { return t.Index + t.Data + t.Padding }
+
+// SizeBreakdown returns the bytes used by each tag in offset order, the
+// region tag is last if the header has one, unless tags were appended
+// after it. The header preamble is not included.
+func (hdr *Header) SizeBreakdown() []TagSize {
+	hdr.settle()
+	tags := make([]*Tag, len(hdr.Tags))
+	copy(tags, hdr.Tags)
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Offset < tags[j].Offset
+	})
+	if hdr.region != nil {
+		tags = (&Header{Tags: tags, region: hdr.region, dribble: hdr.dribble}).dataOrder()
+	}
+
+	r := make([]TagSize, 0, len(tags)+1)
+	var cur int
+	for _, v := range tags {
+		if v == hdr.region {
+			r = append(r, TagSize{Tag: v.Tag, Index: tagSize, Data: tagSize})
+			cur += tagSize
+			continue
+		}
+		s := TagSize{
+			Tag:   v.Tag,
+			Index: tagSize,
+			Data:  v.data.Len(),
+		}
+		if int(v.Offset) > cur {
+			s.Padding = int(v.Offset) - cur
+		}
+		cur += s.Padding + s.Data
+		r = append(r, s)
+	}
+	return r
+}
@@ -0,0 +1,49 @@
+package rpm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSizeBreakdown(t *testing.T) {
+	hdr := NewPayloadHeader()
+	hdr.AddString(RPMTAG_NAME, "foo")
+	hdr.AddInt32(RPMTAG_SIZE, 1)
+	hdr.AddInt64(RPMTAG_LONGSIZE, 1)
+
+	want := []TagSize{
+		{RPMTAG_NAME, 16, 4, 0},
+		{RPMTAG_SIZE, 16, 4, 0},
+		{RPMTAG_LONGSIZE, 16, 8, 0},
+		{HEADER_IMMUTABLE, 16, 16, 0},
+	}
+	have := hdr.SizeBreakdown()
+	if len(have) != len(want) {
+		t.Fatalf("len: %v", have)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("%d: %+v != %+v", i, have[i], want[i])
+		}
+	}
+
+	hdr = new(Header)
+	hdr.AddString(RPMTAG_NAME, "fo")
+	hdr.AddInt64(RPMTAG_LONGSIZE, 1)
+	have = hdr.SizeBreakdown()
+	if have[1].Padding != 5 {
+		t.Errorf("padding: %+v", have[1])
+	}
+
+	var total int
+	for _, v := range have {
+		total += v.Total()
+	}
+	var b bytes.Buffer
+	if _, err := hdr.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if total+16 != b.Len() {
+		t.Errorf("total: %d != %d", total+16, b.Len())
+	}
+}
@@ -0,0 +1,144 @@
+package rpm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Stats counts tag, compressor, digest and signing key usage over a
+// set of packages, each counted once per package.
+type Stats struct {
+	Packages       int
+	Tags           map[string]int
+	Compressors    map[string]int
+	FileDigests    map[string]int
+	PayloadDigests map[string]int
+	Keys           map[string]int
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		Tags:           make(map[string]int),
+		Compressors:    make(map[string]int),
+		FileDigests:    make(map[string]int),
+		PayloadDigests: make(map[string]int),
+		Keys:           make(map[string]int),
+	}
+}
+
+// hashName returns the name of a PGPHASHALGO_* algorithm.
+func hashName(algo uint32) string {
+	if h, ok := LookupHash(algo); ok {
+		return h.Name
+	}
+	return "unknown(" + strconv.FormatUint(uint64(algo), 10) + ")"
+}
+
+func int32Tag(hdr *Header, tag TagType) (uint32, bool) {
+	if t := hdr.Find(tag); t != nil {
+		if v, ok := t.Int32(); ok && len(v) > 0 {
+			return v[0], true
+		}
+	}
+	return 0, false
+}
+
+// Add counts p.
+func (s *Stats) Add(p *Package) {
+	s.Packages++
+	for _, v := range p.Signature.Tags {
+		s.Tags[SigTagString(v.Tag)]++
+	}
+	for _, v := range p.Header.Tags {
+		s.Tags[v.Tag.String()]++
+	}
+
+	// rpm defaults to gzip and md5 without the tags
+	c, ok := p.Header.StringData(RPMTAG_PAYLOADCOMPRESSOR)
+	if !ok {
+		c = "gzip"
+	}
+	s.Compressors[c]++
+	if p.Header.Find(RPMTAG_FILEDIGESTS) != nil {
+		algo, ok := int32Tag(p.Header, RPMTAG_FILEDIGESTALGO)
+		if !ok {
+			algo = PGPHASHALGO_MD5
+		}
+		s.FileDigests[hashName(algo)]++
+	}
+	if algo, ok := int32Tag(p.Header, RPMTAG_PAYLOADDIGESTALGO); ok {
+		s.PayloadDigests[hashName(algo)]++
+	} else {
+		s.PayloadDigests["none"]++
+	}
+
+	keys := signatureKeys(p.Signature)
+	if len(keys) == 0 {
+		s.Keys["unsigned"]++
+	}
+	for _, v := range keys {
+		s.Keys[v]++
+	}
+}
+
+// signatureKeys returns the hex key ids of the header and legacy
+// header+payload signatures in sig.
+func signatureKeys(sig *Header) []string {
+	sigs, _ := HeaderSignatures(sig)
+	for _, v := range []TagType{RPMSIGTAG_PGP, RPMSIGTAG_GPG} {
+		if t := sig.Find(v); t != nil {
+			if b, ok := t.Bytes(); ok {
+				sigs = append(sigs, b)
+			}
+		}
+	}
+	var r []string
+	seen := make(map[string]bool)
+	for _, v := range sigs {
+		id := "unknown"
+		if k, ok := SignatureKeyID(v); ok {
+			id = fmt.Sprintf("%016x", k)
+		}
+		if !seen[id] {
+			seen[id] = true
+			r = append(r, id)
+		}
+	}
+	return r
+}
+
+// WriteCSV writes s as kind,name,count rows, most used first.
+func (s *Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "name", "count"})
+	cw.Write([]string{"packages", "", strconv.Itoa(s.Packages)})
+	for _, v := range []struct {
+		kind string
+		m    map[string]int
+	}{
+		{"tag", s.Tags},
+		{"compressor", s.Compressors},
+		{"filedigest", s.FileDigests},
+		{"payloaddigest", s.PayloadDigests},
+		{"key", s.Keys},
+	} {
+		names := make([]string, 0, len(v.m))
+		for k := range v.m {
+			names = append(names, k)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if v.m[names[i]] != v.m[names[j]] {
+				return v.m[names[i]] > v.m[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, k := range names {
+			cw.Write([]string{v.kind, k, strconv.Itoa(v.m[k])})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,52 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func TestStats(t *testing.T) {
+	key, err := openpgp.NewEntity("a", "", "a@example.com",
+		&packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdr := makeHdr()
+	hdr.AddString(RPMTAG_PAYLOADCOMPRESSOR, "zstd")
+	hdr.AddStringArray(RPMTAG_FILEDIGESTS, "00")
+	hdr.AddInt32(RPMTAG_FILEDIGESTALGO, PGPHASHALGO_SHA256)
+	hdr.AddInt32(RPMTAG_PAYLOADDIGESTALGO, PGPHASHALGO_SHA256)
+	hb, ds := new(bytes.Buffer), new(bytes.Buffer)
+	hdr.WriteTo(hb)
+	if err := openpgp.DetachSign(ds, key, hb, nil); err != nil {
+		t.Fatal(err)
+	}
+	sig := NewSignatureHeader()
+	sig.AddStringArray(RPMSIGTAG_OPENPGP, base64.StdEncoding.EncodeToString(ds.Bytes()))
+
+	s := NewStats()
+	s.Add(&Package{Signature: sig, Header: hdr})
+	s.Add(&Package{Signature: NewSignatureHeader(), Header: makeHdr()})
+
+	id := fmt.Sprintf("%016x", key.PrimaryKey.KeyId)
+	if s.Packages != 2 || s.Compressors["zstd"] != 1 || s.Compressors["gzip"] != 1 ||
+		s.FileDigests["sha256"] != 1 || s.PayloadDigests["none"] != 1 ||
+		s.Keys[id] != 1 || s.Keys["unsigned"] != 1 || s.Tags["RPMSIGTAG_OPENPGP"] != 1 {
+		t.Fatalf("%+v", s)
+	}
+
+	b := new(bytes.Buffer)
+	if err := s.WriteCSV(b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "key,"+id+",1\n") {
+		t.Fatalf("%s", b)
+	}
+}
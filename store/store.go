@@ -0,0 +1,265 @@
+package store
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+var (
+	errDigest   = errors.New("store: file digest mismatch")
+	errIndex    = errors.New("store: entry not in the file index")
+	errBlobSize = errors.New("store: blob size mismatch")
+)
+
+// Store keeps the contents of packages in a directory, each file once,
+// keyed by its digest in the header, and a manifest per package to
+// reconstruct its payload. Blobs are in blobs/algo/xx/digest, manifests
+// in packages/identity.json, identity being rpm.Identity of the header.
+// Files are written to a temporary name and renamed, concurrent writers
+// of the same blob don't conflict.
+type Store struct {
+	dir string
+}
+
+// Open returns the store in dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	for _, v := range []string{"blobs", "packages"} {
+		if err := os.MkdirAll(filepath.Join(dir, v), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{dir: dir}, nil
+}
+
+// entry is an archive entry of a manifest. Stripped entries have Index
+// and Size set, newc entries the whole header. Blob is empty for
+// entries without data.
+type entry struct {
+	scpio.Entry
+	Stripped bool   `json:",omitempty"`
+	Blob     string `json:",omitempty"`
+}
+
+type manifest struct {
+	Entries []entry
+}
+
+func (s *Store) blobPath(algo, digest string) string {
+	return filepath.Join(s.dir, "blobs", algo, digest[:2], digest)
+}
+
+func (s *Store) manifestPath(id string) string {
+	return filepath.Join(s.dir, "packages", id+".json")
+}
+
+// Has reports if the package with identity id is stored.
+func (s *Store) Has(id string) bool {
+	_, err := os.Stat(s.manifestPath(id))
+	return err == nil
+}
+
+// create writes name with fn through a temporary file in the same
+// directory.
+func create(name string, fn func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// hardlinks returns the file indexes that carry no data for being an
+// earlier member of a hardlink set, the payload has it with the last.
+func hardlinks(hdr *rpm.Header, files []rpm.File) map[int]bool {
+	var ino, dev []uint32
+	if t := hdr.Find(rpm.RPMTAG_FILEINODES); t != nil {
+		ino, _ = t.Int32()
+	}
+	if t := hdr.Find(rpm.RPMTAG_FILEDEVICES); t != nil {
+		dev, _ = t.Int32()
+	}
+	if len(ino) != len(files) || len(dev) != len(files) {
+		return nil
+	}
+	last := make(map[[2]uint32]int)
+	for i := range files {
+		last[[2]uint32{dev[i], ino[i]}] = i
+	}
+	r := make(map[int]bool)
+	for i := range files {
+		if last[[2]uint32{dev[i], ino[i]}] != i {
+			r[i] = true
+		}
+	}
+	return r
+}
+
+// Add stores the files of the uncompressed cpio payload of hdr, in the
+// stripped or newc format, and returns the identity of the package.
+// The data of every file is checked against its digest in the header.
+func (s *Store) Add(hdr *rpm.Header, payload io.Reader) (string, error) {
+	id, err := rpm.Identity(hdr)
+	if err != nil {
+		return "", err
+	}
+	idx, err := rpm.FileIndexHeader(hdr)
+	if err != nil {
+		return "", err
+	}
+	h, ok := rpm.LookupHash(idx.DigestAlgo())
+	if !ok {
+		return "", fmt.Errorf("store: unsupported digest algorithm %d", idx.DigestAlgo())
+	}
+	files := idx.Files()
+	links := hardlinks(hdr, files)
+
+	var (
+		m  manifest
+		sz int
+		r  = scpio.NewReader(payload)
+	)
+	for {
+		e, err := r.NextEntry(sz)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		v := entry{Entry: *e}
+		i := int(e.Index)
+		if e.Name == "" {
+			v.Stripped = true
+			if i >= len(files) {
+				return "", fmt.Errorf("%w: %d", errIndex, e.Index)
+			}
+			if files[i].Mode&0170000 == 0100000 && !links[i] {
+				v.Size = int64(files[i].Size)
+			}
+		} else if i, ok = idx.Lookup(e.Name); !ok {
+			return "", fmt.Errorf("%w: %s", errIndex, e.Name)
+		}
+		if v.Size > 0 {
+			if v.Blob, err = s.addBlob(h, files[i].Digest, r.Data(v.Size)); err != nil {
+				return "", fmt.Errorf("%s: %w", files[i].Name, err)
+			}
+		}
+		m.Entries = append(m.Entries, v)
+		sz = int(v.Size)
+	}
+
+	err = create(s.manifestPath(id), func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(&m)
+	})
+	return id, err
+}
+
+// addBlob stores r under digest unless it's already there, it returns
+// the key of the blob.
+func (s *Store) addBlob(h rpm.Hash, digest string, r io.Reader) (string, error) {
+	if len(digest) < 2 {
+		return "", errDigest
+	}
+	key := h.Name + "/" + digest
+	name := s.blobPath(h.Name, digest)
+	if _, err := os.Stat(name); err == nil {
+		_, err := io.Copy(io.Discard, r)
+		return key, err
+	}
+	err := create(name, func(w io.Writer) error {
+		d := h.New()
+		if _, err := io.Copy(io.MultiWriter(w, d), r); err != nil {
+			return err
+		}
+		if hex.EncodeToString(d.Sum(nil)) != digest {
+			return errDigest
+		}
+		return nil
+	})
+	return key, err
+}
+
+// Payload writes the uncompressed cpio payload of the package with
+// identity id, the same bytes Add read. Its digest is the
+// RPMTAG_PAYLOADDIGESTALT of packages that have one.
+func (s *Store) Payload(id string, w io.Writer) error {
+	b, err := os.ReadFile(s.manifestPath(id))
+	if err != nil {
+		return err
+	}
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("store: %s: %w", id, err)
+	}
+
+	cw := scpio.NewWriter(w)
+	for _, v := range m.Entries {
+		if v.Stripped {
+			err = cw.WriteHeader(v.Index)
+		} else {
+			cw.SetPrefix(namePrefix(v.Name))
+			err = cw.WriteEntry(&v.Entry)
+		}
+		if err != nil {
+			return err
+		}
+		if v.Blob == "" {
+			continue
+		}
+		if err := s.copyBlob(cw, v.Blob, v.Size); err != nil {
+			return err
+		}
+	}
+	return cw.Close()
+}
+
+func (s *Store) copyBlob(w io.Writer, key string, size int64) error {
+	algo, digest, _ := strings.Cut(key, "/")
+	if len(digest) < 2 {
+		return fmt.Errorf("store: invalid blob %q", key)
+	}
+	f, err := os.Open(s.blobPath(algo, digest))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("%w: %s", errBlobSize, key)
+	}
+	return nil
+}
+
+// namePrefix returns the prefix name was written with.
+func namePrefix(name string) string {
+	switch {
+	case strings.HasPrefix(name, scpio.PrefixDot):
+		return scpio.PrefixDot
+	case strings.HasPrefix(name, scpio.PrefixSlash):
+		return scpio.PrefixSlash
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/pschou/go-rpm"
+	"github.com/pschou/go-rpm/scpio"
+)
+
+type file struct {
+	name string
+	mode uint16
+	data string
+}
+
+// payload returns the header and uncompressed payload of files, newc
+// entries unless stripped.
+func payload(name string, stripped bool, files ...file) (*rpm.Header, []byte) {
+	idx := rpm.NewFileIndex()
+	idx.SetDigestAlgo(rpm.PGPHASHALGO_SHA256)
+	b := new(bytes.Buffer)
+	cw := scpio.NewWriter(b)
+	for i, v := range files {
+		f := &rpm.File{Name: v.name, Mode: v.mode, Size: uint64(len(v.data))}
+		if v.mode&0170000 == 0100000 {
+			d := sha256.Sum256([]byte(v.data))
+			f.Digest = hex.EncodeToString(d[:])
+		}
+		idx.Add(f)
+		if stripped {
+			cw.WriteHeader(uint32(i))
+		} else {
+			cw.WriteEntry(&scpio.Entry{Name: v.name, Ino: uint32(i), Mode: uint32(v.mode), Nlink: 1, Size: int64(len(v.data))})
+		}
+		io.WriteString(cw, v.data)
+	}
+	cw.Close()
+
+	hdr := rpm.NewPayloadHeader()
+	hdr.AddString(rpm.RPMTAG_NAME, name)
+	idx.Append(hdr)
+	return hdr, b.Bytes()
+}
+
+func blobs(t *testing.T, dir string) int {
+	n := 0
+	err := filepath.WalkDir(filepath.Join(dir, "blobs"), func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared := file{"/usr/share/doc/COPYING", 0100644, "license text\n"}
+	for _, v := range []struct {
+		name     string
+		stripped bool
+		files    []file
+	}{
+		{"a", true, []file{{"/usr/bin", 040755, ""}, {"/usr/bin/a", 0100755, "a"}, shared}},
+		{"b", false, []file{{"/usr/bin/b", 0100755, "bbbbb"}, {"/usr/bin/c", 0120777, ""}, shared}},
+	} {
+		hdr, p := payload(v.name, v.stripped, v.files...)
+		id, err := s.Add(hdr, bytes.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Has(id) {
+			t.Fatalf("%s: %s not stored", v.name, id)
+		}
+		b := new(bytes.Buffer)
+		if err := s.Payload(id, b); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b.Bytes(), p) {
+			t.Errorf("%s: payload differs:\n%q\n%q", v.name, b.Bytes(), p)
+		}
+	}
+	if n := blobs(t, dir); n != 3 {
+		t.Errorf("%d blobs, want 3", n)
+	}
+
+	// data not matching its digest
+	hdr, p := payload("c", true, file{"/x", 0100644, "x"})
+	p[bytes.IndexByte(p[6:], 'x')+6] = 'y'
+	if _, err := s.Add(hdr, bytes.NewReader(p)); !errors.Is(err, errDigest) {
+		t.Errorf("corrupt data: %v", err)
+	}
+	if n := blobs(t, dir); n != 3 {
+		t.Errorf("%d blobs after a failed add", n)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -74,9 +75,7 @@ func (t *Tag) string(sig bool) string {
 	s := t.Tag.String()
 	// TODO: something else, signature and payload tags overlap
 	if sig {
-		if v, ok := sigTagString[t.Tag]; ok {
-			s = v
-		}
+		s = SigTagString(t.Tag)
 	}
 	return fmt.Sprintf("%s, %d, %d, 0x%x, %s", s, t.Tag, t.Count, t.Offset, tt)
 }
@@ -164,6 +163,24 @@ func (t *Tag) UnmarshalJSON(b []byte) (err error) {
 	return err
 }
 
+// clone returns a copy of t that shares no data with it.
+func (t *Tag) clone() *Tag {
+	c := *t
+	switch d := t.data.(type) {
+	case *tagBytes:
+		c.data = &tagBytes{b: bytes.NewBuffer(bytes.Clone(d.b.Bytes())), count: d.count}
+	case *tagString:
+		c.data = &tagString{data: slices.Clone(d.data), len: d.len}
+	case tagUint16:
+		c.data = slices.Clone(d)
+	case tagUint32:
+		c.data = slices.Clone(d)
+	case tagUint64:
+		c.data = slices.Clone(d)
+	}
+	return &c
+}
+
 type tagBytes struct {
 	b     *bytes.Buffer
 	count uint32
@@ -180,10 +197,10 @@ func (t *tagBytes) ReadFrom(w io.Reader) (int64, error) {
 	}
 	n, err := t.b.ReadFrom(w)
 	if err != nil {
-		return 0, err
+		return n, err
 	}
 	if n < int64(t.count) {
-		return 0, errUnexpectedEOF
+		return n, errUnexpectedEOF
 	}
 	t.b.Truncate(int(t.count))
 	return n, nil
@@ -193,6 +210,17 @@ func (t *tagBytes) Len() int {
 	return t.b.Len()
 }
 
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
 type tagString struct {
 	data []string
 	len  int
@@ -201,11 +229,11 @@ type tagString struct {
 func (t *tagString) WriteTo(w io.Writer) (int64, error) {
 	var b int64
 	for _, v := range t.data {
-		n, err := io.WriteString(w, v+string(0))
+		n, err := io.WriteString(w, v+"\x00")
+		b += int64(n)
 		if err != nil {
 			return b, err
 		}
-		b += int64(n)
 	}
 	return b, nil
 }
@@ -235,7 +263,7 @@ func (t *tagString) Len() int {
 
 func (t *Tag) StringData() (string, bool) {
 	r, ok := t.data.(*tagString)
-	if len(r.data) == 0 {
+	if !ok || len(r.data) == 0 {
 		return "", false
 	}
 	return r.data[0], ok
@@ -243,6 +271,9 @@ func (t *Tag) StringData() (string, bool) {
 
 func (t *Tag) StringArray() ([]string, bool) {
 	r, ok := t.data.(*tagString)
+	if !ok {
+		return nil, false
+	}
 	return r.data, ok
 }
 
@@ -250,10 +281,14 @@ type tagUint16 []uint16
 
 func (t tagUint16) Len() int { return len(t) * 2 }
 func (t tagUint16) WriteTo(w io.Writer) (int64, error) {
-	return int64(t.Len()), binary.Write(w, binary.BigEndian, t)
+	cw := &countWriter{w: w}
+	err := binary.Write(cw, binary.BigEndian, t)
+	return cw.n, err
 }
 func (t tagUint16) ReadFrom(r io.Reader) (int64, error) {
-	return int64(t.Len()), binary.Read(r, binary.BigEndian, t)
+	cr := &countReader{r: r}
+	err := binary.Read(cr, binary.BigEndian, t)
+	return cr.n, err
 }
 
 func (t *Tag) Int16() ([]uint16, bool) {
@@ -265,10 +300,14 @@ type tagUint32 []uint32
 
 func (t tagUint32) Len() int { return len(t) * 4 }
 func (t tagUint32) WriteTo(w io.Writer) (int64, error) {
-	return int64(t.Len()), binary.Write(w, binary.BigEndian, t)
+	cw := &countWriter{w: w}
+	err := binary.Write(cw, binary.BigEndian, t)
+	return cw.n, err
 }
 func (t tagUint32) ReadFrom(r io.Reader) (int64, error) {
-	return int64(t.Len()), binary.Read(r, binary.BigEndian, t)
+	cr := &countReader{r: r}
+	err := binary.Read(cr, binary.BigEndian, t)
+	return cr.n, err
 }
 
 func (t *Tag) Int32() ([]uint32, bool) {
@@ -280,10 +319,14 @@ type tagUint64 []uint64
 
 func (t tagUint64) Len() int { return len(t) * 8 }
 func (t tagUint64) WriteTo(w io.Writer) (int64, error) {
-	return int64(t.Len()), binary.Write(w, binary.BigEndian, t)
+	cw := &countWriter{w: w}
+	err := binary.Write(cw, binary.BigEndian, t)
+	return cw.n, err
 }
 func (t tagUint64) ReadFrom(r io.Reader) (int64, error) {
-	return int64(t.Len()), binary.Read(r, binary.BigEndian, t)
+	cr := &countReader{r: r}
+	err := binary.Read(cr, binary.BigEndian, t)
+	return cr.n, err
 }
 
 func (t *Tag) Int64() ([]uint64, bool) {
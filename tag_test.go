@@ -3,6 +3,8 @@ package rpm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"testing"
 )
 
@@ -63,3 +65,44 @@ func TestTagJSON(t *testing.T) {
 		tagEq(t, tag, jt)
 	}
 }
+
+var errLimit = errors.New("limit")
+
+type limitWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitWriter) Write(b []byte) (int, error) {
+	if len(b) <= l.n {
+		l.n -= len(b)
+		return l.w.Write(b)
+	}
+	n, _ := l.w.Write(b[:l.n])
+	l.n = 0
+	return n, errLimit
+}
+
+func testPartialWrite(t *testing.T, wt io.WriterTo) {
+	full := new(bytes.Buffer)
+	if _, err := wt.WriteTo(full); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for i := 0; i < full.Len(); i++ {
+		b := new(bytes.Buffer)
+		n, err := wt.WriteTo(&limitWriter{b, i})
+		if err == nil {
+			t.Fatalf("limit %d: expected error", i)
+		}
+		if a, b := n, int64(b.Len()); a != b {
+			t.Fatalf("%T, limit %d: written: want %d, have %d", wt, i, b, a)
+		}
+	}
+}
+
+func TestTagPartialWrite(t *testing.T) {
+	for _, v := range tagTypes {
+		data, _ := makeTagData(v)
+		testPartialWrite(t, data)
+	}
+}
@@ -0,0 +1,210 @@
+package rpm
+
+import (
+	"errors"
+	"strings"
+)
+
+var errTagMismatch = errors.New("rpm: tag type mismatch")
+
+// mainTagTypes are the types of common main header tags in rpmtag.h
+// notation: s string, s{} i18n string, c, h, i and l 8 to 64 bit
+// integers, x binary and [] an array of any length.
+var mainTagTypes = map[TagType]string{
+	RPMTAG_HEADERI18NTABLE:    "s[]",
+	RPMTAG_SIGMD5:             "x",
+	RPMTAG_PUBKEYS:            "s[]",
+	RPMTAG_DSAHEADER:          "x",
+	RPMTAG_RSAHEADER:          "x",
+	RPMTAG_SHA1HEADER:         "s",
+	RPMTAG_SHA256HEADER:       "s",
+	RPMTAG_NAME:               "s",
+	RPMTAG_VERSION:            "s",
+	RPMTAG_RELEASE:            "s",
+	RPMTAG_EPOCH:              "i",
+	RPMTAG_SUMMARY:            "s{}",
+	RPMTAG_DESCRIPTION:        "s{}",
+	RPMTAG_BUILDTIME:          "i",
+	RPMTAG_BUILDHOST:          "s",
+	RPMTAG_INSTALLTIME:        "i",
+	RPMTAG_SIZE:               "i",
+	RPMTAG_DISTRIBUTION:       "s",
+	RPMTAG_VENDOR:             "s",
+	RPMTAG_LICENSE:            "s",
+	RPMTAG_PACKAGER:           "s",
+	RPMTAG_GROUP:              "s{}",
+	RPMTAG_URL:                "s",
+	RPMTAG_OS:                 "s",
+	RPMTAG_ARCH:               "s",
+	RPMTAG_PREIN:              "s",
+	RPMTAG_POSTIN:             "s",
+	RPMTAG_PREUN:              "s",
+	RPMTAG_POSTUN:             "s",
+	RPMTAG_OLDFILENAMES:       "s[]",
+	RPMTAG_FILESIZES:          "i[]",
+	RPMTAG_FILEMODES:          "h[]",
+	RPMTAG_FILERDEVS:          "h[]",
+	RPMTAG_FILEMTIMES:         "i[]",
+	RPMTAG_FILEDIGESTS:        "s[]",
+	RPMTAG_FILELINKTOS:        "s[]",
+	RPMTAG_FILEFLAGS:          "i[]",
+	RPMTAG_FILEUSERNAME:       "s[]",
+	RPMTAG_FILEGROUPNAME:      "s[]",
+	RPMTAG_SOURCERPM:          "s",
+	RPMTAG_FILEVERIFYFLAGS:    "i[]",
+	RPMTAG_ARCHIVESIZE:        "i",
+	RPMTAG_PROVIDENAME:        "s[]",
+	RPMTAG_PROVIDEFLAGS:       "i[]",
+	RPMTAG_PROVIDEVERSION:     "s[]",
+	RPMTAG_REQUIRENAME:        "s[]",
+	RPMTAG_REQUIREFLAGS:       "i[]",
+	RPMTAG_REQUIREVERSION:     "s[]",
+	RPMTAG_CONFLICTNAME:       "s[]",
+	RPMTAG_CONFLICTFLAGS:      "i[]",
+	RPMTAG_CONFLICTVERSION:    "s[]",
+	RPMTAG_OBSOLETENAME:       "s[]",
+	RPMTAG_OBSOLETEFLAGS:      "i[]",
+	RPMTAG_OBSOLETEVERSION:    "s[]",
+	RPMTAG_RPMVERSION:         "s",
+	RPMTAG_CHANGELOGTIME:      "i[]",
+	RPMTAG_CHANGELOGNAME:      "s[]",
+	RPMTAG_CHANGELOGTEXT:      "s[]",
+	RPMTAG_PREINPROG:          "s[]",
+	RPMTAG_POSTINPROG:         "s[]",
+	RPMTAG_PREUNPROG:          "s[]",
+	RPMTAG_POSTUNPROG:         "s[]",
+	RPMTAG_FILEDEVICES:        "i[]",
+	RPMTAG_FILEINODES:         "i[]",
+	RPMTAG_FILELANGS:          "s[]",
+	RPMTAG_PREFIXES:           "s[]",
+	RPMTAG_INSTALLTID:         "i",
+	RPMTAG_COOKIE:             "s",
+	RPMTAG_OPTFLAGS:           "s",
+	RPMTAG_DISTURL:            "s",
+	RPMTAG_PAYLOADFORMAT:      "s",
+	RPMTAG_PAYLOADCOMPRESSOR:  "s",
+	RPMTAG_PAYLOADFLAGS:       "s",
+	RPMTAG_PLATFORM:           "s",
+	RPMTAG_FILECOLORS:         "i[]",
+	RPMTAG_FILECLASS:          "i[]",
+	RPMTAG_CLASSDICT:          "s[]",
+	RPMTAG_FILEDEPENDSX:       "i[]",
+	RPMTAG_FILEDEPENDSN:       "i[]",
+	RPMTAG_DEPENDSDICT:        "i[]",
+	RPMTAG_SOURCEPKGID:        "x",
+	RPMTAG_FILECONTEXTS:       "s[]",
+	RPMTAG_DIRINDEXES:         "i[]",
+	RPMTAG_BASENAMES:          "s[]",
+	RPMTAG_DIRNAMES:           "s[]",
+	RPMTAG_FILECAPS:           "s[]",
+	RPMTAG_FILEDIGESTALGO:     "i",
+	RPMTAG_BUGURL:             "s",
+	RPMTAG_VCS:                "s",
+	RPMTAG_LONGFILESIZES:      "l[]",
+	RPMTAG_LONGSIZE:           "l",
+	RPMTAG_ENCODING:           "s",
+	RPMTAG_PAYLOADDIGEST:      "s[]",
+	RPMTAG_PAYLOADDIGESTALGO:  "i",
+	RPMTAG_RECOMMENDNAME:      "s[]",
+	RPMTAG_RECOMMENDFLAGS:     "i[]",
+	RPMTAG_RECOMMENDVERSION:   "s[]",
+	RPMTAG_SUGGESTNAME:        "s[]",
+	RPMTAG_SUGGESTFLAGS:       "i[]",
+	RPMTAG_SUGGESTVERSION:     "s[]",
+	RPMTAG_SUPPLEMENTNAME:     "s[]",
+	RPMTAG_SUPPLEMENTFLAGS:    "i[]",
+	RPMTAG_SUPPLEMENTVERSION:  "s[]",
+	RPMTAG_ENHANCENAME:        "s[]",
+	RPMTAG_ENHANCEFLAGS:       "i[]",
+	RPMTAG_ENHANCEVERSION:     "s[]",
+	RPMTAG_SOURCEPACKAGE:      "i",
+	RPMTAG_MODULARITYLABEL:    "s",
+	RPMTAG_PAYLOADDIGESTALT:   "s[]",
+	RPMTAG_PAYLOADSIZE:        "l",
+	RPMTAG_PAYLOADSIZEALT:     "l",
+	RPMTAG_RPMFORMAT:          "i",
+	RPMTAG_FILEMIMEINDEX:      "i[]",
+	RPMTAG_MIMEDICT:           "s[]",
+	RPMTAG_PACKAGEDIGESTS:     "s[]",
+	RPMTAG_PACKAGEDIGESTALGOS: "i[]",
+	RPMTAG_SOURCENEVR:         "s",
+}
+
+// sigTagTypes are the types of the signature header tags.
+var sigTagTypes = map[TagType]string{
+	RPMSIGTAG_SIZE:                "i",
+	RPMSIGTAG_PGP:                 "x",
+	RPMSIGTAG_MD5:                 "x",
+	RPMSIGTAG_GPG:                 "x",
+	RPMSIGTAG_PAYLOADSIZE:         "i",
+	RPMSIGTAG_RESERVEDSPACE:       "x",
+	RPMSIGTAG_DSA:                 "x",
+	RPMSIGTAG_RSA:                 "x",
+	RPMSIGTAG_SHA1:                "s",
+	RPMSIGTAG_LONGSIZE:            "l",
+	RPMSIGTAG_LONGARCHIVESIZE:     "l",
+	RPMSIGTAG_SHA256:              "s",
+	RPMSIGTAG_FILESIGNATURES:      "s[]",
+	RPMSIGTAG_FILESIGNATURELENGTH: "i",
+	RPMSIGTAG_VERITYSIGNATURES:    "s[]",
+	RPMSIGTAG_VERITYSIGNATUREALGO: "i",
+	RPMSIGTAG_OPENPGP:             "s[]",
+	RPMSIGTAG_SHA3_256:            "s",
+}
+
+var tagTypeNames = map[string]uint32{
+	"c": RPM_INT8_TYPE,
+	"h": RPM_INT16_TYPE,
+	"i": RPM_INT32_TYPE,
+	"l": RPM_INT64_TYPE,
+	"s": RPM_STRING_TYPE,
+	"x": RPM_BIN_TYPE,
+}
+
+func isStringType(t uint32) bool {
+	return t == RPM_STRING_TYPE || t == RPM_STRING_ARRAY_TYPE || t == RPM_I18NSTRING_TYPE
+}
+
+// tagMismatch checks the type and count of a known tag of a header of
+// kind k and describes what's expected if they don't match. Like rpm,
+// string tags may have any string type, only arrays and i18n strings
+// more than one value.
+func tagMismatch(k Kind, t *Tag) string {
+	types := mainTagTypes
+	if k == KindSignature {
+		types = sigTagTypes
+	}
+	want, ok := types[t.Tag]
+	if !ok {
+		return ""
+	}
+	name, array := strings.CutSuffix(want, "[]")
+	name, i18n := strings.CutSuffix(name, "{}")
+	typ := tagTypeNames[name]
+
+	switch {
+	case typ == RPM_STRING_TYPE && !isStringType(t.Type),
+		typ != RPM_STRING_TYPE && t.Type != typ:
+		return "want type " + want
+	case !array && !i18n && typ != RPM_BIN_TYPE && t.Count != 1:
+		return "want one value"
+	}
+	return ""
+}
+
+// SetTypeCheck makes Add reject known tags of the wrong type, or with
+// several values where rpm expects one, rather than writing a header
+// rpm refuses to read.
+func (hdr *Header) SetTypeCheck(on bool) {
+	hdr.typeCheck = on
+}
+
+func lintTagTypes(hdr *Header) []Warning {
+	var r []Warning
+	for _, v := range hdr.Tags {
+		if msg := tagMismatch(hdr.Kind(), v); msg != "" {
+			r = append(r, Warning{v.Tag, "tag-type", msg})
+		}
+	}
+	return r
+}
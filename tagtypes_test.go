@@ -0,0 +1,50 @@
+package rpm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypeCheck(t *testing.T) {
+	hdr := NewPayloadHeader()
+	if err := hdr.AddInt32(RPMTAG_NAME, 1); err != nil {
+		t.Fatalf("unchecked: %v", err)
+	}
+	if w := lintTagTypes(hdr); len(w) != 1 || w[0].Tag != RPMTAG_NAME {
+		t.Fatalf("lint: %v", w)
+	}
+
+	hdr = NewPayloadHeader()
+	hdr.SetTypeCheck(true)
+	for i, v := range []struct {
+		add func() error
+		ok  bool
+	}{
+		{func() error { return hdr.AddString(RPMTAG_NAME, "a") }, true},
+		{func() error { return hdr.AddStringArray(RPMTAG_VERSION, "1") }, true},
+		{func() error { return hdr.AddStringArray(RPMTAG_RELEASE, "1", "2") }, false},
+		{func() error { return hdr.AddInt32(RPMTAG_SUMMARY, 1) }, false},
+		{func() error { return hdr.AddStringI18N(RPMTAG_SUMMARY, "a") }, true},
+		{func() error { return hdr.AddInt32(RPMTAG_FILESIZES, 1, 2) }, true},
+		{func() error { return hdr.AddInt64(RPMTAG_FILESIZES, 1, 2) }, false},
+		{func() error { return hdr.AddInt16(RPMTAG_FILEMODES, 0644) }, true},
+		{func() error { return hdr.AddInt32(RPMTAG_BUILDTIME, 1, 2) }, false},
+		{func() error { return hdr.AddBin(RPMTAG_SIGMD5, make([]byte, 16)) }, true},
+		{func() error { return hdr.AddInt32(RPMTAG_PAYLOADDIGESTALGO, PGPHASHALGO_SHA256) }, true},
+		{func() error { return hdr.AddInt32(TagType(9999), 1) }, true},
+	} {
+		if err := v.add(); (err == nil) != v.ok || err != nil && !errors.Is(err, errTagMismatch) {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+
+	// signature tags share numbers with main header tags
+	sig := NewSignatureHeader()
+	sig.SetTypeCheck(true)
+	if err := sig.AddInt32(RPMSIGTAG_SIZE, 1); err != nil {
+		t.Errorf("signature size: %v", err)
+	}
+	if err := sig.AddString(RPMSIGTAG_MD5, "a"); !errors.Is(err, errTagMismatch) {
+		t.Errorf("signature md5: %v", err)
+	}
+}
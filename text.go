@@ -0,0 +1,82 @@
+package rpm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextWidth is the conventional width of summaries and description
+// lines, rpmlint's limit.
+const TextWidth = 79
+
+// WrapText wraps the lines of s longer than width at spaces, keeping
+// their indentation, and strips trailing whitespace and blank lines.
+// Shorter lines are left as is so lists and the like keep their form.
+func WrapText(s string, width int) string {
+	var r []string
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimRightFunc(l, unicode.IsSpace)
+		if utf8.RuneCountInString(l) <= width {
+			r = append(r, l)
+			continue
+		}
+
+		indent := l[:len(l)-len(strings.TrimLeftFunc(l, unicode.IsSpace))]
+		line, n := indent, utf8.RuneCountInString(indent)
+		for _, w := range strings.Fields(l) {
+			wn := utf8.RuneCountInString(w)
+			switch {
+			case len(line) == len(indent):
+			case n+1+wn > width:
+				r = append(r, line)
+				line, n = indent, utf8.RuneCountInString(indent)
+			default:
+				line, n = line+" ", n+1
+			}
+			line, n = line+w, n+wn
+		}
+		r = append(r, line)
+	}
+	for len(r) > 0 && r[len(r)-1] == "" {
+		r = r[:len(r)-1]
+	}
+	return strings.Join(r, "\n")
+}
+
+// CleanSummary joins the lines of a summary and strips surrounding
+// whitespace, a summary is a single line.
+func CleanSummary(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func lintText(hdr *Header) []Warning {
+	var r []Warning
+	if s, ok := hdr.StringData(RPMTAG_SUMMARY); ok {
+		if strings.ContainsAny(s, "\r\n") {
+			r = append(r, Warning{RPMTAG_SUMMARY, "summary-newline", "summary is a single line"})
+		}
+		if utf8.RuneCountInString(s) > TextWidth {
+			r = append(r, Warning{RPMTAG_SUMMARY, "summary-too-long", "longer than 79 characters"})
+		}
+		if strings.TrimSpace(s) != s {
+			r = append(r, Warning{RPMTAG_SUMMARY, "trailing-whitespace", "surrounding whitespace"})
+		}
+	}
+	if s, ok := hdr.StringData(RPMTAG_DESCRIPTION); ok {
+		var long, space bool
+		for _, l := range strings.Split(s, "\n") {
+			t := strings.TrimRightFunc(l, unicode.IsSpace)
+			space = space || t != l
+			// unbreakable lines, URLs and such, are fine
+			long = long || utf8.RuneCountInString(t) > TextWidth && strings.ContainsAny(strings.TrimSpace(t), " \t")
+		}
+		if long {
+			r = append(r, Warning{RPMTAG_DESCRIPTION, "description-line-too-long", "wrap lines at 79 characters"})
+		}
+		if space {
+			r = append(r, Warning{RPMTAG_DESCRIPTION, "trailing-whitespace", "lines end in whitespace"})
+		}
+	}
+	return r
+}
@@ -0,0 +1,40 @@
+package rpm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapText(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 20)
+	for _, v := range []struct {
+		in, out string
+	}{
+		{"short  \nlines\t\n\n", "short\nlines"},
+		{"- a list\n- kept", "- a list\n- kept"},
+		{"aaa bbb ccc", "aaa bbb\nccc"},
+		{"  aaa bbb", "  aaa\n  bbb"},
+		{"aaaaaaaaaa b", "aaaaaaaaaa\nb"},
+	} {
+		if s := WrapText(v.in, 8); s != v.out {
+			t.Errorf("%q: %q", v.in, s)
+		}
+	}
+
+	s := WrapText(long, TextWidth)
+	for _, l := range strings.Split(s, "\n") {
+		if utf8.RuneCountInString(l) > TextWidth || strings.TrimSpace(l) != l {
+			t.Fatalf("%q", l)
+		}
+	}
+	if strings.Join(strings.Fields(s), " ") != strings.TrimSpace(long) {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestCleanSummary(t *testing.T) {
+	if s := CleanSummary(" two\n lines "); s != "two lines" {
+		t.Fatal(s)
+	}
+}
@@ -1,25 +1,58 @@
-// Code generated by generate.awk. DO NOT EDIT.
+// Code generated by gentypes from rpm-6.0.0. DO NOT EDIT.
 
 package rpm
 
+import "strconv"
+
 const (
-	HEADER_I18NTABLE  = 100
+	HEADER_IMAGE      = 61
+	HEADER_SIGNATURES = 62
+	HEADER_IMMUTABLE  = 63
 	HEADER_REGIONS    = 64
+	HEADER_I18NTABLE  = 100
 	HEADER_SIGBASE    = 256
-	HEADER_IMMUTABLE  = 63
 	HEADER_TAGBASE    = 1000
-	HEADER_IMAGE      = 61
-	HEADER_SIGNATURES = 62
 )
 
-//go:generate stringer -output types_string.gen.go -type=TagType
 type TagType uint32
 
 const (
+	RPMTAG_HEADERI18NTABLE             TagType = HEADER_I18NTABLE
+	RPMTAG_HEADERIMAGE                 TagType = HEADER_IMAGE
+	RPMTAG_HEADERIMMUTABLE             TagType = HEADER_IMMUTABLE
+	RPMTAG_HEADERREGIONS               TagType = HEADER_REGIONS
+	RPMTAG_HEADERSIGNATURES            TagType = HEADER_SIGNATURES
+	RPMTAG_SIG_BASE                    TagType = HEADER_SIGBASE
+	RPMTAG_SIGSIZE                     TagType = RPMTAG_SIG_BASE + 1
+	RPMTAG_SIGLEMD5_1                  TagType = RPMTAG_SIG_BASE + 2
+	RPMTAG_SIGPGP                      TagType = RPMTAG_SIG_BASE + 3
+	RPMTAG_SIGLEMD5_2                  TagType = RPMTAG_SIG_BASE + 4
+	RPMTAG_SIGMD5                      TagType = RPMTAG_SIG_BASE + 5
+	RPMTAG_PKGID                       TagType = RPMTAG_SIGMD5
+	RPMTAG_SIGGPG                      TagType = RPMTAG_SIG_BASE + 6
+	RPMTAG_SIGPGP5                     TagType = RPMTAG_SIG_BASE + 7
+	RPMTAG_BADSHA1_1                   TagType = RPMTAG_SIG_BASE + 8
+	RPMTAG_BADSHA1_2                   TagType = RPMTAG_SIG_BASE + 9
+	RPMTAG_PUBKEYS                     TagType = RPMTAG_SIG_BASE + 10
+	RPMTAG_DSAHEADER                   TagType = RPMTAG_SIG_BASE + 11
+	RPMTAG_RSAHEADER                   TagType = RPMTAG_SIG_BASE + 12
+	RPMTAG_SHA1HEADER                  TagType = RPMTAG_SIG_BASE + 13
+	RPMTAG_HDRID                       TagType = RPMTAG_SHA1HEADER
+	RPMTAG_LONGSIGSIZE                 TagType = RPMTAG_SIG_BASE + 14
+	RPMTAG_LONGARCHIVESIZE             TagType = RPMTAG_SIG_BASE + 15
+	RPMTAG_SHA256HEADER                TagType = RPMTAG_SIG_BASE + 17
+	RPMTAG_VERITYSIGNATURES            TagType = RPMTAG_SIG_BASE + 20
+	RPMTAG_VERITYSIGNATUREALGO         TagType = RPMTAG_SIG_BASE + 21
+	RPMTAG_OPENPGP                     TagType = RPMTAG_SIG_BASE + 22
+	RPMTAG_SHA3_256HEADER              TagType = RPMTAG_SIG_BASE + 23
 	RPMTAG_NAME                        TagType = 1000
+	RPMTAG_N                           TagType = RPMTAG_NAME
 	RPMTAG_VERSION                     TagType = 1001
+	RPMTAG_V                           TagType = RPMTAG_VERSION
 	RPMTAG_RELEASE                     TagType = 1002
+	RPMTAG_R                           TagType = RPMTAG_RELEASE
 	RPMTAG_EPOCH                       TagType = 1003
+	RPMTAG_E                           TagType = RPMTAG_EPOCH
 	RPMTAG_SUMMARY                     TagType = 1004
 	RPMTAG_DESCRIPTION                 TagType = 1005
 	RPMTAG_BUILDTIME                   TagType = 1006
@@ -52,6 +85,7 @@ const (
 	RPMTAG_FILERDEVS                   TagType = 1033
 	RPMTAG_FILEMTIMES                  TagType = 1034
 	RPMTAG_FILEDIGESTS                 TagType = 1035
+	RPMTAG_FILEMD5S                    TagType = RPMTAG_FILEDIGESTS
 	RPMTAG_FILELINKTOS                 TagType = 1036
 	RPMTAG_FILEFLAGS                   TagType = 1037
 	RPMTAG_ROOT                        TagType = 1038
@@ -64,13 +98,18 @@ const (
 	RPMTAG_FILEVERIFYFLAGS             TagType = 1045
 	RPMTAG_ARCHIVESIZE                 TagType = 1046
 	RPMTAG_PROVIDENAME                 TagType = 1047
+	RPMTAG_PROVIDES                    TagType = RPMTAG_PROVIDENAME
+	RPMTAG_P                           TagType = RPMTAG_PROVIDENAME
 	RPMTAG_REQUIREFLAGS                TagType = 1048
 	RPMTAG_REQUIRENAME                 TagType = 1049
+	RPMTAG_REQUIRES                    TagType = RPMTAG_REQUIRENAME
 	RPMTAG_REQUIREVERSION              TagType = 1050
 	RPMTAG_NOSOURCE                    TagType = 1051
 	RPMTAG_NOPATCH                     TagType = 1052
 	RPMTAG_CONFLICTFLAGS               TagType = 1053
 	RPMTAG_CONFLICTNAME                TagType = 1054
+	RPMTAG_CONFLICTS                   TagType = RPMTAG_CONFLICTNAME
+	RPMTAG_C                           TagType = RPMTAG_CONFLICTNAME
 	RPMTAG_CONFLICTVERSION             TagType = 1055
 	RPMTAG_DEFAULTPREFIX               TagType = 1056
 	RPMTAG_BUILDROOT                   TagType = 1057
@@ -98,6 +137,8 @@ const (
 	RPMTAG_POSTUNPROG                  TagType = 1088
 	RPMTAG_BUILDARCHS                  TagType = 1089
 	RPMTAG_OBSOLETENAME                TagType = 1090
+	RPMTAG_OBSOLETES                   TagType = RPMTAG_OBSOLETENAME
+	RPMTAG_O                           TagType = RPMTAG_OBSOLETENAME
 	RPMTAG_VERIFYSCRIPTPROG            TagType = 1091
 	RPMTAG_TRIGGERSCRIPTPROG           TagType = 1092
 	RPMTAG_DOCDIR                      TagType = 1093
@@ -164,13 +205,16 @@ const (
 	RPMTAG_POSTTRANSPROG               TagType = 1154
 	RPMTAG_DISTTAG                     TagType = 1155
 	RPMTAG_OLDSUGGESTSNAME             TagType = 1156
+	RPMTAG_OLDSUGGESTS                 TagType = RPMTAG_OLDSUGGESTSNAME
 	RPMTAG_OLDSUGGESTSVERSION          TagType = 1157
 	RPMTAG_OLDSUGGESTSFLAGS            TagType = 1158
 	RPMTAG_OLDENHANCESNAME             TagType = 1159
+	RPMTAG_OLDENHANCES                 TagType = RPMTAG_OLDENHANCESNAME
 	RPMTAG_OLDENHANCESVERSION          TagType = 1160
 	RPMTAG_OLDENHANCESFLAGS            TagType = 1161
 	RPMTAG_PRIORITY                    TagType = 1162
 	RPMTAG_CVSID                       TagType = 1163
+	RPMTAG_SVNID                       TagType = RPMTAG_CVSID
 	RPMTAG_BLINKPKGID                  TagType = 1164
 	RPMTAG_BLINKHDRID                  TagType = 1165
 	RPMTAG_BLINKNEVRA                  TagType = 1166
@@ -250,15 +294,19 @@ const (
 	RPMTAG_CONFLICTNEVRS               TagType = 5044
 	RPMTAG_FILENLINKS                  TagType = 5045
 	RPMTAG_RECOMMENDNAME               TagType = 5046
+	RPMTAG_RECOMMENDS                  TagType = RPMTAG_RECOMMENDNAME
 	RPMTAG_RECOMMENDVERSION            TagType = 5047
 	RPMTAG_RECOMMENDFLAGS              TagType = 5048
 	RPMTAG_SUGGESTNAME                 TagType = 5049
+	RPMTAG_SUGGESTS                    TagType = RPMTAG_SUGGESTNAME
 	RPMTAG_SUGGESTVERSION              TagType = 5050
 	RPMTAG_SUGGESTFLAGS                TagType = 5051
 	RPMTAG_SUPPLEMENTNAME              TagType = 5052
+	RPMTAG_SUPPLEMENTS                 TagType = RPMTAG_SUPPLEMENTNAME
 	RPMTAG_SUPPLEMENTVERSION           TagType = 5053
 	RPMTAG_SUPPLEMENTFLAGS             TagType = 5054
 	RPMTAG_ENHANCENAME                 TagType = 5055
+	RPMTAG_ENHANCES                    TagType = RPMTAG_ENHANCENAME
 	RPMTAG_ENHANCEVERSION              TagType = 5056
 	RPMTAG_ENHANCEFLAGS                TagType = 5057
 	RPMTAG_RECOMMENDNEVRS              TagType = 5058
@@ -301,48 +349,375 @@ const (
 	RPMTAG_IDENTITY                    TagType = 5095
 	RPMTAG_MODULARITYLABEL             TagType = 5096
 	RPMTAG_PAYLOADDIGESTALT            TagType = 5097
-	RPMTAG_HEADERI18NTABLE             TagType = HEADER_I18NTABLE
-	RPMTAG_HEADERIMAGE                 TagType = HEADER_IMAGE
-	RPMTAG_HEADERIMMUTABLE             TagType = HEADER_IMMUTABLE
-	RPMTAG_HEADERREGIONS               TagType = HEADER_REGIONS
-	RPMTAG_SIG_BASE                    TagType = HEADER_SIGBASE
-	RPMTAG_HEADERSIGNATURES            TagType = HEADER_SIGNATURES
-	RPMTAG_SIGSIZE                     TagType = RPMTAG_SIG_BASE + 1
-	RPMTAG_PUBKEYS                     TagType = RPMTAG_SIG_BASE + 10
-	RPMTAG_DSAHEADER                   TagType = RPMTAG_SIG_BASE + 11
-	RPMTAG_RSAHEADER                   TagType = RPMTAG_SIG_BASE + 12
-	RPMTAG_SHA1HEADER                  TagType = RPMTAG_SIG_BASE + 13
-	RPMTAG_LONGSIGSIZE                 TagType = RPMTAG_SIG_BASE + 14
-	RPMTAG_LONGARCHIVESIZE             TagType = RPMTAG_SIG_BASE + 15
-	RPMTAG_SHA256HEADER                TagType = RPMTAG_SIG_BASE + 17
-	RPMTAG_SIGLEMD5_1                  TagType = RPMTAG_SIG_BASE + 2
-	RPMTAG_SIGPGP                      TagType = RPMTAG_SIG_BASE + 3
-	RPMTAG_SIGLEMD5_2                  TagType = RPMTAG_SIG_BASE + 4
-	RPMTAG_SIGMD5                      TagType = RPMTAG_SIG_BASE + 5
-	RPMTAG_SIGGPG                      TagType = RPMTAG_SIG_BASE + 6
-	RPMTAG_SIGPGP5                     TagType = RPMTAG_SIG_BASE + 7
-	RPMTAG_BADSHA1_1                   TagType = RPMTAG_SIG_BASE + 8
-	RPMTAG_BADSHA1_2                   TagType = RPMTAG_SIG_BASE + 9
-	RPMTAG_C                           TagType = RPMTAG_CONFLICTNAME
-	RPMTAG_SVNID                       TagType = RPMTAG_CVSID
-	RPMTAG_ENHANCES                    TagType = RPMTAG_ENHANCENAME
-	RPMTAG_E                           TagType = RPMTAG_EPOCH
-	RPMTAG_FILEMD5S                    TagType = RPMTAG_FILEDIGESTS
-	RPMTAG_N                           TagType = RPMTAG_NAME
-	RPMTAG_O                           TagType = RPMTAG_OBSOLETENAME
-	RPMTAG_OLDENHANCES                 TagType = RPMTAG_OLDENHANCESNAME
-	RPMTAG_OLDSUGGESTS                 TagType = RPMTAG_OLDSUGGESTSNAME
-	RPMTAG_P                           TagType = RPMTAG_PROVIDENAME
-	RPMTAG_RECOMMENDS                  TagType = RPMTAG_RECOMMENDNAME
-	RPMTAG_R                           TagType = RPMTAG_RELEASE
-	RPMTAG_REQUIRES                    TagType = RPMTAG_REQUIRENAME
-	RPMTAG_HDRID                       TagType = RPMTAG_SHA1HEADER
-	RPMTAG_PKGID                       TagType = RPMTAG_SIGMD5
-	RPMTAG_SUGGESTS                    TagType = RPMTAG_SUGGESTNAME
-	RPMTAG_SUPPLEMENTS                 TagType = RPMTAG_SUPPLEMENTNAME
-	RPMTAG_V                           TagType = RPMTAG_VERSION
+	RPMTAG_ARCHSUFFIX                  TagType = 5098
+	RPMTAG_SPEC                        TagType = 5099
+	RPMTAG_TRANSLATIONURL              TagType = 5100
+	RPMTAG_UPSTREAMRELEASES            TagType = 5101
+	RPMTAG_SOURCELICENSE               TagType = 5102
+	RPMTAG_PREUNTRANS                  TagType = 5103
+	RPMTAG_POSTUNTRANS                 TagType = 5104
+	RPMTAG_PREUNTRANSPROG              TagType = 5105
+	RPMTAG_POSTUNTRANSPROG             TagType = 5106
+	RPMTAG_PREUNTRANSFLAGS             TagType = 5107
+	RPMTAG_POSTUNTRANSFLAGS            TagType = 5108
+	RPMTAG_SYSUSERS                    TagType = 5109
+	RPMTAG_BUILDSYSTEM                 TagType = 5110
+	RPMTAG_BUILDOPTION                 TagType = 5111
+	RPMTAG_PAYLOADSIZE                 TagType = 5112
+	RPMTAG_PAYLOADSIZEALT              TagType = 5113
+	RPMTAG_RPMFORMAT                   TagType = 5114
+	RPMTAG_FILEMIMEINDEX               TagType = 5115
+	RPMTAG_MIMEDICT                    TagType = 5116
+	RPMTAG_FILEMIMES                   TagType = 5117
+	RPMTAG_PACKAGEDIGESTS              TagType = 5118
+	RPMTAG_PACKAGEDIGESTALGOS          TagType = 5119
+	RPMTAG_SOURCENEVR                  TagType = 5120
 )
 
+var tagTypeString = map[TagType]string{
+	RPMTAG_HEADERI18NTABLE:             "RPMTAG_HEADERI18NTABLE",
+	RPMTAG_HEADERIMAGE:                 "RPMTAG_HEADERIMAGE",
+	RPMTAG_HEADERIMMUTABLE:             "RPMTAG_HEADERIMMUTABLE",
+	RPMTAG_HEADERREGIONS:               "RPMTAG_HEADERREGIONS",
+	RPMTAG_HEADERSIGNATURES:            "RPMTAG_HEADERSIGNATURES",
+	RPMTAG_SIG_BASE:                    "RPMTAG_SIG_BASE",
+	RPMTAG_SIGSIZE:                     "RPMTAG_SIGSIZE",
+	RPMTAG_SIGLEMD5_1:                  "RPMTAG_SIGLEMD5_1",
+	RPMTAG_SIGPGP:                      "RPMTAG_SIGPGP",
+	RPMTAG_SIGLEMD5_2:                  "RPMTAG_SIGLEMD5_2",
+	RPMTAG_SIGMD5:                      "RPMTAG_SIGMD5",
+	RPMTAG_SIGGPG:                      "RPMTAG_SIGGPG",
+	RPMTAG_SIGPGP5:                     "RPMTAG_SIGPGP5",
+	RPMTAG_BADSHA1_1:                   "RPMTAG_BADSHA1_1",
+	RPMTAG_BADSHA1_2:                   "RPMTAG_BADSHA1_2",
+	RPMTAG_PUBKEYS:                     "RPMTAG_PUBKEYS",
+	RPMTAG_DSAHEADER:                   "RPMTAG_DSAHEADER",
+	RPMTAG_RSAHEADER:                   "RPMTAG_RSAHEADER",
+	RPMTAG_SHA1HEADER:                  "RPMTAG_SHA1HEADER",
+	RPMTAG_LONGSIGSIZE:                 "RPMTAG_LONGSIGSIZE",
+	RPMTAG_LONGARCHIVESIZE:             "RPMTAG_LONGARCHIVESIZE",
+	RPMTAG_SHA256HEADER:                "RPMTAG_SHA256HEADER",
+	RPMTAG_VERITYSIGNATURES:            "RPMTAG_VERITYSIGNATURES",
+	RPMTAG_VERITYSIGNATUREALGO:         "RPMTAG_VERITYSIGNATUREALGO",
+	RPMTAG_OPENPGP:                     "RPMTAG_OPENPGP",
+	RPMTAG_SHA3_256HEADER:              "RPMTAG_SHA3_256HEADER",
+	RPMTAG_NAME:                        "RPMTAG_NAME",
+	RPMTAG_VERSION:                     "RPMTAG_VERSION",
+	RPMTAG_RELEASE:                     "RPMTAG_RELEASE",
+	RPMTAG_EPOCH:                       "RPMTAG_EPOCH",
+	RPMTAG_SUMMARY:                     "RPMTAG_SUMMARY",
+	RPMTAG_DESCRIPTION:                 "RPMTAG_DESCRIPTION",
+	RPMTAG_BUILDTIME:                   "RPMTAG_BUILDTIME",
+	RPMTAG_BUILDHOST:                   "RPMTAG_BUILDHOST",
+	RPMTAG_INSTALLTIME:                 "RPMTAG_INSTALLTIME",
+	RPMTAG_SIZE:                        "RPMTAG_SIZE",
+	RPMTAG_DISTRIBUTION:                "RPMTAG_DISTRIBUTION",
+	RPMTAG_VENDOR:                      "RPMTAG_VENDOR",
+	RPMTAG_GIF:                         "RPMTAG_GIF",
+	RPMTAG_XPM:                         "RPMTAG_XPM",
+	RPMTAG_LICENSE:                     "RPMTAG_LICENSE",
+	RPMTAG_PACKAGER:                    "RPMTAG_PACKAGER",
+	RPMTAG_GROUP:                       "RPMTAG_GROUP",
+	RPMTAG_CHANGELOG:                   "RPMTAG_CHANGELOG",
+	RPMTAG_SOURCE:                      "RPMTAG_SOURCE",
+	RPMTAG_PATCH:                       "RPMTAG_PATCH",
+	RPMTAG_URL:                         "RPMTAG_URL",
+	RPMTAG_OS:                          "RPMTAG_OS",
+	RPMTAG_ARCH:                        "RPMTAG_ARCH",
+	RPMTAG_PREIN:                       "RPMTAG_PREIN",
+	RPMTAG_POSTIN:                      "RPMTAG_POSTIN",
+	RPMTAG_PREUN:                       "RPMTAG_PREUN",
+	RPMTAG_POSTUN:                      "RPMTAG_POSTUN",
+	RPMTAG_OLDFILENAMES:                "RPMTAG_OLDFILENAMES",
+	RPMTAG_FILESIZES:                   "RPMTAG_FILESIZES",
+	RPMTAG_FILESTATES:                  "RPMTAG_FILESTATES",
+	RPMTAG_FILEMODES:                   "RPMTAG_FILEMODES",
+	RPMTAG_FILEUIDS:                    "RPMTAG_FILEUIDS",
+	RPMTAG_FILEGIDS:                    "RPMTAG_FILEGIDS",
+	RPMTAG_FILERDEVS:                   "RPMTAG_FILERDEVS",
+	RPMTAG_FILEMTIMES:                  "RPMTAG_FILEMTIMES",
+	RPMTAG_FILEDIGESTS:                 "RPMTAG_FILEDIGESTS",
+	RPMTAG_FILELINKTOS:                 "RPMTAG_FILELINKTOS",
+	RPMTAG_FILEFLAGS:                   "RPMTAG_FILEFLAGS",
+	RPMTAG_ROOT:                        "RPMTAG_ROOT",
+	RPMTAG_FILEUSERNAME:                "RPMTAG_FILEUSERNAME",
+	RPMTAG_FILEGROUPNAME:               "RPMTAG_FILEGROUPNAME",
+	RPMTAG_EXCLUDE:                     "RPMTAG_EXCLUDE",
+	RPMTAG_EXCLUSIVE:                   "RPMTAG_EXCLUSIVE",
+	RPMTAG_ICON:                        "RPMTAG_ICON",
+	RPMTAG_SOURCERPM:                   "RPMTAG_SOURCERPM",
+	RPMTAG_FILEVERIFYFLAGS:             "RPMTAG_FILEVERIFYFLAGS",
+	RPMTAG_ARCHIVESIZE:                 "RPMTAG_ARCHIVESIZE",
+	RPMTAG_PROVIDENAME:                 "RPMTAG_PROVIDENAME",
+	RPMTAG_REQUIREFLAGS:                "RPMTAG_REQUIREFLAGS",
+	RPMTAG_REQUIRENAME:                 "RPMTAG_REQUIRENAME",
+	RPMTAG_REQUIREVERSION:              "RPMTAG_REQUIREVERSION",
+	RPMTAG_NOSOURCE:                    "RPMTAG_NOSOURCE",
+	RPMTAG_NOPATCH:                     "RPMTAG_NOPATCH",
+	RPMTAG_CONFLICTFLAGS:               "RPMTAG_CONFLICTFLAGS",
+	RPMTAG_CONFLICTNAME:                "RPMTAG_CONFLICTNAME",
+	RPMTAG_CONFLICTVERSION:             "RPMTAG_CONFLICTVERSION",
+	RPMTAG_DEFAULTPREFIX:               "RPMTAG_DEFAULTPREFIX",
+	RPMTAG_BUILDROOT:                   "RPMTAG_BUILDROOT",
+	RPMTAG_INSTALLPREFIX:               "RPMTAG_INSTALLPREFIX",
+	RPMTAG_EXCLUDEARCH:                 "RPMTAG_EXCLUDEARCH",
+	RPMTAG_EXCLUDEOS:                   "RPMTAG_EXCLUDEOS",
+	RPMTAG_EXCLUSIVEARCH:               "RPMTAG_EXCLUSIVEARCH",
+	RPMTAG_EXCLUSIVEOS:                 "RPMTAG_EXCLUSIVEOS",
+	RPMTAG_AUTOREQPROV:                 "RPMTAG_AUTOREQPROV",
+	RPMTAG_RPMVERSION:                  "RPMTAG_RPMVERSION",
+	RPMTAG_TRIGGERSCRIPTS:              "RPMTAG_TRIGGERSCRIPTS",
+	RPMTAG_TRIGGERNAME:                 "RPMTAG_TRIGGERNAME",
+	RPMTAG_TRIGGERVERSION:              "RPMTAG_TRIGGERVERSION",
+	RPMTAG_TRIGGERFLAGS:                "RPMTAG_TRIGGERFLAGS",
+	RPMTAG_TRIGGERINDEX:                "RPMTAG_TRIGGERINDEX",
+	RPMTAG_VERIFYSCRIPT:                "RPMTAG_VERIFYSCRIPT",
+	RPMTAG_CHANGELOGTIME:               "RPMTAG_CHANGELOGTIME",
+	RPMTAG_CHANGELOGNAME:               "RPMTAG_CHANGELOGNAME",
+	RPMTAG_CHANGELOGTEXT:               "RPMTAG_CHANGELOGTEXT",
+	RPMTAG_BROKENMD5:                   "RPMTAG_BROKENMD5",
+	RPMTAG_PREREQ:                      "RPMTAG_PREREQ",
+	RPMTAG_PREINPROG:                   "RPMTAG_PREINPROG",
+	RPMTAG_POSTINPROG:                  "RPMTAG_POSTINPROG",
+	RPMTAG_PREUNPROG:                   "RPMTAG_PREUNPROG",
+	RPMTAG_POSTUNPROG:                  "RPMTAG_POSTUNPROG",
+	RPMTAG_BUILDARCHS:                  "RPMTAG_BUILDARCHS",
+	RPMTAG_OBSOLETENAME:                "RPMTAG_OBSOLETENAME",
+	RPMTAG_VERIFYSCRIPTPROG:            "RPMTAG_VERIFYSCRIPTPROG",
+	RPMTAG_TRIGGERSCRIPTPROG:           "RPMTAG_TRIGGERSCRIPTPROG",
+	RPMTAG_DOCDIR:                      "RPMTAG_DOCDIR",
+	RPMTAG_COOKIE:                      "RPMTAG_COOKIE",
+	RPMTAG_FILEDEVICES:                 "RPMTAG_FILEDEVICES",
+	RPMTAG_FILEINODES:                  "RPMTAG_FILEINODES",
+	RPMTAG_FILELANGS:                   "RPMTAG_FILELANGS",
+	RPMTAG_PREFIXES:                    "RPMTAG_PREFIXES",
+	RPMTAG_INSTPREFIXES:                "RPMTAG_INSTPREFIXES",
+	RPMTAG_TRIGGERIN:                   "RPMTAG_TRIGGERIN",
+	RPMTAG_TRIGGERUN:                   "RPMTAG_TRIGGERUN",
+	RPMTAG_TRIGGERPOSTUN:               "RPMTAG_TRIGGERPOSTUN",
+	RPMTAG_AUTOREQ:                     "RPMTAG_AUTOREQ",
+	RPMTAG_AUTOPROV:                    "RPMTAG_AUTOPROV",
+	RPMTAG_CAPABILITY:                  "RPMTAG_CAPABILITY",
+	RPMTAG_SOURCEPACKAGE:               "RPMTAG_SOURCEPACKAGE",
+	RPMTAG_OLDORIGFILENAMES:            "RPMTAG_OLDORIGFILENAMES",
+	RPMTAG_BUILDPREREQ:                 "RPMTAG_BUILDPREREQ",
+	RPMTAG_BUILDREQUIRES:               "RPMTAG_BUILDREQUIRES",
+	RPMTAG_BUILDCONFLICTS:              "RPMTAG_BUILDCONFLICTS",
+	RPMTAG_BUILDMACROS:                 "RPMTAG_BUILDMACROS",
+	RPMTAG_PROVIDEFLAGS:                "RPMTAG_PROVIDEFLAGS",
+	RPMTAG_PROVIDEVERSION:              "RPMTAG_PROVIDEVERSION",
+	RPMTAG_OBSOLETEFLAGS:               "RPMTAG_OBSOLETEFLAGS",
+	RPMTAG_OBSOLETEVERSION:             "RPMTAG_OBSOLETEVERSION",
+	RPMTAG_DIRINDEXES:                  "RPMTAG_DIRINDEXES",
+	RPMTAG_BASENAMES:                   "RPMTAG_BASENAMES",
+	RPMTAG_DIRNAMES:                    "RPMTAG_DIRNAMES",
+	RPMTAG_ORIGDIRINDEXES:              "RPMTAG_ORIGDIRINDEXES",
+	RPMTAG_ORIGBASENAMES:               "RPMTAG_ORIGBASENAMES",
+	RPMTAG_ORIGDIRNAMES:                "RPMTAG_ORIGDIRNAMES",
+	RPMTAG_OPTFLAGS:                    "RPMTAG_OPTFLAGS",
+	RPMTAG_DISTURL:                     "RPMTAG_DISTURL",
+	RPMTAG_PAYLOADFORMAT:               "RPMTAG_PAYLOADFORMAT",
+	RPMTAG_PAYLOADCOMPRESSOR:           "RPMTAG_PAYLOADCOMPRESSOR",
+	RPMTAG_PAYLOADFLAGS:                "RPMTAG_PAYLOADFLAGS",
+	RPMTAG_INSTALLCOLOR:                "RPMTAG_INSTALLCOLOR",
+	RPMTAG_INSTALLTID:                  "RPMTAG_INSTALLTID",
+	RPMTAG_REMOVETID:                   "RPMTAG_REMOVETID",
+	RPMTAG_SHA1RHN:                     "RPMTAG_SHA1RHN",
+	RPMTAG_RHNPLATFORM:                 "RPMTAG_RHNPLATFORM",
+	RPMTAG_PLATFORM:                    "RPMTAG_PLATFORM",
+	RPMTAG_PATCHESNAME:                 "RPMTAG_PATCHESNAME",
+	RPMTAG_PATCHESFLAGS:                "RPMTAG_PATCHESFLAGS",
+	RPMTAG_PATCHESVERSION:              "RPMTAG_PATCHESVERSION",
+	RPMTAG_CACHECTIME:                  "RPMTAG_CACHECTIME",
+	RPMTAG_CACHEPKGPATH:                "RPMTAG_CACHEPKGPATH",
+	RPMTAG_CACHEPKGSIZE:                "RPMTAG_CACHEPKGSIZE",
+	RPMTAG_CACHEPKGMTIME:               "RPMTAG_CACHEPKGMTIME",
+	RPMTAG_FILECOLORS:                  "RPMTAG_FILECOLORS",
+	RPMTAG_FILECLASS:                   "RPMTAG_FILECLASS",
+	RPMTAG_CLASSDICT:                   "RPMTAG_CLASSDICT",
+	RPMTAG_FILEDEPENDSX:                "RPMTAG_FILEDEPENDSX",
+	RPMTAG_FILEDEPENDSN:                "RPMTAG_FILEDEPENDSN",
+	RPMTAG_DEPENDSDICT:                 "RPMTAG_DEPENDSDICT",
+	RPMTAG_SOURCEPKGID:                 "RPMTAG_SOURCEPKGID",
+	RPMTAG_FILECONTEXTS:                "RPMTAG_FILECONTEXTS",
+	RPMTAG_FSCONTEXTS:                  "RPMTAG_FSCONTEXTS",
+	RPMTAG_RECONTEXTS:                  "RPMTAG_RECONTEXTS",
+	RPMTAG_POLICIES:                    "RPMTAG_POLICIES",
+	RPMTAG_PRETRANS:                    "RPMTAG_PRETRANS",
+	RPMTAG_POSTTRANS:                   "RPMTAG_POSTTRANS",
+	RPMTAG_PRETRANSPROG:                "RPMTAG_PRETRANSPROG",
+	RPMTAG_POSTTRANSPROG:               "RPMTAG_POSTTRANSPROG",
+	RPMTAG_DISTTAG:                     "RPMTAG_DISTTAG",
+	RPMTAG_OLDSUGGESTSNAME:             "RPMTAG_OLDSUGGESTSNAME",
+	RPMTAG_OLDSUGGESTSVERSION:          "RPMTAG_OLDSUGGESTSVERSION",
+	RPMTAG_OLDSUGGESTSFLAGS:            "RPMTAG_OLDSUGGESTSFLAGS",
+	RPMTAG_OLDENHANCESNAME:             "RPMTAG_OLDENHANCESNAME",
+	RPMTAG_OLDENHANCESVERSION:          "RPMTAG_OLDENHANCESVERSION",
+	RPMTAG_OLDENHANCESFLAGS:            "RPMTAG_OLDENHANCESFLAGS",
+	RPMTAG_PRIORITY:                    "RPMTAG_PRIORITY",
+	RPMTAG_CVSID:                       "RPMTAG_CVSID",
+	RPMTAG_BLINKPKGID:                  "RPMTAG_BLINKPKGID",
+	RPMTAG_BLINKHDRID:                  "RPMTAG_BLINKHDRID",
+	RPMTAG_BLINKNEVRA:                  "RPMTAG_BLINKNEVRA",
+	RPMTAG_FLINKPKGID:                  "RPMTAG_FLINKPKGID",
+	RPMTAG_FLINKHDRID:                  "RPMTAG_FLINKHDRID",
+	RPMTAG_FLINKNEVRA:                  "RPMTAG_FLINKNEVRA",
+	RPMTAG_PACKAGEORIGIN:               "RPMTAG_PACKAGEORIGIN",
+	RPMTAG_TRIGGERPREIN:                "RPMTAG_TRIGGERPREIN",
+	RPMTAG_BUILDSUGGESTS:               "RPMTAG_BUILDSUGGESTS",
+	RPMTAG_BUILDENHANCES:               "RPMTAG_BUILDENHANCES",
+	RPMTAG_SCRIPTSTATES:                "RPMTAG_SCRIPTSTATES",
+	RPMTAG_SCRIPTMETRICS:               "RPMTAG_SCRIPTMETRICS",
+	RPMTAG_BUILDCPUCLOCK:               "RPMTAG_BUILDCPUCLOCK",
+	RPMTAG_FILEDIGESTALGOS:             "RPMTAG_FILEDIGESTALGOS",
+	RPMTAG_VARIANTS:                    "RPMTAG_VARIANTS",
+	RPMTAG_XMAJOR:                      "RPMTAG_XMAJOR",
+	RPMTAG_XMINOR:                      "RPMTAG_XMINOR",
+	RPMTAG_REPOTAG:                     "RPMTAG_REPOTAG",
+	RPMTAG_KEYWORDS:                    "RPMTAG_KEYWORDS",
+	RPMTAG_BUILDPLATFORMS:              "RPMTAG_BUILDPLATFORMS",
+	RPMTAG_PACKAGECOLOR:                "RPMTAG_PACKAGECOLOR",
+	RPMTAG_PACKAGEPREFCOLOR:            "RPMTAG_PACKAGEPREFCOLOR",
+	RPMTAG_XATTRSDICT:                  "RPMTAG_XATTRSDICT",
+	RPMTAG_FILEXATTRSX:                 "RPMTAG_FILEXATTRSX",
+	RPMTAG_DEPATTRSDICT:                "RPMTAG_DEPATTRSDICT",
+	RPMTAG_CONFLICTATTRSX:              "RPMTAG_CONFLICTATTRSX",
+	RPMTAG_OBSOLETEATTRSX:              "RPMTAG_OBSOLETEATTRSX",
+	RPMTAG_PROVIDEATTRSX:               "RPMTAG_PROVIDEATTRSX",
+	RPMTAG_REQUIREATTRSX:               "RPMTAG_REQUIREATTRSX",
+	RPMTAG_BUILDPROVIDES:               "RPMTAG_BUILDPROVIDES",
+	RPMTAG_BUILDOBSOLETES:              "RPMTAG_BUILDOBSOLETES",
+	RPMTAG_DBINSTANCE:                  "RPMTAG_DBINSTANCE",
+	RPMTAG_NVRA:                        "RPMTAG_NVRA",
+	RPMTAG_FILENAMES:                   "RPMTAG_FILENAMES",
+	RPMTAG_FILEPROVIDE:                 "RPMTAG_FILEPROVIDE",
+	RPMTAG_FILEREQUIRE:                 "RPMTAG_FILEREQUIRE",
+	RPMTAG_FSNAMES:                     "RPMTAG_FSNAMES",
+	RPMTAG_FSSIZES:                     "RPMTAG_FSSIZES",
+	RPMTAG_TRIGGERCONDS:                "RPMTAG_TRIGGERCONDS",
+	RPMTAG_TRIGGERTYPE:                 "RPMTAG_TRIGGERTYPE",
+	RPMTAG_ORIGFILENAMES:               "RPMTAG_ORIGFILENAMES",
+	RPMTAG_LONGFILESIZES:               "RPMTAG_LONGFILESIZES",
+	RPMTAG_LONGSIZE:                    "RPMTAG_LONGSIZE",
+	RPMTAG_FILECAPS:                    "RPMTAG_FILECAPS",
+	RPMTAG_FILEDIGESTALGO:              "RPMTAG_FILEDIGESTALGO",
+	RPMTAG_BUGURL:                      "RPMTAG_BUGURL",
+	RPMTAG_EVR:                         "RPMTAG_EVR",
+	RPMTAG_NVR:                         "RPMTAG_NVR",
+	RPMTAG_NEVR:                        "RPMTAG_NEVR",
+	RPMTAG_NEVRA:                       "RPMTAG_NEVRA",
+	RPMTAG_HEADERCOLOR:                 "RPMTAG_HEADERCOLOR",
+	RPMTAG_VERBOSE:                     "RPMTAG_VERBOSE",
+	RPMTAG_EPOCHNUM:                    "RPMTAG_EPOCHNUM",
+	RPMTAG_PREINFLAGS:                  "RPMTAG_PREINFLAGS",
+	RPMTAG_POSTINFLAGS:                 "RPMTAG_POSTINFLAGS",
+	RPMTAG_PREUNFLAGS:                  "RPMTAG_PREUNFLAGS",
+	RPMTAG_POSTUNFLAGS:                 "RPMTAG_POSTUNFLAGS",
+	RPMTAG_PRETRANSFLAGS:               "RPMTAG_PRETRANSFLAGS",
+	RPMTAG_POSTTRANSFLAGS:              "RPMTAG_POSTTRANSFLAGS",
+	RPMTAG_VERIFYSCRIPTFLAGS:           "RPMTAG_VERIFYSCRIPTFLAGS",
+	RPMTAG_TRIGGERSCRIPTFLAGS:          "RPMTAG_TRIGGERSCRIPTFLAGS",
+	RPMTAG_COLLECTIONS:                 "RPMTAG_COLLECTIONS",
+	RPMTAG_POLICYNAMES:                 "RPMTAG_POLICYNAMES",
+	RPMTAG_POLICYTYPES:                 "RPMTAG_POLICYTYPES",
+	RPMTAG_POLICYTYPESINDEXES:          "RPMTAG_POLICYTYPESINDEXES",
+	RPMTAG_POLICYFLAGS:                 "RPMTAG_POLICYFLAGS",
+	RPMTAG_VCS:                         "RPMTAG_VCS",
+	RPMTAG_ORDERNAME:                   "RPMTAG_ORDERNAME",
+	RPMTAG_ORDERVERSION:                "RPMTAG_ORDERVERSION",
+	RPMTAG_ORDERFLAGS:                  "RPMTAG_ORDERFLAGS",
+	RPMTAG_MSSFMANIFEST:                "RPMTAG_MSSFMANIFEST",
+	RPMTAG_MSSFDOMAIN:                  "RPMTAG_MSSFDOMAIN",
+	RPMTAG_INSTFILENAMES:               "RPMTAG_INSTFILENAMES",
+	RPMTAG_REQUIRENEVRS:                "RPMTAG_REQUIRENEVRS",
+	RPMTAG_PROVIDENEVRS:                "RPMTAG_PROVIDENEVRS",
+	RPMTAG_OBSOLETENEVRS:               "RPMTAG_OBSOLETENEVRS",
+	RPMTAG_CONFLICTNEVRS:               "RPMTAG_CONFLICTNEVRS",
+	RPMTAG_FILENLINKS:                  "RPMTAG_FILENLINKS",
+	RPMTAG_RECOMMENDNAME:               "RPMTAG_RECOMMENDNAME",
+	RPMTAG_RECOMMENDVERSION:            "RPMTAG_RECOMMENDVERSION",
+	RPMTAG_RECOMMENDFLAGS:              "RPMTAG_RECOMMENDFLAGS",
+	RPMTAG_SUGGESTNAME:                 "RPMTAG_SUGGESTNAME",
+	RPMTAG_SUGGESTVERSION:              "RPMTAG_SUGGESTVERSION",
+	RPMTAG_SUGGESTFLAGS:                "RPMTAG_SUGGESTFLAGS",
+	RPMTAG_SUPPLEMENTNAME:              "RPMTAG_SUPPLEMENTNAME",
+	RPMTAG_SUPPLEMENTVERSION:           "RPMTAG_SUPPLEMENTVERSION",
+	RPMTAG_SUPPLEMENTFLAGS:             "RPMTAG_SUPPLEMENTFLAGS",
+	RPMTAG_ENHANCENAME:                 "RPMTAG_ENHANCENAME",
+	RPMTAG_ENHANCEVERSION:              "RPMTAG_ENHANCEVERSION",
+	RPMTAG_ENHANCEFLAGS:                "RPMTAG_ENHANCEFLAGS",
+	RPMTAG_RECOMMENDNEVRS:              "RPMTAG_RECOMMENDNEVRS",
+	RPMTAG_SUGGESTNEVRS:                "RPMTAG_SUGGESTNEVRS",
+	RPMTAG_SUPPLEMENTNEVRS:             "RPMTAG_SUPPLEMENTNEVRS",
+	RPMTAG_ENHANCENEVRS:                "RPMTAG_ENHANCENEVRS",
+	RPMTAG_ENCODING:                    "RPMTAG_ENCODING",
+	RPMTAG_FILETRIGGERIN:               "RPMTAG_FILETRIGGERIN",
+	RPMTAG_FILETRIGGERUN:               "RPMTAG_FILETRIGGERUN",
+	RPMTAG_FILETRIGGERPOSTUN:           "RPMTAG_FILETRIGGERPOSTUN",
+	RPMTAG_FILETRIGGERSCRIPTS:          "RPMTAG_FILETRIGGERSCRIPTS",
+	RPMTAG_FILETRIGGERSCRIPTPROG:       "RPMTAG_FILETRIGGERSCRIPTPROG",
+	RPMTAG_FILETRIGGERSCRIPTFLAGS:      "RPMTAG_FILETRIGGERSCRIPTFLAGS",
+	RPMTAG_FILETRIGGERNAME:             "RPMTAG_FILETRIGGERNAME",
+	RPMTAG_FILETRIGGERINDEX:            "RPMTAG_FILETRIGGERINDEX",
+	RPMTAG_FILETRIGGERVERSION:          "RPMTAG_FILETRIGGERVERSION",
+	RPMTAG_FILETRIGGERFLAGS:            "RPMTAG_FILETRIGGERFLAGS",
+	RPMTAG_TRANSFILETRIGGERIN:          "RPMTAG_TRANSFILETRIGGERIN",
+	RPMTAG_TRANSFILETRIGGERUN:          "RPMTAG_TRANSFILETRIGGERUN",
+	RPMTAG_TRANSFILETRIGGERPOSTUN:      "RPMTAG_TRANSFILETRIGGERPOSTUN",
+	RPMTAG_TRANSFILETRIGGERSCRIPTS:     "RPMTAG_TRANSFILETRIGGERSCRIPTS",
+	RPMTAG_TRANSFILETRIGGERSCRIPTPROG:  "RPMTAG_TRANSFILETRIGGERSCRIPTPROG",
+	RPMTAG_TRANSFILETRIGGERSCRIPTFLAGS: "RPMTAG_TRANSFILETRIGGERSCRIPTFLAGS",
+	RPMTAG_TRANSFILETRIGGERNAME:        "RPMTAG_TRANSFILETRIGGERNAME",
+	RPMTAG_TRANSFILETRIGGERINDEX:       "RPMTAG_TRANSFILETRIGGERINDEX",
+	RPMTAG_TRANSFILETRIGGERVERSION:     "RPMTAG_TRANSFILETRIGGERVERSION",
+	RPMTAG_TRANSFILETRIGGERFLAGS:       "RPMTAG_TRANSFILETRIGGERFLAGS",
+	RPMTAG_REMOVEPATHPOSTFIXES:         "RPMTAG_REMOVEPATHPOSTFIXES",
+	RPMTAG_FILETRIGGERPRIORITIES:       "RPMTAG_FILETRIGGERPRIORITIES",
+	RPMTAG_TRANSFILETRIGGERPRIORITIES:  "RPMTAG_TRANSFILETRIGGERPRIORITIES",
+	RPMTAG_FILETRIGGERCONDS:            "RPMTAG_FILETRIGGERCONDS",
+	RPMTAG_FILETRIGGERTYPE:             "RPMTAG_FILETRIGGERTYPE",
+	RPMTAG_TRANSFILETRIGGERCONDS:       "RPMTAG_TRANSFILETRIGGERCONDS",
+	RPMTAG_TRANSFILETRIGGERTYPE:        "RPMTAG_TRANSFILETRIGGERTYPE",
+	RPMTAG_FILESIGNATURES:              "RPMTAG_FILESIGNATURES",
+	RPMTAG_FILESIGNATURELENGTH:         "RPMTAG_FILESIGNATURELENGTH",
+	RPMTAG_PAYLOADDIGEST:               "RPMTAG_PAYLOADDIGEST",
+	RPMTAG_PAYLOADDIGESTALGO:           "RPMTAG_PAYLOADDIGESTALGO",
+	RPMTAG_AUTOINSTALLED:               "RPMTAG_AUTOINSTALLED",
+	RPMTAG_IDENTITY:                    "RPMTAG_IDENTITY",
+	RPMTAG_MODULARITYLABEL:             "RPMTAG_MODULARITYLABEL",
+	RPMTAG_PAYLOADDIGESTALT:            "RPMTAG_PAYLOADDIGESTALT",
+	RPMTAG_ARCHSUFFIX:                  "RPMTAG_ARCHSUFFIX",
+	RPMTAG_SPEC:                        "RPMTAG_SPEC",
+	RPMTAG_TRANSLATIONURL:              "RPMTAG_TRANSLATIONURL",
+	RPMTAG_UPSTREAMRELEASES:            "RPMTAG_UPSTREAMRELEASES",
+	RPMTAG_SOURCELICENSE:               "RPMTAG_SOURCELICENSE",
+	RPMTAG_PREUNTRANS:                  "RPMTAG_PREUNTRANS",
+	RPMTAG_POSTUNTRANS:                 "RPMTAG_POSTUNTRANS",
+	RPMTAG_PREUNTRANSPROG:              "RPMTAG_PREUNTRANSPROG",
+	RPMTAG_POSTUNTRANSPROG:             "RPMTAG_POSTUNTRANSPROG",
+	RPMTAG_PREUNTRANSFLAGS:             "RPMTAG_PREUNTRANSFLAGS",
+	RPMTAG_POSTUNTRANSFLAGS:            "RPMTAG_POSTUNTRANSFLAGS",
+	RPMTAG_SYSUSERS:                    "RPMTAG_SYSUSERS",
+	RPMTAG_BUILDSYSTEM:                 "RPMTAG_BUILDSYSTEM",
+	RPMTAG_BUILDOPTION:                 "RPMTAG_BUILDOPTION",
+	RPMTAG_PAYLOADSIZE:                 "RPMTAG_PAYLOADSIZE",
+	RPMTAG_PAYLOADSIZEALT:              "RPMTAG_PAYLOADSIZEALT",
+	RPMTAG_RPMFORMAT:                   "RPMTAG_RPMFORMAT",
+	RPMTAG_FILEMIMEINDEX:               "RPMTAG_FILEMIMEINDEX",
+	RPMTAG_MIMEDICT:                    "RPMTAG_MIMEDICT",
+	RPMTAG_FILEMIMES:                   "RPMTAG_FILEMIMES",
+	RPMTAG_PACKAGEDIGESTS:              "RPMTAG_PACKAGEDIGESTS",
+	RPMTAG_PACKAGEDIGESTALGOS:          "RPMTAG_PACKAGEDIGESTALGOS",
+	RPMTAG_SOURCENEVR:                  "RPMTAG_SOURCENEVR",
+}
+
+func (t TagType) String() string {
+	if s, ok := tagTypeString[t]; ok {
+		return s
+	}
+	return "TagType(" + strconv.FormatInt(int64(t), 10) + ")"
+}
+
 type SigTagType = TagType
 
 const (
@@ -358,13 +733,17 @@ const (
 	RPMSIGTAG_BADSHA1_1           SigTagType = RPMTAG_BADSHA1_1
 	RPMSIGTAG_BADSHA1_2           SigTagType = RPMTAG_BADSHA1_2
 	RPMSIGTAG_DSA                 SigTagType = RPMTAG_DSAHEADER
-	RPMSIGTAG_LONGARCHIVESIZE     SigTagType = RPMTAG_LONGARCHIVESIZE
-	RPMSIGTAG_LONGSIZE            SigTagType = RPMTAG_LONGSIGSIZE
 	RPMSIGTAG_RSA                 SigTagType = RPMTAG_RSAHEADER
 	RPMSIGTAG_SHA1                SigTagType = RPMTAG_SHA1HEADER
+	RPMSIGTAG_LONGSIZE            SigTagType = RPMTAG_LONGSIGSIZE
+	RPMSIGTAG_LONGARCHIVESIZE     SigTagType = RPMTAG_LONGARCHIVESIZE
 	RPMSIGTAG_SHA256              SigTagType = RPMTAG_SHA256HEADER
 	RPMSIGTAG_FILESIGNATURES      SigTagType = RPMTAG_SIG_BASE + 18
 	RPMSIGTAG_FILESIGNATURELENGTH SigTagType = RPMTAG_SIG_BASE + 19
+	RPMSIGTAG_VERITYSIGNATURES    SigTagType = RPMTAG_VERITYSIGNATURES
+	RPMSIGTAG_VERITYSIGNATUREALGO SigTagType = RPMTAG_VERITYSIGNATUREALGO
+	RPMSIGTAG_OPENPGP             SigTagType = RPMTAG_OPENPGP
+	RPMSIGTAG_SHA3_256            SigTagType = RPMTAG_SHA3_256HEADER
 )
 
 var sigTagString = map[TagType]string{
@@ -380,16 +759,21 @@ var sigTagString = map[TagType]string{
 	RPMSIGTAG_BADSHA1_1:           "RPMSIGTAG_BADSHA1_1",
 	RPMSIGTAG_BADSHA1_2:           "RPMSIGTAG_BADSHA1_2",
 	RPMSIGTAG_DSA:                 "RPMSIGTAG_DSA",
-	RPMSIGTAG_LONGARCHIVESIZE:     "RPMSIGTAG_LONGARCHIVESIZE",
-	RPMSIGTAG_LONGSIZE:            "RPMSIGTAG_LONGSIZE",
 	RPMSIGTAG_RSA:                 "RPMSIGTAG_RSA",
 	RPMSIGTAG_SHA1:                "RPMSIGTAG_SHA1",
+	RPMSIGTAG_LONGSIZE:            "RPMSIGTAG_LONGSIZE",
+	RPMSIGTAG_LONGARCHIVESIZE:     "RPMSIGTAG_LONGARCHIVESIZE",
 	RPMSIGTAG_SHA256:              "RPMSIGTAG_SHA256",
 	RPMSIGTAG_FILESIGNATURES:      "RPMSIGTAG_FILESIGNATURES",
 	RPMSIGTAG_FILESIGNATURELENGTH: "RPMSIGTAG_FILESIGNATURELENGTH",
+	RPMSIGTAG_VERITYSIGNATURES:    "RPMSIGTAG_VERITYSIGNATURES",
+	RPMSIGTAG_VERITYSIGNATUREALGO: "RPMSIGTAG_VERITYSIGNATUREALGO",
+	RPMSIGTAG_OPENPGP:             "RPMSIGTAG_OPENPGP",
+	RPMSIGTAG_SHA3_256:            "RPMSIGTAG_SHA3_256",
 }
 
 const (
+	RPM_MIN_TYPE          = 0
 	RPM_NULL_TYPE         = 0
 	RPM_CHAR_TYPE         = 1
 	RPM_INT8_TYPE         = 2
@@ -400,13 +784,20 @@ const (
 	RPM_BIN_TYPE          = 7
 	RPM_STRING_ARRAY_TYPE = 8
 	RPM_I18NSTRING_TYPE   = 9
-	RPM_MIN_TYPE          = 0
-	RPM_MASK_TYPE         = 0x0000ffff
-	RPM_FORCEFREE_TYPE    = 0xff
 	RPM_MAX_TYPE          = 9
+	RPM_FORCEFREE_TYPE    = 0xff
+	RPM_MASK_TYPE         = 0x0000ffff
 )
 const (
+	RPMSENSE_ANY           = 0
 	RPMSENSE_LESS          = (1 << 1)
+	RPMSENSE_GREATER       = (1 << 2)
+	RPMSENSE_EQUAL         = (1 << 3)
+	RPMSENSE_POSTTRANS     = (1 << 5)
+	RPMSENSE_PREREQ        = (1 << 6)
+	RPMSENSE_PRETRANS      = (1 << 7)
+	RPMSENSE_INTERP        = (1 << 8)
+	RPMSENSE_SCRIPT_PRE    = (1 << 9)
 	RPMSENSE_SCRIPT_POST   = (1 << 10)
 	RPMSENSE_SCRIPT_PREUN  = (1 << 11)
 	RPMSENSE_SCRIPT_POSTUN = (1 << 12)
@@ -417,21 +808,16 @@ const (
 	RPMSENSE_TRIGGERUN     = (1 << 17)
 	RPMSENSE_TRIGGERPOSTUN = (1 << 18)
 	RPMSENSE_MISSINGOK     = (1 << 19)
-	RPMSENSE_GREATER       = (1 << 2)
+	RPMSENSE_PREUNTRANS    = (1 << 20)
+	RPMSENSE_POSTUNTRANS   = (1 << 21)
 	RPMSENSE_RPMLIB        = (1 << 24)
 	RPMSENSE_TRIGGERPREIN  = (1 << 25)
 	RPMSENSE_KEYRING       = (1 << 26)
 	RPMSENSE_CONFIG        = (1 << 28)
 	RPMSENSE_META          = (1 << 29)
-	RPMSENSE_EQUAL         = (1 << 3)
-	RPMSENSE_POSTTRANS     = (1 << 5)
-	RPMSENSE_PREREQ        = (1 << 6)
-	RPMSENSE_PRETRANS      = (1 << 7)
-	RPMSENSE_INTERP        = (1 << 8)
-	RPMSENSE_SCRIPT_PRE    = (1 << 9)
-	RPMSENSE_ANY           = 0
 )
 const (
+	RPMFILE_NONE      = 0
 	RPMFILE_CONFIG    = (1 << 0)
 	RPMFILE_DOC       = (1 << 1)
 	RPMFILE_ICON      = (1 << 2)
@@ -443,24 +829,23 @@ const (
 	RPMFILE_README    = (1 << 8)
 	RPMFILE_PUBKEY    = (1 << 11)
 	RPMFILE_ARTIFACT  = (1 << 12)
-	RPMFILE_NONE      = 0
 )
 const (
+	RPMVERIFY_NONE            = 0
 	RPMVERIFY_FILEDIGEST      = (1 << 0)
 	RPMVERIFY_FILESIZE        = (1 << 1)
-	RPMVERIFY_CONTEXTS        = (1 << 15)
 	RPMVERIFY_LINKTO          = (1 << 2)
-	RPMVERIFY_READLINKFAIL    = (1 << 28)
-	RPMVERIFY_READFAIL        = (1 << 29)
 	RPMVERIFY_USER            = (1 << 3)
-	RPMVERIFY_LSTATFAIL       = (1 << 30)
-	RPMVERIFY_LGETFILECONFAIL = (1 << 31)
 	RPMVERIFY_GROUP           = (1 << 4)
 	RPMVERIFY_MTIME           = (1 << 5)
 	RPMVERIFY_MODE            = (1 << 6)
 	RPMVERIFY_RDEV            = (1 << 7)
 	RPMVERIFY_CAPS            = (1 << 8)
-	RPMVERIFY_NONE            = 0
+	RPMVERIFY_CONTEXTS        = (1 << 15)
+	RPMVERIFY_READLINKFAIL    = (1 << 28)
+	RPMVERIFY_READFAIL        = (1 << 29)
+	RPMVERIFY_LSTATFAIL       = (1 << 30)
+	RPMVERIFY_LGETFILECONFAIL = (1 << 31)
 )
 const (
 	PGPHASHALGO_MD5         = 1
@@ -473,4 +858,6 @@ const (
 	PGPHASHALGO_SHA384      = 9
 	PGPHASHALGO_SHA512      = 10
 	PGPHASHALGO_SHA224      = 11
+	PGPHASHALGO_SHA3_256    = 12
+	PGPHASHALGO_SHA3_512    = 14
 )
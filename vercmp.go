@@ -0,0 +1,166 @@
+package rpm
+
+import (
+	"strconv"
+	"strings"
+)
+
+func isAlpha(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlnum(c byte) bool { return isAlpha(c) || isDigit(c) }
+
+// Vercmp compares two version or release strings like rpmvercmp.
+func Vercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	for len(a) > 0 || len(b) > 0 {
+		for len(a) > 0 && !isAlnum(a[0]) && a[0] != '~' && a[0] != '^' {
+			a = a[1:]
+		}
+		for len(b) > 0 && !isAlnum(b[0]) && b[0] != '~' && b[0] != '^' {
+			b = b[1:]
+		}
+
+		// tilde sorts before everything, even the end of a string
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// caret sorts after the end of a string, but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if len(a) == 0 {
+				return -1
+			}
+			if len(b) == 0 {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if len(a) == 0 || len(b) == 0 {
+			break
+		}
+
+		seg := isAlpha
+		if isDigit(a[0]) {
+			seg = isDigit
+		}
+		var i, j int
+		for i < len(a) && seg(a[i]) {
+			i++
+		}
+		for j < len(b) && seg(b[j]) {
+			j++
+		}
+		sa, sb := a[:i], b[:j]
+		a, b = a[i:], b[j:]
+
+		// numeric segments are newer than alpha segments
+		if len(sb) == 0 {
+			if isDigit(sa[0]) {
+				return 1
+			}
+			return -1
+		}
+
+		if isDigit(sa[0]) {
+			sa = strings.TrimLeft(sa, "0")
+			sb = strings.TrimLeft(sb, "0")
+			if len(sa) != len(sb) {
+				if len(sa) > len(sb) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if c := strings.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	}
+	return 1
+}
+
+type EVR struct {
+	Epoch   uint32
+	Version string
+	Release string
+}
+
+// ParseEVR parses [epoch:]version[-release].
+func ParseEVR(s string) EVR {
+	var r EVR
+	if i := strings.IndexByte(s, ':'); i != -1 {
+		if e, err := strconv.ParseUint(s[:i], 10, 32); err == nil {
+			r.Epoch = uint32(e)
+		}
+		s = s[i+1:]
+	}
+	if i := strings.LastIndexByte(s, '-'); i != -1 {
+		s, r.Release = s[:i], s[i+1:]
+	}
+	r.Version = s
+	return r
+}
+
+// HeaderEVR returns the epoch, version and release of hdr.
+func HeaderEVR(hdr *Header) EVR {
+	var r EVR
+	r.Version, _ = hdr.StringData(RPMTAG_VERSION)
+	r.Release, _ = hdr.StringData(RPMTAG_RELEASE)
+	if t := hdr.Find(RPMTAG_EPOCH); t != nil {
+		if e, ok := t.Int32(); ok && len(e) > 0 {
+			r.Epoch = e[0]
+		}
+	}
+	return r
+}
+
+func (e EVR) String() string {
+	r := e.Version
+	if e.Epoch != 0 {
+		r = strconv.FormatUint(uint64(e.Epoch), 10) + ":" + r
+	}
+	if e.Release != "" {
+		r += "-" + e.Release
+	}
+	return r
+}
+
+// Compare compares e to o, the release is ignored if either is empty.
+func (e EVR) Compare(o EVR) int {
+	switch {
+	case e.Epoch < o.Epoch:
+		return -1
+	case e.Epoch > o.Epoch:
+		return 1
+	}
+	if c := Vercmp(e.Version, o.Version); c != 0 {
+		return c
+	}
+	if e.Release == "" || o.Release == "" {
+		return 0
+	}
+	return Vercmp(e.Release, o.Release)
+}
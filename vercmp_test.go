@@ -0,0 +1,83 @@
+package rpm
+
+import "testing"
+
+func TestVercmp(t *testing.T) {
+	for _, v := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "2.0", -1},
+		{"2.0.1", "2.0", 1},
+		{"1.0010", "1.9", 1},
+		{"1.05", "1.5", 0},
+		{"5.5p1", "5.5p2", -1},
+		{"10xyz", "10.1xyz", -1},
+		{"xyz10", "xyz10.1", -1},
+		{"1.0a", "1.0", 1},
+		{"1a", "1.a", 0},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1~git", "1.0~rc1", -1},
+		{"1.0^", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0^git1~pre", "1.0^git1", -1},
+		{"2.0", "2_0", 0},
+		{"a", "1", -1},
+	} {
+		if r := Vercmp(v.a, v.b); r != v.want {
+			t.Errorf("%s <> %s: %d != %d", v.a, v.b, r, v.want)
+		}
+		if r := Vercmp(v.b, v.a); r != -v.want {
+			t.Errorf("%s <> %s: %d != %d", v.b, v.a, r, -v.want)
+		}
+	}
+}
+
+func TestEVR(t *testing.T) {
+	for _, v := range []struct {
+		a, b string
+		want int
+	}{
+		{"1:1.0-1", "2.0-1", 1},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0", "1.0-2", 0},
+		{"0:1.0-1", "1.0-1", 0},
+	} {
+		if r := ParseEVR(v.a).Compare(ParseEVR(v.b)); r != v.want {
+			t.Errorf("%s <> %s: %d != %d", v.a, v.b, r, v.want)
+		}
+	}
+	if s := ParseEVR("2:1.0-3.el8").String(); s != "2:1.0-3.el8" {
+		t.Errorf("string: %s", s)
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	const (
+		lt = RPMSENSE_LESS
+		gt = RPMSENSE_GREATER
+		eq = RPMSENSE_EQUAL
+	)
+	for _, v := range []struct {
+		prov, req Dependency
+		want      bool
+	}{
+		{Dependency{"a", 0, ""}, Dependency{"a", gt | eq, "2"}, true},
+		{Dependency{"a", eq, "2"}, Dependency{"a", 0, ""}, true},
+		{Dependency{"a", eq, "2"}, Dependency{"b", 0, ""}, false},
+		{Dependency{"a", eq, "2"}, Dependency{"a", gt | eq, "2"}, true},
+		{Dependency{"a", eq, "2"}, Dependency{"a", gt, "2"}, false},
+		{Dependency{"a", eq, "1"}, Dependency{"a", gt | eq, "2"}, false},
+		{Dependency{"a", eq, "3"}, Dependency{"a", lt, "2"}, false},
+		{Dependency{"a", eq, "1"}, Dependency{"a", lt, "2"}, true},
+		{Dependency{"a", gt | eq, "1"}, Dependency{"a", eq, "5"}, true},
+		{Dependency{"a", eq, "2-1"}, Dependency{"a", eq, "2"}, true},
+	} {
+		if r := v.prov.Satisfies(v.req); r != v.want {
+			t.Errorf("%s satisfies %s: %v", v.prov, v.req, r)
+		}
+	}
+}
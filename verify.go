@@ -0,0 +1,244 @@
+package rpm
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// VerifyResult is a file that differs from the package. Failed holds
+// the RPMVERIFY_* bits of the differing attributes.
+type VerifyResult struct {
+	Name    string
+	Flags   uint32 // RPMFILE_*
+	Missing bool
+	Failed  uint32
+}
+
+var verifyColumns = []struct {
+	bit uint32
+	c   byte
+}{
+	{RPMVERIFY_FILESIZE, 'S'},
+	{RPMVERIFY_MODE, 'M'},
+	{RPMVERIFY_FILEDIGEST, '5'},
+	{RPMVERIFY_RDEV, 'D'},
+	{RPMVERIFY_LINKTO, 'L'},
+	{RPMVERIFY_USER, 'U'},
+	{RPMVERIFY_GROUP, 'G'},
+	{RPMVERIFY_MTIME, 'T'},
+	{RPMVERIFY_CAPS, 'P'},
+}
+
+// String formats r like rpm -V.
+func (r VerifyResult) String() string {
+	attr := " "
+	for _, v := range []struct {
+		bit uint32
+		c   string
+	}{
+		{RPMFILE_CONFIG, "c"},
+		{RPMFILE_DOC, "d"},
+		{RPMFILE_GHOST, "g"},
+		{RPMFILE_LICENSE, "l"},
+		{RPMFILE_README, "r"},
+	} {
+		if r.Flags&v.bit != 0 {
+			attr = v.c
+			break
+		}
+	}
+	if r.Missing {
+		return fmt.Sprintf("missing   %s %s", attr, r.Name)
+	}
+
+	var b [9]byte
+	for i, v := range verifyColumns {
+		switch {
+		case v.bit == RPMVERIFY_FILEDIGEST && r.Failed&RPMVERIFY_READFAIL != 0:
+			b[i] = '?'
+		case r.Failed&v.bit != 0:
+			b[i] = v.c
+		default:
+			b[i] = '.'
+		}
+	}
+	return fmt.Sprintf("%s  %s %s", b[:], attr, r.Name)
+}
+
+// idNames reads the names of a passwd or group file below root, root is
+// the only name known without one.
+func idNames(root, name string) map[uint32]string {
+	r := map[uint32]string{0: "root"}
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return r
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		v := strings.Split(s.Text(), ":")
+		if len(v) < 3 {
+			continue
+		}
+		id, err := strconv.ParseUint(v[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, ok := r[uint32(id)]; !ok || id == 0 {
+			r[uint32(id)] = v[0]
+		}
+	}
+	return r
+}
+
+// unixMode converts fi to the st_mode stored in RPMTAG_FILEMODES.
+func unixMode(fi fs.FileInfo) uint16 {
+	m := fi.Mode()
+	r := uint16(m & fs.ModePerm)
+	if m&fs.ModeSetuid != 0 {
+		r |= 04000
+	}
+	if m&fs.ModeSetgid != 0 {
+		r |= 02000
+	}
+	if m&fs.ModeSticky != 0 {
+		r |= 01000
+	}
+	switch {
+	case m.IsRegular():
+		r |= typeRegular << 12
+	case m.IsDir():
+		r |= typeDir << 12
+	case m&fs.ModeSymlink != 0:
+		r |= typeSymlink << 12
+	}
+	return r
+}
+
+func fileDigest(name string, h Hash) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	d := h.New()
+	if _, err := io.Copy(d, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(d.Sum(nil)), nil
+}
+
+// VerifyFiles compares the files of hdr with the ones installed below
+// root, without an rpm database, and returns the files that differ.
+// Owners are looked up in the passwd and group files of root. Ghost
+// files and missing %config(missingok) files are skipped.
+func VerifyFiles(hdr *Header, root string) ([]VerifyResult, error) {
+	f, err := FileIndexHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	n := len(f.name)
+	for _, v := range []int{
+		len(f.dirIndexes), len(f.mode), len(f.flags),
+		len(f.user), len(f.group), len(f.digest),
+	} {
+		if v != n {
+			return nil, errFileIndex
+		}
+	}
+
+	algo, ok := LookupHash(f.DigestAlgo())
+	if !ok {
+		return nil, tagError{hdr.Find(RPMTAG_FILEDIGESTALGO), errDigestAlgo}
+	}
+
+	users := idNames(root, "etc/passwd")
+	groups := idNames(root, "etc/group")
+
+	var r []VerifyResult
+	for i := 0; i < n; i++ {
+		name := f.dirNames.s[f.dirIndexes[i]] + f.name[i]
+		flags := f.flags[i]
+		if flags&RPMFILE_GHOST != 0 {
+			continue
+		}
+		verify := ^uint32(0)
+		if i < len(f.verify) {
+			verify = f.verify[i]
+		}
+
+		p := filepath.Join(root, filepath.FromSlash(name))
+		fi, err := os.Lstat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			if flags&RPMFILE_MISSINGOK == 0 {
+				r = append(r, VerifyResult{Name: name, Flags: flags, Missing: true})
+			}
+			continue
+		}
+		if err != nil {
+			return r, err
+		}
+
+		var failed uint32
+		mode := f.mode[i]
+		if unixMode(fi) != mode {
+			failed |= RPMVERIFY_MODE
+		}
+		switch mode >> 12 {
+		case typeRegular:
+			if !fi.Mode().IsRegular() {
+				break
+			}
+			if uint64(fi.Size()) != f.fsize(i) {
+				failed |= RPMVERIFY_FILESIZE
+			}
+			if verify&RPMVERIFY_FILEDIGEST == 0 || f.digest[i] == "" {
+				break
+			}
+			d, err := fileDigest(p, algo)
+			switch {
+			case err != nil:
+				failed |= RPMVERIFY_READFAIL
+			case d != f.digest[i]:
+				failed |= RPMVERIFY_FILEDIGEST
+			}
+		case typeSymlink:
+			l, err := os.Readlink(p)
+			switch {
+			case err != nil:
+				failed |= RPMVERIFY_READLINKFAIL
+			case i < len(f.linkto) && l != f.linkto[i]:
+				failed |= RPMVERIFY_LINKTO
+			}
+		}
+		if i < len(f.mtime) && mode>>12 != typeDir &&
+			fi.ModTime().Unix() != int64(f.mtime[i]) {
+			failed |= RPMVERIFY_MTIME
+		}
+		if uid, gid, ok := fileOwner(fi); ok {
+			if users[uid] != f.user[i] {
+				failed |= RPMVERIFY_USER
+			}
+			if groups[gid] != f.group[i] {
+				failed |= RPMVERIFY_GROUP
+			}
+		}
+
+		// failures to read are reported whatever the verify flags
+		failed &= verify | RPMVERIFY_READFAIL | RPMVERIFY_READLINKFAIL
+		if failed != 0 {
+			r = append(r, VerifyResult{Name: name, Flags: flags, Failed: failed})
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package rpm
+
+import "io/fs"
+
+func fileOwner(fi fs.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,99 @@
+package rpm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVerifyFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, data string, mode os.FileMode) {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), mode); err != nil {
+			t.Fatal(err)
+		}
+		os.Chmod(p, mode)
+	}
+	write("etc/a.conf", "a", 0644)
+	write("usr/bin/b", "b", 0755)
+	write("usr/bin/c", "changed", 0755)
+	if err := os.Symlink("b", filepath.Join(root, "usr/bin/d")); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Lstat(filepath.Join(root, "usr/bin/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid, gid, ok := fileOwner(fi)
+	if !ok {
+		t.Skip("no file owners")
+	}
+	write("etc/passwd", fmt.Sprintf("me:x:%d:%d::/:/bin/sh\n", uid, gid), 0644)
+	write("etc/group", fmt.Sprintf("us:x:%d:\n", gid), 0644)
+	mtime := uint32(fi.ModTime().Unix())
+
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	idx := NewFileIndex()
+	for _, v := range []*File{
+		{Name: "/etc/a.conf", Mode: 0100644, Digest: sum("a"), Size: 1, Flags: RPMFILE_CONFIG},
+		{Name: "/usr/bin/b", Mode: 0100644, Digest: sum("b"), Size: 1},
+		{Name: "/usr/bin/c", Mode: 0100755, Digest: sum("c"), Size: 1},
+		{Name: "/usr/bin/d", Mode: 0120777, LinkTo: "c"},
+		{Name: "/usr/bin/e", Mode: 0100755},
+		{Name: "/usr/bin/f", Mode: 0100755, Flags: RPMFILE_GHOST},
+		{Name: "/usr/bin/g", Mode: 0100755, Digest: sum("g"), Size: 1, NoVerify: RPMVERIFY_FILEDIGEST | RPMVERIFY_FILESIZE},
+	} {
+		v.User, v.Group, v.MTime = "me", "us", mtime
+		idx.Add(v)
+	}
+	write("usr/bin/g", "gg", 0755)
+
+	hdr := new(Header)
+	hdr.AddInt32(RPMTAG_FILEDIGESTALGO, PGPHASHALGO_SHA256)
+	idx.Append(hdr)
+
+	r, err := VerifyFiles(hdr, root)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	want := []string{
+		".M.......    /usr/bin/b",
+		"S.5......    /usr/bin/c",
+		"....L....    /usr/bin/d",
+		"missing     /usr/bin/e",
+	}
+	if len(r) != len(want) {
+		t.Fatalf("verify: want %d, have %d: %v", len(want), len(r), r)
+	}
+	for i, v := range r {
+		if v.String() != want[i] {
+			t.Errorf("verify: want %q, have %q", want[i], v)
+		}
+	}
+
+	os.Chtimes(filepath.Join(root, "etc/a.conf"), time.Now(), time.Unix(1, 0))
+	if r, _ := VerifyFiles(hdr, root); len(r) == 0 || r[0].String() != ".......T.  c /etc/a.conf" {
+		t.Errorf("mtime: %v", r)
+	}
+
+	bad := new(Header)
+	bad.AddStringArray(RPMTAG_DIRNAMES, "/etc/")
+	bad.AddStringArray(RPMTAG_BASENAMES, "a.conf")
+	bad.AddInt32(RPMTAG_DIRINDEXES, 5)
+	if _, err := VerifyFiles(bad, root); !errors.Is(err, errFileIndex) {
+		t.Errorf("dir index: %v", err)
+	}
+}